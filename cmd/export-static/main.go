@@ -0,0 +1,74 @@
+// Command export-static writes the public part of the blog as a static site
+// that can be hosted on a CDN without the server or the database.
+//
+// Usage:
+//
+//	go run ./cmd/export-static -out dist -base-url https://blog.example.com
+package main
+
+import (
+	"flag"
+	"log"
+	"path/filepath"
+
+	"github.com/joho/godotenv"
+	"github.com/yantology/go-gin-simple-blog-with-fts/internal/config"
+	"github.com/yantology/go-gin-simple-blog-with-fts/internal/services"
+	"github.com/yantology/go-gin-simple-blog-with-fts/internal/theme"
+	"github.com/yantology/go-gin-simple-blog-with-fts/internal/utils"
+	"github.com/yantology/go-gin-simple-blog-with-fts/pkg/repositories/articlesrepository"
+	"github.com/yantology/go-gin-simple-blog-with-fts/pkg/repositories/authrepository"
+	"github.com/yantology/go-gin-simple-blog-with-fts/pkg/repositories/mediarepository"
+	"github.com/yantology/go-gin-simple-blog-with-fts/pkg/services/articlesservices/postgresarticlesservices"
+	"github.com/yantology/go-gin-simple-blog-with-fts/pkg/services/authservices/postgresauthservices"
+	"github.com/yantology/go-gin-simple-blog-with-fts/pkg/services/mediaservices/postgresmediaservices"
+)
+
+func main() {
+	// Load environment variables
+	err := godotenv.Load()
+	if err != nil {
+		log.Fatal("Error loading .env file")
+	}
+
+	// Initialize configurations
+	config.InitConfig()
+
+	out := flag.String("out", "dist", "directory the site is written to")
+	baseURL := flag.String("base-url", config.SITE_URL(), "address the site will be served from")
+	copyAssets := flag.Bool("assets", config.MEDIA_STORAGE() == "local", "copy PUBLIC_ASSETS_DIR, where local uploads are stored, into the site")
+	flag.Parse()
+
+	// Initialize repositories and services
+	authRepo := authrepository.NewAuthRepository(postgresauthservices.NewPostgresAuthService(config.DB()))
+	mediaRepo := mediarepository.NewMediaRepository(postgresmediaservices.NewPostgresMediaService(config.DB()))
+	articlesRepo := articlesrepository.NewArticlesRepository(postgresarticlesservices.NewPostgresArticlesService(config.DB()))
+	articlesService := services.NewArticlesService(articlesRepo, authRepo, mediaRepo)
+
+	// The export is always the HTML frontend
+	links := utils.NewSiteLinks(*baseURL, true)
+	feedsService := services.NewFeedsService(articlesService, authRepo, links, config.SITE_TITLE(), config.SITE_DESCRIPTION(), config.FEED_ITEMS())
+	sitemapService := services.NewSitemapService(articlesRepo, links)
+
+	siteTheme, err := theme.Load(filepath.Join(config.PUBLIC_ASSETS_DIR(), "theme"), links)
+	if err != nil {
+		log.Fatal("Error loading theme: ", err)
+	}
+	exportService := services.NewStaticExportService(articlesService, feedsService, sitemapService, siteTheme, &theme.Site{
+		Title:       config.SITE_TITLE(),
+		Description: config.SITE_DESCRIPTION(),
+		Links:       links,
+	})
+
+	count, cuserr := exportService.Export(*out)
+	if cuserr != nil {
+		log.Fatalf("Error exporting site: %s: %s", cuserr.Error(), cuserr.OriginalMessage())
+	}
+	if *copyAssets {
+		if cuserr := exportService.CopyAssets(*out, config.PUBLIC_ASSETS_DIR(), config.PUBLIC_ROUTE()); cuserr != nil {
+			log.Fatalf("Error copying assets: %s: %s", cuserr.Error(), cuserr.OriginalMessage())
+		}
+	}
+
+	log.Printf("Exported %d articles to %s", count, *out)
+}
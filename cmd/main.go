@@ -1,7 +1,9 @@
 package main
 
 import (
+	"context"
 	"log"
+	"path/filepath"
 
 	"github.com/gin-gonic/gin"
 	"github.com/joho/godotenv"
@@ -9,11 +11,27 @@ import (
 	"github.com/yantology/go-gin-simple-blog-with-fts/internal/handlers"
 	"github.com/yantology/go-gin-simple-blog-with-fts/internal/middleware"
 	"github.com/yantology/go-gin-simple-blog-with-fts/internal/services"
+	"github.com/yantology/go-gin-simple-blog-with-fts/internal/theme"
 	"github.com/yantology/go-gin-simple-blog-with-fts/internal/utils"
+	"github.com/yantology/go-gin-simple-blog-with-fts/pkg/interfaces/mediainterface"
 	"github.com/yantology/go-gin-simple-blog-with-fts/pkg/repositories/articlesrepository"
 	"github.com/yantology/go-gin-simple-blog-with-fts/pkg/repositories/authrepository"
+	"github.com/yantology/go-gin-simple-blog-with-fts/pkg/repositories/commentsrepository"
+	"github.com/yantology/go-gin-simple-blog-with-fts/pkg/repositories/followsrepository"
+	"github.com/yantology/go-gin-simple-blog-with-fts/pkg/repositories/importsrepository"
+	"github.com/yantology/go-gin-simple-blog-with-fts/pkg/repositories/mediarepository"
+	"github.com/yantology/go-gin-simple-blog-with-fts/pkg/repositories/scrapingrepository"
 	"github.com/yantology/go-gin-simple-blog-with-fts/pkg/services/articlesservices/postgresarticlesservices"
 	"github.com/yantology/go-gin-simple-blog-with-fts/pkg/services/authservices/postgresauthservices"
+	"github.com/yantology/go-gin-simple-blog-with-fts/pkg/services/commentsservices/postgrescommentsservices"
+	"github.com/yantology/go-gin-simple-blog-with-fts/pkg/services/followsservices/postgresfollowsservices"
+	"github.com/yantology/go-gin-simple-blog-with-fts/pkg/services/importsservices/postgresimportsservices"
+	"github.com/yantology/go-gin-simple-blog-with-fts/pkg/services/mediaservices/localmediaservices"
+	"github.com/yantology/go-gin-simple-blog-with-fts/pkg/services/mediaservices/postgresmediaservices"
+	"github.com/yantology/go-gin-simple-blog-with-fts/pkg/services/mediaservices/s3mediaservices"
+	"github.com/yantology/go-gin-simple-blog-with-fts/pkg/services/scrapingservices/postgresscrapingservices"
+	"github.com/yantology/go-gin-simple-blog-with-fts/pkg/utils/extract"
+	"github.com/yantology/go-gin-simple-blog-with-fts/pkg/utils/fetch"
 
 	swaggerFiles "github.com/swaggo/files"
 	ginSwagger "github.com/swaggo/gin-swagger"
@@ -53,10 +71,95 @@ func main() {
 	authService := services.NewAuthService(authRepo, jwtUtil)
 	authHandler := handlers.NewAuthHandler(authService)
 
+	// Uploads go below PUBLIC_ASSETS_DIR unless an S3-compatible bucket is configured
+	var blobStore mediainterface.BlobStore = localmediaservices.NewLocalBlobStore(config.PUBLIC_ASSETS_DIR(), config.PUBLIC_ROUTE())
+	if config.MEDIA_STORAGE() == "s3" {
+		s3BlobStore, err := s3mediaservices.NewS3BlobStore(config.MEDIA_S3_ENDPOINT(), config.MEDIA_S3_REGION(), config.MEDIA_S3_BUCKET(),
+			config.MEDIA_S3_ACCESS_KEY(), config.MEDIA_S3_SECRET_KEY(), config.MEDIA_S3_PUBLIC_URL())
+		if err != nil {
+			log.Fatal("Error configuring S3 media storage: ", err)
+		}
+		blobStore = s3BlobStore
+	}
+	postgresMediaService := postgresmediaservices.NewPostgresMediaService(config.DB())
+	mediaRepo := mediarepository.NewMediaRepository(postgresMediaService)
+	mediaService := services.NewMediaService(mediaRepo, blobStore, config.MEDIA_MAX_UPLOAD_SIZE())
+	mediaHandler := handlers.NewMediaHandler(mediaService, config.MEDIA_MAX_UPLOAD_SIZE())
+
 	postgresArticlesService := postgresarticlesservices.NewPostgresArticlesService(config.DB())
 	articlesRepo := articlesrepository.NewArticlesRepository(postgresArticlesService)
-	articlesService := services.NewArticlesService(articlesRepo)
-	articlesHandler := handlers.NewArticlesHandler(articlesService)
+	articlesService := services.NewArticlesService(articlesRepo, authRepo, mediaRepo)
+	articlesHandler := handlers.NewArticlesHandler(articlesService, config.ARTICLES_REQUIRE_IF_MATCH())
+
+	// Scraping rules: built-in, then SCRAPE_RULES_FILE, then those set by admins
+	var fileRules []extract.Rule
+	if config.SCRAPE_RULES_FILE() != "" {
+		fileRules, err = extract.LoadRules(config.SCRAPE_RULES_FILE())
+		if err != nil {
+			log.Fatal("Error loading scraping rules: ", err)
+		}
+	}
+	postgresScrapingService := postgresscrapingservices.NewPostgresScrapingService(config.DB())
+	scrapingRepo := scrapingrepository.NewScrapingRepository(postgresScrapingService)
+	scrapingRulesService, cuserr := services.NewScrapingRulesService(scrapingRepo, fileRules)
+	if cuserr != nil {
+		log.Fatal("Error loading scraping rules: ", cuserr.OriginalMessage())
+	}
+	scrapingRulesHandler := handlers.NewScrapingRulesHandler(scrapingRulesService)
+	fetcher := fetch.New(fetch.Options{
+		UserAgent:       config.SCRAPE_USER_AGENT(),
+		Timeout:         config.SCRAPE_TIMEOUT(),
+		MaxBodySize:     config.SCRAPE_MAX_PAGE_SIZE(),
+		Retries:         config.SCRAPE_RETRIES(),
+		HostConcurrency: config.SCRAPE_HOST_CONCURRENCY(),
+		HostInterval:    config.SCRAPE_HOST_INTERVAL(),
+		AllowPrivate:    config.SCRAPE_ALLOW_PRIVATE(),
+	})
+	scraper := utils.NewScraper(fetcher, scrapingRulesService.Registry())
+	refreshIntervals, err := utils.NewRefreshIntervals(config.SCRAPE_REFRESH_INTERVAL(), config.SCRAPE_REFRESH_HOSTS())
+	if err != nil {
+		log.Fatal("Error configuring refresh intervals: ", err)
+	}
+	articlesService.SetRefreshIntervals(refreshIntervals)
+
+	postgresImportsService := postgresimportsservices.NewPostgresImportsService(config.DB())
+	importsRepo := importsrepository.NewImportsRepository(postgresImportsService)
+	importsService := services.NewImportsService(importsRepo, articlesService, scraper, config.ARTICLES_IMPORT_MAX_SIZE())
+	importsHandler := handlers.NewImportsHandler(importsService, config.ARTICLES_IMPORT_MAX_SIZE())
+
+	tagsService := services.NewTagsService(articlesRepo)
+	tagsHandler := handlers.NewTagsHandler(tagsService)
+
+	postgresCommentsService := postgrescommentsservices.NewPostgresCommentsService(config.DB())
+	commentsRepo := commentsrepository.NewCommentsRepository(postgresCommentsService)
+	commentsService := services.NewCommentsService(commentsRepo, articlesRepo)
+	commentsHandler := handlers.NewCommentsHandler(commentsService)
+
+	reactionsService := services.NewReactionsService(articlesRepo)
+	reactionsHandler := handlers.NewReactionsHandler(reactionsService)
+
+	postgresFollowsService := postgresfollowsservices.NewPostgresFollowsService(config.DB())
+	followsRepo := followsrepository.NewFollowsRepository(postgresFollowsService)
+	followsService := services.NewFollowsService(followsRepo, authRepo, articlesRepo)
+	followsHandler := handlers.NewFollowsHandler(followsService)
+
+	// Public addresses point at the HTML pages when the frontend is enabled, otherwise at the API
+	links := utils.NewSiteLinks(config.SITE_URL(), config.SITE_FRONTEND())
+
+	feedsService := services.NewFeedsService(articlesService, authRepo, links, config.SITE_TITLE(), config.SITE_DESCRIPTION(), config.FEED_ITEMS())
+	feedsHandler := handlers.NewFeedsHandler(feedsService)
+
+	sitemapService := services.NewSitemapService(articlesRepo, links)
+	sitemapHandler := handlers.NewSitemapHandler(sitemapService)
+	articlesService.OnArticlesChanged(sitemapService.Invalidate)
+
+	// Background jobs
+	if cuserr := importsService.FailInterruptedImports(); cuserr != nil {
+		log.Fatal("Error recovering imports: ", cuserr.OriginalMessage())
+	}
+	go importsService.RunInterruptedImportsCheck(context.Background())
+	go articlesService.RunTrashPurger(context.Background(), config.ARTICLES_TRASH_RETENTION(), config.ARTICLES_TRASH_PURGE_INTERVAL())
+	go articlesService.RunSourceRefresher(context.Background(), scraper, config.SCRAPE_REFRESH_POLL_INTERVAL())
 
 	// Initialize Gin router
 	router := gin.Default()
@@ -64,6 +167,37 @@ func main() {
 
 	router.Static(config.PUBLIC_ROUTE(), config.PUBLIC_ASSETS_DIR())
 
+	// Feeds, the format is taken from the extension
+	for _, format := range []string{"rss", "atom", "json"} {
+		router.GET("/feeds/articles."+format, feedsHandler.GetArticlesFeed)
+		router.GET("/users/:id/feed."+format, feedsHandler.GetUserFeed)
+	}
+	router.GET("/feeds/search", feedsHandler.GetSearchFeed)
+
+	router.GET("/sitemap.xml", sitemapHandler.GetSitemap)
+	router.GET("/sitemaps/:page", sitemapHandler.GetSitemapPage)
+	router.GET("/robots.txt", sitemapHandler.GetRobots)
+
+	// HTML frontend, a theme below PUBLIC_ASSETS_DIR/theme overrides the built-in one file by file
+	if config.SITE_FRONTEND() {
+		siteTheme, err := theme.Load(filepath.Join(config.PUBLIC_ASSETS_DIR(), "theme"), links)
+		if err != nil {
+			log.Fatal("Error loading theme: ", err)
+		}
+		frontendHandler := handlers.NewFrontendHandler(articlesService, siteTheme, &theme.Site{
+			Title:       config.SITE_TITLE(),
+			Description: config.SITE_DESCRIPTION(),
+			Links:       links,
+		})
+
+		router.StaticFS("/theme", siteTheme.Static())
+		router.GET("/", frontendHandler.Home)
+		router.GET("/articles/:slug", frontendHandler.GetArticle)
+		router.GET("/authors/:username", frontendHandler.GetAuthor)
+		router.GET("/tags/:slug", frontendHandler.GetTag)
+		router.GET("/search", frontendHandler.Search)
+	}
+
 	v1 := router.Group("/api/v1")
 	{
 		v1.POST("/register", authHandler.Register)
@@ -78,8 +212,31 @@ func main() {
 
 		v1.GET("/users/:id/articles", articlesHandler.GetArticlesByUserID)
 
+		v1.GET("/articles/by-slug/:slug", articlesHandler.GetArticleBySlug)
+
+		// :id holds the author's username, gin requires one name per path segment
+		v1.GET("/users/:id/articles/:slug", articlesHandler.GetArticleByAuthorSlug)
+
 		v1.GET("/articles/search", articlesHandler.SearchArticles)
 
+		v1.GET("/articles/:id/revisions", articlesHandler.GetArticleRevisions)
+
+		v1.GET("/articles/:id/revisions/:rev", articlesHandler.GetArticleRevision)
+
+		v1.GET("/articles/:id/revisions/:rev/diff", articlesHandler.DiffArticleRevision)
+
+		v1.GET("/tags", tagsHandler.GetTags)
+
+		v1.GET("/tags/:slug/articles", tagsHandler.GetArticlesByTag)
+
+		v1.GET("/media/:id", mediaHandler.GetMediaByID)
+
+		v1.GET("/articles/:id/comments", commentsHandler.GetArticleComments)
+
+		v1.GET("/users/:id/followers", followsHandler.GetFollowers)
+
+		v1.GET("/users/:id/following", followsHandler.GetFollowing)
+
 		// Protected Routes - Require Authorization Header
 		authMiddleware := middleware.AuthMiddleware(jwtUtil)
 		protected := v1.Group("/")
@@ -88,15 +245,78 @@ func main() {
 
 			protected.POST("/articles", articlesHandler.CreateArticle)
 
-			protected.POST("/articles/csv", articlesHandler.CreateArticlesWithCsv)
+			protected.POST("/articles/csv", importsHandler.CreateArticlesWithCsv)
 
 			protected.PUT("/articles/:id", articlesHandler.UpdateArticle)
 
+			protected.PATCH("/articles/:id", articlesHandler.PatchArticle)
+
 			protected.DELETE("/articles/:id", articlesHandler.DeleteArticleByID)
 
+			protected.POST("/articles/:id/revisions/:rev/restore", articlesHandler.RestoreArticleRevision)
+
+			protected.POST("/articles/:id/restore", articlesHandler.RestoreArticle)
+
+			protected.GET("/me/trash", articlesHandler.GetTrash)
+
+			protected.DELETE("/me/trash/:id", articlesHandler.PurgeArticle)
+
+			protected.POST("/media", mediaHandler.UploadMedia)
+
+			protected.DELETE("/media/:id", mediaHandler.DeleteMedia)
+
+			protected.POST("/articles/:id/comments", commentsHandler.CreateComment)
+
+			protected.PUT("/comments/:id", commentsHandler.UpdateComment)
+
+			protected.DELETE("/comments/:id", commentsHandler.DeleteComment)
+
+			protected.POST("/comments/:id/hide", commentsHandler.HideComment)
+
+			protected.POST("/comments/:id/unhide", commentsHandler.UnhideComment)
+
+			protected.GET("/articles/:id/reactions", reactionsHandler.GetReactions)
+
+			protected.PUT("/articles/:id/reactions/:kind", reactionsHandler.AddReaction)
+
+			protected.DELETE("/articles/:id/reactions/:kind", reactionsHandler.RemoveReaction)
+
+			protected.PUT("/articles/:id/bookmark", reactionsHandler.AddBookmark)
+
+			protected.DELETE("/articles/:id/bookmark", reactionsHandler.RemoveBookmark)
+
+			protected.GET("/me/bookmarks", reactionsHandler.GetBookmarks)
+
+			protected.POST("/users/:id/follow", followsHandler.Follow)
+
+			protected.DELETE("/users/:id/follow", followsHandler.Unfollow)
+
+			protected.GET("/me/feed", followsHandler.GetFeed)
+
+			protected.POST("/imports", importsHandler.CreateImport)
+
+			protected.GET("/imports/:id", importsHandler.GetImport)
+
+			protected.DELETE("/imports/:id", importsHandler.CancelImport)
+
+			protected.GET("/imports/:id/rows", importsHandler.GetImportRows)
+
+			protected.GET("/imports/:id/events", importsHandler.GetImportEvents)
+
 			protected.POST("/change-password", authHandler.ChangePassword)
 
 			protected.POST("/check-username", authHandler.CheckUsernameExists)
+
+			// Admin Routes - Require a user listed in ADMIN_USER_IDS
+			admin := protected.Group("/admin")
+			admin.Use(middleware.AdminMiddleware(config.ADMIN_USER_IDS()))
+			{
+				admin.GET("/scraping-rules", scrapingRulesHandler.GetScrapingRules)
+
+				admin.PUT("/scraping-rules/:host", scrapingRulesHandler.SaveScrapingRule)
+
+				admin.DELETE("/scraping-rules/:host", scrapingRulesHandler.DeleteScrapingRule)
+			}
 		}
 	}
 
@@ -0,0 +1,2 @@
+-- Incremented on every update and exposed as the article ETag
+ALTER TABLE articles ADD COLUMN version INTEGER NOT NULL DEFAULT 1;
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS articles_deleted_at_idx;
+
+ALTER TABLE articles DROP COLUMN IF EXISTS deleted_at;
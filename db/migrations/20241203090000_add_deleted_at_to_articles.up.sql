@@ -0,0 +1,4 @@
+-- Soft delete: trashed articles keep their row until purged
+ALTER TABLE articles ADD COLUMN deleted_at TIMESTAMP;
+
+CREATE INDEX articles_deleted_at_idx ON articles (deleted_at) WHERE deleted_at IS NOT NULL;
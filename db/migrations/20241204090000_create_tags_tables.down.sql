@@ -0,0 +1,22 @@
+-- Drop the article_tags trigger
+DROP TRIGGER IF EXISTS article_tags_tsvupdate ON article_tags;
+
+-- Drop the function
+DROP FUNCTION IF EXISTS article_tags_tsv_trigger();
+
+-- Restore the title and content only tsvector
+CREATE OR REPLACE FUNCTION articles_tsv_trigger() RETURNS TRIGGER AS $$
+BEGIN
+  NEW.tsv :=
+    setweight(to_tsvector('indonesian', coalesce(NEW.title, '')), 'A') ||
+    setweight(to_tsvector('indonesian', coalesce(NEW.content, '')), 'B');
+  RETURN NEW;
+END
+$$ LANGUAGE plpgsql;
+
+-- Drop the tables
+DROP TABLE IF EXISTS article_tags;
+DROP TABLE IF EXISTS tags;
+
+-- Rebuild the tsvector of existing articles
+UPDATE articles SET tsv = NULL;
@@ -0,0 +1,48 @@
+CREATE TABLE tags (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(50) NOT NULL,
+    slug VARCHAR(60) NOT NULL UNIQUE,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE article_tags (
+    article_id INTEGER NOT NULL REFERENCES articles(id) ON DELETE CASCADE,
+    tag_id INTEGER NOT NULL REFERENCES tags(id) ON DELETE CASCADE,
+    PRIMARY KEY (article_id, tag_id)
+);
+
+CREATE INDEX article_tags_tag_id_idx ON article_tags (tag_id);
+
+-- Index tag names between title and content so tag matches rank well
+CREATE OR REPLACE FUNCTION articles_tsv_trigger() RETURNS TRIGGER AS $$
+BEGIN
+  NEW.tsv :=
+    setweight(to_tsvector('indonesian', coalesce(NEW.title, '')), 'A') ||
+    setweight(to_tsvector('indonesian', coalesce((
+      SELECT string_agg(t.name, ' ')
+      FROM article_tags at
+      JOIN tags t ON t.id = at.tag_id
+      WHERE at.article_id = NEW.id
+    ), '')), 'B') ||
+    setweight(to_tsvector('indonesian', coalesce(NEW.content, '')), 'C');
+  RETURN NEW;
+END
+$$ LANGUAGE plpgsql;
+
+-- Tags change without touching the article row, so touch it to rebuild its tsvector
+CREATE FUNCTION article_tags_tsv_trigger() RETURNS TRIGGER AS $$
+BEGIN
+  IF TG_OP = 'DELETE' THEN
+    UPDATE articles SET tsv = NULL WHERE id = OLD.article_id;
+  ELSE
+    UPDATE articles SET tsv = NULL WHERE id = NEW.article_id;
+  END IF;
+  RETURN NULL;
+END
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER article_tags_tsvupdate AFTER INSERT OR DELETE
+ON article_tags FOR EACH ROW EXECUTE FUNCTION article_tags_tsv_trigger();
+
+-- Rebuild the tsvector of existing articles with the new weights
+UPDATE articles SET tsv = NULL;
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS article_slug_history;
+
+ALTER TABLE articles DROP COLUMN IF EXISTS slug;
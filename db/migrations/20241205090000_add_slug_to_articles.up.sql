@@ -0,0 +1,95 @@
+ALTER TABLE articles ADD COLUMN slug VARCHAR(100);
+
+-- article_slug_base makes the slug of a title like slug.MakeIndonesian, cut to
+-- the 80 characters the application keeps, for the backfill below
+CREATE FUNCTION article_slug_base(title TEXT) RETURNS TEXT AS $$
+DECLARE
+    s TEXT := lower(title);
+    pair TEXT[];
+BEGIN
+    -- Symbols spelled out in Indonesian, then letters written with two
+    FOREACH pair SLICE 1 IN ARRAY ARRAY[
+        ['&', '-dan-'], ['%', '-persen-'], ['+', '-plus-'], ['@', '-di-'],
+        ['Æ', 'ae'], ['Þ', 'th'], ['ß', 'ss'], ['æ', 'ae'], ['þ', 'th'], ['Œ', 'oe'], ['œ', 'oe'], ['Ǣ', 'ae'], ['ǣ', 'ae'], ['Ǽ', 'ae'], ['ǽ', 'ae'], ['ẞ', 'ss']
+    ] LOOP
+        s := replace(s, pair[1], pair[2]);
+    END LOOP;
+
+    -- Accented letters become their base letter; apostrophes and combining
+    -- accents, having no counterpart, are dropped
+    s := translate(s,
+        'ÀÁÂÃÄÅÇÈÉÊËÌÍÎÏÐÑÒÓÔÕÖØÙÚÛÜÝàáâãäåçèéêëìíîïðñòóôõöøùúûüýÿ' ||
+        'ĀāĂăĄąĆćĈĉĊċČčĎďĐđĒēĔĕĖėĘęĚěĜĝĞğĠġĢģĤĥĨĩĪīĬĭĮįİıĴĵĶķĹĺĻļĽľŁłŃńŅņ' ||
+        'ŇňŌōŎŏŐőŔŕŖŗŘřŚśŜŝŞşŠšŢţŤťŨũŪūŬŭŮůŰűŲųŴŵŶŷŸŹźŻżŽž' ||
+        'ƠơƯưǍǎǏǐǑǒǓǔǕǖǗǘǙǚǛǜǞǟǠǡǦǧǨǩǪǫǬǭǰǴǵǸǹǺǻǾǿȀȁȂȃȄȅȆȇȈȉȊȋȌȍȎȏȐȑȒȓȔȕȖ' ||
+        'ȗȘșȚțȞȟȦȧȨȩȪȫȬȭȮȯȰȱȲȳ' ||
+        'ḀḁḂḃḄḅḆḇḈḉḊḋḌḍḎḏḐḑḒḓḔḕḖḗḘḙḚḛḜḝḞḟḠḡḢḣḤḥḦḧḨḩḪḫḬḭḮḯḰḱḲḳḴḵḶḷḸḹḺḻḼḽḾḿ' ||
+        'ṀṁṂṃṄṅṆṇṈṉṊṋṌṍṎṏṐṑṒṓṔṕṖṗṘṙṚṛṜṝṞṟṠṡṢṣṤṥṦṧṨṩṪṫṬṭṮṯṰṱṲṳṴṵṶṷṸṹṺṻṼṽṾṿ' ||
+        'ẀẁẂẃẄẅẆẇẈẉẊẋẌẍẎẏẐẑẒẓẔẕẖẗẘẙẠạẢảẤấẦầẨẩẪẫẬậẮắẰằẲẳẴẵẶặẸẹẺẻẼẽẾếỀềỂểỄễ' ||
+        'ỆệỈỉỊịỌọỎỏỐốỒồỔổỖỗỘộỚớỜờỞởỠỡỢợỤụỦủỨứỪừỬửỮữỰựỲỳỴỵỶỷỸỹ' ||
+        '''’‘`' || (SELECT string_agg(chr(c), '') FROM generate_series(768, 879) c),
+        'aaaaaaceeeeiiiidnoooooouuuuyaaaaaaceeeeiiiidnoooooouuuuyy' ||
+        'aaaaaaccccccccddddeeeeeeeeeegggggggghhiiiiiiiiiijjkkllllllllnnnn' ||
+        'nnoooooorrrrrrssssssssttttuuuuuuuuuuuuwwyyyzzzzzz' ||
+        'oouuaaiioouuuuuuuuuuaaaaggkkoooojggnnaaooaaaaeeeeiiiioooorrrruuu' ||
+        'usstthhaaeeooooooooyy' ||
+        'aabbbbbbccddddddddddeeeeeeeeeeffgghhhhhhhhhhiiiikkkkkkllllllllmm' ||
+        'mmmmnnnnnnnnoooooooopppprrrrrrrrssssssssssttttttttuuuuuuuuuuvvvv' ||
+        'wwwwwwwwwwxxxxyyzzzzzzhtwyaaaaaaaaaaaaaaaaaaaaaaaaeeeeeeeeeeeeee' ||
+        'eeiiiioooooooooooooooooooooooouuuuuuuuuuuuuuyyyyyyyy');
+    s := trim(BOTH '-' FROM regexp_replace(s, '[^a-z0-9]+', '-', 'g'));
+
+    -- Cut at a word boundary
+    IF length(s) > 80 THEN
+        IF substr(s, 81, 1) <> '-' AND position('-' IN left(s, 80)) > 0 THEN
+            s := regexp_replace(left(s, 80), '-[^-]*$', '');
+        ELSE
+            s := left(s, 80);
+        END IF;
+        s := trim(BOTH '-' FROM s);
+    END IF;
+    RETURN COALESCE(NULLIF(s, ''), 'artikel');
+END;
+$$ LANGUAGE plpgsql IMMUTABLE;
+
+UPDATE articles SET slug = article_slug_base(title);
+CREATE INDEX articles_slug_backfill_idx ON articles (slug);
+
+-- The oldest article of each slug keeps it. The others take the first free
+-- numeric suffix, checked against every slug, including the ones articles
+-- got from titles such as "Judul 5".
+DO $$
+DECLARE
+    dup RECORD;
+    n INTEGER;
+BEGIN
+    FOR dup IN
+        SELECT id, slug FROM (
+            SELECT id, slug, ROW_NUMBER() OVER (PARTITION BY slug ORDER BY id) AS rn FROM articles
+        ) ranked
+        WHERE rn > 1
+        ORDER BY id
+    LOOP
+        n := 2;
+        WHILE EXISTS (SELECT 1 FROM articles WHERE slug = dup.slug || '-' || n) LOOP
+            n := n + 1;
+        END LOOP;
+        UPDATE articles SET slug = dup.slug || '-' || n WHERE id = dup.id;
+    END LOOP;
+END;
+$$;
+
+DROP INDEX articles_slug_backfill_idx;
+DROP FUNCTION article_slug_base(TEXT);
+
+ALTER TABLE articles ALTER COLUMN slug SET NOT NULL;
+ALTER TABLE articles ADD CONSTRAINT articles_slug_key UNIQUE (slug);
+
+-- Slugs an article had before its title changed, kept so old links redirect
+CREATE TABLE article_slug_history (
+    slug VARCHAR(100) PRIMARY KEY,
+    article_id INTEGER NOT NULL REFERENCES articles(id) ON DELETE CASCADE,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX article_slug_history_article_id_idx ON article_slug_history (article_id);
@@ -0,0 +1,6 @@
+ALTER TABLE article_revisions DROP COLUMN IF EXISTS format;
+
+ALTER TABLE articles
+    DROP COLUMN IF EXISTS excerpt,
+    DROP COLUMN IF EXISTS content_html,
+    DROP COLUMN IF EXISTS format;
@@ -0,0 +1,24 @@
+ALTER TABLE articles
+    ADD COLUMN format VARCHAR(10) NOT NULL DEFAULT 'plain'
+        CHECK (format IN ('markdown', 'html', 'plain')),
+    ADD COLUMN content_html TEXT NOT NULL DEFAULT '',
+    ADD COLUMN excerpt TEXT NOT NULL DEFAULT '';
+
+ALTER TABLE article_revisions
+    ADD COLUMN format VARCHAR(10) NOT NULL DEFAULT 'plain';
+
+-- Existing articles are plain text: escape them and split paragraphs on blank lines
+UPDATE articles SET
+    content_html = (
+        SELECT COALESCE(string_agg('<p>' || replace(trim(p), E'\n', '<br>') || '</p>' || E'\n', '' ORDER BY n), '')
+        FROM regexp_split_to_table(
+            replace(replace(replace(replace(content, E'\r\n', E'\n'), '&', '&amp;'), '<', '&lt;'), '>', '&gt;'),
+            E'\n\n'
+        ) WITH ORDINALITY AS paragraphs(p, n)
+        WHERE trim(p) <> ''
+    ),
+    excerpt = CASE
+        WHEN char_length(regexp_replace(trim(content), '\s+', ' ', 'g')) <= 200
+            THEN regexp_replace(trim(content), '\s+', ' ', 'g')
+        ELSE left(regexp_replace(trim(content), '\s+', ' ', 'g'), 199) || '…'
+    END;
@@ -0,0 +1,3 @@
+ALTER TABLE articles DROP COLUMN IF EXISTS cover_media_id;
+
+DROP TABLE IF EXISTS media;
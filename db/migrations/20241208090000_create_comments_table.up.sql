@@ -0,0 +1,16 @@
+CREATE TABLE comments (
+    id SERIAL PRIMARY KEY,
+    article_id INTEGER NOT NULL REFERENCES articles(id) ON DELETE CASCADE,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    -- parent_id is the comment replied to, root_id the top-level comment of the thread
+    parent_id INTEGER REFERENCES comments(id) ON DELETE CASCADE,
+    root_id INTEGER REFERENCES comments(id) ON DELETE CASCADE,
+    content TEXT NOT NULL,
+    hidden_at TIMESTAMP,
+    deleted_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX comments_article_roots_idx ON comments (article_id, created_at, id) WHERE parent_id IS NULL;
+CREATE INDEX comments_root_id_idx ON comments (root_id);
@@ -0,0 +1,7 @@
+DROP TABLE IF EXISTS bookmarks;
+
+DROP TRIGGER IF EXISTS article_reactions_count ON article_reactions;
+DROP FUNCTION IF EXISTS article_reaction_counts_trigger();
+
+DROP TABLE IF EXISTS article_reaction_counts;
+DROP TABLE IF EXISTS article_reactions;
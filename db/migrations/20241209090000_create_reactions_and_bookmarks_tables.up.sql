@@ -0,0 +1,41 @@
+CREATE TABLE article_reactions (
+    article_id INTEGER NOT NULL REFERENCES articles(id) ON DELETE CASCADE,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    kind VARCHAR(20) NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (article_id, user_id, kind)
+);
+
+-- Reaction totals per article, kept up to date by trigger so listings read counts without aggregating
+CREATE TABLE article_reaction_counts (
+    article_id INTEGER NOT NULL REFERENCES articles(id) ON DELETE CASCADE,
+    kind VARCHAR(20) NOT NULL,
+    count INTEGER NOT NULL DEFAULT 0,
+    PRIMARY KEY (article_id, kind)
+);
+
+CREATE FUNCTION article_reaction_counts_trigger() RETURNS TRIGGER AS $$
+BEGIN
+  IF TG_OP = 'INSERT' THEN
+    INSERT INTO article_reaction_counts (article_id, kind, count)
+    VALUES (NEW.article_id, NEW.kind, 1)
+    ON CONFLICT (article_id, kind) DO UPDATE SET count = article_reaction_counts.count + 1;
+  ELSE
+    UPDATE article_reaction_counts SET count = count - 1
+    WHERE article_id = OLD.article_id AND kind = OLD.kind;
+  END IF;
+  RETURN NULL;
+END
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER article_reactions_count AFTER INSERT OR DELETE
+ON article_reactions FOR EACH ROW EXECUTE FUNCTION article_reaction_counts_trigger();
+
+CREATE TABLE bookmarks (
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    article_id INTEGER NOT NULL REFERENCES articles(id) ON DELETE CASCADE,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (user_id, article_id)
+);
+
+CREATE INDEX bookmarks_user_created_idx ON bookmarks (user_id, created_at DESC);
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS articles_user_created_idx;
+
+DROP TABLE IF EXISTS follows;
@@ -0,0 +1,12 @@
+CREATE TABLE follows (
+    follower_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    followee_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (follower_id, followee_id),
+    CHECK (follower_id <> followee_id)
+);
+
+CREATE INDEX follows_followee_created_idx ON follows (followee_id, created_at DESC);
+
+-- Feed pages walk the articles of each followed author newest first
+CREATE INDEX articles_user_created_idx ON articles (user_id, created_at DESC, id DESC) WHERE deleted_at IS NULL;
@@ -0,0 +1,13 @@
+CREATE TABLE import_rows (
+    import_id INTEGER NOT NULL REFERENCES imports(id) ON DELETE CASCADE,
+    row_number INTEGER NOT NULL,
+    title TEXT NOT NULL,
+    url TEXT NOT NULL,
+    status VARCHAR(20) NOT NULL CHECK (status IN ('succeeded', 'failed', 'skipped')),
+    error TEXT,
+    article_id INTEGER REFERENCES articles(id) ON DELETE SET NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (import_id, row_number)
+);
+
+CREATE INDEX import_rows_status_idx ON import_rows (import_id, status, row_number);
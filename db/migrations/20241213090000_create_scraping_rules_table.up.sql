@@ -0,0 +1,10 @@
+-- Scraping rules managed through the admin endpoints, replacing the rules of
+-- the rules file and the built-in rules for the same host
+CREATE TABLE scraping_rules (
+    host VARCHAR(255) PRIMARY KEY,
+    title_selector TEXT NOT NULL DEFAULT '',
+    content_selector TEXT NOT NULL,
+    exclude_selectors TEXT[] NOT NULL DEFAULT '{}',
+    updated_by INTEGER REFERENCES users(id) ON DELETE SET NULL,
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS articles_user_source_url_idx;
+ALTER TABLE articles DROP COLUMN IF EXISTS content_hash;
+ALTER TABLE articles DROP COLUMN IF EXISTS source_url;
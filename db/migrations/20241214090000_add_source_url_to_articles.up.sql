@@ -0,0 +1,7 @@
+-- source_url is the page an imported article was scraped from, content_hash the
+-- SHA-256 of the scraped content, used to tell whether a re-import changes anything
+ALTER TABLE articles ADD COLUMN source_url TEXT;
+ALTER TABLE articles ADD COLUMN content_hash CHAR(64);
+
+-- A page is imported once per user, trashed articles included
+CREATE UNIQUE INDEX articles_user_source_url_idx ON articles (user_id, source_url) WHERE source_url IS NOT NULL;
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS imports_user_idempotency_key_idx;
+ALTER TABLE imports DROP COLUMN IF EXISTS request_hash;
+ALTER TABLE imports DROP COLUMN IF EXISTS idempotency_key;
+ALTER TABLE imports DROP COLUMN IF EXISTS mode;
@@ -0,0 +1,11 @@
+-- mode is what an import does with a URL imported before: skip it, update its
+-- article when the content changed, or create another article
+ALTER TABLE imports ADD COLUMN mode VARCHAR(20) NOT NULL DEFAULT 'skip'
+    CHECK (mode IN ('skip', 'update', 'duplicate'));
+
+-- idempotency_key is the Idempotency-Key header of the upload, request_hash the
+-- SHA-256 of the mode and file, so a retried upload returns the first import
+ALTER TABLE imports ADD COLUMN idempotency_key VARCHAR(255);
+ALTER TABLE imports ADD COLUMN request_hash CHAR(64);
+
+CREATE UNIQUE INDEX imports_user_idempotency_key_idx ON imports (user_id, idempotency_key) WHERE idempotency_key IS NOT NULL;
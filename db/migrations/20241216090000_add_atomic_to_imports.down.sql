@@ -0,0 +1 @@
+ALTER TABLE imports DROP COLUMN IF EXISTS atomic;
@@ -0,0 +1,3 @@
+-- atomic imports create all of their articles in one transaction at the end,
+-- or none of them when any row failed; the others keep every row that works
+ALTER TABLE imports ADD COLUMN atomic BOOLEAN NOT NULL DEFAULT FALSE;
@@ -0,0 +1,2 @@
+ALTER TABLE imports DROP COLUMN IF EXISTS refresh_interval;
+DROP TABLE IF EXISTS article_sources;
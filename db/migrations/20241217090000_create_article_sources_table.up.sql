@@ -0,0 +1,26 @@
+-- How imported articles follow the page they were scraped from. Once
+-- next_check_at is past the page is fetched again, asking only for a version
+-- newer than etag and last_modified, and the article is updated, its previous
+-- version kept as a revision, when the content hash changed.
+CREATE TABLE article_sources (
+    article_id INTEGER PRIMARY KEY REFERENCES articles(id) ON DELETE CASCADE,
+    -- minutes between two checks set by the import, NULL for the interval of the host
+    refresh_interval INTEGER,
+    -- the article took its title from the page, and follows its changes too
+    title_from_page BOOLEAN NOT NULL DEFAULT FALSE,
+    etag TEXT NOT NULL DEFAULT '',
+    last_modified TEXT NOT NULL DEFAULT '',
+    checked_at TIMESTAMP,
+    -- NULL when the page is not checked again
+    next_check_at TIMESTAMP,
+    changed_at TIMESTAMP,
+    -- checks failed in a row, and why the last one failed
+    failures INTEGER NOT NULL DEFAULT 0,
+    last_error TEXT
+);
+
+CREATE INDEX article_sources_next_check_at_idx ON article_sources (next_check_at) WHERE next_check_at IS NOT NULL;
+
+-- refresh_interval is the minutes between two checks of the pages of an
+-- import, NULL for the interval of each host
+ALTER TABLE imports ADD COLUMN refresh_interval INTEGER;
@@ -0,0 +1,17 @@
+CREATE OR REPLACE FUNCTION article_reaction_counts_trigger() RETURNS TRIGGER AS $$
+BEGIN
+  IF TG_OP = 'INSERT' THEN
+    INSERT INTO article_reaction_counts (article_id, kind, count)
+    VALUES (NEW.article_id, NEW.kind, 1)
+    ON CONFLICT (article_id, kind) DO UPDATE SET count = article_reaction_counts.count + 1;
+  ELSE
+    UPDATE article_reaction_counts SET count = count - 1
+    WHERE article_id = OLD.article_id AND kind = OLD.kind;
+  END IF;
+  RETURN NULL;
+END
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER tsvectorupdate ON articles;
+CREATE TRIGGER tsvectorupdate BEFORE INSERT OR UPDATE
+ON articles FOR EACH ROW EXECUTE FUNCTION articles_tsv_trigger();
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS imports_unfinished_lease_idx;
+ALTER TABLE imports DROP COLUMN IF EXISTS lease_expires_at;
+ALTER TABLE imports DROP COLUMN IF EXISTS owner;
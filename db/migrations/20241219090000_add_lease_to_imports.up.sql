@@ -0,0 +1,7 @@
+-- owner is the server process running the import. It renews lease_expires_at
+-- while the import is queued or running; an unfinished import whose lease ran
+-- out was left behind by a process that stopped, NULL for imports from before.
+ALTER TABLE imports ADD COLUMN owner VARCHAR(255);
+ALTER TABLE imports ADD COLUMN lease_expires_at TIMESTAMP;
+
+CREATE INDEX imports_unfinished_lease_idx ON imports (lease_expires_at) WHERE state IN ('queued', 'running');
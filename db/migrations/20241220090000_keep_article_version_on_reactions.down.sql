@@ -0,0 +1,19 @@
+DROP TRIGGER tsvectorupdate ON articles;
+CREATE TRIGGER tsvectorupdate BEFORE INSERT OR UPDATE OF title, content
+ON articles FOR EACH ROW EXECUTE FUNCTION articles_tsv_trigger();
+
+CREATE OR REPLACE FUNCTION article_reaction_counts_trigger() RETURNS TRIGGER AS $$
+BEGIN
+  IF TG_OP = 'INSERT' THEN
+    INSERT INTO article_reaction_counts (article_id, kind, count)
+    VALUES (NEW.article_id, NEW.kind, 1)
+    ON CONFLICT (article_id, kind) DO UPDATE SET count = article_reaction_counts.count + 1;
+    UPDATE articles SET version = version + 1 WHERE id = NEW.article_id;
+  ELSE
+    UPDATE article_reaction_counts SET count = count - 1
+    WHERE article_id = OLD.article_id AND kind = OLD.kind;
+    UPDATE articles SET version = version + 1 WHERE id = OLD.article_id;
+  END IF;
+  RETURN NULL;
+END
+$$ LANGUAGE plpgsql;
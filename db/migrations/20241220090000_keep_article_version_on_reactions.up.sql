@@ -0,0 +1,24 @@
+-- Reactions are not edits: they no longer bump the version an editor's
+-- If-Match is checked against, the ETag carries a hash of the counts instead.
+CREATE OR REPLACE FUNCTION article_reaction_counts_trigger() RETURNS TRIGGER AS $$
+BEGIN
+  IF TG_OP = 'INSERT' THEN
+    INSERT INTO article_reaction_counts (article_id, kind, count)
+    VALUES (NEW.article_id, NEW.kind, 1)
+    ON CONFLICT (article_id, kind) DO UPDATE SET count = article_reaction_counts.count + 1;
+  ELSE
+    UPDATE article_reaction_counts SET count = count - 1
+    WHERE article_id = OLD.article_id AND kind = OLD.kind;
+  END IF;
+  RETURN NULL;
+END
+$$ LANGUAGE plpgsql;
+
+-- article_tags_tsv_trigger rebuilds the search vector by setting tsv, so that
+-- column fires the trigger too
+DROP TRIGGER tsvectorupdate ON articles;
+CREATE TRIGGER tsvectorupdate BEFORE INSERT OR UPDATE OF title, content, tsv
+ON articles FOR EACH ROW EXECUTE FUNCTION articles_tsv_trigger();
+
+-- Rebuild the search vectors tag changes left empty meanwhile
+UPDATE articles SET tsv = NULL WHERE tsv IS NULL;
@@ -0,0 +1 @@
+ALTER TABLE article_sources DROP COLUMN IF EXISTS page_hash;
@@ -0,0 +1,4 @@
+-- page_hash is the SHA-256 of the title and content found on the page at the
+-- last check, so edits made to the article since do not read as page changes.
+-- NULL for sources checked before it was kept.
+ALTER TABLE article_sources ADD COLUMN page_hash CHAR(64);
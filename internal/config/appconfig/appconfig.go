@@ -3,6 +3,8 @@ package appconfig
 import (
 	"log"
 	"os"
+	"strconv"
+	"strings"
 )
 
 var PORT = ":8000" //string
@@ -11,10 +13,23 @@ var PORT = ":8000" //string
 var PUBLIC_ROUTE = "/public"
 var PUBLIC_ASSETS_DIR = "./public"
 
+// users allowed on the /admin routes, a comma separated list of user IDs
+var ADMIN_USER_IDS = []int{}
+
 func InitAppConfig() {
 	env_APP_PORT := os.Getenv("APP_PORT")
 	if env_APP_PORT != "" {
 		log.Println("APP_PORT => ", env_APP_PORT)
 		PORT = env_APP_PORT
 	}
+	env_ADMIN_USER_IDS := os.Getenv("ADMIN_USER_IDS")
+	if env_ADMIN_USER_IDS != "" {
+		ids := []int{}
+		for _, field := range strings.Split(env_ADMIN_USER_IDS, ",") {
+			if id, err := strconv.Atoi(strings.TrimSpace(field)); err == nil && id > 0 {
+				ids = append(ids, id)
+			}
+		}
+		ADMIN_USER_IDS = ids
+	}
 }
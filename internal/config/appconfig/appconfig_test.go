@@ -53,3 +53,43 @@ func TestInitAppConfig(t *testing.T) {
 		})
 	}
 }
+
+func TestInitAppConfigAdminUserIDs(t *testing.T) {
+	tests := []struct {
+		name     string
+		envValue string
+		wantIDs  []int
+	}{
+		{
+			name:     "Environment variable not set",
+			envValue: "",
+			wantIDs:  []int{},
+		},
+		{
+			name:     "List of IDs",
+			envValue: "1, 7,42",
+			wantIDs:  []int{1, 7, 42},
+		},
+		{
+			name:     "Invalid IDs are ignored",
+			envValue: "1,admin,-3,0",
+			wantIDs:  []int{1},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Backup original ADMIN_USER_IDS value and restore it after test
+			originalIDs := ADMIN_USER_IDS
+			defer func() {
+				ADMIN_USER_IDS = originalIDs
+			}()
+
+			t.Setenv("ADMIN_USER_IDS", tt.envValue)
+
+			InitAppConfig()
+
+			assert.Equal(t, tt.wantIDs, ADMIN_USER_IDS)
+		})
+	}
+}
@@ -0,0 +1,45 @@
+package articleconfig
+
+import (
+	"os"
+	"strconv"
+)
+
+// when true, PUT and DELETE on an article must send If-Match
+var ARTICLES_REQUIRE_IF_MATCH = false
+
+// trashed articles older than this are purged, 0 keeps them forever
+var ARTICLES_TRASH_RETENTION_DAYS = 30
+
+// minutes between two trash purges
+var ARTICLES_TRASH_PURGE_INTERVAL = 60
+
+// largest accepted import upload in megabytes
+var ARTICLES_IMPORT_MAX_SIZE_MB = 50
+
+func InitArticleConfig() {
+	env_ARTICLES_REQUIRE_IF_MATCH := os.Getenv("ARTICLES_REQUIRE_IF_MATCH")
+	if env_ARTICLES_REQUIRE_IF_MATCH != "" {
+		if required, err := strconv.ParseBool(env_ARTICLES_REQUIRE_IF_MATCH); err == nil {
+			ARTICLES_REQUIRE_IF_MATCH = required
+		}
+	}
+	env_ARTICLES_TRASH_RETENTION_DAYS := os.Getenv("ARTICLES_TRASH_RETENTION_DAYS")
+	if env_ARTICLES_TRASH_RETENTION_DAYS != "" {
+		if days, err := strconv.Atoi(env_ARTICLES_TRASH_RETENTION_DAYS); err == nil {
+			ARTICLES_TRASH_RETENTION_DAYS = days
+		}
+	}
+	env_ARTICLES_TRASH_PURGE_INTERVAL := os.Getenv("ARTICLES_TRASH_PURGE_INTERVAL")
+	if env_ARTICLES_TRASH_PURGE_INTERVAL != "" {
+		if interval, err := strconv.Atoi(env_ARTICLES_TRASH_PURGE_INTERVAL); err == nil {
+			ARTICLES_TRASH_PURGE_INTERVAL = interval
+		}
+	}
+	env_ARTICLES_IMPORT_MAX_SIZE_MB := os.Getenv("ARTICLES_IMPORT_MAX_SIZE_MB")
+	if env_ARTICLES_IMPORT_MAX_SIZE_MB != "" {
+		if size, err := strconv.Atoi(env_ARTICLES_IMPORT_MAX_SIZE_MB); err == nil && size > 0 {
+			ARTICLES_IMPORT_MAX_SIZE_MB = size
+		}
+	}
+}
@@ -0,0 +1,83 @@
+package articleconfig
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInitArticleConfig(t *testing.T) {
+	tests := []struct {
+		name                  string
+		envRequire            string
+		envRetention          string
+		envPurgeInterval      string
+		envImportMaxSize      string
+		expectedRequire       bool
+		expectedRetention     int
+		expectedPurgeInterval int
+		expectedImportMaxSize int
+	}{
+		{
+			name:                  "Default values",
+			envRequire:            "",
+			envRetention:          "",
+			envPurgeInterval:      "",
+			envImportMaxSize:      "",
+			expectedRequire:       false,
+			expectedRetention:     30,
+			expectedPurgeInterval: 60,
+			expectedImportMaxSize: 50,
+		},
+		{
+			name:                  "Environment variables set",
+			envRequire:            "true",
+			envRetention:          "7",
+			envPurgeInterval:      "15",
+			envImportMaxSize:      "200",
+			expectedRequire:       true,
+			expectedRetention:     7,
+			expectedPurgeInterval: 15,
+			expectedImportMaxSize: 200,
+		},
+		{
+			name:                  "Invalid values",
+			envRequire:            "sometimes",
+			envRetention:          "a week",
+			envPurgeInterval:      "hourly",
+			envImportMaxSize:      "0",
+			expectedRequire:       false,
+			expectedRetention:     30,
+			expectedPurgeInterval: 60,
+			expectedImportMaxSize: 50,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Backup original values and restore them after test
+			originalRequire := ARTICLES_REQUIRE_IF_MATCH
+			originalRetention := ARTICLES_TRASH_RETENTION_DAYS
+			originalPurgeInterval := ARTICLES_TRASH_PURGE_INTERVAL
+			originalImportMaxSize := ARTICLES_IMPORT_MAX_SIZE_MB
+			defer func() {
+				ARTICLES_REQUIRE_IF_MATCH = originalRequire
+				ARTICLES_TRASH_RETENTION_DAYS = originalRetention
+				ARTICLES_TRASH_PURGE_INTERVAL = originalPurgeInterval
+				ARTICLES_IMPORT_MAX_SIZE_MB = originalImportMaxSize
+			}()
+
+			t.Setenv("ARTICLES_REQUIRE_IF_MATCH", tt.envRequire)
+			t.Setenv("ARTICLES_TRASH_RETENTION_DAYS", tt.envRetention)
+			t.Setenv("ARTICLES_TRASH_PURGE_INTERVAL", tt.envPurgeInterval)
+			t.Setenv("ARTICLES_IMPORT_MAX_SIZE_MB", tt.envImportMaxSize)
+
+			InitArticleConfig()
+
+			assert.Equal(t, tt.expectedRequire, ARTICLES_REQUIRE_IF_MATCH)
+			assert.Equal(t, tt.expectedRetention, ARTICLES_TRASH_RETENTION_DAYS)
+			assert.Equal(t, tt.expectedPurgeInterval, ARTICLES_TRASH_PURGE_INTERVAL)
+			assert.Equal(t, tt.expectedImportMaxSize, ARTICLES_IMPORT_MAX_SIZE_MB)
+		})
+	}
+}
@@ -2,12 +2,17 @@ package config
 
 import (
 	"database/sql"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/yantology/go-gin-simple-blog-with-fts/internal/config/appconfig"
+	"github.com/yantology/go-gin-simple-blog-with-fts/internal/config/articleconfig"
 	"github.com/yantology/go-gin-simple-blog-with-fts/internal/config/corsconfig"
 	"github.com/yantology/go-gin-simple-blog-with-fts/internal/config/dbconfig"
 	"github.com/yantology/go-gin-simple-blog-with-fts/internal/config/jwtconfig"
+	"github.com/yantology/go-gin-simple-blog-with-fts/internal/config/mediaconfig"
+	"github.com/yantology/go-gin-simple-blog-with-fts/internal/config/scrapeconfig"
+	"github.com/yantology/go-gin-simple-blog-with-fts/internal/config/siteconfig"
 )
 
 func InitConfig() {
@@ -15,6 +20,10 @@ func InitConfig() {
 	dbconfig.InitDatabaseConfig()
 	dbconfig.ConnectDatabase(sql.Open)
 	jwtconfig.InitJWTConfig()
+	articleconfig.InitArticleConfig()
+	mediaconfig.InitMediaConfig()
+	siteconfig.InitSiteConfig()
+	scrapeconfig.InitScrapeConfig()
 }
 
 // variable appconfig
@@ -36,6 +45,10 @@ func PUBLIC_ASSETS_DIR() string {
 	return appconfig.PUBLIC_ASSETS_DIR
 }
 
+func ADMIN_USER_IDS() []int {
+	return appconfig.ADMIN_USER_IDS
+}
+
 // variable dbconfig
 
 func DB_DRIVER() string {
@@ -87,3 +100,123 @@ func JWT_ACCESS_TIMEOUT() int {
 func JWT_REFRESH_TIMEOUT() int {
 	return jwtconfig.JWT_REFRESH_TIMEOUT
 }
+
+// variable articleconfig
+func ARTICLES_REQUIRE_IF_MATCH() bool {
+	return articleconfig.ARTICLES_REQUIRE_IF_MATCH
+}
+
+func ARTICLES_TRASH_RETENTION() time.Duration {
+	return time.Duration(articleconfig.ARTICLES_TRASH_RETENTION_DAYS) * 24 * time.Hour
+}
+
+func ARTICLES_TRASH_PURGE_INTERVAL() time.Duration {
+	return time.Duration(articleconfig.ARTICLES_TRASH_PURGE_INTERVAL) * time.Minute
+}
+
+// ARTICLES_IMPORT_MAX_SIZE is the largest accepted import upload in bytes
+func ARTICLES_IMPORT_MAX_SIZE() int64 {
+	return int64(articleconfig.ARTICLES_IMPORT_MAX_SIZE_MB) << 20
+}
+
+// variable mediaconfig
+func MEDIA_STORAGE() string {
+	return mediaconfig.MEDIA_STORAGE
+}
+
+// MEDIA_MAX_UPLOAD_SIZE is the largest accepted upload in bytes
+func MEDIA_MAX_UPLOAD_SIZE() int64 {
+	return int64(mediaconfig.MEDIA_MAX_UPLOAD_SIZE_MB) << 20
+}
+
+func MEDIA_S3_ENDPOINT() string {
+	return mediaconfig.MEDIA_S3_ENDPOINT
+}
+
+func MEDIA_S3_REGION() string {
+	return mediaconfig.MEDIA_S3_REGION
+}
+
+func MEDIA_S3_BUCKET() string {
+	return mediaconfig.MEDIA_S3_BUCKET
+}
+
+func MEDIA_S3_ACCESS_KEY() string {
+	return mediaconfig.MEDIA_S3_ACCESS_KEY
+}
+
+func MEDIA_S3_SECRET_KEY() string {
+	return mediaconfig.MEDIA_S3_SECRET_KEY
+}
+
+func MEDIA_S3_PUBLIC_URL() string {
+	return mediaconfig.MEDIA_S3_PUBLIC_URL
+}
+
+// variable siteconfig
+func SITE_URL() string {
+	return siteconfig.SITE_URL
+}
+
+func SITE_TITLE() string {
+	return siteconfig.SITE_TITLE
+}
+
+func SITE_DESCRIPTION() string {
+	return siteconfig.SITE_DESCRIPTION
+}
+
+func FEED_ITEMS() int {
+	return siteconfig.FEED_ITEMS
+}
+
+func SITE_FRONTEND() bool {
+	return siteconfig.SITE_FRONTEND
+}
+
+// variable scrapeconfig
+func SCRAPE_RULES_FILE() string {
+	return scrapeconfig.SCRAPE_RULES_FILE
+}
+
+func SCRAPE_USER_AGENT() string {
+	return scrapeconfig.SCRAPE_USER_AGENT
+}
+
+func SCRAPE_TIMEOUT() time.Duration {
+	return time.Duration(scrapeconfig.SCRAPE_TIMEOUT) * time.Second
+}
+
+// SCRAPE_MAX_PAGE_SIZE is the largest page read in bytes
+func SCRAPE_MAX_PAGE_SIZE() int64 {
+	return int64(scrapeconfig.SCRAPE_MAX_PAGE_SIZE_MB) << 20
+}
+
+func SCRAPE_RETRIES() int {
+	return scrapeconfig.SCRAPE_RETRIES
+}
+
+func SCRAPE_HOST_CONCURRENCY() int {
+	return scrapeconfig.SCRAPE_HOST_CONCURRENCY
+}
+
+func SCRAPE_HOST_INTERVAL() time.Duration {
+	return time.Duration(scrapeconfig.SCRAPE_HOST_INTERVAL) * time.Millisecond
+}
+
+func SCRAPE_ALLOW_PRIVATE() bool {
+	return scrapeconfig.SCRAPE_ALLOW_PRIVATE
+}
+
+// SCRAPE_REFRESH_INTERVAL is in minutes, the unit of the host intervals of SCRAPE_REFRESH_HOSTS
+func SCRAPE_REFRESH_INTERVAL() int {
+	return scrapeconfig.SCRAPE_REFRESH_INTERVAL
+}
+
+func SCRAPE_REFRESH_HOSTS() string {
+	return scrapeconfig.SCRAPE_REFRESH_HOSTS
+}
+
+func SCRAPE_REFRESH_POLL_INTERVAL() time.Duration {
+	return time.Duration(scrapeconfig.SCRAPE_REFRESH_POLL_INTERVAL) * time.Minute
+}
@@ -0,0 +1,61 @@
+package mediaconfig
+
+import (
+	"log"
+	"os"
+	"strconv"
+)
+
+// where uploads are stored: "local" writes below PUBLIC_ASSETS_DIR, "s3" uses an S3-compatible bucket
+var MEDIA_STORAGE = "local"
+
+// largest accepted upload in megabytes
+var MEDIA_MAX_UPLOAD_SIZE_MB = 10
+
+// S3-compatible storage, e.g. https://s3.ap-southeast-1.amazonaws.com or a MinIO URL
+var MEDIA_S3_ENDPOINT = ""
+var MEDIA_S3_REGION = "us-east-1"
+var MEDIA_S3_BUCKET = ""
+var MEDIA_S3_ACCESS_KEY = ""
+var MEDIA_S3_SECRET_KEY = ""
+
+// base URL files are served from, defaults to <endpoint>/<bucket>
+var MEDIA_S3_PUBLIC_URL = ""
+
+func InitMediaConfig() {
+	env_MEDIA_STORAGE := os.Getenv("MEDIA_STORAGE")
+	if env_MEDIA_STORAGE != "" {
+		log.Println("MEDIA_STORAGE => ", env_MEDIA_STORAGE)
+		MEDIA_STORAGE = env_MEDIA_STORAGE
+	}
+	env_MEDIA_MAX_UPLOAD_SIZE_MB := os.Getenv("MEDIA_MAX_UPLOAD_SIZE_MB")
+	if env_MEDIA_MAX_UPLOAD_SIZE_MB != "" {
+		if size, err := strconv.Atoi(env_MEDIA_MAX_UPLOAD_SIZE_MB); err == nil && size > 0 {
+			MEDIA_MAX_UPLOAD_SIZE_MB = size
+		}
+	}
+	env_MEDIA_S3_ENDPOINT := os.Getenv("MEDIA_S3_ENDPOINT")
+	if env_MEDIA_S3_ENDPOINT != "" {
+		MEDIA_S3_ENDPOINT = env_MEDIA_S3_ENDPOINT
+	}
+	env_MEDIA_S3_REGION := os.Getenv("MEDIA_S3_REGION")
+	if env_MEDIA_S3_REGION != "" {
+		MEDIA_S3_REGION = env_MEDIA_S3_REGION
+	}
+	env_MEDIA_S3_BUCKET := os.Getenv("MEDIA_S3_BUCKET")
+	if env_MEDIA_S3_BUCKET != "" {
+		MEDIA_S3_BUCKET = env_MEDIA_S3_BUCKET
+	}
+	env_MEDIA_S3_ACCESS_KEY := os.Getenv("MEDIA_S3_ACCESS_KEY")
+	if env_MEDIA_S3_ACCESS_KEY != "" {
+		MEDIA_S3_ACCESS_KEY = env_MEDIA_S3_ACCESS_KEY
+	}
+	env_MEDIA_S3_SECRET_KEY := os.Getenv("MEDIA_S3_SECRET_KEY")
+	if env_MEDIA_S3_SECRET_KEY != "" {
+		MEDIA_S3_SECRET_KEY = env_MEDIA_S3_SECRET_KEY
+	}
+	env_MEDIA_S3_PUBLIC_URL := os.Getenv("MEDIA_S3_PUBLIC_URL")
+	if env_MEDIA_S3_PUBLIC_URL != "" {
+		MEDIA_S3_PUBLIC_URL = env_MEDIA_S3_PUBLIC_URL
+	}
+}
@@ -0,0 +1,71 @@
+package mediaconfig
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInitMediaConfig(t *testing.T) {
+	tests := []struct {
+		name            string
+		envStorage      string
+		envMaxSize      string
+		envBucket       string
+		expectedStorage string
+		expectedMaxSize int
+		expectedBucket  string
+	}{
+		{
+			name:            "Default values",
+			envStorage:      "",
+			envMaxSize:      "",
+			envBucket:       "",
+			expectedStorage: "local",
+			expectedMaxSize: 10,
+			expectedBucket:  "",
+		},
+		{
+			name:            "Environment variables set",
+			envStorage:      "s3",
+			envMaxSize:      "25",
+			envBucket:       "blog-media",
+			expectedStorage: "s3",
+			expectedMaxSize: 25,
+			expectedBucket:  "blog-media",
+		},
+		{
+			name:            "Invalid size",
+			envStorage:      "",
+			envMaxSize:      "-1",
+			envBucket:       "",
+			expectedStorage: "local",
+			expectedMaxSize: 10,
+			expectedBucket:  "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Backup original values and restore them after test
+			originalStorage := MEDIA_STORAGE
+			originalMaxSize := MEDIA_MAX_UPLOAD_SIZE_MB
+			originalBucket := MEDIA_S3_BUCKET
+			defer func() {
+				MEDIA_STORAGE = originalStorage
+				MEDIA_MAX_UPLOAD_SIZE_MB = originalMaxSize
+				MEDIA_S3_BUCKET = originalBucket
+			}()
+
+			t.Setenv("MEDIA_STORAGE", tt.envStorage)
+			t.Setenv("MEDIA_MAX_UPLOAD_SIZE_MB", tt.envMaxSize)
+			t.Setenv("MEDIA_S3_BUCKET", tt.envBucket)
+
+			InitMediaConfig()
+
+			assert.Equal(t, tt.expectedStorage, MEDIA_STORAGE)
+			assert.Equal(t, tt.expectedMaxSize, MEDIA_MAX_UPLOAD_SIZE_MB)
+			assert.Equal(t, tt.expectedBucket, MEDIA_S3_BUCKET)
+		})
+	}
+}
@@ -0,0 +1,104 @@
+package scrapeconfig
+
+import (
+	"log"
+	"os"
+	"strconv"
+)
+
+// YAML or JSON file of per-host scraping rules, empty for the built-in rules only
+var SCRAPE_RULES_FILE = ""
+
+// User-Agent sent when fetching pages, also the name looked up in robots.txt
+var SCRAPE_USER_AGENT = "SimpleBlogImporter/1.0"
+
+// seconds one attempt to fetch a page may take
+var SCRAPE_TIMEOUT = 15
+
+// largest page read in megabytes
+var SCRAPE_MAX_PAGE_SIZE_MB = 5
+
+// times a page failing with a network error, 429 or 5xx is fetched again
+var SCRAPE_RETRIES = 2
+
+// requests to one host running at once
+var SCRAPE_HOST_CONCURRENCY = 2
+
+// milliseconds between the start of two requests to one host
+var SCRAPE_HOST_INTERVAL = 1000
+
+// when true, pages on loopback and private addresses may be fetched, for intranet sources
+var SCRAPE_ALLOW_PRIVATE = false
+
+// minutes between two fetches of the page of an imported article, 0 never fetches it again
+var SCRAPE_REFRESH_INTERVAL = 1440
+
+// comma separated host=minutes overriding SCRAPE_REFRESH_INTERVAL for those hosts and their subdomains
+var SCRAPE_REFRESH_HOSTS = ""
+
+// minutes between two looks for imported articles due to be fetched again
+var SCRAPE_REFRESH_POLL_INTERVAL = 5
+
+func InitScrapeConfig() {
+	env_SCRAPE_RULES_FILE := os.Getenv("SCRAPE_RULES_FILE")
+	if env_SCRAPE_RULES_FILE != "" {
+		log.Println("SCRAPE_RULES_FILE => ", env_SCRAPE_RULES_FILE)
+		SCRAPE_RULES_FILE = env_SCRAPE_RULES_FILE
+	}
+	env_SCRAPE_USER_AGENT := os.Getenv("SCRAPE_USER_AGENT")
+	if env_SCRAPE_USER_AGENT != "" {
+		SCRAPE_USER_AGENT = env_SCRAPE_USER_AGENT
+	}
+	env_SCRAPE_TIMEOUT := os.Getenv("SCRAPE_TIMEOUT")
+	if env_SCRAPE_TIMEOUT != "" {
+		if seconds, err := strconv.Atoi(env_SCRAPE_TIMEOUT); err == nil && seconds > 0 {
+			SCRAPE_TIMEOUT = seconds
+		}
+	}
+	env_SCRAPE_MAX_PAGE_SIZE_MB := os.Getenv("SCRAPE_MAX_PAGE_SIZE_MB")
+	if env_SCRAPE_MAX_PAGE_SIZE_MB != "" {
+		if size, err := strconv.Atoi(env_SCRAPE_MAX_PAGE_SIZE_MB); err == nil && size > 0 {
+			SCRAPE_MAX_PAGE_SIZE_MB = size
+		}
+	}
+	env_SCRAPE_RETRIES := os.Getenv("SCRAPE_RETRIES")
+	if env_SCRAPE_RETRIES != "" {
+		if retries, err := strconv.Atoi(env_SCRAPE_RETRIES); err == nil && retries >= 0 {
+			SCRAPE_RETRIES = retries
+		}
+	}
+	env_SCRAPE_HOST_CONCURRENCY := os.Getenv("SCRAPE_HOST_CONCURRENCY")
+	if env_SCRAPE_HOST_CONCURRENCY != "" {
+		if concurrency, err := strconv.Atoi(env_SCRAPE_HOST_CONCURRENCY); err == nil && concurrency > 0 {
+			SCRAPE_HOST_CONCURRENCY = concurrency
+		}
+	}
+	env_SCRAPE_HOST_INTERVAL := os.Getenv("SCRAPE_HOST_INTERVAL")
+	if env_SCRAPE_HOST_INTERVAL != "" {
+		if interval, err := strconv.Atoi(env_SCRAPE_HOST_INTERVAL); err == nil && interval >= 0 {
+			SCRAPE_HOST_INTERVAL = interval
+		}
+	}
+	env_SCRAPE_ALLOW_PRIVATE := os.Getenv("SCRAPE_ALLOW_PRIVATE")
+	if env_SCRAPE_ALLOW_PRIVATE != "" {
+		if allowed, err := strconv.ParseBool(env_SCRAPE_ALLOW_PRIVATE); err == nil {
+			SCRAPE_ALLOW_PRIVATE = allowed
+		}
+	}
+	env_SCRAPE_REFRESH_INTERVAL := os.Getenv("SCRAPE_REFRESH_INTERVAL")
+	if env_SCRAPE_REFRESH_INTERVAL != "" {
+		if minutes, err := strconv.Atoi(env_SCRAPE_REFRESH_INTERVAL); err == nil && minutes >= 0 {
+			SCRAPE_REFRESH_INTERVAL = minutes
+		}
+	}
+	env_SCRAPE_REFRESH_HOSTS := os.Getenv("SCRAPE_REFRESH_HOSTS")
+	if env_SCRAPE_REFRESH_HOSTS != "" {
+		SCRAPE_REFRESH_HOSTS = env_SCRAPE_REFRESH_HOSTS
+	}
+	env_SCRAPE_REFRESH_POLL_INTERVAL := os.Getenv("SCRAPE_REFRESH_POLL_INTERVAL")
+	if env_SCRAPE_REFRESH_POLL_INTERVAL != "" {
+		if minutes, err := strconv.Atoi(env_SCRAPE_REFRESH_POLL_INTERVAL); err == nil && minutes > 0 {
+			SCRAPE_REFRESH_POLL_INTERVAL = minutes
+		}
+	}
+}
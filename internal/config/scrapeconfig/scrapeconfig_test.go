@@ -0,0 +1,153 @@
+package scrapeconfig
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInitScrapeConfig(t *testing.T) {
+	tests := []struct {
+		name                string
+		envRulesFile        string
+		envUserAgent        string
+		envTimeout          string
+		envMaxPageSize      string
+		envRetries          string
+		envHostConcurrency  string
+		envHostInterval     string
+		envAllowPrivate     string
+		envRefresh          string
+		envRefreshHosts     string
+		envRefreshPoll      string
+		wantRulesFile       string
+		wantUserAgent       string
+		wantTimeout         int
+		wantMaxPageSize     int
+		wantRetries         int
+		wantHostConcurrency int
+		wantHostInterval    int
+		wantAllowPrivate    bool
+		wantRefresh         int
+		wantRefreshHosts    string
+		wantRefreshPoll     int
+	}{
+		{
+			name:                "Default values",
+			wantRulesFile:       "",
+			wantUserAgent:       "SimpleBlogImporter/1.0",
+			wantTimeout:         15,
+			wantMaxPageSize:     5,
+			wantRetries:         2,
+			wantHostConcurrency: 2,
+			wantHostInterval:    1000,
+			wantAllowPrivate:    false,
+			wantRefresh:         1440,
+			wantRefreshHosts:    "",
+			wantRefreshPoll:     5,
+		},
+		{
+			name:                "Environment variables set",
+			envRulesFile:        "./scraping_rules.yaml",
+			envUserAgent:        "MyBlogBot/2.0 (+https://blog.example.com)",
+			envTimeout:          "30",
+			envMaxPageSize:      "2",
+			envRetries:          "0",
+			envHostConcurrency:  "4",
+			envHostInterval:     "0",
+			envAllowPrivate:     "true",
+			envRefresh:          "0",
+			envRefreshHosts:     "news.example.com=60",
+			envRefreshPoll:      "1",
+			wantRulesFile:       "./scraping_rules.yaml",
+			wantUserAgent:       "MyBlogBot/2.0 (+https://blog.example.com)",
+			wantTimeout:         30,
+			wantMaxPageSize:     2,
+			wantRetries:         0,
+			wantHostConcurrency: 4,
+			wantHostInterval:    0,
+			wantAllowPrivate:    true,
+			wantRefresh:         0,
+			wantRefreshHosts:    "news.example.com=60",
+			wantRefreshPoll:     1,
+		},
+		{
+			name:                "Invalid values",
+			envTimeout:          "0",
+			envMaxPageSize:      "-1",
+			envRetries:          "-1",
+			envHostConcurrency:  "0",
+			envHostInterval:     "soon",
+			envAllowPrivate:     "maybe",
+			envRefresh:          "-1",
+			envRefreshPoll:      "0",
+			wantRulesFile:       "",
+			wantUserAgent:       "SimpleBlogImporter/1.0",
+			wantTimeout:         15,
+			wantMaxPageSize:     5,
+			wantRetries:         2,
+			wantHostConcurrency: 2,
+			wantHostInterval:    1000,
+			wantAllowPrivate:    false,
+			wantRefresh:         1440,
+			wantRefreshHosts:    "",
+			wantRefreshPoll:     5,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Backup original values and restore them after test
+			originalRulesFile := SCRAPE_RULES_FILE
+			originalUserAgent := SCRAPE_USER_AGENT
+			originalTimeout := SCRAPE_TIMEOUT
+			originalMaxPageSize := SCRAPE_MAX_PAGE_SIZE_MB
+			originalRetries := SCRAPE_RETRIES
+			originalHostConcurrency := SCRAPE_HOST_CONCURRENCY
+			originalHostInterval := SCRAPE_HOST_INTERVAL
+			originalAllowPrivate := SCRAPE_ALLOW_PRIVATE
+			originalRefresh := SCRAPE_REFRESH_INTERVAL
+			originalRefreshHosts := SCRAPE_REFRESH_HOSTS
+			originalRefreshPoll := SCRAPE_REFRESH_POLL_INTERVAL
+			defer func() {
+				SCRAPE_RULES_FILE = originalRulesFile
+				SCRAPE_USER_AGENT = originalUserAgent
+				SCRAPE_TIMEOUT = originalTimeout
+				SCRAPE_MAX_PAGE_SIZE_MB = originalMaxPageSize
+				SCRAPE_RETRIES = originalRetries
+				SCRAPE_HOST_CONCURRENCY = originalHostConcurrency
+				SCRAPE_HOST_INTERVAL = originalHostInterval
+				SCRAPE_ALLOW_PRIVATE = originalAllowPrivate
+				SCRAPE_REFRESH_INTERVAL = originalRefresh
+				SCRAPE_REFRESH_HOSTS = originalRefreshHosts
+				SCRAPE_REFRESH_POLL_INTERVAL = originalRefreshPoll
+			}()
+
+			t.Setenv("SCRAPE_RULES_FILE", tt.envRulesFile)
+			t.Setenv("SCRAPE_USER_AGENT", tt.envUserAgent)
+			t.Setenv("SCRAPE_TIMEOUT", tt.envTimeout)
+			t.Setenv("SCRAPE_MAX_PAGE_SIZE_MB", tt.envMaxPageSize)
+			t.Setenv("SCRAPE_RETRIES", tt.envRetries)
+			t.Setenv("SCRAPE_HOST_CONCURRENCY", tt.envHostConcurrency)
+			t.Setenv("SCRAPE_HOST_INTERVAL", tt.envHostInterval)
+			t.Setenv("SCRAPE_ALLOW_PRIVATE", tt.envAllowPrivate)
+			t.Setenv("SCRAPE_REFRESH_INTERVAL", tt.envRefresh)
+			t.Setenv("SCRAPE_REFRESH_HOSTS", tt.envRefreshHosts)
+			t.Setenv("SCRAPE_REFRESH_POLL_INTERVAL", tt.envRefreshPoll)
+
+			InitScrapeConfig()
+
+			assert.Equal(t, tt.wantRulesFile, SCRAPE_RULES_FILE)
+			assert.Equal(t, tt.wantUserAgent, SCRAPE_USER_AGENT)
+			assert.Equal(t, tt.wantTimeout, SCRAPE_TIMEOUT)
+			assert.Equal(t, tt.wantMaxPageSize, SCRAPE_MAX_PAGE_SIZE_MB)
+			assert.Equal(t, tt.wantRetries, SCRAPE_RETRIES)
+			assert.Equal(t, tt.wantHostConcurrency, SCRAPE_HOST_CONCURRENCY)
+			assert.Equal(t, tt.wantHostInterval, SCRAPE_HOST_INTERVAL)
+			assert.Equal(t, tt.wantAllowPrivate, SCRAPE_ALLOW_PRIVATE)
+			assert.Equal(t, tt.wantRefresh, SCRAPE_REFRESH_INTERVAL)
+			assert.Equal(t, tt.wantRefreshHosts, SCRAPE_REFRESH_HOSTS)
+			assert.Equal(t, tt.wantRefreshPoll, SCRAPE_REFRESH_POLL_INTERVAL)
+		})
+	}
+}
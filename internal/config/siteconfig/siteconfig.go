@@ -0,0 +1,48 @@
+package siteconfig
+
+import (
+	"log"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// public address of the blog, used for absolute links in feeds
+var SITE_URL = "http://localhost:8000"
+
+var SITE_TITLE = "Simple Blog"
+var SITE_DESCRIPTION = "Latest articles"
+
+// number of articles in a feed
+var FEED_ITEMS = 50
+
+// when true, the HTML blog is served at / and links point to its pages instead of the API
+var SITE_FRONTEND = false
+
+func InitSiteConfig() {
+	env_SITE_URL := os.Getenv("SITE_URL")
+	if env_SITE_URL != "" {
+		log.Println("SITE_URL => ", env_SITE_URL)
+		SITE_URL = strings.TrimRight(env_SITE_URL, "/")
+	}
+	env_SITE_TITLE := os.Getenv("SITE_TITLE")
+	if env_SITE_TITLE != "" {
+		SITE_TITLE = env_SITE_TITLE
+	}
+	env_SITE_DESCRIPTION := os.Getenv("SITE_DESCRIPTION")
+	if env_SITE_DESCRIPTION != "" {
+		SITE_DESCRIPTION = env_SITE_DESCRIPTION
+	}
+	env_SITE_FRONTEND := os.Getenv("SITE_FRONTEND")
+	if env_SITE_FRONTEND != "" {
+		if enabled, err := strconv.ParseBool(env_SITE_FRONTEND); err == nil {
+			SITE_FRONTEND = enabled
+		}
+	}
+	env_FEED_ITEMS := os.Getenv("FEED_ITEMS")
+	if env_FEED_ITEMS != "" {
+		if items, err := strconv.Atoi(env_FEED_ITEMS); err == nil && items > 0 {
+			FEED_ITEMS = items
+		}
+	}
+}
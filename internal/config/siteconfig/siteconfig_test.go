@@ -0,0 +1,81 @@
+package siteconfig
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInitSiteConfig(t *testing.T) {
+	tests := []struct {
+		name             string
+		envURL           string
+		envTitle         string
+		envItems         string
+		envFrontend      string
+		expectedURL      string
+		expectedTitle    string
+		expectedItems    int
+		expectedFrontend bool
+	}{
+		{
+			name:          "Default values",
+			envURL:        "",
+			envTitle:      "",
+			envItems:      "",
+			envFrontend:   "",
+			expectedURL:   "http://localhost:8000",
+			expectedTitle: "Simple Blog",
+			expectedItems: 50,
+		},
+		{
+			name:             "Environment variables set",
+			envURL:           "https://blog.example.com/",
+			envTitle:         "Catatan Budi",
+			envItems:         "20",
+			envFrontend:      "true",
+			expectedURL:      "https://blog.example.com",
+			expectedTitle:    "Catatan Budi",
+			expectedItems:    20,
+			expectedFrontend: true,
+		},
+		{
+			name:          "Invalid item count",
+			envURL:        "",
+			envTitle:      "",
+			envItems:      "0",
+			envFrontend:   "maybe",
+			expectedURL:   "http://localhost:8000",
+			expectedTitle: "Simple Blog",
+			expectedItems: 50,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Backup original values and restore them after test
+			originalURL := SITE_URL
+			originalTitle := SITE_TITLE
+			originalItems := FEED_ITEMS
+			originalFrontend := SITE_FRONTEND
+			defer func() {
+				SITE_URL = originalURL
+				SITE_TITLE = originalTitle
+				FEED_ITEMS = originalItems
+				SITE_FRONTEND = originalFrontend
+			}()
+
+			t.Setenv("SITE_URL", tt.envURL)
+			t.Setenv("SITE_TITLE", tt.envTitle)
+			t.Setenv("FEED_ITEMS", tt.envItems)
+			t.Setenv("SITE_FRONTEND", tt.envFrontend)
+
+			InitSiteConfig()
+
+			assert.Equal(t, tt.expectedURL, SITE_URL)
+			assert.Equal(t, tt.expectedTitle, SITE_TITLE)
+			assert.Equal(t, tt.expectedItems, FEED_ITEMS)
+			assert.Equal(t, tt.expectedFrontend, SITE_FRONTEND)
+		})
+	}
+}
@@ -3,23 +3,70 @@ package handlers
 import (
 	"log"
 	"net/http"
+	"path"
+	"slices"
 	"strconv"
+	"strings"
 
 	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
 	"github.com/yantology/go-gin-simple-blog-with-fts/internal/models"
 	"github.com/yantology/go-gin-simple-blog-with-fts/internal/services"
+	"github.com/yantology/go-gin-simple-blog-with-fts/internal/utils"
+	"github.com/yantology/go-gin-simple-blog-with-fts/pkg/utils/slug"
 )
 
 type ArticlesHandler struct {
 	articleService *services.ArticlesService
+	requireIfMatch bool
 }
 
-func NewArticlesHandler(articleService *services.ArticlesService) *ArticlesHandler {
+// NewArticlesHandler creates an ArticlesHandler.
+// When requireIfMatch is true, writes without an If-Match header are rejected with 428.
+func NewArticlesHandler(articleService *services.ArticlesService, requireIfMatch bool) *ArticlesHandler {
 	return &ArticlesHandler{
 		articleService: articleService,
+		requireIfMatch: requireIfMatch,
 	}
 }
 
+// ifMatchVersions reads the If-Match header into the article versions it accepts.
+// It returns nil when there is no precondition to check, and false after writing
+// an error response when the header is required but missing.
+func (h *ArticlesHandler) ifMatchVersions(c *gin.Context) ([]int, bool) {
+	header := c.GetHeader("If-Match")
+	if header == "" {
+		if h.requireIfMatch {
+			c.JSON(http.StatusPreconditionRequired, models.NewMessage("If-Match header is required"))
+			return nil, false
+		}
+		return nil, true
+	}
+
+	tags := utils.ParseETags(header)
+	if slices.Contains(tags, "*") {
+		return nil, true
+	}
+	return utils.ETagVersions(tags), true
+}
+
+// tagsQuery reads the comma separated tags query parameter as tag slugs.
+// It returns nil when the parameter is absent so no tag filter is applied.
+func tagsQuery(c *gin.Context) []string {
+	value := c.Query("tags")
+	if value == "" {
+		return nil
+	}
+
+	tags := []string{}
+	for _, name := range strings.Split(value, ",") {
+		if tagSlug := slug.Make(name, 0); tagSlug != "" {
+			tags = append(tags, tagSlug)
+		}
+	}
+	return tags
+}
+
 // CreateArticle creates a new article.
 // @Summary Create a new article
 // @Description Create a new article
@@ -30,6 +77,9 @@ func NewArticlesHandler(articleService *services.ArticlesService) *ArticlesHandl
 // @Param article body models.ArticleRequest false "Article Request"
 // @Param title formData string false "Title"
 // @Param content formData string false "Content"
+// @Param format formData string false "Content format" Enums(markdown, html, plain)
+// @Param cover_media_id formData int false "Cover image media ID"
+// @Param tags formData []string false "Tags" collectionFormat(multi)
 // @Success 200 {object} models.Message
 // @Failure 400 {object} models.Message
 // @Failure 401 {object} models.Message
@@ -58,68 +108,110 @@ func (h *ArticlesHandler) CreateArticle(c *gin.Context) {
 	c.JSON(200, models.NewMessage("article created successfully"))
 }
 
-// CreateArticlesWithCsv godoc
-// @Summary Create articles with CSV
-// @Description Create multiple articles by uploading a CSV file
+// GetArticleByID retrieves an article by its ID.
+// @Summary Get an article by ID
+// @Description Get an article by ID. The response carries the article version, with a hash of its reaction counts, as a strong ETag; If-Match only compares the version.
 // @Tags articles
-// @Accept multipart/form-data
 // @Produce json
-// @Param file formData file true "file"
-// @Success 200 {object} models.Message "articles created successfully"
-// @Failure 400 {object} models.Message "file upload failed"
-// @Failure 401 {object} models.Message "user not authenticated"
-// @Failure 500 {object} models.Message "internal server error"
-// @Router /articles/csv [post]
-// @Security ApiKeyAuth
-func (h *ArticlesHandler) CreateArticlesWithCsv(c *gin.Context) {
-	log.Println("CreateArticlesWithCsv")
-	userID, exists := c.Get("user_id")
-	if !exists {
-		c.JSON(http.StatusUnauthorized, models.NewMessage("user not authenticated"))
-		return
-	}
-
-	file, err := c.FormFile("file")
+// @Param id path int true "Article ID"
+// @Param If-None-Match header string false "ETag from a previous response"
+// @Success 200 {object} models.ArticleResponse
+// @Success 304 "Not Modified"
+// @Failure 400 {object} models.Message
+// @Failure 404 {object} models.Message
+// @Failure 500 {object} models.Message
+// @Router /articles/{id} [get]
+func (h *ArticlesHandler) GetArticleByID(c *gin.Context) {
+	id := c.Param("id")
+	articleID, err := strconv.Atoi(id)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, models.NewMessage("file upload failed"))
+		c.JSON(400, models.NewMessage("invalid article ID"))
 		return
 	}
 
-	cuserr := h.articleService.CreateArticlesWithCsv(userID.(int), file)
+	article, cuserr := h.articleService.GetArticleByID(articleID)
 	if cuserr != nil {
 		c.JSON(cuserr.HTTPCode, models.NewMessage(cuserr.Error()))
 		return
 	}
 
-	c.JSON(200, models.NewMessage("articles created successfully"))
+	writeArticle(c, article)
 }
 
-// GetArticleByID retrieves an article by its ID.
-// @Summary Get an article by ID
-// @Description Get an article by ID
+// writeArticle sends an article with its version and reactions as ETag, or 304 when the client's copy is current
+func writeArticle(c *gin.Context, article *models.ArticleResponse) {
+	etag := utils.ArticleETag(article.Version, article.Reactions)
+	c.Header("ETag", etag)
+	if utils.IfNoneMatch(c.GetHeader("If-None-Match"), etag) {
+		c.Status(http.StatusNotModified)
+		return
+	}
+
+	c.JSON(200, article)
+}
+
+// redirectToCurrentSlug sends a permanent redirect when an article was requested by an old slug.
+// It reports whether a redirect was written.
+func redirectToCurrentSlug(c *gin.Context, article *models.ArticleResponse) bool {
+	if c.Param("slug") == article.Slug {
+		return false
+	}
+
+	c.Redirect(http.StatusMovedPermanently, path.Join(path.Dir(c.Request.URL.Path), article.Slug))
+	return true
+}
+
+// GetArticleBySlug retrieves an article by its slug.
+// @Summary Get an article by slug
+// @Description Get an article by its slug. Slugs the article had before a title change redirect to the current one.
 // @Tags articles
 // @Produce json
-// @Param id path int true "Article ID"
+// @Param slug path string true "Article slug"
+// @Param If-None-Match header string false "ETag from a previous response"
 // @Success 200 {object} models.ArticleResponse
-// @Failure 400 {object} models.Message
+// @Success 301 "Moved Permanently to the current slug"
+// @Success 304 "Not Modified"
 // @Failure 404 {object} models.Message
 // @Failure 500 {object} models.Message
-// @Router /articles/{id} [get]
-func (h *ArticlesHandler) GetArticleByID(c *gin.Context) {
-	id := c.Param("id")
-	articleID, err := strconv.Atoi(id)
-	if err != nil {
-		c.JSON(400, models.NewMessage("invalid article ID"))
+// @Router /articles/by-slug/{slug} [get]
+func (h *ArticlesHandler) GetArticleBySlug(c *gin.Context) {
+	article, cuserr := h.articleService.GetArticleBySlug(c.Param("slug"))
+	if cuserr != nil {
+		c.JSON(cuserr.HTTPCode, models.NewMessage(cuserr.Error()))
 		return
 	}
 
-	article, cuserr := h.articleService.GetArticleByID(articleID)
+	if redirectToCurrentSlug(c, article) {
+		return
+	}
+	writeArticle(c, article)
+}
+
+// GetArticleByAuthorSlug retrieves an article by its author's username and its slug.
+// @Summary Get an article by author and slug
+// @Description Get an article by its author's username and its slug. Slugs the article had before a title change redirect to the current one.
+// @Tags articles
+// @Produce json
+// @Param id path string true "Author username"
+// @Param slug path string true "Article slug"
+// @Param If-None-Match header string false "ETag from a previous response"
+// @Success 200 {object} models.ArticleResponse
+// @Success 301 "Moved Permanently to the current slug"
+// @Success 304 "Not Modified"
+// @Failure 404 {object} models.Message
+// @Failure 500 {object} models.Message
+// @Router /users/{id}/articles/{slug} [get]
+func (h *ArticlesHandler) GetArticleByAuthorSlug(c *gin.Context) {
+	article, cuserr := h.articleService.GetArticleByAuthorSlug(c.Param("id"), c.Param("slug"))
 	if cuserr != nil {
 		c.JSON(cuserr.HTTPCode, models.NewMessage(cuserr.Error()))
 		return
 	}
 
-	c.JSON(200, article)
+	if redirectToCurrentSlug(c, article) {
+		return
+	}
+	writeArticle(c, article)
 }
 
 // GetArticlesByUserID retrieves all articles created by a specific user.
@@ -151,16 +243,17 @@ func (h *ArticlesHandler) GetArticlesByUserID(c *gin.Context) {
 
 // GetAllArticles retrieves all available articles.
 // @Summary Get all articles
-// @Description Get all articles
+// @Description Get all articles, optionally only those carrying every given tag
 // @Tags articles
 // @Produce json
+// @Param tags query string false "Comma separated tag slugs"
 // @Success 200 {object} models.ArticlesResponse
 // @Failure 400 {object} models.Message
 // @Failure 404 {object} models.Message
 // @Failure 500 {object} models.Message
 // @Router /articles [get]
 func (h *ArticlesHandler) GetAllArticles(c *gin.Context) {
-	articles, cuserr := h.articleService.GetAllArticles()
+	articles, cuserr := h.articleService.GetAllArticles(tagsQuery(c))
 	if cuserr != nil {
 		c.JSON(cuserr.HTTPCode, models.NewMessage(cuserr.Error()))
 		return
@@ -177,6 +270,7 @@ func (h *ArticlesHandler) GetAllArticles(c *gin.Context) {
 // @Param query query string true "Search Query"
 // @Param limit query int false "Limit"
 // @Param offset query int false "Offset"
+// @Param tags query string false "Comma separated tag slugs"
 // @Success 200 {array} models.ArticleResponse
 // @Failure 400 {object} models.Message
 // @Failure 404 {object} models.Message
@@ -198,7 +292,7 @@ func (h *ArticlesHandler) SearchArticles(c *gin.Context) {
 
 	query := c.Query("query")
 	log.Printf("SearchArticles query: %s", query)
-	articles, cuserr := h.articleService.SearchArticles(limit, offset, query)
+	articles, cuserr := h.articleService.SearchArticles(limit, offset, query, tagsQuery(c))
 	if cuserr != nil {
 		c.JSON(cuserr.HTTPCode, models.NewMessage(cuserr.Error()))
 		return
@@ -215,13 +309,19 @@ func (h *ArticlesHandler) SearchArticles(c *gin.Context) {
 // @Accept x-www-form-urlencoded
 // @Produce json
 // @Param id path int true "Article ID"
+// @Param If-Match header string false "ETag of the version being edited"
 // @Param article body models.ArticleRequest false "Article Request"
 // @Param title formData string false "Title"
 // @Param content formData string flase "Content"
+// @Param format formData string false "Content format" Enums(markdown, html, plain)
+// @Param cover_media_id formData int false "Cover image media ID"
+// @Param tags formData []string false "Tags" collectionFormat(multi)
 // @Success 200 {object} models.Message
 // @Failure 400 {object} models.Message
 // @Failure 401 {object} models.Message
 // @Failure 404 {object} models.Message
+// @Failure 412 {object} models.Message
+// @Failure 428 {object} models.Message
 // @Failure 500 {object} models.Message
 // @Router /articles/{id} [put]
 // @Security ApiKeyAuth
@@ -244,7 +344,12 @@ func (h *ArticlesHandler) UpdateArticle(c *gin.Context) {
 		return
 	}
 
-	cuserr := h.articleService.UpdateArticle(userID.(int), articleID, &req)
+	ifMatch, ok := h.ifMatchVersions(c)
+	if !ok {
+		return
+	}
+
+	cuserr := h.articleService.UpdateArticle(userID.(int), articleID, &req, ifMatch)
 	if cuserr != nil {
 		c.JSON(cuserr.HTTPCode, models.NewMessage(cuserr.Error()))
 		return
@@ -253,16 +358,86 @@ func (h *ArticlesHandler) UpdateArticle(c *gin.Context) {
 	c.JSON(200, models.NewMessage("article updated successfully"))
 }
 
-// DeleteArticleByID removes an article by its ID.
+// PatchArticle partially updates an existing article.
+// @Summary Partially update an article
+// @Description Update only the fields present in a JSON Merge Patch (RFC 7396) document
+// @Tags articles
+// @Accept application/merge-patch+json
+// @Produce json
+// @Param id path int true "Article ID"
+// @Param If-Match header string false "ETag of the version being edited"
+// @Param article body models.ArticlePatchRequest true "Merge patch document"
+// @Success 200 {object} models.Message
+// @Failure 400 {object} models.Message
+// @Failure 401 {object} models.Message
+// @Failure 403 {object} models.Message
+// @Failure 404 {object} models.Message
+// @Failure 412 {object} models.Message
+// @Failure 415 {object} models.Message
+// @Failure 428 {object} models.Message
+// @Failure 500 {object} models.Message
+// @Router /articles/{id} [patch]
+// @Security ApiKeyAuth
+func (h *ArticlesHandler) PatchArticle(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, models.NewMessage("user not authenticated"))
+		return
+	}
+	articleID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(400, models.NewMessage("invalid article ID"))
+		return
+	}
+
+	if contentType := c.ContentType(); contentType != "application/merge-patch+json" && contentType != binding.MIMEJSON {
+		c.JSON(http.StatusUnsupportedMediaType, models.NewMessage("content type must be application/merge-patch+json"))
+		return
+	}
+
+	body, err := c.GetRawData()
+	if err != nil {
+		c.JSON(400, models.NewMessage(err.Error()))
+		return
+	}
+
+	req, err := models.DecodeArticlePatch(body)
+	if err != nil {
+		c.JSON(400, models.NewMessage(err.Error()))
+		return
+	}
+	if err := binding.Validator.ValidateStruct(req); err != nil {
+		c.JSON(400, models.NewMessage(err.Error()))
+		return
+	}
+
+	ifMatch, ok := h.ifMatchVersions(c)
+	if !ok {
+		return
+	}
+
+	cuserr := h.articleService.PatchArticle(userID.(int), articleID, req, ifMatch)
+	if cuserr != nil {
+		c.JSON(cuserr.HTTPCode, models.NewMessage(cuserr.Error()))
+		return
+	}
+
+	c.JSON(200, models.NewMessage("article updated successfully"))
+}
+
+// DeleteArticleByID moves an article to the trash by its ID.
 // @Summary Delete an article by ID
-// @Description Delete an article by ID
+// @Description Move an article to the trash. It can be restored until it is purged.
 // @Tags articles
 // @Produce json
 // @Param id path int true "Article ID"
+// @Param If-Match header string false "ETag of the version being deleted"
 // @Success 200 {object} models.Message
 // @Failure 400 {object} models.Message
 // @Failure 401 {object} models.Message
 // @Failure 404 {object} models.Message
+// @Failure 412 {object} models.Message
+// @Failure 428 {object} models.Message
 // @Failure 500 {object} models.Message
 // @Router /articles/{id} [delete]
 // @Security ApiKeyAuth
@@ -281,7 +456,12 @@ func (h *ArticlesHandler) DeleteArticleByID(c *gin.Context) {
 		return
 	}
 
-	cuserr := h.articleService.DeleteArticleByID(userID.(int), articleID)
+	ifMatch, ok := h.ifMatchVersions(c)
+	if !ok {
+		return
+	}
+
+	cuserr := h.articleService.DeleteArticleByID(userID.(int), articleID, ifMatch)
 	if cuserr != nil {
 		c.JSON(cuserr.HTTPCode, models.NewMessage(cuserr.Error()))
 		return
@@ -289,3 +469,249 @@ func (h *ArticlesHandler) DeleteArticleByID(c *gin.Context) {
 
 	c.JSON(200, models.NewMessage("article deleted successfully"))
 }
+
+// GetArticleRevisions lists the stored revisions of an article.
+// @Summary Get article revisions
+// @Description List previous versions of an article, newest first
+// @Tags articles
+// @Produce json
+// @Param id path int true "Article ID"
+// @Success 200 {object} models.ArticleRevisionsResponse
+// @Failure 400 {object} models.Message
+// @Failure 404 {object} models.Message
+// @Failure 500 {object} models.Message
+// @Router /articles/{id}/revisions [get]
+func (h *ArticlesHandler) GetArticleRevisions(c *gin.Context) {
+	articleID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(400, models.NewMessage("invalid article ID"))
+		return
+	}
+
+	revisions, cuserr := h.articleService.GetArticleRevisions(articleID)
+	if cuserr != nil {
+		c.JSON(cuserr.HTTPCode, models.NewMessage(cuserr.Error()))
+		return
+	}
+
+	c.JSON(200, revisions)
+}
+
+// GetArticleRevision retrieves a single revision of an article.
+// @Summary Get an article revision
+// @Description Get the title and content of an article as it was at a given revision
+// @Tags articles
+// @Produce json
+// @Param id path int true "Article ID"
+// @Param rev path int true "Revision number"
+// @Success 200 {object} models.ArticleRevisionResponse
+// @Failure 400 {object} models.Message
+// @Failure 404 {object} models.Message
+// @Failure 500 {object} models.Message
+// @Router /articles/{id}/revisions/{rev} [get]
+func (h *ArticlesHandler) GetArticleRevision(c *gin.Context) {
+	articleID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(400, models.NewMessage("invalid article ID"))
+		return
+	}
+
+	revision, err := strconv.Atoi(c.Param("rev"))
+	if err != nil {
+		c.JSON(400, models.NewMessage("invalid revision"))
+		return
+	}
+
+	rev, cuserr := h.articleService.GetArticleRevision(articleID, revision)
+	if cuserr != nil {
+		c.JSON(cuserr.HTTPCode, models.NewMessage(cuserr.Error()))
+		return
+	}
+
+	c.JSON(200, rev)
+}
+
+// DiffArticleRevision compares a revision with another revision or the current article.
+// @Summary Diff article revisions
+// @Description Line-level diff from a revision to another revision, or to the current article when against is omitted
+// @Tags articles
+// @Produce json
+// @Param id path int true "Article ID"
+// @Param rev path int true "Revision number"
+// @Param against query string false "Revision number to compare with, or current" default(current)
+// @Success 200 {object} models.ArticleRevisionDiffResponse
+// @Failure 400 {object} models.Message
+// @Failure 404 {object} models.Message
+// @Failure 500 {object} models.Message
+// @Router /articles/{id}/revisions/{rev}/diff [get]
+func (h *ArticlesHandler) DiffArticleRevision(c *gin.Context) {
+	articleID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(400, models.NewMessage("invalid article ID"))
+		return
+	}
+
+	revision, err := strconv.Atoi(c.Param("rev"))
+	if err != nil {
+		c.JSON(400, models.NewMessage("invalid revision"))
+		return
+	}
+
+	against := 0
+	if value := c.DefaultQuery("against", "current"); value != "current" {
+		against, err = strconv.Atoi(value)
+		if err != nil || against <= 0 {
+			c.JSON(400, models.NewMessage("invalid against revision"))
+			return
+		}
+	}
+
+	diff, cuserr := h.articleService.DiffArticleRevisions(articleID, revision, against)
+	if cuserr != nil {
+		c.JSON(cuserr.HTTPCode, models.NewMessage(cuserr.Error()))
+		return
+	}
+
+	c.JSON(200, diff)
+}
+
+// RestoreArticleRevision restores an article to a previous revision.
+// @Summary Restore an article revision
+// @Description Replace the article title and content with those of a revision. The replaced version is kept as a new revision.
+// @Tags articles
+// @Produce json
+// @Param id path int true "Article ID"
+// @Param rev path int true "Revision number"
+// @Success 200 {object} models.Message
+// @Failure 400 {object} models.Message
+// @Failure 401 {object} models.Message
+// @Failure 403 {object} models.Message
+// @Failure 404 {object} models.Message
+// @Failure 500 {object} models.Message
+// @Router /articles/{id}/revisions/{rev}/restore [post]
+// @Security ApiKeyAuth
+func (h *ArticlesHandler) RestoreArticleRevision(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, models.NewMessage("user not authenticated"))
+		return
+	}
+
+	articleID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(400, models.NewMessage("invalid article ID"))
+		return
+	}
+
+	revision, err := strconv.Atoi(c.Param("rev"))
+	if err != nil {
+		c.JSON(400, models.NewMessage("invalid revision"))
+		return
+	}
+
+	cuserr := h.articleService.RestoreArticleRevision(userID.(int), articleID, revision)
+	if cuserr != nil {
+		c.JSON(cuserr.HTTPCode, models.NewMessage(cuserr.Error()))
+		return
+	}
+
+	c.JSON(200, models.NewMessage("article restored successfully"))
+}
+
+// GetTrash lists the articles the current user has deleted.
+// @Summary Get trashed articles
+// @Description List the current user's deleted articles, most recently deleted first
+// @Tags articles
+// @Produce json
+// @Success 200 {object} models.ArticlesResponse
+// @Failure 401 {object} models.Message
+// @Failure 500 {object} models.Message
+// @Router /me/trash [get]
+// @Security ApiKeyAuth
+func (h *ArticlesHandler) GetTrash(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, models.NewMessage("user not authenticated"))
+		return
+	}
+
+	articles, cuserr := h.articleService.GetTrash(userID.(int))
+	if cuserr != nil {
+		c.JSON(cuserr.HTTPCode, models.NewMessage(cuserr.Error()))
+		return
+	}
+
+	c.JSON(200, articles)
+}
+
+// RestoreArticle takes an article out of the trash.
+// @Summary Restore a deleted article
+// @Description Restore an article from the trash
+// @Tags articles
+// @Produce json
+// @Param id path int true "Article ID"
+// @Success 200 {object} models.Message
+// @Failure 400 {object} models.Message
+// @Failure 401 {object} models.Message
+// @Failure 403 {object} models.Message
+// @Failure 404 {object} models.Message
+// @Failure 500 {object} models.Message
+// @Router /articles/{id}/restore [post]
+// @Security ApiKeyAuth
+func (h *ArticlesHandler) RestoreArticle(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, models.NewMessage("user not authenticated"))
+		return
+	}
+
+	articleID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(400, models.NewMessage("invalid article ID"))
+		return
+	}
+
+	cuserr := h.articleService.RestoreArticle(userID.(int), articleID)
+	if cuserr != nil {
+		c.JSON(cuserr.HTTPCode, models.NewMessage(cuserr.Error()))
+		return
+	}
+
+	c.JSON(200, models.NewMessage("article restored successfully"))
+}
+
+// PurgeArticle permanently deletes an article from the trash.
+// @Summary Permanently delete an article
+// @Description Permanently delete an article that is in the trash, together with its revisions
+// @Tags articles
+// @Produce json
+// @Param id path int true "Article ID"
+// @Success 200 {object} models.Message
+// @Failure 400 {object} models.Message
+// @Failure 401 {object} models.Message
+// @Failure 403 {object} models.Message
+// @Failure 404 {object} models.Message
+// @Failure 500 {object} models.Message
+// @Router /me/trash/{id} [delete]
+// @Security ApiKeyAuth
+func (h *ArticlesHandler) PurgeArticle(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, models.NewMessage("user not authenticated"))
+		return
+	}
+
+	articleID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(400, models.NewMessage("invalid article ID"))
+		return
+	}
+
+	cuserr := h.articleService.PurgeArticle(userID.(int), articleID)
+	if cuserr != nil {
+		c.JSON(cuserr.HTTPCode, models.NewMessage(cuserr.Error()))
+		return
+	}
+
+	c.JSON(200, models.NewMessage("article permanently deleted"))
+}
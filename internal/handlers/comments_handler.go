@@ -0,0 +1,243 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yantology/go-gin-simple-blog-with-fts/internal/models"
+	"github.com/yantology/go-gin-simple-blog-with-fts/internal/services"
+)
+
+type CommentsHandler struct {
+	commentsService *services.CommentsService
+}
+
+func NewCommentsHandler(commentsService *services.CommentsService) *CommentsHandler {
+	return &CommentsHandler{
+		commentsService: commentsService,
+	}
+}
+
+// GetArticleComments lists the comments of an article.
+// @Summary Get article comments
+// @Description Get a page of top-level comments of an article, oldest first, with their replies nested. Hidden and deleted comments are returned without content so threads stay intact.
+// @Tags comments
+// @Produce json
+// @Param id path int true "Article ID"
+// @Param limit query int false "Top-level comments per page, at most 100" default(20)
+// @Param offset query int false "Top-level comments to skip" default(0)
+// @Success 200 {object} models.CommentsResponse
+// @Failure 400 {object} models.Message
+// @Failure 404 {object} models.Message
+// @Failure 500 {object} models.Message
+// @Router /articles/{id}/comments [get]
+func (h *CommentsHandler) GetArticleComments(c *gin.Context) {
+	articleID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(400, models.NewMessage("invalid article ID"))
+		return
+	}
+
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	if err != nil {
+		c.JSON(400, models.NewMessage("invalid limit"))
+		return
+	}
+
+	offset, err := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	if err != nil {
+		c.JSON(400, models.NewMessage("invalid offset"))
+		return
+	}
+
+	comments, cuserr := h.commentsService.GetArticleComments(articleID, limit, offset)
+	if cuserr != nil {
+		c.JSON(cuserr.HTTPCode, models.NewMessage(cuserr.Error()))
+		return
+	}
+
+	c.JSON(200, comments)
+}
+
+// CreateComment comments on an article.
+// @Summary Comment on an article
+// @Description Comment on an article, or reply to a comment by setting parent_id
+// @Tags comments
+// @Accept json
+// @Produce json
+// @Param id path int true "Article ID"
+// @Param comment body models.CommentRequest true "Comment Request"
+// @Success 201 {object} models.CommentResponse
+// @Failure 400 {object} models.Message
+// @Failure 401 {object} models.Message
+// @Failure 404 {object} models.Message
+// @Failure 500 {object} models.Message
+// @Router /articles/{id}/comments [post]
+// @Security ApiKeyAuth
+func (h *CommentsHandler) CreateComment(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, models.NewMessage("user not authenticated"))
+		return
+	}
+
+	articleID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(400, models.NewMessage("invalid article ID"))
+		return
+	}
+
+	var req models.CommentRequest
+	if err := c.ShouldBind(&req); err != nil {
+		c.JSON(400, models.NewMessage(err.Error()))
+		return
+	}
+
+	comment, cuserr := h.commentsService.CreateComment(userID.(int), articleID, &req)
+	if cuserr != nil {
+		c.JSON(cuserr.HTTPCode, models.NewMessage(cuserr.Error()))
+		return
+	}
+
+	c.JSON(http.StatusCreated, comment)
+}
+
+// UpdateComment edits a comment.
+// @Summary Update a comment
+// @Description Update the content of one of your own comments
+// @Tags comments
+// @Accept json
+// @Produce json
+// @Param id path int true "Comment ID"
+// @Param comment body models.CommentUpdateRequest true "Comment Update Request"
+// @Success 200 {object} models.Message
+// @Failure 400 {object} models.Message
+// @Failure 401 {object} models.Message
+// @Failure 403 {object} models.Message
+// @Failure 404 {object} models.Message
+// @Failure 500 {object} models.Message
+// @Router /comments/{id} [put]
+// @Security ApiKeyAuth
+func (h *CommentsHandler) UpdateComment(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, models.NewMessage("user not authenticated"))
+		return
+	}
+
+	commentID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(400, models.NewMessage("invalid comment ID"))
+		return
+	}
+
+	var req models.CommentUpdateRequest
+	if err := c.ShouldBind(&req); err != nil {
+		c.JSON(400, models.NewMessage(err.Error()))
+		return
+	}
+
+	if cuserr := h.commentsService.UpdateComment(userID.(int), commentID, &req); cuserr != nil {
+		c.JSON(cuserr.HTTPCode, models.NewMessage(cuserr.Error()))
+		return
+	}
+
+	c.JSON(200, models.NewMessage("comment updated successfully"))
+}
+
+// DeleteComment deletes a comment.
+// @Summary Delete a comment
+// @Description Delete one of your own comments. Replies to it stay visible.
+// @Tags comments
+// @Produce json
+// @Param id path int true "Comment ID"
+// @Success 200 {object} models.Message
+// @Failure 400 {object} models.Message
+// @Failure 401 {object} models.Message
+// @Failure 403 {object} models.Message
+// @Failure 404 {object} models.Message
+// @Failure 500 {object} models.Message
+// @Router /comments/{id} [delete]
+// @Security ApiKeyAuth
+func (h *CommentsHandler) DeleteComment(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, models.NewMessage("user not authenticated"))
+		return
+	}
+
+	commentID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(400, models.NewMessage("invalid comment ID"))
+		return
+	}
+
+	if cuserr := h.commentsService.DeleteComment(userID.(int), commentID); cuserr != nil {
+		c.JSON(cuserr.HTTPCode, models.NewMessage(cuserr.Error()))
+		return
+	}
+
+	c.JSON(200, models.NewMessage("comment deleted successfully"))
+}
+
+// HideComment hides a comment on your article.
+// @Summary Hide a comment
+// @Description Hide a comment on one of your own articles from readers
+// @Tags comments
+// @Produce json
+// @Param id path int true "Comment ID"
+// @Success 200 {object} models.Message
+// @Failure 400 {object} models.Message
+// @Failure 401 {object} models.Message
+// @Failure 403 {object} models.Message
+// @Failure 404 {object} models.Message
+// @Failure 500 {object} models.Message
+// @Router /comments/{id}/hide [post]
+// @Security ApiKeyAuth
+func (h *CommentsHandler) HideComment(c *gin.Context) {
+	h.setCommentHidden(c, true)
+}
+
+// UnhideComment shows a hidden comment on your article again.
+// @Summary Unhide a comment
+// @Description Show a comment you hid on one of your own articles again
+// @Tags comments
+// @Produce json
+// @Param id path int true "Comment ID"
+// @Success 200 {object} models.Message
+// @Failure 400 {object} models.Message
+// @Failure 401 {object} models.Message
+// @Failure 403 {object} models.Message
+// @Failure 404 {object} models.Message
+// @Failure 500 {object} models.Message
+// @Router /comments/{id}/unhide [post]
+// @Security ApiKeyAuth
+func (h *CommentsHandler) UnhideComment(c *gin.Context) {
+	h.setCommentHidden(c, false)
+}
+
+func (h *CommentsHandler) setCommentHidden(c *gin.Context, hidden bool) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, models.NewMessage("user not authenticated"))
+		return
+	}
+
+	commentID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(400, models.NewMessage("invalid comment ID"))
+		return
+	}
+
+	if cuserr := h.commentsService.SetCommentHidden(userID.(int), commentID, hidden); cuserr != nil {
+		c.JSON(cuserr.HTTPCode, models.NewMessage(cuserr.Error()))
+		return
+	}
+
+	if hidden {
+		c.JSON(200, models.NewMessage("comment hidden successfully"))
+		return
+	}
+	c.JSON(200, models.NewMessage("comment shown successfully"))
+}
@@ -0,0 +1,110 @@
+package handlers
+
+import (
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yantology/go-gin-simple-blog-with-fts/internal/models"
+	"github.com/yantology/go-gin-simple-blog-with-fts/internal/services"
+	"github.com/yantology/go-gin-simple-blog-with-fts/internal/utils"
+	"github.com/yantology/go-gin-simple-blog-with-fts/pkg/utils/feed"
+)
+
+// FeedsHandler serves feeds for feed readers. Its routes live outside /api/v1
+// so subscriptions keep working across API versions.
+type FeedsHandler struct {
+	feedsService *services.FeedsService
+}
+
+func NewFeedsHandler(feedsService *services.FeedsService) *FeedsHandler {
+	return &FeedsHandler{
+		feedsService: feedsService,
+	}
+}
+
+// routeFormat reads the feed format from the extension of the matched route, e.g. "rss" for /feeds/articles.rss
+func routeFormat(c *gin.Context) string {
+	return strings.TrimPrefix(path.Ext(c.FullPath()), ".")
+}
+
+// GetArticlesFeed serves the newest articles of the blog.
+// Routes: GET /feeds/articles.rss, /feeds/articles.atom and /feeds/articles.json
+func (h *FeedsHandler) GetArticlesFeed(c *gin.Context) {
+	format := routeFormat(c)
+	f, cuserr := h.feedsService.GetArticlesFeed(format)
+	if cuserr != nil {
+		c.JSON(cuserr.HTTPCode, models.NewMessage(cuserr.Error()))
+		return
+	}
+
+	writeFeed(c, f, format)
+}
+
+// GetUserFeed serves the newest articles of one author.
+// Routes: GET /users/:id/feed.rss, /users/:id/feed.atom and /users/:id/feed.json
+func (h *FeedsHandler) GetUserFeed(c *gin.Context) {
+	userID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(400, models.NewMessage("invalid user ID"))
+		return
+	}
+
+	format := routeFormat(c)
+	f, cuserr := h.feedsService.GetUserFeed(userID, format)
+	if cuserr != nil {
+		c.JSON(cuserr.HTTPCode, models.NewMessage(cuserr.Error()))
+		return
+	}
+
+	writeFeed(c, f, format)
+}
+
+// GetSearchFeed serves the full-text search results for ?query=, in the format
+// given by ?format= (rss, atom or json, rss by default).
+// Route: GET /feeds/search
+func (h *FeedsHandler) GetSearchFeed(c *gin.Context) {
+	format := c.DefaultQuery("format", feed.FormatRSS)
+	if !feed.IsValidFormat(format) {
+		c.JSON(400, models.NewMessage("format must be one of: rss, atom, json"))
+		return
+	}
+
+	f, cuserr := h.feedsService.GetSearchFeed(c.Query("query"), format)
+	if cuserr != nil {
+		c.JSON(cuserr.HTTPCode, models.NewMessage(cuserr.Error()))
+		return
+	}
+
+	writeFeed(c, f, format)
+}
+
+// writeFeed renders a feed with validators readers can poll with. The ETag is a
+// hash of the body, so it also changes when an article leaves the feed, which
+// Last-Modified alone cannot express. If-None-Match takes precedence over
+// If-Modified-Since as RFC 9110 requires.
+func writeFeed(c *gin.Context, f *feed.Feed, format string) {
+	body, err := f.Render(format)
+	if err != nil {
+		c.JSON(500, models.NewMessage("failed to render feed"))
+		return
+	}
+
+	etag := utils.ContentETag(body)
+	c.Header("ETag", etag)
+	c.Header("Last-Modified", f.Updated.UTC().Format(http.TimeFormat))
+
+	if inm := c.GetHeader("If-None-Match"); inm != "" {
+		if utils.IfNoneMatch(inm, etag) {
+			c.Status(http.StatusNotModified)
+			return
+		}
+	} else if utils.NotModifiedSince(c.GetHeader("If-Modified-Since"), f.Updated) {
+		c.Status(http.StatusNotModified)
+		return
+	}
+
+	c.Data(200, feed.ContentType(format), body)
+}
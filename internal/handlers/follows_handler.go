@@ -0,0 +1,179 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yantology/go-gin-simple-blog-with-fts/internal/models"
+	"github.com/yantology/go-gin-simple-blog-with-fts/internal/services"
+	"github.com/yantology/go-gin-simple-blog-with-fts/pkg/utils/customerror"
+)
+
+type FollowsHandler struct {
+	followsService *services.FollowsService
+}
+
+func NewFollowsHandler(followsService *services.FollowsService) *FollowsHandler {
+	return &FollowsHandler{
+		followsService: followsService,
+	}
+}
+
+// Follow follows a user.
+// @Summary Follow a user
+// @Description Follow a user to see their articles in your feed. Following them again changes nothing.
+// @Tags follows
+// @Produce json
+// @Param id path int true "User ID"
+// @Success 200 {object} models.FollowResponse
+// @Failure 400 {object} models.Message
+// @Failure 401 {object} models.Message
+// @Failure 404 {object} models.Message
+// @Failure 500 {object} models.Message
+// @Router /users/{id}/follow [post]
+// @Security ApiKeyAuth
+func (h *FollowsHandler) Follow(c *gin.Context) {
+	h.setFollowing(c, true)
+}
+
+// Unfollow stops following a user.
+// @Summary Unfollow a user
+// @Description Stop following a user. Unfollowing a user you do not follow changes nothing.
+// @Tags follows
+// @Produce json
+// @Param id path int true "User ID"
+// @Success 200 {object} models.FollowResponse
+// @Failure 400 {object} models.Message
+// @Failure 401 {object} models.Message
+// @Failure 500 {object} models.Message
+// @Router /users/{id}/follow [delete]
+// @Security ApiKeyAuth
+func (h *FollowsHandler) Unfollow(c *gin.Context) {
+	h.setFollowing(c, false)
+}
+
+func (h *FollowsHandler) setFollowing(c *gin.Context, following bool) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, models.NewMessage("user not authenticated"))
+		return
+	}
+
+	followeeID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(400, models.NewMessage("invalid user ID"))
+		return
+	}
+
+	var follow *models.FollowResponse
+	var cuserr *customerror.CustomError
+	if following {
+		follow, cuserr = h.followsService.Follow(userID.(int), followeeID)
+	} else {
+		follow, cuserr = h.followsService.Unfollow(userID.(int), followeeID)
+	}
+	if cuserr != nil {
+		c.JSON(cuserr.HTTPCode, models.NewMessage(cuserr.Error()))
+		return
+	}
+
+	c.JSON(200, follow)
+}
+
+// GetFollowers lists the followers of a user.
+// @Summary Get followers
+// @Description Get a page of the users following a user, most recent followers first
+// @Tags follows
+// @Produce json
+// @Param id path int true "User ID"
+// @Param limit query int false "Users per page, at most 100" default(20)
+// @Param offset query int false "Users to skip" default(0)
+// @Success 200 {object} models.FollowUsersResponse
+// @Failure 400 {object} models.Message
+// @Failure 404 {object} models.Message
+// @Failure 500 {object} models.Message
+// @Router /users/{id}/followers [get]
+func (h *FollowsHandler) GetFollowers(c *gin.Context) {
+	h.getFollowUsers(c, h.followsService.GetFollowers)
+}
+
+// GetFollowing lists the users a user follows.
+// @Summary Get followed users
+// @Description Get a page of the users a user follows, most recently followed first
+// @Tags follows
+// @Produce json
+// @Param id path int true "User ID"
+// @Param limit query int false "Users per page, at most 100" default(20)
+// @Param offset query int false "Users to skip" default(0)
+// @Success 200 {object} models.FollowUsersResponse
+// @Failure 400 {object} models.Message
+// @Failure 404 {object} models.Message
+// @Failure 500 {object} models.Message
+// @Router /users/{id}/following [get]
+func (h *FollowsHandler) GetFollowing(c *gin.Context) {
+	h.getFollowUsers(c, h.followsService.GetFollowing)
+}
+
+func (h *FollowsHandler) getFollowUsers(c *gin.Context, list func(userID int, limit int, offset int) (*models.FollowUsersResponse, *customerror.CustomError)) {
+	userID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(400, models.NewMessage("invalid user ID"))
+		return
+	}
+
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	if err != nil {
+		c.JSON(400, models.NewMessage("invalid limit"))
+		return
+	}
+
+	offset, err := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	if err != nil {
+		c.JSON(400, models.NewMessage("invalid offset"))
+		return
+	}
+
+	users, cuserr := list(userID, limit, offset)
+	if cuserr != nil {
+		c.JSON(cuserr.HTTPCode, models.NewMessage(cuserr.Error()))
+		return
+	}
+
+	c.JSON(200, users)
+}
+
+// GetFeed lists the articles of followed authors.
+// @Summary Get my feed
+// @Description Get the articles of the authors you follow, newest first. Pass next_cursor of a page as cursor to read the next one.
+// @Tags follows
+// @Produce json
+// @Param cursor query string false "next_cursor of the previous page"
+// @Param limit query int false "Articles per page, at most 100" default(20)
+// @Success 200 {object} models.FeedResponse
+// @Failure 400 {object} models.Message
+// @Failure 401 {object} models.Message
+// @Failure 500 {object} models.Message
+// @Router /me/feed [get]
+// @Security ApiKeyAuth
+func (h *FollowsHandler) GetFeed(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, models.NewMessage("user not authenticated"))
+		return
+	}
+
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	if err != nil {
+		c.JSON(400, models.NewMessage("invalid limit"))
+		return
+	}
+
+	feed, cuserr := h.followsService.GetFeed(userID.(int), c.Query("cursor"), limit)
+	if cuserr != nil {
+		c.JSON(cuserr.HTTPCode, models.NewMessage(cuserr.Error()))
+		return
+	}
+
+	c.JSON(200, feed)
+}
@@ -0,0 +1,250 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yantology/go-gin-simple-blog-with-fts/internal/models"
+	"github.com/yantology/go-gin-simple-blog-with-fts/internal/services"
+	"github.com/yantology/go-gin-simple-blog-with-fts/internal/theme"
+	"github.com/yantology/go-gin-simple-blog-with-fts/internal/utils"
+	"github.com/yantology/go-gin-simple-blog-with-fts/pkg/utils/customerror"
+)
+
+// frontendPageSize is the number of articles on one page of a listing
+const frontendPageSize = 10
+
+// FrontendHandler serves the server-rendered HTML pages of the site.
+type FrontendHandler struct {
+	articlesService *services.ArticlesService
+	theme           *theme.Theme
+	site            *theme.Site
+}
+
+func NewFrontendHandler(articlesService *services.ArticlesService, theme *theme.Theme, site *theme.Site) *FrontendHandler {
+	return &FrontendHandler{
+		articlesService: articlesService,
+		theme:           theme,
+		site:            site,
+	}
+}
+
+// Home lists the latest articles.
+// Route: GET /?page=
+func (h *FrontendHandler) Home(c *gin.Context) {
+	page, ok := h.pageNumber(c)
+	if !ok {
+		return
+	}
+
+	articles, cuserr := h.articlesService.GetLatestArticles(frontendPageSize+1, (page-1)*frontendPageSize, nil)
+	if cuserr != nil {
+		h.renderError(c, cuserr)
+		return
+	}
+
+	data := &theme.Page{
+		Description: h.site.Description,
+		URL:         pageURL(h.site.Links.Home(), page),
+	}
+	if page > 1 {
+		data.Title = "Page " + strconv.Itoa(page)
+	}
+	paginate(data, articles.Articles, h.site.Links.Home(), page)
+	h.render(c, http.StatusOK, theme.PageHome, data)
+}
+
+// GetArticle shows an article. Slugs the article had before a title change redirect to the current one.
+// Route: GET /articles/:slug
+func (h *FrontendHandler) GetArticle(c *gin.Context) {
+	article, cuserr := h.articlesService.GetArticleBySlug(c.Param("slug"))
+	if cuserr != nil {
+		h.renderError(c, cuserr)
+		return
+	}
+	if article.Slug != c.Param("slug") {
+		c.Redirect(http.StatusMovedPermanently, h.site.Links.Article(article.Slug))
+		return
+	}
+
+	data := &theme.Page{
+		Title:       article.Title,
+		Description: article.Excerpt,
+		URL:         h.site.Links.Article(article.Slug),
+		Type:        "article",
+		FeedURL:     h.site.Links.UserFeed(article.UserID, "rss"),
+		Article:     article,
+	}
+	if article.CoverURL != nil {
+		data.Image = h.site.Links.Absolute(*article.CoverURL)
+	}
+	h.render(c, http.StatusOK, theme.PageArticle, data)
+}
+
+// GetAuthor lists the latest articles of an author.
+// Route: GET /authors/:username?page=
+func (h *FrontendHandler) GetAuthor(c *gin.Context) {
+	page, ok := h.pageNumber(c)
+	if !ok {
+		return
+	}
+
+	author, cuserr := h.articlesService.GetLatestArticlesByUsername(c.Param("username"), frontendPageSize+1, (page-1)*frontendPageSize)
+	if cuserr != nil {
+		h.renderError(c, cuserr)
+		return
+	}
+
+	first := h.site.Links.Author(author.UserID, author.Username)
+	data := &theme.Page{
+		Title:       author.Username,
+		Description: "Articles by " + author.Username,
+		URL:         pageURL(first, page),
+		Type:        "profile",
+		FeedURL:     h.site.Links.UserFeed(author.UserID, "rss"),
+		Heading:     author.Username,
+	}
+	paginate(data, author.Articles, first, page)
+	h.render(c, http.StatusOK, theme.PageAuthor, data)
+}
+
+// GetTag lists the latest articles carrying a tag.
+// Route: GET /tags/:slug?page=
+func (h *FrontendHandler) GetTag(c *gin.Context) {
+	page, ok := h.pageNumber(c)
+	if !ok {
+		return
+	}
+
+	tagSlug := c.Param("slug")
+	articles, cuserr := h.articlesService.GetLatestArticles(frontendPageSize+1, (page-1)*frontendPageSize, []string{tagSlug})
+	if cuserr != nil {
+		h.renderError(c, cuserr)
+		return
+	}
+	if len(articles.Articles) == 0 {
+		h.renderError(c, customerror.NewCustomError(nil, "Record not found", http.StatusNotFound))
+		return
+	}
+
+	// Only the slug is in the address, the name comes from the articles carrying the tag
+	name := tagSlug
+	for _, tag := range articles.Articles[0].Tags {
+		if utils.TagSlug(tag) == tagSlug {
+			name = tag
+			break
+		}
+	}
+
+	first := h.site.Links.Tag(tagSlug)
+	data := &theme.Page{
+		Title:       "#" + name,
+		Description: "Articles tagged " + name,
+		URL:         pageURL(first, page),
+		Heading:     name,
+	}
+	paginate(data, articles.Articles, first, page)
+	h.render(c, http.StatusOK, theme.PageTag, data)
+}
+
+// Search shows the articles matching a full-text query.
+// Route: GET /search?q=&page=
+func (h *FrontendHandler) Search(c *gin.Context) {
+	page, ok := h.pageNumber(c)
+	if !ok {
+		return
+	}
+
+	query := strings.TrimSpace(c.Query("q"))
+	data := &theme.Page{
+		Title:       "Search",
+		Description: h.site.Description,
+		URL:         h.site.Links.Search(query),
+		Query:       query,
+	}
+	if query == "" {
+		h.render(c, http.StatusOK, theme.PageSearch, data)
+		return
+	}
+
+	articles, cuserr := h.articlesService.SearchArticles(frontendPageSize+1, (page-1)*frontendPageSize, query, nil)
+	if cuserr != nil {
+		h.renderError(c, cuserr)
+		return
+	}
+
+	data.Title = "Search: " + query
+	data.URL = pageURL(data.URL, page)
+	data.FeedURL = h.site.Links.SearchFeed(query, "rss")
+	paginate(data, articles.Articles, h.site.Links.Search(query), page)
+	h.render(c, http.StatusOK, theme.PageSearch, data)
+}
+
+// pageNumber reads the page query parameter, rendering an error page when it is not a positive number.
+func (h *FrontendHandler) pageNumber(c *gin.Context) (int, bool) {
+	page, err := strconv.Atoi(c.DefaultQuery("page", "1"))
+	if err != nil || page < 1 {
+		h.renderError(c, customerror.NewCustomError(err, "invalid page", http.StatusBadRequest))
+		return 0, false
+	}
+	return page, true
+}
+
+// paginate puts one page of articles, fetched with one extra to tell whether
+// an older page follows, and the links to the pages around it into data.
+func paginate(data *theme.Page, articles []*models.ArticleResponse, first string, page int) {
+	if len(articles) > frontendPageSize {
+		articles = articles[:frontendPageSize]
+		data.NextURL = pageURL(first, page+1)
+	}
+	if page > 1 {
+		data.PrevURL = pageURL(first, page-1)
+	}
+	data.Articles = articles
+}
+
+// pageURL returns the address of page n of the listing whose first page is at first.
+func pageURL(first string, n int) string {
+	if n <= 1 {
+		return first
+	}
+	if strings.Contains(first, "?") {
+		return first + "&page=" + strconv.Itoa(n)
+	}
+	return first + "?page=" + strconv.Itoa(n)
+}
+
+func (h *FrontendHandler) renderError(c *gin.Context, cuserr *customerror.CustomError) {
+	data := &theme.Page{
+		Title:   http.StatusText(cuserr.HTTPCode),
+		Status:  cuserr.HTTPCode,
+		Message: cuserr.Error(),
+	}
+	switch {
+	case cuserr.HTTPCode == http.StatusNotFound:
+		data.Title = "Page not found"
+		data.Message = "The page you are looking for does not exist."
+	case cuserr.HTTPCode >= 500:
+		data.Message = "Something went wrong, please try again later."
+	}
+	h.render(c, cuserr.HTTPCode, theme.PageError, data)
+}
+
+func (h *FrontendHandler) render(c *gin.Context, status int, page string, data *theme.Page) {
+	data.Site = h.site
+	if data.Type == "" {
+		data.Type = "website"
+	}
+	if data.FeedURL == "" {
+		data.FeedURL = h.site.Links.ArticlesFeed("rss")
+	}
+
+	// Render writes nothing when it fails, so the error can still be reported
+	c.Header("Content-Type", "text/html; charset=utf-8")
+	c.Status(status)
+	if err := h.theme.Render(c.Writer, page, data); err != nil {
+		c.String(http.StatusInternalServerError, "failed to render page")
+	}
+}
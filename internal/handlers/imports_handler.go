@@ -0,0 +1,456 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yantology/go-gin-simple-blog-with-fts/internal/models"
+	"github.com/yantology/go-gin-simple-blog-with-fts/internal/services"
+	"github.com/yantology/go-gin-simple-blog-with-fts/internal/utils"
+	"github.com/yantology/go-gin-simple-blog-with-fts/pkg/models/importsmodels"
+)
+
+type ImportsHandler struct {
+	importsService *services.ImportsService
+	maxUploadSize  int64
+}
+
+func NewImportsHandler(importsService *services.ImportsService, maxUploadSize int64) *ImportsHandler {
+	return &ImportsHandler{
+		importsService: importsService,
+		maxUploadSize:  maxUploadSize,
+	}
+}
+
+// CreateArticlesWithCsv godoc
+// @Summary Create articles with CSV
+// @Description Start importing a CSV file in the background, like POST /imports with format csv. The file has a title column and a url or content column; rows without content are scraped from their URL. Poll the returned import for progress.
+// @Description The mode decides what happens to a URL you imported before: skip leaves its article alone, update scrapes it again and updates the article when the content changed, duplicate creates another article.
+// @Description An atomic import creates all of its articles in one transaction once every row is processed, or none of them when any row failed. Otherwise every row that works is kept.
+// @Description Articles scraped from their URL follow the page: it is fetched again every refresh_interval minutes, and when its content changed the article is updated, its previous version kept as a revision.
+// @Description Sending the upload again with the same Idempotency-Key returns the import started by the first one instead of importing twice.
+// @Description With dry_run=true nothing is imported: the file is read and checked, the pages of a sample of rows are scraped, and what the import would do with each row is returned.
+// @Tags imports
+// @Accept multipart/form-data
+// @Produce json
+// @Param file formData file true "file"
+// @Param mode formData string false "What to do with URLs imported before" Enums(skip, update, duplicate) default(skip)
+// @Param atomic formData bool false "Create every article or, when any row fails, none; not with mode update" default(false)
+// @Param refresh_interval formData int false "Minutes between two checks of the scraped pages for changes, 0 to never check; the interval of each host when left out" minimum(0)
+// @Param Idempotency-Key header string false "Unique key of this upload, at most 255 characters"
+// @Param dry_run query bool false "Preview the import without writing anything" default(false)
+// @Param sample query int false "Number of pages a dry run scrapes" default(5) minimum(1) maximum(20)
+// @Success 200 {object} models.ImportPreviewResponse "dry run"
+// @Success 202 {object} models.ImportResponse "import queued"
+// @Header 202 {string} Location "Address of the import"
+// @Header 202 {string} Idempotent-Replayed "true when the import was started by an earlier upload with the same Idempotency-Key"
+// @Failure 400 {object} models.Message "file upload failed"
+// @Failure 401 {object} models.Message "user not authenticated"
+// @Failure 413 {object} models.Message "file too large"
+// @Failure 422 {object} models.Message "Idempotency-Key used for a different upload"
+// @Failure 500 {object} models.Message "internal server error"
+// @Router /articles/csv [post]
+// @Security ApiKeyAuth
+func (h *ImportsHandler) CreateArticlesWithCsv(c *gin.Context) {
+	h.startImport(c, importsmodels.SourceCSV)
+}
+
+// CreateImport godoc
+// @Summary Import articles from a file
+// @Description Start importing a file of articles in the background. Poll the returned import for progress.
+// @Description csv: a CSV file with a title column and a url or content column, and optional format, tags, date and status columns. Rows without content are scraped from their URL.
+// @Description ndjson: one JSON object per line with title, url, content, format, tags, date and status fields. Objects without content are scraped from their URL.
+// @Description markdown: a zip file of .md files with YAML front matter giving the title, tags, date and status.
+// @Description wxr: a WordPress export file. Posts are imported as HTML with their categories and tags as tags; pages and attachments are left out.
+// @Description Only published articles are imported; drafts and private posts are skipped. The format is told by the file extension when not given.
+// @Description The mode decides what happens to a URL you imported before: skip leaves its article alone, update updates the article when the content changed, duplicate creates another article.
+// @Description An atomic import creates all of its articles in one transaction once every row is processed, or none of them when any row failed. Otherwise every row that works is kept.
+// @Description Articles scraped from their URL follow the page: it is fetched again every refresh_interval minutes, and when its content changed the article is updated, its previous version kept as a revision.
+// @Description Sending the upload again with the same Idempotency-Key returns the import started by the first one instead of importing twice.
+// @Description With dry_run=true nothing is imported: the file is read and checked, the pages of a sample of rows are scraped, and what the import would do with each row is returned.
+// @Tags imports
+// @Accept multipart/form-data
+// @Produce json
+// @Param file formData file true "file"
+// @Param format formData string false "Kind of file, told by its extension when empty" Enums(csv, ndjson, markdown, wxr)
+// @Param mode formData string false "What to do with URLs imported before" Enums(skip, update, duplicate) default(skip)
+// @Param atomic formData bool false "Create every article or, when any row fails, none; not with mode update" default(false)
+// @Param refresh_interval formData int false "Minutes between two checks of the scraped pages for changes, 0 to never check; the interval of each host when left out" minimum(0)
+// @Param Idempotency-Key header string false "Unique key of this upload, at most 255 characters"
+// @Param dry_run query bool false "Preview the import without writing anything" default(false)
+// @Param sample query int false "Number of pages a dry run scrapes" default(5) minimum(1) maximum(20)
+// @Success 200 {object} models.ImportPreviewResponse "dry run"
+// @Success 202 {object} models.ImportResponse "import queued"
+// @Header 202 {string} Location "Address of the import"
+// @Header 202 {string} Idempotent-Replayed "true when the import was started by an earlier upload with the same Idempotency-Key"
+// @Failure 400 {object} models.Message "file upload failed"
+// @Failure 401 {object} models.Message "user not authenticated"
+// @Failure 413 {object} models.Message "file too large"
+// @Failure 422 {object} models.Message "Idempotency-Key used for a different upload"
+// @Failure 500 {object} models.Message "internal server error"
+// @Router /imports [post]
+// @Security ApiKeyAuth
+func (h *ImportsHandler) CreateImport(c *gin.Context) {
+	h.startImport(c, "")
+}
+
+// startImport starts importing the uploaded file as format, or else as the
+// format form field, told by its extension when both are empty, and answers
+// with the queued import
+func (h *ImportsHandler) startImport(c *gin.Context, format string) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, models.NewMessage("user not authenticated"))
+		return
+	}
+
+	// Stop reading oversized bodies early instead of buffering them to disk
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, h.maxUploadSize+multipartOverhead)
+	file, err := c.FormFile("file")
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			c.JSON(http.StatusRequestEntityTooLarge, models.NewMessage("file too large"))
+			return
+		}
+		c.JSON(http.StatusBadRequest, models.NewMessage("file upload failed"))
+		return
+	}
+	if format == "" {
+		format = c.PostForm("format")
+	}
+
+	if value := c.Query("dry_run"); value != "" {
+		dryRun, err := strconv.ParseBool(value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, models.NewMessage("dry_run must be true or false"))
+			return
+		}
+		if dryRun {
+			h.previewImport(c, userID.(int), file, format)
+			return
+		}
+	}
+
+	atomic := false
+	if value := c.PostForm("atomic"); value != "" {
+		if atomic, err = strconv.ParseBool(value); err != nil {
+			c.JSON(http.StatusBadRequest, models.NewMessage("atomic must be true or false"))
+			return
+		}
+	}
+
+	var refreshInterval *int
+	if value := c.PostForm("refresh_interval"); value != "" {
+		minutes, err := strconv.Atoi(value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, models.NewMessage("refresh_interval must be a number of minutes, 0 to never check"))
+			return
+		}
+		refreshInterval = &minutes
+	}
+
+	imp, created, cuserr := h.importsService.CreateImport(userID.(int), file, format, c.PostForm("mode"), atomic, refreshInterval, c.GetHeader("Idempotency-Key"))
+	if cuserr != nil {
+		c.JSON(cuserr.HTTPCode, models.NewMessage(cuserr.Error()))
+		return
+	}
+
+	if !created {
+		c.Header("Idempotent-Replayed", "true")
+	}
+	c.Header("Location", "/api/v1/imports/"+strconv.Itoa(imp.ID))
+	c.JSON(http.StatusAccepted, imp)
+}
+
+// previewImport answers a dry run of an import with what it would do
+func (h *ImportsHandler) previewImport(c *gin.Context, userID int, file *multipart.FileHeader, format string) {
+	sample := 0
+	if value := c.Query("sample"); value != "" {
+		var err error
+		if sample, err = strconv.Atoi(value); err != nil {
+			c.JSON(http.StatusBadRequest, models.NewMessage("sample must be a number"))
+			return
+		}
+	}
+
+	preview, cuserr := h.importsService.PreviewImport(c.Request.Context(), userID, file, format, c.PostForm("mode"), sample)
+	if cuserr != nil {
+		c.JSON(cuserr.HTTPCode, models.NewMessage(cuserr.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, preview)
+}
+
+// GetImport reports the progress of an import.
+// @Summary Get an import
+// @Description Get the state (queued, running, completed, failed or cancelled), row counts and timing of one of your imports
+// @Tags imports
+// @Produce json
+// @Param id path int true "Import ID"
+// @Success 200 {object} models.ImportResponse
+// @Failure 400 {object} models.Message
+// @Failure 401 {object} models.Message
+// @Failure 403 {object} models.Message
+// @Failure 404 {object} models.Message
+// @Failure 500 {object} models.Message
+// @Router /imports/{id} [get]
+// @Security ApiKeyAuth
+func (h *ImportsHandler) GetImport(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, models.NewMessage("user not authenticated"))
+		return
+	}
+
+	importID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(400, models.NewMessage("invalid import ID"))
+		return
+	}
+
+	imp, cuserr := h.importsService.GetImport(userID.(int), importID)
+	if cuserr != nil {
+		c.JSON(cuserr.HTTPCode, models.NewMessage(cuserr.Error()))
+		return
+	}
+
+	c.JSON(200, imp)
+}
+
+// GetImportRows reports what became of each row of an import.
+// @Summary Get the rows of an import
+// @Description Get the row number, title, URL, status (succeeded, failed or skipped), error reason and created article ID of each processed row of one of your imports, in file order. With format=csv every matching row is returned as a downloadable CSV report and limit and offset are ignored.
+// @Tags imports
+// @Produce json
+// @Produce text/csv
+// @Param id path int true "Import ID"
+// @Param status query string false "Only rows with this status" Enums(succeeded, failed, skipped)
+// @Param format query string false "Response format" Enums(json, csv) default(json)
+// @Param limit query int false "Rows per page, at most 100" default(20)
+// @Param offset query int false "Rows to skip" default(0)
+// @Success 200 {object} models.ImportRowsResponse
+// @Failure 400 {object} models.Message
+// @Failure 401 {object} models.Message
+// @Failure 403 {object} models.Message
+// @Failure 404 {object} models.Message
+// @Failure 500 {object} models.Message
+// @Router /imports/{id}/rows [get]
+// @Security ApiKeyAuth
+func (h *ImportsHandler) GetImportRows(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, models.NewMessage("user not authenticated"))
+		return
+	}
+
+	importID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(400, models.NewMessage("invalid import ID"))
+		return
+	}
+
+	status := c.Query("status")
+	switch c.DefaultQuery("format", "json") {
+	case "json":
+	case "csv":
+		rows, cuserr := h.importsService.GetImportReport(userID.(int), importID, status)
+		if cuserr != nil {
+			c.JSON(cuserr.HTTPCode, models.NewMessage(cuserr.Error()))
+			return
+		}
+		writeImportReport(c, importID, rows)
+		return
+	default:
+		c.JSON(400, models.NewMessage("format must be json or csv"))
+		return
+	}
+
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	if err != nil {
+		c.JSON(400, models.NewMessage("invalid limit"))
+		return
+	}
+
+	offset, err := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	if err != nil {
+		c.JSON(400, models.NewMessage("invalid offset"))
+		return
+	}
+
+	rows, cuserr := h.importsService.GetImportRows(userID.(int), importID, status, limit, offset)
+	if cuserr != nil {
+		c.JSON(cuserr.HTTPCode, models.NewMessage(cuserr.Error()))
+		return
+	}
+
+	c.JSON(200, rows)
+}
+
+// writeImportReport sends rows as a CSV attachment with the same columns as the JSON rows.
+func writeImportReport(c *gin.Context, importID int, rows []*models.ImportRowResponse) {
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	w.Write([]string{"row", "title", "url", "status", "error", "article_id"})
+	for _, row := range rows {
+		reason := ""
+		if row.Error != nil {
+			reason = *row.Error
+		}
+		articleID := ""
+		if row.ArticleID != nil {
+			articleID = strconv.Itoa(*row.ArticleID)
+		}
+		w.Write([]string{strconv.Itoa(row.Row), utils.CSVSafe(row.Title), utils.CSVSafe(row.URL), row.Status, utils.CSVSafe(reason), articleID})
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		c.JSON(500, models.NewMessage("failed to write report"))
+		return
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="import-%d-report.csv"`, importID))
+	c.Data(200, "text/csv; charset=utf-8", buf.Bytes())
+}
+
+// importEventsHeartbeat is how often an idle event stream gets a comment,
+// keeping proxies from closing it
+const importEventsHeartbeat = 15 * time.Second
+
+// GetImportEvents streams the progress of an import as server-sent events.
+// @Summary Follow an import
+// @Description Stream the progress of one of your imports as server-sent events: started when a row is picked up, succeeded, failed or skipped with the row result and the counts so far, and completed with the import once it finished, which ends the stream.
+// @Description Every event has an id. A client reconnecting with the Last-Event-ID header gets the events after that one, as far as the latest 10000 events go. A finished import sends completed only.
+// @Tags imports
+// @Produce text/event-stream
+// @Param id path int true "Import ID"
+// @Param Last-Event-ID header int false "ID of the last event received before reconnecting"
+// @Success 200 {string} string "event stream"
+// @Failure 400 {object} models.Message
+// @Failure 401 {object} models.Message
+// @Failure 403 {object} models.Message
+// @Failure 404 {object} models.Message
+// @Failure 409 {object} models.Message "import is running in another server process"
+// @Failure 500 {object} models.Message
+// @Router /imports/{id}/events [get]
+// @Security ApiKeyAuth
+func (h *ImportsHandler) GetImportEvents(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, models.NewMessage("user not authenticated"))
+		return
+	}
+
+	importID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(400, models.NewMessage("invalid import ID"))
+		return
+	}
+
+	events, imp, cuserr := h.importsService.FollowImport(userID.(int), importID)
+	if cuserr != nil {
+		c.JSON(cuserr.HTTPCode, models.NewMessage(cuserr.Error()))
+		return
+	}
+	// Not a number, or missing, streams from the first event
+	lastID, _ := strconv.Atoi(c.GetHeader("Last-Event-ID"))
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	// Keeps nginx from buffering the stream
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+	fmt.Fprint(c.Writer, "retry: 3000\n\n")
+
+	if events == nil {
+		writeImportEvent(c.Writer, utils.ImportEvent{Type: utils.ImportEventCompleted, Data: imp})
+		c.Writer.Flush()
+		return
+	}
+
+	heartbeat := time.NewTicker(importEventsHeartbeat)
+	defer heartbeat.Stop()
+	for {
+		pending, changed, closed := events.Since(lastID)
+		for _, event := range pending {
+			if err := writeImportEvent(c.Writer, event); err != nil {
+				return
+			}
+			lastID = event.ID
+		}
+		c.Writer.Flush()
+		if closed {
+			return
+		}
+
+		select {
+		case <-changed:
+		case <-heartbeat.C:
+			fmt.Fprint(c.Writer, ": ping\n\n")
+			c.Writer.Flush()
+		case <-c.Request.Context().Done():
+			return
+		}
+	}
+}
+
+// writeImportEvent writes event in the server-sent events format, with its
+// data as one line of JSON
+func writeImportEvent(w io.Writer, event utils.ImportEvent) error {
+	data, err := json.Marshal(event.Data)
+	if err != nil {
+		return err
+	}
+	if event.ID > 0 {
+		fmt.Fprintf(w, "id: %d\n", event.ID)
+	}
+	_, err = fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event.Type, data)
+	return err
+}
+
+// CancelImport stops a running import.
+// @Summary Cancel an import
+// @Description Stop one of your running imports after the rows in flight. Articles already created are kept.
+// @Tags imports
+// @Produce json
+// @Param id path int true "Import ID"
+// @Success 200 {object} models.ImportResponse
+// @Failure 400 {object} models.Message
+// @Failure 401 {object} models.Message
+// @Failure 403 {object} models.Message
+// @Failure 404 {object} models.Message
+// @Failure 409 {object} models.Message "import is not running"
+// @Failure 500 {object} models.Message
+// @Router /imports/{id} [delete]
+// @Security ApiKeyAuth
+func (h *ImportsHandler) CancelImport(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, models.NewMessage("user not authenticated"))
+		return
+	}
+
+	importID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(400, models.NewMessage("invalid import ID"))
+		return
+	}
+
+	imp, cuserr := h.importsService.CancelImport(userID.(int), importID)
+	if cuserr != nil {
+		c.JSON(cuserr.HTTPCode, models.NewMessage(cuserr.Error()))
+		return
+	}
+
+	c.JSON(200, imp)
+}
@@ -0,0 +1,132 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yantology/go-gin-simple-blog-with-fts/internal/models"
+	"github.com/yantology/go-gin-simple-blog-with-fts/internal/services"
+)
+
+// multipartOverhead is the room left for form boundaries and headers on top of the file itself
+const multipartOverhead = 1 << 20
+
+type MediaHandler struct {
+	mediaService  *services.MediaService
+	maxUploadSize int64
+}
+
+func NewMediaHandler(mediaService *services.MediaService, maxUploadSize int64) *MediaHandler {
+	return &MediaHandler{
+		mediaService:  mediaService,
+		maxUploadSize: maxUploadSize,
+	}
+}
+
+// UploadMedia uploads an image.
+// @Summary Upload an image
+// @Description Upload a JPEG, PNG or GIF image for use in articles or as a cover. The type is detected from the content, metadata such as EXIF is removed and thumbnails are generated.
+// @Tags media
+// @Accept multipart/form-data
+// @Produce json
+// @Param file formData file true "Image file"
+// @Success 201 {object} models.MediaResponse
+// @Failure 400 {object} models.Message
+// @Failure 401 {object} models.Message
+// @Failure 413 {object} models.Message
+// @Failure 415 {object} models.Message
+// @Failure 500 {object} models.Message
+// @Router /media [post]
+// @Security ApiKeyAuth
+func (h *MediaHandler) UploadMedia(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, models.NewMessage("user not authenticated"))
+		return
+	}
+
+	// Stop reading oversized bodies early instead of buffering them to disk
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, h.maxUploadSize+multipartOverhead)
+	file, err := c.FormFile("file")
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			c.JSON(http.StatusRequestEntityTooLarge, models.NewMessage("file too large"))
+			return
+		}
+		c.JSON(http.StatusBadRequest, models.NewMessage("file upload failed"))
+		return
+	}
+
+	media, cuserr := h.mediaService.UploadMedia(c.Request.Context(), userID.(int), file)
+	if cuserr != nil {
+		c.JSON(cuserr.HTTPCode, models.NewMessage(cuserr.Error()))
+		return
+	}
+
+	c.JSON(http.StatusCreated, media)
+}
+
+// GetMediaByID retrieves an uploaded image.
+// @Summary Get an uploaded image
+// @Description Get an uploaded image with its URL and thumbnails
+// @Tags media
+// @Produce json
+// @Param id path int true "Media ID"
+// @Success 200 {object} models.MediaResponse
+// @Failure 400 {object} models.Message
+// @Failure 404 {object} models.Message
+// @Failure 500 {object} models.Message
+// @Router /media/{id} [get]
+func (h *MediaHandler) GetMediaByID(c *gin.Context) {
+	mediaID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(400, models.NewMessage("invalid media ID"))
+		return
+	}
+
+	media, cuserr := h.mediaService.GetMediaByID(mediaID)
+	if cuserr != nil {
+		c.JSON(cuserr.HTTPCode, models.NewMessage(cuserr.Error()))
+		return
+	}
+
+	c.JSON(200, media)
+}
+
+// DeleteMedia deletes an uploaded image.
+// @Summary Delete an uploaded image
+// @Description Delete an uploaded image and its thumbnails. Articles using it as cover lose their cover.
+// @Tags media
+// @Produce json
+// @Param id path int true "Media ID"
+// @Success 200 {object} models.Message
+// @Failure 400 {object} models.Message
+// @Failure 401 {object} models.Message
+// @Failure 403 {object} models.Message
+// @Failure 404 {object} models.Message
+// @Failure 500 {object} models.Message
+// @Router /media/{id} [delete]
+// @Security ApiKeyAuth
+func (h *MediaHandler) DeleteMedia(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, models.NewMessage("user not authenticated"))
+		return
+	}
+
+	mediaID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(400, models.NewMessage("invalid media ID"))
+		return
+	}
+
+	if cuserr := h.mediaService.DeleteMedia(c.Request.Context(), userID.(int), mediaID); cuserr != nil {
+		c.JSON(cuserr.HTTPCode, models.NewMessage(cuserr.Error()))
+		return
+	}
+
+	c.JSON(200, models.NewMessage("media deleted successfully"))
+}
@@ -0,0 +1,222 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yantology/go-gin-simple-blog-with-fts/internal/models"
+	"github.com/yantology/go-gin-simple-blog-with-fts/internal/services"
+	"github.com/yantology/go-gin-simple-blog-with-fts/pkg/utils/customerror"
+)
+
+type ReactionsHandler struct {
+	reactionsService *services.ReactionsService
+}
+
+func NewReactionsHandler(reactionsService *services.ReactionsService) *ReactionsHandler {
+	return &ReactionsHandler{
+		reactionsService: reactionsService,
+	}
+}
+
+// GetReactions returns the reactions on an article.
+// @Summary Get article reactions
+// @Description Get the reaction counts of an article and the reactions you left on it
+// @Tags reactions
+// @Produce json
+// @Param id path int true "Article ID"
+// @Success 200 {object} models.ReactionsResponse
+// @Failure 400 {object} models.Message
+// @Failure 401 {object} models.Message
+// @Failure 404 {object} models.Message
+// @Failure 500 {object} models.Message
+// @Router /articles/{id}/reactions [get]
+// @Security ApiKeyAuth
+func (h *ReactionsHandler) GetReactions(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, models.NewMessage("user not authenticated"))
+		return
+	}
+
+	articleID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(400, models.NewMessage("invalid article ID"))
+		return
+	}
+
+	reactions, cuserr := h.reactionsService.GetReactions(userID.(int), articleID)
+	if cuserr != nil {
+		c.JSON(cuserr.HTTPCode, models.NewMessage(cuserr.Error()))
+		return
+	}
+
+	c.JSON(200, reactions)
+}
+
+// AddReaction reacts to an article.
+// @Summary React to an article
+// @Description Leave a reaction on an article. Each reaction can be left once per user, repeating the request changes nothing.
+// @Tags reactions
+// @Produce json
+// @Param id path int true "Article ID"
+// @Param kind path string true "Reaction" Enums(like, love, laugh, wow, sad, clap)
+// @Success 200 {object} models.ReactionsResponse
+// @Failure 400 {object} models.Message
+// @Failure 401 {object} models.Message
+// @Failure 404 {object} models.Message
+// @Failure 500 {object} models.Message
+// @Router /articles/{id}/reactions/{kind} [put]
+// @Security ApiKeyAuth
+func (h *ReactionsHandler) AddReaction(c *gin.Context) {
+	h.setReaction(c, true)
+}
+
+// RemoveReaction withdraws a reaction from an article.
+// @Summary Remove a reaction
+// @Description Withdraw your reaction from an article. Removing a reaction you did not leave changes nothing.
+// @Tags reactions
+// @Produce json
+// @Param id path int true "Article ID"
+// @Param kind path string true "Reaction" Enums(like, love, laugh, wow, sad, clap)
+// @Success 200 {object} models.ReactionsResponse
+// @Failure 400 {object} models.Message
+// @Failure 401 {object} models.Message
+// @Failure 404 {object} models.Message
+// @Failure 500 {object} models.Message
+// @Router /articles/{id}/reactions/{kind} [delete]
+// @Security ApiKeyAuth
+func (h *ReactionsHandler) RemoveReaction(c *gin.Context) {
+	h.setReaction(c, false)
+}
+
+func (h *ReactionsHandler) setReaction(c *gin.Context, present bool) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, models.NewMessage("user not authenticated"))
+		return
+	}
+
+	articleID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(400, models.NewMessage("invalid article ID"))
+		return
+	}
+
+	var reactions *models.ReactionsResponse
+	var cuserr *customerror.CustomError
+	if present {
+		reactions, cuserr = h.reactionsService.AddReaction(userID.(int), articleID, c.Param("kind"))
+	} else {
+		reactions, cuserr = h.reactionsService.RemoveReaction(userID.(int), articleID, c.Param("kind"))
+	}
+	if cuserr != nil {
+		c.JSON(cuserr.HTTPCode, models.NewMessage(cuserr.Error()))
+		return
+	}
+
+	c.JSON(200, reactions)
+}
+
+// AddBookmark bookmarks an article.
+// @Summary Bookmark an article
+// @Description Add an article to your private bookmarks. Bookmarking it again changes nothing.
+// @Tags bookmarks
+// @Produce json
+// @Param id path int true "Article ID"
+// @Success 200 {object} models.BookmarkResponse
+// @Failure 400 {object} models.Message
+// @Failure 401 {object} models.Message
+// @Failure 404 {object} models.Message
+// @Failure 500 {object} models.Message
+// @Router /articles/{id}/bookmark [put]
+// @Security ApiKeyAuth
+func (h *ReactionsHandler) AddBookmark(c *gin.Context) {
+	h.setBookmark(c, true)
+}
+
+// RemoveBookmark removes an article from the bookmarks.
+// @Summary Remove a bookmark
+// @Description Remove an article from your bookmarks. Removing an article you did not bookmark changes nothing.
+// @Tags bookmarks
+// @Produce json
+// @Param id path int true "Article ID"
+// @Success 200 {object} models.BookmarkResponse
+// @Failure 400 {object} models.Message
+// @Failure 401 {object} models.Message
+// @Failure 500 {object} models.Message
+// @Router /articles/{id}/bookmark [delete]
+// @Security ApiKeyAuth
+func (h *ReactionsHandler) RemoveBookmark(c *gin.Context) {
+	h.setBookmark(c, false)
+}
+
+func (h *ReactionsHandler) setBookmark(c *gin.Context, present bool) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, models.NewMessage("user not authenticated"))
+		return
+	}
+
+	articleID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(400, models.NewMessage("invalid article ID"))
+		return
+	}
+
+	var bookmark *models.BookmarkResponse
+	var cuserr *customerror.CustomError
+	if present {
+		bookmark, cuserr = h.reactionsService.AddBookmark(userID.(int), articleID)
+	} else {
+		bookmark, cuserr = h.reactionsService.RemoveBookmark(userID.(int), articleID)
+	}
+	if cuserr != nil {
+		c.JSON(cuserr.HTTPCode, models.NewMessage(cuserr.Error()))
+		return
+	}
+
+	c.JSON(200, bookmark)
+}
+
+// GetBookmarks lists the bookmarked articles.
+// @Summary Get my bookmarks
+// @Description Get a page of the articles you bookmarked, most recently bookmarked first. Articles in the trash are left out.
+// @Tags bookmarks
+// @Produce json
+// @Param limit query int false "Articles per page, at most 100" default(20)
+// @Param offset query int false "Articles to skip" default(0)
+// @Success 200 {object} models.BookmarksResponse
+// @Failure 400 {object} models.Message
+// @Failure 401 {object} models.Message
+// @Failure 500 {object} models.Message
+// @Router /me/bookmarks [get]
+// @Security ApiKeyAuth
+func (h *ReactionsHandler) GetBookmarks(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, models.NewMessage("user not authenticated"))
+		return
+	}
+
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	if err != nil {
+		c.JSON(400, models.NewMessage("invalid limit"))
+		return
+	}
+
+	offset, err := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	if err != nil {
+		c.JSON(400, models.NewMessage("invalid offset"))
+		return
+	}
+
+	bookmarks, cuserr := h.reactionsService.GetBookmarks(userID.(int), limit, offset)
+	if cuserr != nil {
+		c.JSON(cuserr.HTTPCode, models.NewMessage(cuserr.Error()))
+		return
+	}
+
+	c.JSON(200, bookmarks)
+}
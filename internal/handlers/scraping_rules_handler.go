@@ -0,0 +1,100 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yantology/go-gin-simple-blog-with-fts/internal/models"
+	"github.com/yantology/go-gin-simple-blog-with-fts/internal/services"
+)
+
+type ScrapingRulesHandler struct {
+	scrapingRulesService *services.ScrapingRulesService
+}
+
+func NewScrapingRulesHandler(scrapingRulesService *services.ScrapingRulesService) *ScrapingRulesHandler {
+	return &ScrapingRulesHandler{
+		scrapingRulesService: scrapingRulesService,
+	}
+}
+
+// GetScrapingRules lists the scraping rules in use.
+// @Summary Get scraping rules
+// @Description Get the rules used to find the article on the pages of imported URLs, sorted by host. A rule also covers the subdomains of its host. Pages of other hosts go through a generic extractor. Admins only.
+// @Tags admin
+// @Produce json
+// @Success 200 {object} models.ScrapingRulesResponse
+// @Failure 401 {object} models.Message
+// @Failure 403 {object} models.Message
+// @Failure 500 {object} models.Message
+// @Router /admin/scraping-rules [get]
+// @Security ApiKeyAuth
+func (h *ScrapingRulesHandler) GetScrapingRules(c *gin.Context) {
+	rules, cuserr := h.scrapingRulesService.GetScrapingRules()
+	if cuserr != nil {
+		c.JSON(cuserr.HTTPCode, models.NewMessage(cuserr.Error()))
+		return
+	}
+
+	c.JSON(200, rules)
+}
+
+// SaveScrapingRule sets the scraping rule of a host.
+// @Summary Set a scraping rule
+// @Description Set the CSS selectors used to scrape the pages of a host, replacing any rule of the host. The title selector is optional; without it the title comes from the page metadata. Admins only.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param host path string true "Host, e.g. news.example.com"
+// @Param rule body models.ScrapingRuleRequest true "Scraping rule"
+// @Success 200 {object} models.ScrapingRuleResponse
+// @Failure 400 {object} models.Message
+// @Failure 401 {object} models.Message
+// @Failure 403 {object} models.Message
+// @Failure 500 {object} models.Message
+// @Router /admin/scraping-rules/{host} [put]
+// @Security ApiKeyAuth
+func (h *ScrapingRulesHandler) SaveScrapingRule(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, models.NewMessage("user not authenticated"))
+		return
+	}
+
+	var req models.ScrapingRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(400, models.NewMessage(err.Error()))
+		return
+	}
+
+	rule, cuserr := h.scrapingRulesService.SaveScrapingRule(userID.(int), c.Param("host"), &req)
+	if cuserr != nil {
+		c.JSON(cuserr.HTTPCode, models.NewMessage(cuserr.Error()))
+		return
+	}
+
+	c.JSON(200, rule)
+}
+
+// DeleteScrapingRule removes the scraping rule set for a host.
+// @Summary Delete a scraping rule
+// @Description Delete the rule set for a host through this API. A rule for the host from the rules file or the built-in rules applies again. Admins only.
+// @Tags admin
+// @Produce json
+// @Param host path string true "Host"
+// @Success 200 {object} models.Message
+// @Failure 400 {object} models.Message
+// @Failure 401 {object} models.Message
+// @Failure 403 {object} models.Message
+// @Failure 404 {object} models.Message
+// @Failure 500 {object} models.Message
+// @Router /admin/scraping-rules/{host} [delete]
+// @Security ApiKeyAuth
+func (h *ScrapingRulesHandler) DeleteScrapingRule(c *gin.Context) {
+	if cuserr := h.scrapingRulesService.DeleteScrapingRule(c.Param("host")); cuserr != nil {
+		c.JSON(cuserr.HTTPCode, models.NewMessage(cuserr.Error()))
+		return
+	}
+
+	c.JSON(200, models.NewMessage("scraping rule deleted successfully"))
+}
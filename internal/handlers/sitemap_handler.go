@@ -0,0 +1,76 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yantology/go-gin-simple-blog-with-fts/internal/models"
+	"github.com/yantology/go-gin-simple-blog-with-fts/internal/services"
+	"github.com/yantology/go-gin-simple-blog-with-fts/internal/utils"
+)
+
+// SitemapHandler serves the files search engines look for at the root of the site.
+type SitemapHandler struct {
+	sitemapService *services.SitemapService
+}
+
+func NewSitemapHandler(sitemapService *services.SitemapService) *SitemapHandler {
+	return &SitemapHandler{
+		sitemapService: sitemapService,
+	}
+}
+
+// GetSitemap serves the sitemap of every visible article, or the sitemap index past 50,000 articles.
+// Route: GET /sitemap.xml
+func (h *SitemapHandler) GetSitemap(c *gin.Context) {
+	body, cuserr := h.sitemapService.GetSitemap()
+	if cuserr != nil {
+		c.JSON(cuserr.HTTPCode, models.NewMessage(cuserr.Error()))
+		return
+	}
+
+	writeWithETag(c, "application/xml; charset=utf-8", body)
+}
+
+// GetSitemapPage serves one page of a sitemap split by the index.
+// Route: GET /sitemaps/:page, e.g. /sitemaps/articles-2.xml
+func (h *SitemapHandler) GetSitemapPage(c *gin.Context) {
+	page, ok := strings.CutPrefix(c.Param("page"), "articles-")
+	if ok {
+		page, ok = strings.CutSuffix(page, ".xml")
+	}
+	n, err := strconv.Atoi(page)
+	if !ok || err != nil {
+		c.JSON(404, models.NewMessage("Record not found"))
+		return
+	}
+
+	body, cuserr := h.sitemapService.GetSitemapPage(n)
+	if cuserr != nil {
+		c.JSON(cuserr.HTTPCode, models.NewMessage(cuserr.Error()))
+		return
+	}
+
+	writeWithETag(c, "application/xml; charset=utf-8", body)
+}
+
+// GetRobots serves robots.txt.
+// Route: GET /robots.txt
+func (h *SitemapHandler) GetRobots(c *gin.Context) {
+	writeWithETag(c, "text/plain; charset=utf-8", h.sitemapService.GetRobots())
+}
+
+// writeWithETag sends body tagged with a hash of its content, or 304 when the
+// client already has it.
+func writeWithETag(c *gin.Context, contentType string, body []byte) {
+	etag := utils.ContentETag(body)
+	c.Header("ETag", etag)
+	if utils.IfNoneMatch(c.GetHeader("If-None-Match"), etag) {
+		c.Status(http.StatusNotModified)
+		return
+	}
+
+	c.Data(200, contentType, body)
+}
@@ -0,0 +1,55 @@
+package handlers
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/yantology/go-gin-simple-blog-with-fts/internal/models"
+	"github.com/yantology/go-gin-simple-blog-with-fts/internal/services"
+)
+
+type TagsHandler struct {
+	tagsService *services.TagsService
+}
+
+func NewTagsHandler(tagsService *services.TagsService) *TagsHandler {
+	return &TagsHandler{
+		tagsService: tagsService,
+	}
+}
+
+// GetTags lists the tags in use.
+// @Summary Get all tags
+// @Description Get every tag used by at least one article, with usage counts, most used first
+// @Tags tags
+// @Produce json
+// @Success 200 {object} models.TagsResponse
+// @Failure 500 {object} models.Message
+// @Router /tags [get]
+func (h *TagsHandler) GetTags(c *gin.Context) {
+	tags, cuserr := h.tagsService.GetTags()
+	if cuserr != nil {
+		c.JSON(cuserr.HTTPCode, models.NewMessage(cuserr.Error()))
+		return
+	}
+
+	c.JSON(200, tags)
+}
+
+// GetArticlesByTag lists the articles carrying a tag.
+// @Summary Get articles by tag
+// @Description Get articles carrying the tag with the given slug
+// @Tags tags
+// @Produce json
+// @Param slug path string true "Tag slug"
+// @Success 200 {object} models.ArticlesResponse
+// @Failure 404 {object} models.Message
+// @Failure 500 {object} models.Message
+// @Router /tags/{slug}/articles [get]
+func (h *TagsHandler) GetArticlesByTag(c *gin.Context) {
+	articles, cuserr := h.tagsService.GetArticlesByTag(c.Param("slug"))
+	if cuserr != nil {
+		c.JSON(cuserr.HTTPCode, models.NewMessage(cuserr.Error()))
+		return
+	}
+
+	c.JSON(200, articles)
+}
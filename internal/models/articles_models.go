@@ -1,17 +1,118 @@
 package models
 
+import (
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/yantology/go-gin-simple-blog-with-fts/internal/utils"
+)
+
+// ArticleRequest is a full article. Format is one of markdown, html or plain;
+// new articles default to markdown and updates keep the current format when it is empty.
+// CoverMediaID refers to an image uploaded by the same user; updates keep the current
+// cover when it is absent.
 type ArticleRequest struct {
-	Title   string `json:"title" form:"title" validate:"required,min=3,max=255"`
-	Content string `json:"content" form:"content" validate:"required,min=3"`
+	Title        string   `json:"title" form:"title" validate:"required,min=3,max=255"`
+	Content      string   `json:"content" form:"content" validate:"required,min=3"`
+	Format       string   `json:"format" form:"format" validate:"omitempty,oneof=markdown html plain" enums:"markdown,html,plain"`
+	Tags         []string `json:"tags" form:"tags" validate:"max=10,dive,max=50"`
+	CoverMediaID *int     `json:"cover_media_id" form:"cover_media_id"`
+}
+
+// ArticlePatchRequest is a JSON Merge Patch document for an article.
+// Absent fields are left unchanged and only present fields are validated.
+type ArticlePatchRequest struct {
+	Title   *string   `json:"title" binding:"omitempty,min=3,max=255"`
+	Content *string   `json:"content" binding:"omitempty,min=3"`
+	Format  *string   `json:"format" binding:"omitempty,oneof=markdown html plain" enums:"markdown,html,plain"`
+	Tags    *[]string `json:"tags" binding:"omitempty,max=10,dive,max=50"`
+	// CoverMediaID of 0, sent as null, removes the cover
+	CoverMediaID *int `json:"cover_media_id" binding:"omitempty,min=0"`
 }
 
+// DecodeArticlePatch reads a merge patch document. A null tags member clears
+// the tags and a null cover_media_id removes the cover; title, content and
+// format are required and cannot be removed. The error is fit for the client.
+func DecodeArticlePatch(body []byte) (*ArticlePatchRequest, error) {
+	// A merge patch must be an object, and null would remove a member,
+	// which is not allowed for required article fields
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(body, &fields); err != nil || fields == nil {
+		return nil, errors.New("merge patch must be a JSON object")
+	}
+	for _, name := range []string{"title", "content", "format"} {
+		if value, ok := fields[name]; ok && string(value) == "null" {
+			return nil, errors.New(name + " cannot be removed")
+		}
+	}
+
+	var req ArticlePatchRequest
+	if err := json.Unmarshal(body, &req); err != nil {
+		return nil, err
+	}
+	if value, ok := fields["tags"]; ok && string(value) == "null" {
+		// removing the tags member clears every tag
+		req.Tags = &[]string{}
+	}
+	if value, ok := fields["cover_media_id"]; ok && string(value) == "null" {
+		noCover := 0
+		req.CoverMediaID = &noCover
+	}
+	return &req, nil
+}
+
+// ArticleResponse is an article as returned by the API. ContentHTML is the
+// sanitized rendering of Content and Excerpt its plain-text opening.
 type ArticleResponse struct {
-	ID      int    `json:"id"`
-	UserID  int    `json:"user_id"`
-	Title   string `json:"title"`
-	Content string `json:"content"`
+	ID           int            `json:"id"`
+	UserID       int            `json:"user_id"`
+	Title        string         `json:"title"`
+	Slug         string         `json:"slug"`
+	Content      string         `json:"content"`
+	Format       string         `json:"format"`
+	ContentHTML  string         `json:"content_html"`
+	Excerpt      string         `json:"excerpt"`
+	CoverMediaID *int           `json:"cover_media_id"`
+	CoverURL     *string        `json:"cover_url"`
+	Version      int            `json:"version"`
+	DeletedAt    *time.Time     `json:"deleted_at,omitempty"`
+	Tags         []string       `json:"tags"`
+	Reactions    map[string]int `json:"reactions"`
+	Author       string         `json:"author"`
+	SourceURL    *string        `json:"source_url"`
+	CreatedAt    time.Time      `json:"created_at"`
+	UpdatedAt    time.Time      `json:"updated_at"`
 }
 
 type ArticlesResponse struct {
 	Articles []*ArticleResponse `json:"articles"`
 }
+
+type AuthorArticlesResponse struct {
+	UserID   int                `json:"user_id"`
+	Username string             `json:"username"`
+	Articles []*ArticleResponse `json:"articles"`
+}
+
+type ArticleRevisionResponse struct {
+	Revision  int       `json:"revision"`
+	Title     string    `json:"title"`
+	Content   string    `json:"content,omitempty"`
+	Format    string    `json:"format,omitempty"`
+	EditorID  *int      `json:"editor_id"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type ArticleRevisionsResponse struct {
+	Revisions []*ArticleRevisionResponse `json:"revisions"`
+}
+
+// ArticleRevisionDiffResponse is a line-level diff between two versions of an article.
+// From and To are revision numbers, or "current" for the live article.
+type ArticleRevisionDiffResponse struct {
+	From    string           `json:"from"`
+	To      string           `json:"to"`
+	Title   []utils.DiffLine `json:"title"`
+	Content []utils.DiffLine `json:"content"`
+}
@@ -0,0 +1,89 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDecodeArticlePatch(t *testing.T) {
+	title := "New title"
+	noCover := 0
+	cover := 7
+
+	tests := []struct {
+		name     string
+		body     string
+		expected *ArticlePatchRequest
+		err      string
+	}{
+		{
+			name:     "Empty patch",
+			body:     `{}`,
+			expected: &ArticlePatchRequest{},
+		},
+		{
+			name:     "Present fields are set",
+			body:     `{"title": "New title", "tags": ["go"], "cover_media_id": 7}`,
+			expected: &ArticlePatchRequest{Title: &title, Tags: &[]string{"go"}, CoverMediaID: &cover},
+		},
+		{
+			name:     "Null tags clear the tags",
+			body:     `{"tags": null}`,
+			expected: &ArticlePatchRequest{Tags: &[]string{}},
+		},
+		{
+			name:     "Null cover removes the cover",
+			body:     `{"cover_media_id": null}`,
+			expected: &ArticlePatchRequest{CoverMediaID: &noCover},
+		},
+		{
+			name: "Null title",
+			body: `{"title": null}`,
+			err:  "title cannot be removed",
+		},
+		{
+			name: "Null content",
+			body: `{"content": null, "title": "New title"}`,
+			err:  "content cannot be removed",
+		},
+		{
+			name: "Null format",
+			body: `{"format": null}`,
+			err:  "format cannot be removed",
+		},
+		{
+			name: "Null document",
+			body: `null`,
+			err:  "merge patch must be a JSON object",
+		},
+		{
+			name: "Array document",
+			body: `[]`,
+			err:  "merge patch must be a JSON object",
+		},
+		{
+			name: "Malformed JSON",
+			body: `{"title":`,
+			err:  "merge patch must be a JSON object",
+		},
+		{
+			name: "Wrong type",
+			body: `{"title": 3}`,
+			err:  "json: cannot unmarshal number into Go struct field ArticlePatchRequest.title of type string",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := DecodeArticlePatch([]byte(tt.body))
+			if tt.err != "" {
+				assert.EqualError(t, err, tt.err)
+				assert.Nil(t, req)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, req)
+		})
+	}
+}
@@ -0,0 +1,35 @@
+package models
+
+import "time"
+
+type CommentRequest struct {
+	Content  string `json:"content" form:"content" binding:"required,min=1,max=5000"`
+	ParentID *int   `json:"parent_id" form:"parent_id"`
+}
+
+type CommentUpdateRequest struct {
+	Content string `json:"content" form:"content" binding:"required,min=1,max=5000"`
+}
+
+// CommentResponse is a comment with its direct replies. Hidden and deleted comments
+// keep their place in the thread but have no content.
+type CommentResponse struct {
+	ID        int                `json:"id"`
+	ArticleID int                `json:"article_id"`
+	UserID    int                `json:"user_id"`
+	Username  string             `json:"username"`
+	ParentID  *int               `json:"parent_id"`
+	Content   string             `json:"content"`
+	Hidden    bool               `json:"hidden"`
+	Deleted   bool               `json:"deleted"`
+	CreatedAt time.Time          `json:"created_at"`
+	UpdatedAt time.Time          `json:"updated_at"`
+	Replies   []*CommentResponse `json:"replies"`
+}
+
+type CommentsResponse struct {
+	Comments []*CommentResponse `json:"comments"`
+	Total    int                `json:"total"`
+	Limit    int                `json:"limit"`
+	Offset   int                `json:"offset"`
+}
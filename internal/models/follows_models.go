@@ -0,0 +1,28 @@
+package models
+
+import "time"
+
+// FollowResponse tells whether the current user follows a user
+type FollowResponse struct {
+	UserID    int  `json:"user_id"`
+	Following bool `json:"following"`
+}
+
+type FollowUserResponse struct {
+	ID         int       `json:"id"`
+	Username   string    `json:"username"`
+	FollowedAt time.Time `json:"followed_at"`
+}
+
+type FollowUsersResponse struct {
+	Users  []*FollowUserResponse `json:"users"`
+	Total  int                   `json:"total"`
+	Limit  int                   `json:"limit"`
+	Offset int                   `json:"offset"`
+}
+
+// FeedResponse is a page of the feed. NextCursor is empty on the last page.
+type FeedResponse struct {
+	Articles   []*ArticleResponse `json:"articles"`
+	NextCursor string             `json:"next_cursor,omitempty"`
+}
@@ -0,0 +1,83 @@
+package models
+
+import "time"
+
+// ImportResponse is the state and progress of a background import
+type ImportResponse struct {
+	ID       int    `json:"id"`
+	Source   string `json:"source"`
+	Filename string `json:"filename"`
+	// Mode is what the import does with URLs imported before: skip, update or duplicate
+	Mode string `json:"mode"`
+	// Atomic imports create every article or, when any row fails, none
+	Atomic    bool       `json:"atomic"`
+	State     string     `json:"state"`
+	Processed int        `json:"processed"`
+	Succeeded int        `json:"succeeded"`
+	Failed    int        `json:"failed"`
+	Skipped   int        `json:"skipped"`
+	Error     *string    `json:"error"`
+	CreatedAt time.Time  `json:"created_at"`
+	StartedAt *time.Time `json:"started_at"`
+	// FinishedAt is nil while the import is queued or running
+	FinishedAt *time.Time `json:"finished_at"`
+	// DurationSeconds is how long the import has been running, or ran for once finished
+	DurationSeconds *float64 `json:"duration_seconds"`
+	// RefreshInterval is the minutes between two checks of the scraped pages
+	// for changes, null for the interval of each host
+	RefreshInterval *int `json:"refresh_interval"`
+}
+
+// ImportRowResponse is what became of one row of an imported file
+type ImportRowResponse struct {
+	// Row is the line of the file the row starts on, the header being line 1
+	Row       int     `json:"row"`
+	Title     string  `json:"title"`
+	URL       string  `json:"url"`
+	Status    string  `json:"status"`
+	Error     *string `json:"error"`
+	ArticleID *int    `json:"article_id"`
+}
+
+type ImportRowsResponse struct {
+	Rows   []*ImportRowResponse `json:"rows"`
+	Total  int                  `json:"total"`
+	Limit  int                  `json:"limit"`
+	Offset int                  `json:"offset"`
+}
+
+// ImportPreviewResponse is what an import of a file would do, found without
+// writing anything
+type ImportPreviewResponse struct {
+	Source   string `json:"source"`
+	Filename string `json:"filename"`
+	Mode     string `json:"mode"`
+	// Create, Update, Skip and Fail count the rows by action
+	Create int `json:"create"`
+	Update int `json:"update"`
+	Skip   int `json:"skip"`
+	Fail   int `json:"fail"`
+	// Scraped is how many rows took their content from a scraped page, at most the sample asked for
+	Scraped int                 `json:"scraped"`
+	Rows    []*ImportPreviewRow `json:"rows"`
+}
+
+// ImportPreviewRow is what importing one row of a file would do
+type ImportPreviewRow struct {
+	// Row is the line of the file the row starts on, the header being line 1
+	Row int `json:"row"`
+	// Action is create, update, skip or fail
+	Action string     `json:"action"`
+	Title  string     `json:"title"`
+	URL    string     `json:"url"`
+	Format string     `json:"format,omitempty"`
+	Tags   []string   `json:"tags,omitempty"`
+	Date   *time.Time `json:"date,omitempty"`
+	// Excerpt is the opening of the content, empty when the page was not in the sample
+	Excerpt string `json:"excerpt,omitempty"`
+	// Scraped is set when the content comes from the page at URL
+	Scraped bool `json:"scraped"`
+	// ArticleID is the article updated or skipped for
+	ArticleID *int    `json:"article_id"`
+	Error     *string `json:"error"`
+}
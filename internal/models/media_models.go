@@ -0,0 +1,22 @@
+package models
+
+import "time"
+
+type MediaThumbnailResponse struct {
+	URL         string `json:"url"`
+	ContentType string `json:"content_type"`
+	Width       int    `json:"width"`
+	Height      int    `json:"height"`
+}
+
+type MediaResponse struct {
+	ID          int                       `json:"id"`
+	UserID      int                       `json:"user_id"`
+	URL         string                    `json:"url"`
+	ContentType string                    `json:"content_type"`
+	Size        int64                     `json:"size"`
+	Width       int                       `json:"width"`
+	Height      int                       `json:"height"`
+	Thumbnails  []*MediaThumbnailResponse `json:"thumbnails"`
+	CreatedAt   time.Time                 `json:"created_at"`
+}
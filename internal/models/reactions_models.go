@@ -0,0 +1,20 @@
+package models
+
+// ReactionsResponse holds the reaction counts of an article and the reactions of the current user
+type ReactionsResponse struct {
+	ArticleID   int            `json:"article_id"`
+	Reactions   map[string]int `json:"reactions"`
+	MyReactions []string       `json:"my_reactions"`
+}
+
+// BookmarkResponse tells whether the current user has bookmarked an article
+type BookmarkResponse struct {
+	ArticleID  int  `json:"article_id"`
+	Bookmarked bool `json:"bookmarked"`
+}
+
+type BookmarksResponse struct {
+	Articles []*ArticleResponse `json:"articles"`
+	Limit    int                `json:"limit"`
+	Offset   int                `json:"offset"`
+}
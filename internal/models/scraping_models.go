@@ -0,0 +1,27 @@
+package models
+
+import "time"
+
+// ScrapingRuleRequest sets the selectors used to scrape the pages of a host
+type ScrapingRuleRequest struct {
+	TitleSelector    string   `json:"title_selector" example:"h1.detail__title"`
+	ContentSelector  string   `json:"content_selector" binding:"required" example:"div.detail__body-text"`
+	ExcludeSelectors []string `json:"exclude_selectors" example:".linksisip"`
+}
+
+// ScrapingRuleResponse is a scraping rule in use
+type ScrapingRuleResponse struct {
+	Host             string   `json:"host"`
+	TitleSelector    string   `json:"title_selector"`
+	ContentSelector  string   `json:"content_selector"`
+	ExcludeSelectors []string `json:"exclude_selectors"`
+	// Source is where the rule comes from: default, file or admin. An admin rule
+	// replaces a file rule, which replaces a default one, for the same host.
+	Source    string     `json:"source"`
+	UpdatedBy *int       `json:"updated_by"`
+	UpdatedAt *time.Time `json:"updated_at"`
+}
+
+type ScrapingRulesResponse struct {
+	Rules []*ScrapingRuleResponse `json:"rules"`
+}
@@ -0,0 +1,11 @@
+package models
+
+type TagResponse struct {
+	Name         string `json:"name"`
+	Slug         string `json:"slug"`
+	ArticleCount int    `json:"article_count"`
+}
+
+type TagsResponse struct {
+	Tags []*TagResponse `json:"tags"`
+}
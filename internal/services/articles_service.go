@@ -1,64 +1,610 @@
 package services
 
 import (
+	"context"
 	"errors"
+	"fmt"
 	"log"
-	"mime/multipart"
-	"path/filepath"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/yantology/go-gin-simple-blog-with-fts/internal/models"
 	"github.com/yantology/go-gin-simple-blog-with-fts/internal/utils"
+	"github.com/yantology/go-gin-simple-blog-with-fts/pkg/models/articlesmodels"
+	"github.com/yantology/go-gin-simple-blog-with-fts/pkg/models/importsmodels"
 	"github.com/yantology/go-gin-simple-blog-with-fts/pkg/repositories/articlesrepository"
+	"github.com/yantology/go-gin-simple-blog-with-fts/pkg/repositories/authrepository"
+	"github.com/yantology/go-gin-simple-blog-with-fts/pkg/repositories/mediarepository"
 	"github.com/yantology/go-gin-simple-blog-with-fts/pkg/utils/customerror"
+	"github.com/yantology/go-gin-simple-blog-with-fts/pkg/utils/fetch"
+	"github.com/yantology/go-gin-simple-blog-with-fts/pkg/utils/markup"
+	"github.com/yantology/go-gin-simple-blog-with-fts/pkg/utils/slug"
 )
 
 type ArticlesService struct {
 	articlesRepo *articlesrepository.ArticlesRepository
+	authRepo     *authrepository.AuthRepository
+	mediaRepo    *mediarepository.MediaRepository
+	// changeListeners are called after an article is created, edited, trashed or restored
+	changeListeners []func()
+	// refreshIntervals schedule the checks of the pages articles were imported from, none when nil
+	refreshIntervals *utils.RefreshIntervals
 }
 
-func NewArticlesService(articlesRepo *articlesrepository.ArticlesRepository) *ArticlesService {
+func NewArticlesService(articlesRepo *articlesrepository.ArticlesRepository, authRepo *authrepository.AuthRepository, mediaRepo *mediarepository.MediaRepository) *ArticlesService {
 	return &ArticlesService{
 		articlesRepo: articlesRepo,
+		authRepo:     authRepo,
+		mediaRepo:    mediaRepo,
 	}
 }
 
-func (s *ArticlesService) CreateArticle(userId int, req *models.ArticleRequest) *customerror.CustomError {
-	return s.articlesRepo.CreateArticle(userId, req.Title, req.Content)
+// OnArticlesChanged registers fn to be called after any change to the visible articles,
+// e.g. to drop a cache built from them. fn runs on the request goroutine and must be quick.
+func (s *ArticlesService) OnArticlesChanged(fn func()) {
+	s.changeListeners = append(s.changeListeners, fn)
+}
+
+// SetRefreshIntervals sets how often the pages articles were imported from
+// are checked for changes by the source refresher, when their import set no
+// interval. It must be called before importing.
+func (s *ArticlesService) SetRefreshIntervals(intervals *utils.RefreshIntervals) {
+	s.refreshIntervals = intervals
+}
+
+// notifyChanged calls the change listeners when a write succeeded and passes its error through.
+func (s *ArticlesService) notifyChanged(cuserr *customerror.CustomError) *customerror.CustomError {
+	if cuserr == nil {
+		for _, fn := range s.changeListeners {
+			fn()
+		}
+	}
+	return cuserr
+}
+
+func newArticleResponse(article *articlesmodels.Article) *models.ArticleResponse {
+	return &models.ArticleResponse{
+		ID:           article.ID,
+		UserID:       article.UserID,
+		Title:        article.Title,
+		Slug:         article.Slug,
+		Content:      article.Content,
+		Format:       article.Format,
+		ContentHTML:  article.ContentHTML,
+		Excerpt:      article.Excerpt,
+		CoverMediaID: article.CoverMediaID,
+		CoverURL:     article.CoverURL,
+		Version:      article.Version,
+		DeletedAt:    article.DeletedAt,
+		Tags:         tagsOrEmpty(article.Tags),
+		Reactions:    reactionsOrEmpty(article.Reactions),
+		Author:       article.Author,
+		SourceURL:    article.SourceURL,
+		CreatedAt:    article.CreatedAt,
+		UpdatedAt:    article.UpdatedAt,
+	}
+}
+
+func tagsOrEmpty(tags []string) []string {
+	if tags == nil {
+		return []string{}
+	}
+	return tags
+}
+
+func reactionsOrEmpty(reactions map[string]int) map[string]int {
+	if reactions == nil {
+		return map[string]int{}
+	}
+	return reactions
+}
+
+const (
+	maxTagsPerArticle = 10
+	maxTagNameLength  = 50
+)
+
+// normalizeTags trims tag names, drops empty and duplicate ones and enforces the tag limits.
+// Two names are duplicates when they share a slug, in which case the first spelling wins.
+func normalizeTags(names []string) ([]string, *customerror.CustomError) {
+	tags := []string{}
+	seen := map[string]bool{}
+	for _, name := range names {
+		name = strings.Join(strings.Fields(name), " ")
+		if name == "" {
+			continue
+		}
+		if len(name) > maxTagNameLength {
+			return nil, customerror.NewCustomError(errors.New("tag too long"), fmt.Sprintf("tag %q is longer than %d characters", name, maxTagNameLength), 400)
+		}
+
+		tagSlug := slug.Make(name, 0)
+		if tagSlug == "" {
+			return nil, customerror.NewCustomError(errors.New("invalid tag"), fmt.Sprintf("tag %q must contain letters or digits", name), 400)
+		}
+		if seen[tagSlug] {
+			continue
+		}
+		seen[tagSlug] = true
+		tags = append(tags, name)
+	}
+
+	if len(tags) > maxTagsPerArticle {
+		return nil, customerror.NewCustomError(errors.New("too many tags"), fmt.Sprintf("an article can have at most %d tags", maxTagsPerArticle), 400)
+	}
+	return tags, nil
 }
 
-func (s *ArticlesService) CreateArticlesWithCsv(userId int, file *multipart.FileHeader) *customerror.CustomError {
-	enterFunc := func(title string, url string) *customerror.CustomError {
-		return s.articlesRepo.CreateArticle(userId, title, url)
+// checkFormat rejects content formats the renderer does not know
+func checkFormat(format string) *customerror.CustomError {
+	if !markup.IsValidFormat(format) {
+		return customerror.NewCustomError(errors.New("invalid format"), "format must be one of markdown, html or plain", 400)
 	}
+	return nil
+}
 
-	// Validate file extension
-	log.Printf("file.Filename: %s", filepath.Ext(file.Filename))
-	if filepath.Ext(file.Filename) != ".csv" {
-		return customerror.NewCustomError(errors.New("Only CSV files are allowed"), "Only CSV files are allowed", 400)
+// checkCoverMedia makes sure a cover image exists and was uploaded by userID.
+// A nil or zero mediaID means no cover and is always accepted.
+func (s *ArticlesService) checkCoverMedia(userID int, mediaID *int) *customerror.CustomError {
+	if mediaID == nil || *mediaID == 0 {
+		return nil
 	}
 
-	// Process the uploaded file
+	media, cuserr := s.mediaRepo.GetMediaByID(*mediaID)
+	if cuserr != nil {
+		if cuserr.HTTPCode == 404 {
+			return customerror.NewCustomError(cuserr.Original, "cover media does not exist", 400)
+		}
+		return cuserr
+	}
+	if media.UserID != userID {
+		return customerror.NewCustomError(nil, "You are not authorized to use this media", 403)
+	}
+	return nil
+}
 
-	cuserr := utils.ProssesCSV(file, enterFunc)
+func (s *ArticlesService) CreateArticle(userId int, req *models.ArticleRequest) *customerror.CustomError {
+	tags, cuserr := normalizeTags(req.Tags)
 	if cuserr != nil {
 		return cuserr
 	}
 
+	format := req.Format
+	if format == "" {
+		format = markup.FormatMarkdown
+	}
+	if cuserr := checkFormat(format); cuserr != nil {
+		return cuserr
+	}
+	if cuserr := s.checkCoverMedia(userId, req.CoverMediaID); cuserr != nil {
+		return cuserr
+	}
+
+	return s.notifyChanged(s.articlesRepo.CreateArticle(&articlesmodels.Article{
+		UserID:       userId,
+		Title:        req.Title,
+		Content:      req.Content,
+		Format:       format,
+		Tags:         tags,
+		CoverMediaID: req.CoverMediaID,
+	}))
+}
+
+// Import creates an article owned by userId for every record of reader. A
+// record without content has it scraped from its URL by scraper. mode tells
+// what to do with a URL the user imported before: skip it, update its article
+// when the content changed, or create another article. A URL repeated in the
+// file is only imported once unless mode is duplicate. The result of each record
+// is recorded in progress; cancelling ctx stops the import after the records in flight.
+//
+// New articles are created in batches. A best-effort import keeps the articles
+// of every row that works; an atomic one creates them all in one transaction
+// at the end, or none when any row failed, and cannot update articles.
+//
+// Articles whose content was scraped follow their page, checked for changes
+// every refreshInterval minutes, or at the interval of its host when nil.
+func (s *ArticlesService) Import(ctx context.Context, userId int, reader utils.ImportReader, mode string, atomic bool, refreshInterval *int, scraper *utils.Scraper, progress *utils.ImportProgress) *customerror.CustomError {
+	if atomic && mode == importsmodels.ModeUpdate {
+		return customerror.NewCustomError(errors.New("atomic update import"), "an atomic import cannot use mode update", 400)
+	}
+	batch := &articleBatch{service: s, progress: progress, atomic: atomic}
+
+	enterFunc := func(record *utils.ImportRecord) (int, string, *customerror.CustomError) {
+		article, existing, reason, cuserr := s.planImport(userId, mode, refreshInterval, record)
+		if cuserr != nil {
+			return 0, "", cuserr
+		}
+		if existing == nil {
+			batch.add(record, article)
+			return 0, "", nil
+		}
+
+		if reason == "" {
+			if cuserr := s.updateImportedArticle(userId, existing, article); cuserr != nil {
+				return 0, "", cuserr
+			}
+		}
+		if mode == importsmodels.ModeUpdate && existing.DeletedAt == nil && article.Source != nil {
+			// Follows the page from now on, even when its content was unchanged
+			article.Source.ArticleID = existing.ID
+			if cuserr := s.articlesRepo.SaveArticleSource(article.Source); cuserr != nil {
+				log.Printf("Error saving the source of article %d: %s", existing.ID, cuserr.OriginalMessage())
+			}
+		}
+		return existing.ID, reason, nil
+	}
+
+	cuserr := utils.ProcessImport(ctx, reader, scraper, s.importCheckFunc(userId, mode), enterFunc, progress)
+	if atomic {
+		if cuserr != nil {
+			progress.Discard("nothing was imported, the file could not be read to the end")
+			return cuserr
+		}
+		return batch.commit(ctx)
+	}
+	batch.flush()
+	return cuserr
+}
+
+// PreviewImport finds what Import would do with every record of reader in
+// mode, without writing anything. Only the pages of the first sample records
+// without content are scraped; the other records are previewed without it.
+func (s *ArticlesService) PreviewImport(ctx context.Context, userId int, reader utils.ImportReader, mode string, sample int, scraper *utils.Scraper) ([]*models.ImportPreviewRow, *customerror.CustomError) {
+	var mu sync.Mutex
+	rows := []*models.ImportPreviewRow{}
+
+	enterFunc := func(record *utils.ImportRecord) (int, string, *customerror.CustomError) {
+		article, existing, reason, cuserr := s.planImport(userId, mode, nil, record)
+		if cuserr != nil {
+			return 0, "", cuserr
+		}
+		if reason != "" {
+			return existing.ID, reason, nil
+		}
+
+		row := &models.ImportPreviewRow{
+			Row:     record.Line,
+			Action:  importsmodels.ActionCreate,
+			Title:   record.Title,
+			URL:     record.URL,
+			Format:  article.Format,
+			Tags:    article.Tags,
+			Date:    record.Date,
+			Scraped: record.Page != nil,
+		}
+		if existing != nil {
+			row.Action = importsmodels.ActionUpdate
+			row.ArticleID = &existing.ID
+		}
+		if article.Content != "" {
+			contentHTML, err := markup.Render(article.Format, article.Content)
+			if err != nil {
+				return 0, "", customerror.NewCustomError(err, "content cannot be rendered", 400)
+			}
+			row.Excerpt = markup.Excerpt(contentHTML, previewExcerptLength)
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+		rows = append(rows, row)
+		// Left unreported, nothing is created
+		return 0, "", nil
+	}
+
+	progress := &utils.ImportProgress{}
+	if cuserr := utils.PreviewImport(ctx, reader, scraper, sample, s.importCheckFunc(userId, mode), enterFunc, progress); cuserr != nil {
+		return nil, cuserr
+	}
+
+	for _, result := range progress.TakeResults() {
+		row := &models.ImportPreviewRow{
+			Row:       result.Row,
+			Action:    importsmodels.ActionSkip,
+			Title:     result.Title,
+			URL:       result.URL,
+			ArticleID: result.ArticleID,
+			Error:     result.Error,
+		}
+		if result.Status == importsmodels.RowFailed {
+			row.Action = importsmodels.ActionFail
+		}
+		rows = append(rows, row)
+	}
+	sort.Slice(rows, func(i, j int) bool {
+		return rows[i].Row < rows[j].Row
+	})
+	return rows, nil
+}
+
+// previewExcerptLength is the number of characters of content shown per previewed row
+const previewExcerptLength = 200
+
+// importBatchSize is how many new articles of a best-effort import are created per transaction
+const importBatchSize = 100
+
+// articleBatch gathers the new articles of an import to create them together.
+// Each row is reported once its article is written. It is safe for concurrent use.
+type articleBatch struct {
+	service  *ArticlesService
+	progress *utils.ImportProgress
+	// atomic batches keep every article until commit instead of writing every importBatchSize
+	atomic bool
+
+	mu       sync.Mutex
+	records  []*utils.ImportRecord
+	articles []*articlesmodels.Article
+}
+
+// add stages the article of record, writing the batch once it is full
+func (b *articleBatch) add(record *utils.ImportRecord, article *articlesmodels.Article) {
+	b.progress.Stage(record)
+
+	b.mu.Lock()
+	b.records = append(b.records, record)
+	b.articles = append(b.articles, article)
+	if b.atomic || len(b.articles) < importBatchSize {
+		b.mu.Unlock()
+		return
+	}
+	records, articles := b.take()
+	b.mu.Unlock()
+
+	b.write(records, articles)
+}
+
+// take empties the batch, returning what it held. b.mu must be held.
+func (b *articleBatch) take() ([]*utils.ImportRecord, []*articlesmodels.Article) {
+	records, articles := b.records, b.articles
+	b.records, b.articles = nil, nil
+	return records, articles
+}
+
+// flush writes the articles left in a best-effort batch
+func (b *articleBatch) flush() {
+	b.mu.Lock()
+	records, articles := b.take()
+	b.mu.Unlock()
+
+	if len(articles) > 0 {
+		b.write(records, articles)
+	}
+}
+
+// write creates articles in one transaction. When that fails they are created
+// one by one, so only the rows at fault fail.
+func (b *articleBatch) write(records []*utils.ImportRecord, articles []*articlesmodels.Article) {
+	if cuserr := b.service.notifyChanged(b.service.articlesRepo.CreateArticles(articles)); cuserr == nil {
+		for i, article := range articles {
+			b.progress.Settle(records[i], article.ID, "")
+		}
+		return
+	}
+
+	for i, article := range articles {
+		if cuserr := b.service.notifyChanged(b.service.articlesRepo.CreateArticle(article)); cuserr != nil {
+			b.progress.Settle(records[i], 0, cuserr.Error())
+			continue
+		}
+		b.progress.Settle(records[i], article.ID, "")
+	}
+}
+
+// commit creates every article of an atomic batch in one transaction, or none
+// when a row of the import failed, the import was cancelled or the transaction fails
+func (b *articleBatch) commit(ctx context.Context) *customerror.CustomError {
+	b.mu.Lock()
+	records, articles := b.take()
+	b.mu.Unlock()
+
+	if ctx.Err() != nil {
+		b.progress.Discard("nothing was imported, the import was cancelled")
+		return nil
+	}
+	if failed := b.progress.Counts().Failed; failed > 0 {
+		message := fmt.Sprintf("nothing was imported, %d rows failed", failed)
+		b.progress.Discard(message)
+		return customerror.NewCustomError(errors.New(message), message, 400)
+	}
+
+	if cuserr := b.service.notifyChanged(b.service.articlesRepo.CreateArticles(articles)); cuserr != nil {
+		b.progress.Discard("nothing was imported: " + cuserr.Error())
+		return cuserr
+	}
+	for i, article := range articles {
+		b.progress.Settle(records[i], article.ID, "")
+	}
 	return nil
 }
 
+// importCheckFunc returns the check of the URLs of an import of userId in
+// mode, skipping a URL repeated in the file unless mode is duplicate, and a
+// URL imported before in mode skip, before its page is scraped.
+func (s *ArticlesService) importCheckFunc(userId int, mode string) utils.ImportCheckFunc {
+	return func(record *utils.ImportRecord) (int, string, *customerror.CustomError) {
+		if record.RepeatedURL && mode != importsmodels.ModeDuplicate {
+			return 0, "url appears earlier in the file", nil
+		}
+		if mode != importsmodels.ModeSkip {
+			return 0, "", nil
+		}
+
+		sourceURL := utils.NormalizeURL(record.URL)
+
+		existing, cuserr := s.articlesRepo.GetArticleBySourceURL(userId, sourceURL)
+		if cuserr != nil {
+			if cuserr.HTTPCode == http.StatusNotFound {
+				return 0, "", nil
+			}
+			return 0, "", cuserr
+		}
+		return existing.ID, "article from this url already exists", nil
+	}
+}
+
+// planImport builds the article of record for userId and finds what importing
+// it in mode does, without writing anything: create the article, update the
+// existing article imported from the same page with it, or skip the record
+// for the reason returned along with that existing article. A URL repeated in
+// the file in mode duplicate stays with the article of its first record.
+func (s *ArticlesService) planImport(userId int, mode string, refreshInterval *int, record *utils.ImportRecord) (*articlesmodels.Article, *articlesmodels.Article, string, *customerror.CustomError) {
+	tags, cuserr := normalizeTags(record.Tags)
+	if cuserr != nil {
+		return nil, nil, "", cuserr
+	}
+	format := record.Format
+	if format == "" {
+		format = markup.FormatPlain
+	}
+	if cuserr := checkFormat(format); cuserr != nil {
+		return nil, nil, "", cuserr
+	}
+
+	article := &articlesmodels.Article{
+		UserID:  userId,
+		Title:   record.Title,
+		Content: record.Content,
+		Format:  format,
+		Tags:    tags,
+	}
+	if record.Date != nil {
+		article.CreatedAt = *record.Date
+	}
+	if record.URL == "" {
+		return article, nil, "", nil
+	}
+
+	sourceURL := utils.NormalizeURL(record.URL)
+	contentHash := utils.ContentHash(record.Title, record.Content)
+	article.SourceURL = &sourceURL
+	article.ContentHash = &contentHash
+	if record.Page != nil {
+		article.Source = s.newArticleSource(sourceURL, record, refreshInterval)
+	}
+
+	existing, cuserr := s.articlesRepo.GetArticleBySourceURL(userId, sourceURL)
+	if cuserr != nil {
+		if cuserr.HTTPCode != http.StatusNotFound {
+			return nil, nil, "", cuserr
+		}
+		// Repeated in the file, the URL stays with the article of its first
+		// record, which may still be waiting in the batch
+		if record.RepeatedURL {
+			article.SourceURL = nil
+			article.Source = nil
+		}
+		return article, nil, "", nil
+	}
+
+	switch mode {
+	case importsmodels.ModeSkip:
+		// Imported by another import since the check
+		return article, existing, "article from this url already exists", nil
+	case importsmodels.ModeUpdate:
+		if existing.DeletedAt != nil {
+			return article, existing, "article from this url is in the trash", nil
+		}
+		if existing.ContentHash != nil && *existing.ContentHash == contentHash {
+			return article, existing, "content unchanged", nil
+		}
+		return article, existing, "", nil
+	}
+
+	// Mode duplicate: the URL stays with the first article
+	article.SourceURL = nil
+	article.Source = nil
+	return article, nil, "", nil
+}
+
+// updateImportedArticle writes the title and content imported again into the
+// article imported from the same page, along with the tags of the import if
+// it has any. The previous version is kept as a revision.
+func (s *ArticlesService) updateImportedArticle(userId int, existing *articlesmodels.Article, imported *articlesmodels.Article) *customerror.CustomError {
+	changes := &articlesmodels.ArticleChanges{
+		Title:       &imported.Title,
+		Content:     &imported.Content,
+		Format:      &imported.Format,
+		ContentHash: imported.ContentHash,
+	}
+	if len(imported.Tags) > 0 {
+		changes.Tags = &imported.Tags
+	}
+	return s.notifyChanged(s.articlesRepo.PatchArticle(existing.ID, userId, changes, nil))
+}
+
+// newArticleSource returns how the article of a record scraped from the page
+// at sourceURL follows it, the page having been checked just now
+func (s *ArticlesService) newArticleSource(sourceURL string, record *utils.ImportRecord, refreshInterval *int) *articlesmodels.ArticleSource {
+	now := time.Now()
+	pageHash := scrapedPageHash(record.Page)
+	return &articlesmodels.ArticleSource{
+		PageHash:        &pageHash,
+		RefreshInterval: refreshInterval,
+		TitleFromPage:   record.TitleFromPage,
+		ETag:            record.Page.ETag,
+		LastModified:    record.Page.LastModified,
+		CheckedAt:       &now,
+		NextCheckAt:     s.nextSourceCheck(sourceURL, refreshInterval, now),
+	}
+}
+
+// scrapedPageHash identifies what was found on a page, to tell whether it
+// changed at the next check whatever was done to the article meanwhile
+func scrapedPageHash(page *utils.ScrapedPage) string {
+	return utils.ContentHash(page.Title, page.Content)
+}
+
+// nextSourceCheck returns when the page at sourceURL checked at from is
+// checked again, nil for never
+func (s *ArticlesService) nextSourceCheck(sourceURL string, refreshInterval *int, from time.Time) *time.Time {
+	interval := s.refreshIntervals.For(sourceURL, refreshInterval)
+	if interval <= 0 {
+		return nil
+	}
+	next := from.Add(interval)
+	return &next
+}
+
 func (s *ArticlesService) GetArticleByID(id int) (*models.ArticleResponse, *customerror.CustomError) {
 	article, cuserr := s.articlesRepo.GetArticleByID(id)
 	if cuserr != nil {
 		return nil, cuserr
 	}
 
-	return &models.ArticleResponse{
-		ID:      article.ID,
-		UserID:  article.UserID,
-		Title:   article.Title,
-		Content: article.Content,
-	}, nil
+	return newArticleResponse(article), nil
+}
+
+// GetArticleBySlug looks an article up by its current or an earlier slug.
+// The response carries the current slug, which differs from articleSlug for old links.
+func (s *ArticlesService) GetArticleBySlug(articleSlug string) (*models.ArticleResponse, *customerror.CustomError) {
+	article, cuserr := s.articlesRepo.GetArticleBySlug(articleSlug)
+	if cuserr != nil {
+		return nil, cuserr
+	}
+
+	return newArticleResponse(article), nil
+}
+
+// GetArticleByAuthorSlug looks an article up by its author's username and its slug.
+// Articles written by someone else are reported as not found.
+func (s *ArticlesService) GetArticleByAuthorSlug(username string, articleSlug string) (*models.ArticleResponse, *customerror.CustomError) {
+	user, cuserr := s.authRepo.GetUserByUsername(username)
+	if cuserr != nil {
+		return nil, cuserr
+	}
+
+	article, cuserr := s.articlesRepo.GetArticleBySlug(articleSlug)
+	if cuserr != nil {
+		return nil, cuserr
+	}
+	if article.UserID != user.ID {
+		return nil, customerror.NewCustomError(errors.New("article belongs to another user"), "Record not found", 404)
+	}
+
+	return newArticleResponse(article), nil
 }
 
 func (s *ArticlesService) GetArticlesByUserID(userID int) (*models.ArticlesResponse, *customerror.CustomError) {
@@ -71,19 +617,15 @@ func (s *ArticlesService) GetArticlesByUserID(userID int) (*models.ArticlesRespo
 		Articles: []*models.ArticleResponse{},
 	}
 	for _, article := range articles {
-		response.Articles = append(response.Articles, &models.ArticleResponse{
-			ID:      article.ID,
-			UserID:  article.UserID,
-			Title:   article.Title,
-			Content: article.Content,
-		})
+		response.Articles = append(response.Articles, newArticleResponse(article))
 	}
 
 	return response, nil
 }
 
-func (s *ArticlesService) GetAllArticles() (*models.ArticlesResponse, *customerror.CustomError) {
-	articles, cuserr := s.articlesRepo.GetAllArticles()
+// GetAllArticles lists visible articles, only those carrying every tag slug in tags when it is not nil.
+func (s *ArticlesService) GetAllArticles(tags []string) (*models.ArticlesResponse, *customerror.CustomError) {
+	articles, cuserr := s.articlesRepo.GetAllArticles(tags)
 	if cuserr != nil {
 		return nil, cuserr
 	}
@@ -92,18 +634,15 @@ func (s *ArticlesService) GetAllArticles() (*models.ArticlesResponse, *customerr
 		Articles: []*models.ArticleResponse{},
 	}
 	for _, article := range articles {
-		response.Articles = append(response.Articles, &models.ArticleResponse{
-			ID:      article.ID,
-			UserID:  article.UserID,
-			Title:   article.Title,
-			Content: article.Content,
-		})
+		response.Articles = append(response.Articles, newArticleResponse(article))
 	}
 	return response, nil
 }
 
-func (s *ArticlesService) SearchArticles(limit, offset int, query string) (*models.ArticlesResponse, *customerror.CustomError) {
-	articles, cuserr := s.articlesRepo.SearchArticles(limit, offset, query)
+// GetLatestArticles lists a page of the most recently created visible articles, newest first,
+// only those carrying every tag slug in tags when it is not nil.
+func (s *ArticlesService) GetLatestArticles(limit int, offset int, tags []string) (*models.ArticlesResponse, *customerror.CustomError) {
+	articles, cuserr := s.articlesRepo.GetLatestArticles(limit, offset, tags)
 	if cuserr != nil {
 		return nil, cuserr
 	}
@@ -112,17 +651,123 @@ func (s *ArticlesService) SearchArticles(limit, offset int, query string) (*mode
 		Articles: []*models.ArticleResponse{},
 	}
 	for _, article := range articles {
-		response.Articles = append(response.Articles, &models.ArticleResponse{
-			ID:      article.ID,
-			UserID:  article.UserID,
-			Title:   article.Title,
-			Content: article.Content,
-		})
+		response.Articles = append(response.Articles, newArticleResponse(article))
+	}
+	return response, nil
+}
+
+// GetLatestArticlesByUserID lists a page of the most recently created visible articles of a user, newest first.
+func (s *ArticlesService) GetLatestArticlesByUserID(userID int, limit int, offset int) (*models.ArticlesResponse, *customerror.CustomError) {
+	articles, cuserr := s.articlesRepo.GetLatestArticlesByUserID(userID, limit, offset)
+	if cuserr != nil {
+		return nil, cuserr
+	}
+
+	var response = &models.ArticlesResponse{
+		Articles: []*models.ArticleResponse{},
+	}
+	for _, article := range articles {
+		response.Articles = append(response.Articles, newArticleResponse(article))
+	}
+	return response, nil
+}
+
+// GetLatestArticlesByUsername lists a page of the most recently created visible articles of the
+// user called username, newest first.
+func (s *ArticlesService) GetLatestArticlesByUsername(username string, limit int, offset int) (*models.AuthorArticlesResponse, *customerror.CustomError) {
+	user, cuserr := s.authRepo.GetUserByUsername(username)
+	if cuserr != nil {
+		return nil, cuserr
+	}
+
+	articles, cuserr := s.GetLatestArticlesByUserID(user.ID, limit, offset)
+	if cuserr != nil {
+		return nil, cuserr
+	}
+
+	return &models.AuthorArticlesResponse{
+		UserID:   user.ID,
+		Username: user.Username,
+		Articles: articles.Articles,
+	}, nil
+}
+
+func (s *ArticlesService) SearchArticles(limit, offset int, query string, tags []string) (*models.ArticlesResponse, *customerror.CustomError) {
+	articles, cuserr := s.articlesRepo.SearchArticles(limit, offset, query, tags)
+	if cuserr != nil {
+		return nil, cuserr
+	}
+
+	var response = &models.ArticlesResponse{
+		Articles: []*models.ArticleResponse{},
+	}
+	for _, article := range articles {
+		response.Articles = append(response.Articles, newArticleResponse(article))
 	}
 	return response, nil
 }
 
-func (s *ArticlesService) UpdateArticle(userID int, articleId int, req *models.ArticleRequest) *customerror.CustomError {
+// UpdateArticle replaces the title, content and tags of an article owned by userID.
+// ifMatch holds the versions from the If-Match header, nil when the client sent none.
+func (s *ArticlesService) UpdateArticle(userID int, articleId int, req *models.ArticleRequest, ifMatch []int) *customerror.CustomError {
+	if cuserr := s.checkArticleOwner(userID, articleId); cuserr != nil {
+		return cuserr
+	}
+
+	tags, cuserr := normalizeTags(req.Tags)
+	if cuserr != nil {
+		return cuserr
+	}
+
+	changes := &articlesmodels.ArticleChanges{
+		Title:   &req.Title,
+		Content: &req.Content,
+		Tags:    &tags,
+	}
+	if req.Format != "" {
+		if cuserr := checkFormat(req.Format); cuserr != nil {
+			return cuserr
+		}
+		changes.Format = &req.Format
+	}
+	if req.CoverMediaID != nil {
+		if cuserr := s.checkCoverMedia(userID, req.CoverMediaID); cuserr != nil {
+			return cuserr
+		}
+		changes.CoverMediaID = req.CoverMediaID
+	}
+	return s.notifyChanged(s.articlesRepo.PatchArticle(articleId, userID, changes, ifMatch))
+}
+
+// PatchArticle updates only the fields present in req on an article owned by userID.
+func (s *ArticlesService) PatchArticle(userID int, articleId int, req *models.ArticlePatchRequest, ifMatch []int) *customerror.CustomError {
+	if cuserr := s.checkArticleOwner(userID, articleId); cuserr != nil {
+		return cuserr
+	}
+
+	changes := &articlesmodels.ArticleChanges{
+		Title:   req.Title,
+		Content: req.Content,
+		Format:  req.Format,
+	}
+	if req.CoverMediaID != nil {
+		if cuserr := s.checkCoverMedia(userID, req.CoverMediaID); cuserr != nil {
+			return cuserr
+		}
+		changes.CoverMediaID = req.CoverMediaID
+	}
+	if req.Tags != nil {
+		tags, cuserr := normalizeTags(*req.Tags)
+		if cuserr != nil {
+			return cuserr
+		}
+		changes.Tags = &tags
+	}
+	return s.notifyChanged(s.articlesRepo.PatchArticle(articleId, userID, changes, ifMatch))
+}
+
+// checkArticleOwner makes sure the article exists and belongs to userID.
+func (s *ArticlesService) checkArticleOwner(userID int, articleId int) *customerror.CustomError {
 	article, cuserr := s.articlesRepo.GetArticleByID(articleId)
 
 	if cuserr != nil {
@@ -132,11 +777,10 @@ func (s *ArticlesService) UpdateArticle(userID int, articleId int, req *models.A
 	if article.UserID != userID {
 		return customerror.NewCustomError(nil, "You are not authorized to update this article", 403)
 	}
-
-	return s.articlesRepo.UpdateArticle(articleId, req.Title, req.Content)
+	return nil
 }
 
-func (s *ArticlesService) DeleteArticleByID(userID int, articleId int) *customerror.CustomError {
+func (s *ArticlesService) DeleteArticleByID(userID int, articleId int, ifMatch []int) *customerror.CustomError {
 	article, cuserr := s.articlesRepo.GetArticleByID(articleId)
 
 	if cuserr != nil {
@@ -146,5 +790,343 @@ func (s *ArticlesService) DeleteArticleByID(userID int, articleId int) *customer
 	if article.UserID != userID {
 		return customerror.NewCustomError(nil, "You are not authorized to update this article", 403)
 	}
-	return s.articlesRepo.DeleteArticleByID(articleId)
+	return s.notifyChanged(s.articlesRepo.DeleteArticleByID(articleId, ifMatch))
+}
+
+func (s *ArticlesService) GetArticleRevisions(articleID int) (*models.ArticleRevisionsResponse, *customerror.CustomError) {
+	if _, cuserr := s.articlesRepo.GetArticleByID(articleID); cuserr != nil {
+		return nil, cuserr
+	}
+
+	revisions, cuserr := s.articlesRepo.GetArticleRevisions(articleID)
+	if cuserr != nil {
+		return nil, cuserr
+	}
+
+	var response = &models.ArticleRevisionsResponse{
+		Revisions: []*models.ArticleRevisionResponse{},
+	}
+	for _, revision := range revisions {
+		response.Revisions = append(response.Revisions, &models.ArticleRevisionResponse{
+			Revision:  revision.Revision,
+			Title:     revision.Title,
+			EditorID:  revision.EditorID,
+			CreatedAt: revision.CreatedAt,
+		})
+	}
+	return response, nil
+}
+
+func (s *ArticlesService) GetArticleRevision(articleID int, revision int) (*models.ArticleRevisionResponse, *customerror.CustomError) {
+	rev, cuserr := s.articlesRepo.GetArticleRevision(articleID, revision)
+	if cuserr != nil {
+		return nil, cuserr
+	}
+
+	return &models.ArticleRevisionResponse{
+		Revision:  rev.Revision,
+		Title:     rev.Title,
+		Content:   rev.Content,
+		Format:    rev.Format,
+		EditorID:  rev.EditorID,
+		CreatedAt: rev.CreatedAt,
+	}, nil
+}
+
+// DiffArticleRevisions compares revision from against revision to.
+// A to of 0 compares against the current version of the article.
+func (s *ArticlesService) DiffArticleRevisions(articleID int, from int, to int) (*models.ArticleRevisionDiffResponse, *customerror.CustomError) {
+	fromRev, cuserr := s.articlesRepo.GetArticleRevision(articleID, from)
+	if cuserr != nil {
+		return nil, cuserr
+	}
+
+	response := &models.ArticleRevisionDiffResponse{
+		From: strconv.Itoa(from),
+	}
+
+	var toTitle, toContent string
+	if to == 0 {
+		article, cuserr := s.articlesRepo.GetArticleByID(articleID)
+		if cuserr != nil {
+			return nil, cuserr
+		}
+		response.To = "current"
+		toTitle, toContent = article.Title, article.Content
+	} else {
+		toRev, cuserr := s.articlesRepo.GetArticleRevision(articleID, to)
+		if cuserr != nil {
+			return nil, cuserr
+		}
+		response.To = strconv.Itoa(to)
+		toTitle, toContent = toRev.Title, toRev.Content
+	}
+
+	response.Title = utils.DiffLines(fromRev.Title, toTitle)
+	response.Content = utils.DiffLines(fromRev.Content, toContent)
+	return response, nil
+}
+
+// RestoreArticleRevision makes an old revision the current version of the article.
+// The version being replaced is kept as a new revision, so a restore can itself be undone.
+func (s *ArticlesService) RestoreArticleRevision(userID int, articleID int, revision int) *customerror.CustomError {
+	if cuserr := s.checkArticleOwner(userID, articleID); cuserr != nil {
+		return cuserr
+	}
+
+	rev, cuserr := s.articlesRepo.GetArticleRevision(articleID, revision)
+	if cuserr != nil {
+		return cuserr
+	}
+
+	changes := &articlesmodels.ArticleChanges{
+		Title:   &rev.Title,
+		Content: &rev.Content,
+		Format:  &rev.Format,
+	}
+	return s.notifyChanged(s.articlesRepo.PatchArticle(articleID, userID, changes, nil))
+}
+
+func (s *ArticlesService) GetTrash(userID int) (*models.ArticlesResponse, *customerror.CustomError) {
+	articles, cuserr := s.articlesRepo.GetDeletedArticlesByUserID(userID)
+	if cuserr != nil {
+		return nil, cuserr
+	}
+
+	var response = &models.ArticlesResponse{
+		Articles: []*models.ArticleResponse{},
+	}
+	for _, article := range articles {
+		response.Articles = append(response.Articles, newArticleResponse(article))
+	}
+	return response, nil
+}
+
+func (s *ArticlesService) RestoreArticle(userID int, articleId int) *customerror.CustomError {
+	if cuserr := s.checkTrashOwner(userID, articleId); cuserr != nil {
+		return cuserr
+	}
+
+	return s.notifyChanged(s.articlesRepo.RestoreArticleByID(articleId))
+}
+
+func (s *ArticlesService) PurgeArticle(userID int, articleId int) *customerror.CustomError {
+	if cuserr := s.checkTrashOwner(userID, articleId); cuserr != nil {
+		return cuserr
+	}
+
+	return s.articlesRepo.PurgeArticleByID(articleId)
+}
+
+// checkTrashOwner makes sure the article is in the trash and belongs to userID.
+func (s *ArticlesService) checkTrashOwner(userID int, articleId int) *customerror.CustomError {
+	article, cuserr := s.articlesRepo.GetDeletedArticleByID(articleId)
+	if cuserr != nil {
+		return cuserr
+	}
+
+	if article.UserID != userID {
+		return customerror.NewCustomError(nil, "You are not authorized to update this article", 403)
+	}
+	return nil
+}
+
+// RunTrashPurger permanently deletes articles that have been in the trash longer
+// than retention, checking every interval until ctx is cancelled.
+// A retention of zero or less keeps trashed articles forever.
+func (s *ArticlesService) RunTrashPurger(ctx context.Context, retention time.Duration, interval time.Duration) {
+	if retention <= 0 || interval <= 0 {
+		log.Println("Trash purger disabled")
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		purged, cuserr := s.articlesRepo.PurgeDeletedArticles(time.Now().Add(-retention))
+		if cuserr != nil {
+			log.Printf("Trash purge failed: %s", cuserr.OriginalMessage())
+		} else if purged > 0 {
+			log.Printf("Trash purge removed %d articles", purged)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+const (
+	// sourceRefreshBatch is how many due sources the refresher takes at once
+	sourceRefreshBatch = 50
+	// sourceRefreshWorkers is how many pages the refresher checks at once, the
+	// fetcher still limiting the requests to each host
+	sourceRefreshWorkers = 5
+	// sourceRefreshLease is how long a source taken by a refresher that never
+	// saved it back waits before being checked again
+	sourceRefreshLease = 30 * time.Minute
+)
+
+// RunSourceRefresher checks the pages articles were imported from for changes
+// with scraper, looking for those due every interval until ctx is cancelled.
+// Several server processes can run it at once, each source is checked by one.
+func (s *ArticlesService) RunSourceRefresher(ctx context.Context, scraper *utils.Scraper, interval time.Duration) {
+	if interval <= 0 {
+		log.Println("Source refresher disabled")
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		s.refreshDueSources(ctx, scraper)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// refreshDueSources checks every source due, a batch at a time
+func (s *ArticlesService) refreshDueSources(ctx context.Context, scraper *utils.Scraper) {
+	for ctx.Err() == nil {
+		sources, cuserr := s.articlesRepo.ClaimDueArticleSources(sourceRefreshBatch, sourceRefreshLease)
+		if cuserr != nil {
+			log.Printf("Source refresh failed: %s", cuserr.OriginalMessage())
+			return
+		}
+
+		jobs := make(chan *articlesmodels.ArticleSource)
+		var wg sync.WaitGroup
+		for range sourceRefreshWorkers {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for source := range jobs {
+					s.refreshSource(ctx, scraper, source)
+				}
+			}()
+		}
+		for _, source := range sources {
+			jobs <- source
+		}
+		close(jobs)
+		wg.Wait()
+
+		if len(sources) < sourceRefreshBatch {
+			return
+		}
+	}
+}
+
+// refreshSource fetches the page of source again if it changed since the last
+// check, and updates the article when the hash of what was found on the page
+// changed, the previous version being kept as a revision. The source is saved with the
+// outcome and its next check.
+func (s *ArticlesService) refreshSource(ctx context.Context, scraper *utils.Scraper, source *articlesmodels.ArticleSource) {
+	now := time.Now()
+	article, cuserr := s.articlesRepo.GetArticleByID(source.ArticleID)
+	trashed := cuserr != nil && cuserr.HTTPCode == http.StatusNotFound
+	if trashed {
+		article, cuserr = s.articlesRepo.GetDeletedArticleByID(source.ArticleID)
+	}
+	if cuserr != nil {
+		// Checked again once the lease runs out, unless purged with its source meanwhile
+		if cuserr.HTTPCode != http.StatusNotFound {
+			log.Printf("Error refreshing article %d: %s", source.ArticleID, cuserr.OriginalMessage())
+		}
+		return
+	}
+	if trashed || article.SourceURL == nil {
+		// Not checked while in the trash; an article without a page has nothing to check
+		source.NextCheckAt = nil
+		if article.SourceURL != nil {
+			source.NextCheckAt = s.nextSourceCheck(*article.SourceURL, source.RefreshInterval, now)
+		}
+		if cuserr := s.articlesRepo.SaveArticleSource(source); cuserr != nil {
+			log.Printf("Error saving the source of article %d: %s", article.ID, cuserr.OriginalMessage())
+		}
+		return
+	}
+
+	sourceURL := *article.SourceURL
+	page, err := scraper.ScrapeIfChanged(ctx, &fetch.Request{URL: sourceURL, ETag: source.ETag, LastModified: source.LastModified})
+	if ctx.Err() != nil {
+		// Stopped by a shutdown, not a failure of the page
+		return
+	}
+
+	source.CheckedAt = &now
+	failure := ""
+	switch {
+	case errors.Is(err, fetch.ErrNotModified):
+	case err != nil:
+		failure = err.Error()
+	case page.Content == "":
+		failure = "no content found"
+	default:
+		changed, cuserr := s.updateFromSource(article, source, page)
+		if cuserr != nil {
+			failure = cuserr.Error()
+			break
+		}
+		// Only once the article has the content, so a failed update is retried
+		pageHash := scrapedPageHash(page)
+		source.ETag, source.LastModified, source.PageHash = page.ETag, page.LastModified, &pageHash
+		if changed {
+			source.ChangedAt = &now
+			log.Printf("Article %d updated from %s", article.ID, sourceURL)
+		}
+	}
+
+	if failure != "" {
+		source.Failures++
+		source.LastError = &failure
+	} else {
+		source.Failures = 0
+		source.LastError = nil
+	}
+	source.NextCheckAt = s.nextSourceCheck(sourceURL, source.RefreshInterval, now)
+	if cuserr := s.articlesRepo.SaveArticleSource(source); cuserr != nil {
+		log.Printf("Error saving the source of article %d: %s", article.ID, cuserr.OriginalMessage())
+	}
+}
+
+// updateFromSource writes the content scraped again from the page of source
+// into article, along with the title when it came from the page, and reports
+// whether it did. Only a change of the page counts: edits made to the article
+// since the last check are kept while the page stays the same. The owner is
+// the editor of the revision kept, as when importing again in mode update.
+func (s *ArticlesService) updateFromSource(article *articlesmodels.Article, source *articlesmodels.ArticleSource, page *utils.ScrapedPage) (bool, *customerror.CustomError) {
+	title := article.Title
+	if source.TitleFromPage && page.Title != "" {
+		title = page.Title
+	}
+	if source.PageHash != nil {
+		if *source.PageHash == scrapedPageHash(page) {
+			return false, nil
+		}
+	} else if article.Title == title && article.Content == page.Content {
+		// Checked before the page hash was kept, the article still matches the page
+		return false, nil
+	}
+	contentHash := utils.ContentHash(title, page.Content)
+
+	format := markup.FormatPlain
+	changes := &articlesmodels.ArticleChanges{
+		Title:       &title,
+		Content:     &page.Content,
+		Format:      &format,
+		ContentHash: &contentHash,
+	}
+	if cuserr := s.notifyChanged(s.articlesRepo.PatchArticle(article.ID, article.UserID, changes, nil)); cuserr != nil {
+		return false, cuserr
+	}
+	return true, nil
 }
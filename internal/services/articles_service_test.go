@@ -0,0 +1,455 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yantology/go-gin-simple-blog-with-fts/internal/utils"
+	"github.com/yantology/go-gin-simple-blog-with-fts/pkg/models/articlesmodels"
+	"github.com/yantology/go-gin-simple-blog-with-fts/pkg/models/importsmodels"
+	"github.com/yantology/go-gin-simple-blog-with-fts/pkg/repositories/articlesrepository"
+	"github.com/yantology/go-gin-simple-blog-with-fts/pkg/utils/customerror"
+	"github.com/yantology/go-gin-simple-blog-with-fts/pkg/utils/extract"
+	"github.com/yantology/go-gin-simple-blog-with-fts/pkg/utils/fetch"
+	"github.com/yantology/go-gin-simple-blog-with-fts/pkg/utils/markup"
+)
+
+func (f *fakeArticles) GetArticleBySourceURL(userID int, sourceURL string) (*articlesmodels.Article, *customerror.CustomError) {
+	for _, article := range f.articles {
+		if article.UserID == userID && article.SourceURL != nil && *article.SourceURL == sourceURL {
+			return article, nil
+		}
+	}
+	return nil, customerror.NewCustomError(sql.ErrNoRows, "article not found", 404)
+}
+
+func TestPlanImportDuplicateURLs(t *testing.T) {
+	imported := "https://example.com/imported"
+	articles := &fakeArticles{articles: map[int]*articlesmodels.Article{
+		1: {ID: 1, UserID: 10, SourceURL: &imported},
+	}}
+	service := NewArticlesService(articlesrepository.NewArticlesRepository(articles), nil, nil)
+	page := &utils.ScrapedPage{Result: &extract.Result{Title: "Page", Content: "Body"}}
+
+	// The records of one file in mode duplicate, marked as they are read
+	tests := []struct {
+		name     string
+		url      string
+		repeated bool
+		linked   bool
+	}{
+		{name: "First occurrence keeps the URL", url: "https://example.com/post", linked: true},
+		{name: "Repeated URL", url: "https://example.com/post", repeated: true},
+		{name: "Repeated URL written differently", url: "https://EXAMPLE.com/post#comments", repeated: true},
+		{name: "Another URL", url: "https://example.com/other", linked: true},
+		{name: "URL imported before", url: imported},
+		{name: "URL imported before repeated", url: imported, repeated: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			record := &utils.ImportRecord{Title: tt.name, URL: tt.url, Content: "Body", Page: page, RepeatedURL: tt.repeated}
+			article, existing, reason, cuserr := service.planImport(10, importsmodels.ModeDuplicate, nil, record)
+			assert.Nil(t, cuserr)
+			assert.Nil(t, existing)
+			assert.Empty(t, reason)
+			if tt.linked {
+				if assert.NotNil(t, article.SourceURL) {
+					assert.Equal(t, utils.NormalizeURL(tt.url), *article.SourceURL)
+				}
+				assert.NotNil(t, article.Source)
+			} else {
+				assert.Nil(t, article.SourceURL)
+				assert.Nil(t, article.Source)
+			}
+		})
+	}
+}
+
+func TestImportCheckFunc(t *testing.T) {
+	imported := "https://example.com/imported"
+	articles := &fakeArticles{articles: map[int]*articlesmodels.Article{
+		1: {ID: 1, UserID: 10, SourceURL: &imported},
+	}}
+	service := NewArticlesService(articlesrepository.NewArticlesRepository(articles), nil, nil)
+
+	tests := []struct {
+		name      string
+		mode      string
+		url       string
+		repeated  bool
+		reason    string
+		articleID int
+	}{
+		{name: "New URL", mode: importsmodels.ModeSkip, url: "https://example.com/post"},
+		{name: "Repeated URL", mode: importsmodels.ModeSkip, url: "https://example.com/post", repeated: true, reason: "url appears earlier in the file"},
+		{name: "Repeated URL in mode update", mode: importsmodels.ModeUpdate, url: "https://example.com/post", repeated: true, reason: "url appears earlier in the file"},
+		{name: "Repeated URL in mode duplicate", mode: importsmodels.ModeDuplicate, url: "https://example.com/post", repeated: true},
+		{name: "URL imported before", mode: importsmodels.ModeSkip, url: imported, reason: "article from this url already exists", articleID: 1},
+		{name: "URL imported before in mode update", mode: importsmodels.ModeUpdate, url: imported},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			record := &utils.ImportRecord{Title: tt.name, URL: tt.url, RepeatedURL: tt.repeated}
+			articleID, reason, cuserr := service.importCheckFunc(10, tt.mode)(record)
+			assert.Nil(t, cuserr)
+			assert.Equal(t, tt.reason, reason)
+			assert.Equal(t, tt.articleID, articleID)
+		})
+	}
+}
+
+// fakeSourceArticles records the patches and sources the source refresher writes
+type fakeSourceArticles struct {
+	*fakeArticles
+	patches []*articlesmodels.ArticleChanges
+	saved   *articlesmodels.ArticleSource
+}
+
+func (f *fakeSourceArticles) GetDeletedArticleByID(id int) (*articlesmodels.Article, *customerror.CustomError) {
+	return nil, customerror.NewCustomError(sql.ErrNoRows, "article not found", 404)
+}
+
+func (f *fakeSourceArticles) PatchArticle(articleId int, editorID int, changes *articlesmodels.ArticleChanges, ifMatch []int) *customerror.CustomError {
+	f.patches = append(f.patches, changes)
+	return nil
+}
+
+func (f *fakeSourceArticles) SaveArticleSource(source *articlesmodels.ArticleSource) *customerror.CustomError {
+	f.saved = source
+	return nil
+}
+
+// fakeFetcher answers every request with one page, or err
+type fakeFetcher struct {
+	title, content string
+	err            error
+}
+
+func (f *fakeFetcher) Fetch(ctx context.Context, req *fetch.Request) (*fetch.Response, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	pageURL, err := url.Parse(req.URL)
+	if err != nil {
+		return nil, err
+	}
+	body := "<h1>" + f.title + "</h1><div class=\"body\"><p>" + f.content + "</p></div>"
+	return &fetch.Response{URL: pageURL, StatusCode: http.StatusOK, Header: http.Header{"Etag": {`"v2"`}}, Body: []byte(body)}, nil
+}
+
+func TestRefreshSource(t *testing.T) {
+	sourceURL := "https://news.example.com/a"
+	rules, err := extract.NewRegistry(extract.Rule{Host: "news.example.com", Title: "h1", Content: "div.body"})
+	require.NoError(t, err)
+	hash := func(title, content string) *string {
+		h := utils.ContentHash(title, content)
+		return &h
+	}
+
+	// The article was imported with the title "Imported" from a page titled
+	// "Page title" with the content "Body"
+	tests := []struct {
+		name          string
+		title         string
+		content       string
+		titleFromPage bool
+		pageHash      *string
+		fetcher       *fakeFetcher
+		// patched is the title and content written into the article, empty for none
+		patched  []string
+		failures int
+	}{
+		{
+			name: "Unchanged page", title: "Imported", content: "Body", pageHash: hash("Page title", "Body"),
+			fetcher: &fakeFetcher{title: "Page title", content: "Body"},
+		},
+		{
+			name: "Title edited since the import", title: "Edited title", content: "Body", pageHash: hash("Page title", "Body"),
+			fetcher: &fakeFetcher{title: "Page title", content: "Body"},
+		},
+		{
+			name: "Content edited since the import", title: "Imported", content: "Edited body", pageHash: hash("Page title", "Body"),
+			fetcher: &fakeFetcher{title: "Page title", content: "Body"},
+		},
+		{
+			name: "Changed page keeps a title given in the file", title: "Edited title", content: "Body", pageHash: hash("Page title", "Body"),
+			fetcher: &fakeFetcher{title: "Page title", content: "Body v2"},
+			patched: []string{"Edited title", "Body v2"},
+		},
+		{
+			name: "Changed page title followed", title: "Page title", content: "Body", titleFromPage: true, pageHash: hash("Page title", "Body"),
+			fetcher: &fakeFetcher{title: "New page title", content: "Body"},
+			patched: []string{"New page title", "Body"},
+		},
+		{
+			name: "Not modified", title: "Imported", content: "Body", pageHash: hash("Page title", "Body"),
+			fetcher: &fakeFetcher{err: fetch.ErrNotModified},
+		},
+		{
+			name: "Fetch failed", title: "Imported", content: "Body", pageHash: hash("Page title", "Body"),
+			fetcher:  &fakeFetcher{err: errors.New("unexpected status 500")},
+			failures: 1,
+		},
+		{
+			name: "Source without page hash matching the article", title: "Imported", content: "Body",
+			fetcher: &fakeFetcher{title: "Page title", content: "Body"},
+		},
+		{
+			name: "Source without page hash and a changed page", title: "Imported", content: "Body",
+			fetcher: &fakeFetcher{title: "Page title", content: "Body v2"},
+			patched: []string{"Imported", "Body v2"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Edits keep the content hash of the import
+			importedTitle := "Imported"
+			if tt.titleFromPage {
+				importedTitle = "Page title"
+			}
+			repo := &fakeSourceArticles{fakeArticles: &fakeArticles{articles: map[int]*articlesmodels.Article{
+				1: {ID: 1, UserID: 10, Title: tt.title, Content: tt.content, SourceURL: &sourceURL, ContentHash: hash(importedTitle, "Body")},
+			}}}
+			service := NewArticlesService(articlesrepository.NewArticlesRepository(repo), nil, nil)
+			interval := 60
+			source := &articlesmodels.ArticleSource{ArticleID: 1, RefreshInterval: &interval, TitleFromPage: tt.titleFromPage, ETag: `"v1"`, PageHash: tt.pageHash}
+
+			service.refreshSource(context.Background(), utils.NewScraper(tt.fetcher, rules), source)
+
+			if assert.NotNil(t, repo.saved) {
+				assert.Equal(t, tt.failures, repo.saved.Failures)
+				assert.NotNil(t, repo.saved.NextCheckAt)
+			}
+			if tt.failures > 0 || tt.fetcher.err != nil {
+				assert.Empty(t, repo.patches)
+				assert.Equal(t, `"v1"`, source.ETag)
+				return
+			}
+			// The page is remembered as it is now, whatever the article became
+			assert.Equal(t, `"v2"`, source.ETag)
+			assert.Equal(t, hash(tt.fetcher.title, tt.fetcher.content), source.PageHash)
+
+			if tt.patched == nil {
+				assert.Empty(t, repo.patches)
+				assert.Nil(t, source.ChangedAt)
+				return
+			}
+			if assert.Len(t, repo.patches, 1) {
+				patch := repo.patches[0]
+				assert.Equal(t, tt.patched[0], *patch.Title)
+				assert.Equal(t, tt.patched[1], *patch.Content)
+				assert.Equal(t, markup.FormatPlain, *patch.Format)
+				assert.Equal(t, hash(tt.patched[0], tt.patched[1]), patch.ContentHash)
+			}
+			assert.NotNil(t, source.ChangedAt)
+		})
+	}
+}
+
+// fakeCreatedArticles creates articles in memory, refusing the one titled rejected
+type fakeCreatedArticles struct {
+	*fakeArticles
+	mu       sync.Mutex
+	rejected string
+	// failBatch makes every CreateArticles fail, like a transaction cut short
+	failBatch bool
+	batches   int
+	created   []string
+}
+
+func (f *fakeCreatedArticles) CreateArticles(articles []*articlesmodels.Article) *customerror.CustomError {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.batches++
+	if f.failBatch {
+		return customerror.NewCustomError(errors.New("connection reset"), "Database error", 500)
+	}
+	for _, article := range articles {
+		if article.Title == f.rejected {
+			return customerror.NewCustomError(errors.New("duplicate key"), "Record already exists", 400)
+		}
+	}
+	for _, article := range articles {
+		f.create(article)
+	}
+	return nil
+}
+
+func (f *fakeCreatedArticles) CreateArticle(article *articlesmodels.Article) *customerror.CustomError {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if article.Title == f.rejected {
+		return customerror.NewCustomError(errors.New("duplicate key"), "Record already exists", 400)
+	}
+	f.create(article)
+	return nil
+}
+
+func (f *fakeCreatedArticles) create(article *articlesmodels.Article) {
+	f.created = append(f.created, article.Title)
+	article.ID = len(f.created)
+}
+
+func TestArticleBatch(t *testing.T) {
+	tests := []struct {
+		name      string
+		atomic    bool
+		rows      int
+		rejected  string
+		failBatch bool
+		// failedRow records a row of the import failing before the batch is written
+		failedRow bool
+		cancelled bool
+		// created are the titles of the articles created, in order
+		created []string
+		batches int
+		counts  importsmodels.Counts
+		// reasons are the errors reported, by row
+		reasons map[int]string
+		code    int
+	}{
+		{
+			name:    "Best effort writes every importBatchSize rows",
+			rows:    importBatchSize + 2,
+			batches: 2,
+			counts:  importsmodels.Counts{Processed: importBatchSize + 2, Succeeded: importBatchSize + 2},
+		},
+		{
+			name:     "Best effort falls back to one by one when the batch fails",
+			rows:     3,
+			rejected: "Row 2",
+			created:  []string{"Row 1", "Row 3"},
+			batches:  1,
+			counts:   importsmodels.Counts{Processed: 3, Succeeded: 2, Failed: 1},
+			reasons:  map[int]string{2: "Record already exists"},
+		},
+		{
+			name:      "Best effort creates every row one by one when the transaction fails",
+			rows:      3,
+			failBatch: true,
+			batches:   1,
+			counts:    importsmodels.Counts{Processed: 3, Succeeded: 3},
+		},
+		{
+			name:    "Atomic creates every row in one batch",
+			atomic:  true,
+			rows:    importBatchSize + 2,
+			batches: 1,
+			counts:  importsmodels.Counts{Processed: importBatchSize + 2, Succeeded: importBatchSize + 2},
+		},
+		{
+			name:      "Atomic discards every row after a failed row",
+			atomic:    true,
+			rows:      3,
+			failedRow: true,
+			created:   []string{},
+			counts:    importsmodels.Counts{Processed: 4, Failed: 1, Skipped: 3},
+			reasons: map[int]string{
+				1: "nothing was imported, 1 rows failed",
+				2: "nothing was imported, 1 rows failed",
+				3: "nothing was imported, 1 rows failed",
+				4: "invalid date",
+			},
+			code: 400,
+		},
+		{
+			name:      "Atomic discards every row when cancelled",
+			atomic:    true,
+			rows:      3,
+			cancelled: true,
+			created:   []string{},
+			counts:    importsmodels.Counts{Processed: 3, Skipped: 3},
+			reasons: map[int]string{
+				1: "nothing was imported, the import was cancelled",
+				2: "nothing was imported, the import was cancelled",
+				3: "nothing was imported, the import was cancelled",
+			},
+		},
+		{
+			name:     "Atomic discards every row when the batch fails",
+			atomic:   true,
+			rows:     3,
+			rejected: "Row 2",
+			created:  []string{},
+			batches:  1,
+			counts:   importsmodels.Counts{Processed: 3, Skipped: 3},
+			reasons: map[int]string{
+				1: "nothing was imported: Record already exists",
+				2: "nothing was imported: Record already exists",
+				3: "nothing was imported: Record already exists",
+			},
+			code: 400,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			articles := &fakeCreatedArticles{fakeArticles: &fakeArticles{}, rejected: tt.rejected, failBatch: tt.failBatch}
+			service := NewArticlesService(articlesrepository.NewArticlesRepository(articles), nil, nil)
+			progress := &utils.ImportProgress{}
+			batch := &articleBatch{service: service, progress: progress, atomic: tt.atomic}
+
+			titles := []string{}
+			for line := 1; line <= tt.rows; line++ {
+				title := fmt.Sprintf("Row %d", line)
+				titles = append(titles, title)
+				batch.add(&utils.ImportRecord{Line: line, Title: title}, &articlesmodels.Article{Title: title})
+			}
+			if tt.failedRow {
+				progress.Settle(&utils.ImportRecord{Line: tt.rows + 1}, 0, "invalid date")
+			}
+
+			var cuserr *customerror.CustomError
+			if tt.atomic {
+				ctx, cancel := context.WithCancel(context.Background())
+				if tt.cancelled {
+					cancel()
+				}
+				cuserr = batch.commit(ctx)
+				cancel()
+			} else {
+				batch.flush()
+			}
+
+			if tt.code != 0 {
+				if assert.NotNil(t, cuserr) {
+					assert.Equal(t, tt.code, cuserr.HTTPCode)
+				}
+			} else {
+				assert.Nil(t, cuserr)
+			}
+			created := tt.created
+			if created == nil {
+				created = titles
+			}
+			assert.ElementsMatch(t, created, articles.created)
+			assert.Equal(t, tt.batches, articles.batches)
+			assert.Equal(t, tt.counts, progress.Counts())
+
+			reasons := map[int]string{}
+			for _, result := range progress.TakeResults() {
+				if result.Error != nil {
+					reasons[result.Row] = *result.Error
+				} else if assert.NotNil(t, result.ArticleID) {
+					assert.Equal(t, result.Title, articles.created[*result.ArticleID-1])
+				}
+			}
+			if tt.reasons == nil {
+				assert.Empty(t, reasons)
+			} else {
+				assert.Equal(t, tt.reasons, reasons)
+			}
+		})
+	}
+}
@@ -0,0 +1,178 @@
+package services
+
+import (
+	"errors"
+
+	"github.com/yantology/go-gin-simple-blog-with-fts/internal/models"
+	"github.com/yantology/go-gin-simple-blog-with-fts/pkg/models/commentsmodels"
+	"github.com/yantology/go-gin-simple-blog-with-fts/pkg/repositories/articlesrepository"
+	"github.com/yantology/go-gin-simple-blog-with-fts/pkg/repositories/commentsrepository"
+	"github.com/yantology/go-gin-simple-blog-with-fts/pkg/utils/customerror"
+)
+
+const (
+	defaultCommentsLimit = 20
+	maxCommentsLimit     = 100
+)
+
+type CommentsService struct {
+	commentsRepo *commentsrepository.CommentsRepository
+	articlesRepo *articlesrepository.ArticlesRepository
+}
+
+func NewCommentsService(commentsRepo *commentsrepository.CommentsRepository, articlesRepo *articlesrepository.ArticlesRepository) *CommentsService {
+	return &CommentsService{
+		commentsRepo: commentsRepo,
+		articlesRepo: articlesRepo,
+	}
+}
+
+func newCommentResponse(comment *commentsmodels.Comment) *models.CommentResponse {
+	response := &models.CommentResponse{
+		ID:        comment.ID,
+		ArticleID: comment.ArticleID,
+		UserID:    comment.UserID,
+		Username:  comment.Username,
+		ParentID:  comment.ParentID,
+		Content:   comment.Content,
+		Hidden:    comment.HiddenAt != nil,
+		Deleted:   comment.DeletedAt != nil,
+		CreatedAt: comment.CreatedAt,
+		UpdatedAt: comment.UpdatedAt,
+		Replies:   []*models.CommentResponse{},
+	}
+	if response.Hidden || response.Deleted {
+		response.Content = ""
+	}
+	return response
+}
+
+// GetArticleComments returns a page of comment threads of an article, oldest first.
+// Replies are nested below the comment they answer.
+func (s *CommentsService) GetArticleComments(articleID int, limit int, offset int) (*models.CommentsResponse, *customerror.CustomError) {
+	if limit <= 0 {
+		limit = defaultCommentsLimit
+	}
+	if limit > maxCommentsLimit {
+		limit = maxCommentsLimit
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	if _, cuserr := s.articlesRepo.GetArticleByID(articleID); cuserr != nil {
+		return nil, cuserr
+	}
+
+	comments, total, cuserr := s.commentsRepo.GetCommentThreads(articleID, limit, offset)
+	if cuserr != nil {
+		return nil, cuserr
+	}
+
+	response := &models.CommentsResponse{
+		Comments: []*models.CommentResponse{},
+		Total:    total,
+		Limit:    limit,
+		Offset:   offset,
+	}
+
+	// Comments arrive oldest first, so a parent is always seen before its replies
+	byID := map[int]*models.CommentResponse{}
+	for _, comment := range comments {
+		node := newCommentResponse(comment)
+		byID[comment.ID] = node
+		if comment.ParentID == nil {
+			response.Comments = append(response.Comments, node)
+		} else if parent, ok := byID[*comment.ParentID]; ok {
+			parent.Replies = append(parent.Replies, node)
+		}
+	}
+	return response, nil
+}
+
+// CreateComment adds a comment by userID to an article, or a reply when req.ParentID is set.
+func (s *CommentsService) CreateComment(userID int, articleID int, req *models.CommentRequest) (*models.CommentResponse, *customerror.CustomError) {
+	if _, cuserr := s.articlesRepo.GetArticleByID(articleID); cuserr != nil {
+		return nil, cuserr
+	}
+
+	comment := &commentsmodels.Comment{
+		ArticleID: articleID,
+		UserID:    userID,
+		Content:   req.Content,
+	}
+
+	if req.ParentID != nil {
+		parent, cuserr := s.commentsRepo.GetCommentByID(*req.ParentID)
+		if cuserr != nil {
+			if cuserr.HTTPCode == 404 {
+				return nil, customerror.NewCustomError(cuserr.Original, "parent comment does not exist", 400)
+			}
+			return nil, cuserr
+		}
+		if parent.ArticleID != articleID {
+			return nil, customerror.NewCustomError(errors.New("parent on another article"), "parent comment belongs to another article", 400)
+		}
+		if parent.DeletedAt != nil {
+			return nil, customerror.NewCustomError(errors.New("parent deleted"), "cannot reply to a deleted comment", 400)
+		}
+
+		comment.ParentID = &parent.ID
+		comment.RootID = parent.RootID
+		if comment.RootID == nil {
+			comment.RootID = &parent.ID
+		}
+	}
+
+	if cuserr := s.commentsRepo.CreateComment(comment); cuserr != nil {
+		return nil, cuserr
+	}
+	return newCommentResponse(comment), nil
+}
+
+// UpdateComment changes the content of a comment written by userID.
+func (s *CommentsService) UpdateComment(userID int, commentID int, req *models.CommentUpdateRequest) *customerror.CustomError {
+	comment, cuserr := s.commentsRepo.GetCommentByID(commentID)
+	if cuserr != nil {
+		return cuserr
+	}
+
+	if comment.UserID != userID {
+		return customerror.NewCustomError(nil, "You are not authorized to update this comment", 403)
+	}
+
+	return s.commentsRepo.UpdateComment(commentID, req.Content)
+}
+
+// DeleteComment deletes a comment written by userID. Its replies stay visible.
+func (s *CommentsService) DeleteComment(userID int, commentID int) *customerror.CustomError {
+	comment, cuserr := s.commentsRepo.GetCommentByID(commentID)
+	if cuserr != nil {
+		return cuserr
+	}
+
+	if comment.UserID != userID {
+		return customerror.NewCustomError(nil, "You are not authorized to delete this comment", 403)
+	}
+
+	return s.commentsRepo.DeleteCommentByID(commentID)
+}
+
+// SetCommentHidden hides or shows a comment on an article written by userID.
+func (s *CommentsService) SetCommentHidden(userID int, commentID int, hidden bool) *customerror.CustomError {
+	comment, cuserr := s.commentsRepo.GetCommentByID(commentID)
+	if cuserr != nil {
+		return cuserr
+	}
+
+	article, cuserr := s.articlesRepo.GetArticleByID(comment.ArticleID)
+	if cuserr != nil {
+		return cuserr
+	}
+
+	if article.UserID != userID {
+		return customerror.NewCustomError(nil, "You are not authorized to moderate comments on this article", 403)
+	}
+
+	return s.commentsRepo.SetCommentHidden(commentID, hidden)
+}
@@ -0,0 +1,261 @@
+package services
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/yantology/go-gin-simple-blog-with-fts/internal/models"
+	"github.com/yantology/go-gin-simple-blog-with-fts/pkg/interfaces/articlesinterface"
+	"github.com/yantology/go-gin-simple-blog-with-fts/pkg/models/articlesmodels"
+	"github.com/yantology/go-gin-simple-blog-with-fts/pkg/models/commentsmodels"
+	"github.com/yantology/go-gin-simple-blog-with-fts/pkg/repositories/articlesrepository"
+	"github.com/yantology/go-gin-simple-blog-with-fts/pkg/repositories/commentsrepository"
+	"github.com/yantology/go-gin-simple-blog-with-fts/pkg/utils/customerror"
+)
+
+// fakeArticles serves articles from a map; the other methods are not used by these tests
+type fakeArticles struct {
+	articlesinterface.ArticleRepository
+	articles map[int]*articlesmodels.Article
+}
+
+func (f *fakeArticles) GetArticleByID(id int) (*articlesmodels.Article, *customerror.CustomError) {
+	article, ok := f.articles[id]
+	if !ok {
+		return nil, customerror.NewCustomError(sql.ErrNoRows, "article not found", 404)
+	}
+	return article, nil
+}
+
+// fakeComments keeps comments in memory and records the last threads query
+type fakeComments struct {
+	comments      map[int]*commentsmodels.Comment
+	threads       []*commentsmodels.Comment
+	limit, offset int
+}
+
+func (f *fakeComments) CreateComment(comment *commentsmodels.Comment) *customerror.CustomError {
+	comment.ID = len(f.comments) + 100
+	f.comments[comment.ID] = comment
+	return nil
+}
+
+func (f *fakeComments) GetCommentByID(id int) (*commentsmodels.Comment, *customerror.CustomError) {
+	comment, ok := f.comments[id]
+	if !ok {
+		return nil, customerror.NewCustomError(sql.ErrNoRows, "comment not found", 404)
+	}
+	return comment, nil
+}
+
+func (f *fakeComments) GetCommentThreads(articleID int, limit int, offset int) ([]*commentsmodels.Comment, int, *customerror.CustomError) {
+	f.limit, f.offset = limit, offset
+	total := 0
+	for _, comment := range f.threads {
+		if comment.ParentID == nil {
+			total++
+		}
+	}
+	return f.threads, total, nil
+}
+
+func (f *fakeComments) UpdateComment(id int, content string) *customerror.CustomError {
+	f.comments[id].Content = content
+	return nil
+}
+
+func (f *fakeComments) DeleteCommentByID(id int) *customerror.CustomError {
+	now := time.Now()
+	f.comments[id].DeletedAt = &now
+	return nil
+}
+
+func (f *fakeComments) SetCommentHidden(id int, hidden bool) *customerror.CustomError {
+	f.comments[id].HiddenAt = nil
+	if hidden {
+		now := time.Now()
+		f.comments[id].HiddenAt = &now
+	}
+	return nil
+}
+
+// newFakeCommentsService returns a service over article 1 by user 10, with a
+// comment 1 by user 20 and its reply 2 by user 30
+func newFakeCommentsService() (*CommentsService, *fakeComments) {
+	one := 1
+	comments := &fakeComments{comments: map[int]*commentsmodels.Comment{
+		1: {ID: 1, ArticleID: 1, UserID: 20, Content: "First"},
+		2: {ID: 2, ArticleID: 1, UserID: 30, ParentID: &one, RootID: &one, Content: "Reply"},
+		3: {ID: 3, ArticleID: 2, UserID: 20, Content: "Elsewhere"},
+	}}
+	articles := &fakeArticles{articles: map[int]*articlesmodels.Article{
+		1: {ID: 1, UserID: 10},
+		2: {ID: 2, UserID: 40},
+	}}
+	service := NewCommentsService(commentsrepository.NewCommentsRepository(comments), articlesrepository.NewArticlesRepository(articles))
+	return service, comments
+}
+
+func TestNewCommentResponse(t *testing.T) {
+	now := time.Now()
+	tests := []struct {
+		name     string
+		comment  *commentsmodels.Comment
+		expected *models.CommentResponse
+	}{
+		{
+			name:     "Visible",
+			comment:  &commentsmodels.Comment{ID: 1, Content: "Hello"},
+			expected: &models.CommentResponse{ID: 1, Content: "Hello", Replies: []*models.CommentResponse{}},
+		},
+		{
+			name:     "Hidden content is blanked",
+			comment:  &commentsmodels.Comment{ID: 1, Content: "Spam", HiddenAt: &now},
+			expected: &models.CommentResponse{ID: 1, Hidden: true, Replies: []*models.CommentResponse{}},
+		},
+		{
+			name:     "Deleted content is blanked",
+			comment:  &commentsmodels.Comment{ID: 1, Content: "Oops", DeletedAt: &now},
+			expected: &models.CommentResponse{ID: 1, Deleted: true, Replies: []*models.CommentResponse{}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, newCommentResponse(tt.comment))
+		})
+	}
+}
+
+func TestGetArticleComments(t *testing.T) {
+	service, comments := newFakeCommentsService()
+	one, two, four := 1, 2, 4
+	deletedAt := time.Now()
+	// Two threads, oldest first as the repository returns them
+	comments.threads = []*commentsmodels.Comment{
+		{ID: 1, Content: "First"},
+		{ID: 2, ParentID: &one, RootID: &one, Content: "Reply"},
+		{ID: 4, Content: "Second", DeletedAt: &deletedAt},
+		{ID: 3, ParentID: &two, RootID: &one, Content: "Reply to reply"},
+		{ID: 5, ParentID: &four, RootID: &four, Content: "Reply to deleted"},
+	}
+
+	response, cuserr := service.GetArticleComments(1, 0, -5)
+	assert.Nil(t, cuserr)
+	assert.Equal(t, 2, response.Total)
+	assert.Equal(t, defaultCommentsLimit, response.Limit)
+	assert.Equal(t, 0, response.Offset)
+	assert.Equal(t, defaultCommentsLimit, comments.limit)
+	assert.Equal(t, 0, comments.offset)
+
+	if !assert.Len(t, response.Comments, 2) {
+		return
+	}
+	first, second := response.Comments[0], response.Comments[1]
+	assert.Equal(t, 1, first.ID)
+	if assert.Len(t, first.Replies, 1) {
+		assert.Equal(t, 2, first.Replies[0].ID)
+		if assert.Len(t, first.Replies[0].Replies, 1) {
+			assert.Equal(t, 3, first.Replies[0].Replies[0].ID)
+		}
+	}
+
+	// A deleted comment keeps its place and its replies, not its content
+	assert.Equal(t, 4, second.ID)
+	assert.True(t, second.Deleted)
+	assert.Empty(t, second.Content)
+	if assert.Len(t, second.Replies, 1) {
+		assert.Equal(t, "Reply to deleted", second.Replies[0].Content)
+	}
+
+	_, _ = service.GetArticleComments(1, 1000, 3)
+	assert.Equal(t, maxCommentsLimit, comments.limit)
+	assert.Equal(t, 3, comments.offset)
+
+	_, cuserr = service.GetArticleComments(99, 10, 0)
+	if assert.NotNil(t, cuserr) {
+		assert.Equal(t, 404, cuserr.HTTPCode)
+	}
+}
+
+func TestCreateCommentReply(t *testing.T) {
+	one, two, three, missing := 1, 2, 3, 99
+
+	tests := []struct {
+		name     string
+		parentID *int
+		deleted  bool
+		rootID   *int
+		code     int
+	}{
+		{name: "Top-level comment", parentID: nil, rootID: nil},
+		{name: "Reply to a top-level comment", parentID: &one, rootID: &one},
+		{name: "Reply to a reply keeps the thread root", parentID: &two, rootID: &one},
+		{name: "Parent on another article", parentID: &three, code: 400},
+		{name: "Missing parent", parentID: &missing, code: 400},
+		{name: "Deleted parent", parentID: &one, deleted: true, code: 400},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, comments := newFakeCommentsService()
+			if tt.deleted {
+				now := time.Now()
+				comments.comments[*tt.parentID].DeletedAt = &now
+			}
+
+			response, cuserr := service.CreateComment(50, 1, &models.CommentRequest{Content: "Hi", ParentID: tt.parentID})
+			if tt.code != 0 {
+				if assert.NotNil(t, cuserr) {
+					assert.Equal(t, tt.code, cuserr.HTTPCode)
+				}
+				return
+			}
+			assert.Nil(t, cuserr)
+			assert.Equal(t, tt.parentID, response.ParentID)
+			assert.Equal(t, tt.rootID, comments.comments[response.ID].RootID)
+		})
+	}
+}
+
+func TestSetCommentHidden(t *testing.T) {
+	tests := []struct {
+		name      string
+		userID    int
+		commentID int
+		hidden    bool
+		code      int
+	}{
+		{name: "Article author hides", userID: 10, commentID: 2, hidden: true},
+		{name: "Article author shows again", userID: 10, commentID: 2, hidden: false},
+		{name: "Comment author cannot hide", userID: 30, commentID: 2, hidden: true, code: 403},
+		{name: "Another user cannot hide", userID: 50, commentID: 1, hidden: true, code: 403},
+		{name: "Author of another article cannot hide", userID: 40, commentID: 1, hidden: true, code: 403},
+		{name: "Missing comment", userID: 10, commentID: 99, hidden: true, code: 404},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, comments := newFakeCommentsService()
+			if !tt.hidden {
+				now := time.Now()
+				comments.comments[tt.commentID].HiddenAt = &now
+			}
+
+			cuserr := service.SetCommentHidden(tt.userID, tt.commentID, tt.hidden)
+			if tt.code != 0 {
+				if assert.NotNil(t, cuserr) {
+					assert.Equal(t, tt.code, cuserr.HTTPCode)
+				}
+				if comment, ok := comments.comments[tt.commentID]; ok {
+					assert.Nil(t, comment.HiddenAt)
+				}
+				return
+			}
+			assert.Nil(t, cuserr)
+			assert.Equal(t, tt.hidden, comments.comments[tt.commentID].HiddenAt != nil)
+		})
+	}
+}
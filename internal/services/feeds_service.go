@@ -0,0 +1,113 @@
+package services
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/yantology/go-gin-simple-blog-with-fts/internal/models"
+	"github.com/yantology/go-gin-simple-blog-with-fts/internal/utils"
+	"github.com/yantology/go-gin-simple-blog-with-fts/pkg/repositories/authrepository"
+	"github.com/yantology/go-gin-simple-blog-with-fts/pkg/utils/customerror"
+	"github.com/yantology/go-gin-simple-blog-with-fts/pkg/utils/feed"
+)
+
+// FeedsService builds RSS, Atom and JSON feeds from article listings.
+type FeedsService struct {
+	articlesService *ArticlesService
+	authRepo        *authrepository.AuthRepository
+	links           *utils.SiteLinks
+	siteTitle       string
+	siteDescription string
+	items           int
+}
+
+func NewFeedsService(articlesService *ArticlesService, authRepo *authrepository.AuthRepository, links *utils.SiteLinks, siteTitle string, siteDescription string, items int) *FeedsService {
+	return &FeedsService{
+		articlesService: articlesService,
+		authRepo:        authRepo,
+		links:           links,
+		siteTitle:       siteTitle,
+		siteDescription: siteDescription,
+		items:           items,
+	}
+}
+
+// GetArticlesFeed returns the newest articles of the whole blog.
+func (s *FeedsService) GetArticlesFeed(format string) (*feed.Feed, *customerror.CustomError) {
+	articles, cuserr := s.articlesService.GetLatestArticles(s.items, 0, nil)
+	if cuserr != nil {
+		return nil, cuserr
+	}
+
+	return s.newFeed(articles, &feed.Feed{
+		Title:       s.siteTitle,
+		Description: s.siteDescription,
+		Link:        s.links.Home(),
+		FeedURL:     s.links.ArticlesFeed(format),
+	}), nil
+}
+
+// GetUserFeed returns the newest articles of one author.
+func (s *FeedsService) GetUserFeed(userID int, format string) (*feed.Feed, *customerror.CustomError) {
+	user, cuserr := s.authRepo.GetUserByID(userID)
+	if cuserr != nil {
+		return nil, cuserr
+	}
+
+	articles, cuserr := s.articlesService.GetLatestArticlesByUserID(userID, s.items, 0)
+	if cuserr != nil {
+		return nil, cuserr
+	}
+
+	return s.newFeed(articles, &feed.Feed{
+		Title:       user.Username + " - " + s.siteTitle,
+		Description: "Articles by " + user.Username,
+		Link:        s.links.Author(user.ID, user.Username),
+		FeedURL:     s.links.UserFeed(user.ID, format),
+	}), nil
+}
+
+// GetSearchFeed returns the best full-text search matches for query, most relevant first.
+func (s *FeedsService) GetSearchFeed(query string, format string) (*feed.Feed, *customerror.CustomError) {
+	query = strings.TrimSpace(query)
+	if query == "" {
+		return nil, customerror.NewCustomError(errors.New("empty search query"), "query is required", 400)
+	}
+
+	articles, cuserr := s.articlesService.SearchArticles(s.items, 0, query, nil)
+	if cuserr != nil {
+		return nil, cuserr
+	}
+
+	return s.newFeed(articles, &feed.Feed{
+		Title:       "Search results for \"" + query + "\" - " + s.siteTitle,
+		Description: "Articles matching " + query,
+		Link:        s.links.Search(query),
+		FeedURL:     s.links.SearchFeed(query, format),
+	}), nil
+}
+
+// newFeed fills f with one item per article. The feed is as recent as its
+// most recently updated article; an empty feed is dated at the Unix epoch so
+// that it renders identically on every request.
+func (s *FeedsService) newFeed(articles *models.ArticlesResponse, f *feed.Feed) *feed.Feed {
+	f.Updated = time.Unix(0, 0).UTC()
+	for _, article := range articles.Articles {
+		f.Items = append(f.Items, &feed.Item{
+			ID:          s.links.ArticleID(article.ID),
+			Title:       article.Title,
+			Link:        s.links.Article(article.Slug),
+			Author:      article.Author,
+			Summary:     article.Excerpt,
+			ContentHTML: article.ContentHTML,
+			Tags:        article.Tags,
+			Published:   article.CreatedAt,
+			Updated:     article.UpdatedAt,
+		})
+		if article.UpdatedAt.After(f.Updated) {
+			f.Updated = article.UpdatedAt
+		}
+	}
+	return f
+}
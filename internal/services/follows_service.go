@@ -0,0 +1,144 @@
+package services
+
+import (
+	"errors"
+
+	"github.com/yantology/go-gin-simple-blog-with-fts/internal/models"
+	"github.com/yantology/go-gin-simple-blog-with-fts/internal/utils"
+	"github.com/yantology/go-gin-simple-blog-with-fts/pkg/models/articlesmodels"
+	"github.com/yantology/go-gin-simple-blog-with-fts/pkg/models/followsmodels"
+	"github.com/yantology/go-gin-simple-blog-with-fts/pkg/repositories/articlesrepository"
+	"github.com/yantology/go-gin-simple-blog-with-fts/pkg/repositories/authrepository"
+	"github.com/yantology/go-gin-simple-blog-with-fts/pkg/repositories/followsrepository"
+	"github.com/yantology/go-gin-simple-blog-with-fts/pkg/utils/customerror"
+)
+
+const (
+	defaultFollowsLimit = 20
+	maxFollowsLimit     = 100
+	defaultFeedLimit    = 20
+	maxFeedLimit        = 100
+)
+
+type FollowsService struct {
+	followsRepo  *followsrepository.FollowsRepository
+	authRepo     *authrepository.AuthRepository
+	articlesRepo *articlesrepository.ArticlesRepository
+}
+
+func NewFollowsService(followsRepo *followsrepository.FollowsRepository, authRepo *authrepository.AuthRepository, articlesRepo *articlesrepository.ArticlesRepository) *FollowsService {
+	return &FollowsService{
+		followsRepo:  followsRepo,
+		authRepo:     authRepo,
+		articlesRepo: articlesRepo,
+	}
+}
+
+// Follow makes userID follow another user. Following a user twice changes nothing.
+func (s *FollowsService) Follow(userID int, followeeID int) (*models.FollowResponse, *customerror.CustomError) {
+	if userID == followeeID {
+		return nil, customerror.NewCustomError(errors.New("self follow"), "you cannot follow yourself", 400)
+	}
+	if _, cuserr := s.authRepo.GetUserByID(followeeID); cuserr != nil {
+		return nil, cuserr
+	}
+
+	if cuserr := s.followsRepo.Follow(userID, followeeID); cuserr != nil {
+		return nil, cuserr
+	}
+	return &models.FollowResponse{UserID: followeeID, Following: true}, nil
+}
+
+// Unfollow makes userID stop following another user. Unfollowing a user that is not followed changes nothing.
+func (s *FollowsService) Unfollow(userID int, followeeID int) (*models.FollowResponse, *customerror.CustomError) {
+	if cuserr := s.followsRepo.Unfollow(userID, followeeID); cuserr != nil {
+		return nil, cuserr
+	}
+	return &models.FollowResponse{UserID: followeeID, Following: false}, nil
+}
+
+// GetFollowers returns a page of the users following userID, most recent followers first.
+func (s *FollowsService) GetFollowers(userID int, limit int, offset int) (*models.FollowUsersResponse, *customerror.CustomError) {
+	return s.getFollowUsers(userID, limit, offset, s.followsRepo.GetFollowers)
+}
+
+// GetFollowing returns a page of the users userID follows, most recently followed first.
+func (s *FollowsService) GetFollowing(userID int, limit int, offset int) (*models.FollowUsersResponse, *customerror.CustomError) {
+	return s.getFollowUsers(userID, limit, offset, s.followsRepo.GetFollowing)
+}
+
+func (s *FollowsService) getFollowUsers(userID int, limit int, offset int,
+	list func(userID int, limit int, offset int) ([]*followsmodels.FollowUser, int, *customerror.CustomError)) (*models.FollowUsersResponse, *customerror.CustomError) {
+	if limit <= 0 {
+		limit = defaultFollowsLimit
+	}
+	if limit > maxFollowsLimit {
+		limit = maxFollowsLimit
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	if _, cuserr := s.authRepo.GetUserByID(userID); cuserr != nil {
+		return nil, cuserr
+	}
+
+	users, total, cuserr := list(userID, limit, offset)
+	if cuserr != nil {
+		return nil, cuserr
+	}
+
+	response := &models.FollowUsersResponse{
+		Users:  []*models.FollowUserResponse{},
+		Total:  total,
+		Limit:  limit,
+		Offset: offset,
+	}
+	for _, user := range users {
+		response.Users = append(response.Users, &models.FollowUserResponse{
+			ID:         user.ID,
+			Username:   user.Username,
+			FollowedAt: user.FollowedAt,
+		})
+	}
+	return response, nil
+}
+
+// GetFeed returns a page of the articles of the authors userID follows, newest first.
+// cursor is the next_cursor of the previous page, empty for the first page.
+func (s *FollowsService) GetFeed(userID int, cursor string, limit int) (*models.FeedResponse, *customerror.CustomError) {
+	if limit <= 0 {
+		limit = defaultFeedLimit
+	}
+	if limit > maxFeedLimit {
+		limit = maxFeedLimit
+	}
+
+	var after *articlesmodels.ArticleCursor
+	if cursor != "" {
+		decoded, err := utils.DecodeArticleCursor(cursor)
+		if err != nil {
+			return nil, customerror.NewCustomError(err, "invalid cursor", 400)
+		}
+		after = decoded
+	}
+
+	// One extra article tells whether another page follows
+	articles, cuserr := s.articlesRepo.GetFollowedArticles(userID, after, limit+1)
+	if cuserr != nil {
+		return nil, cuserr
+	}
+
+	response := &models.FeedResponse{
+		Articles: []*models.ArticleResponse{},
+	}
+	if len(articles) > limit {
+		articles = articles[:limit]
+		last := articles[limit-1]
+		response.NextCursor = utils.EncodeArticleCursor(&articlesmodels.ArticleCursor{CreatedAt: last.CreatedAt, ID: last.ID})
+	}
+	for _, article := range articles {
+		response.Articles = append(response.Articles, newArticleResponse(article))
+	}
+	return response, nil
+}
@@ -0,0 +1,627 @@
+package services
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/yantology/go-gin-simple-blog-with-fts/internal/models"
+	"github.com/yantology/go-gin-simple-blog-with-fts/internal/utils"
+	"github.com/yantology/go-gin-simple-blog-with-fts/pkg/models/importsmodels"
+	"github.com/yantology/go-gin-simple-blog-with-fts/pkg/repositories/importsrepository"
+	"github.com/yantology/go-gin-simple-blog-with-fts/pkg/utils/customerror"
+)
+
+// importProgressInterval is how often the progress of a running import is saved
+const importProgressInterval = time.Second
+
+// importLease is how long an unfinished import is left alone after its owner
+// last renewed it, which it does with every progress save. Once it runs out
+// the process running the import is taken as stopped and the import failed.
+const importLease = time.Minute
+
+const (
+	defaultImportRowsLimit = 20
+	maxImportRowsLimit     = 100
+	// importReportPage is the number of rows read per query for a full report
+	importReportPage = 1000
+)
+
+// importCancelTimeout bounds how long a cancel request waits for the rows in flight
+const importCancelTimeout = 30 * time.Second
+
+const (
+	// importEventsKept is how many of the latest events of an import are kept
+	// for clients reconnecting to its event stream
+	importEventsKept = 10000
+	// importEventsRetention is how long the events of a finished import are kept
+	importEventsRetention = 5 * time.Minute
+)
+
+// ImportsService runs imports as background jobs. Their state is kept in the
+// database; cancelling needs the job to run in this process.
+type ImportsService struct {
+	importsRepo *importsrepository.ImportsRepository
+	// owner identifies this process on the imports it runs
+	owner           string
+	articlesService *ArticlesService
+	// scraper fetches the pages of imported URLs and finds the article on them
+	scraper *utils.Scraper
+	// maxUploadSize is the largest accepted file in bytes
+	maxUploadSize int64
+
+	mu      sync.Mutex
+	running map[int]*runningImport
+	// finished holds the events of imports that finished in this process lately
+	finished map[int]*utils.ImportEvents
+}
+
+// runningImport is an import job running in this process
+type runningImport struct {
+	cancel context.CancelFunc
+	// done is closed once the final state is saved
+	done   chan struct{}
+	events *utils.ImportEvents
+}
+
+func NewImportsService(importsRepo *importsrepository.ImportsRepository, articlesService *ArticlesService, scraper *utils.Scraper, maxUploadSize int64) *ImportsService {
+	return &ImportsService{
+		importsRepo:     importsRepo,
+		owner:           importOwner(),
+		articlesService: articlesService,
+		scraper:         scraper,
+		maxUploadSize:   maxUploadSize,
+		running:         map[int]*runningImport{},
+		finished:        map[int]*utils.ImportEvents{},
+	}
+}
+
+func newImportResponse(imp *importsmodels.Import) *models.ImportResponse {
+	response := &models.ImportResponse{
+		ID:              imp.ID,
+		Source:          imp.Source,
+		Filename:        imp.Filename,
+		Mode:            imp.Mode,
+		Atomic:          imp.Atomic,
+		State:           imp.State,
+		Processed:       imp.Counts.Processed,
+		Succeeded:       imp.Counts.Succeeded,
+		Failed:          imp.Counts.Failed,
+		Skipped:         imp.Counts.Skipped,
+		Error:           imp.Error,
+		CreatedAt:       imp.CreatedAt,
+		StartedAt:       imp.StartedAt,
+		FinishedAt:      imp.FinishedAt,
+		RefreshInterval: imp.RefreshInterval,
+	}
+	if imp.StartedAt != nil {
+		end := time.Now()
+		if imp.FinishedAt != nil {
+			end = *imp.FinishedAt
+		}
+		duration := end.Sub(*imp.StartedAt).Seconds()
+		response.DurationSeconds = &duration
+	}
+	return response
+}
+
+// importOwner identifies this process among the server processes sharing the
+// database, the start time telling apart processes given the same PID
+func importOwner() string {
+	host, err := os.Hostname()
+	if err != nil {
+		host = "unknown"
+	}
+	return fmt.Sprintf("%s/%d/%d", host, os.Getpid(), time.Now().UnixNano())
+}
+
+// FailInterruptedImports marks imports whose process stopped while they were
+// queued or running as failed; nothing is left to finish them. Imports other
+// processes still renew the lease of are left alone.
+func (s *ImportsService) FailInterruptedImports() *customerror.CustomError {
+	count, cuserr := s.importsRepo.FailExpiredImports("interrupted by a server restart")
+	if cuserr != nil {
+		return cuserr
+	}
+	if count > 0 {
+		log.Printf("Marked %d interrupted imports as failed", count)
+	}
+	return nil
+}
+
+// RunInterruptedImportsCheck fails the imports of stopped processes every
+// lease until ctx is cancelled, for processes that stop while others keep
+// running.
+func (s *ImportsService) RunInterruptedImportsCheck(ctx context.Context) {
+	ticker := time.NewTicker(importLease)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		if cuserr := s.FailInterruptedImports(); cuserr != nil {
+			log.Printf("Interrupted imports check failed: %s", cuserr.OriginalMessage())
+		}
+	}
+}
+
+// maxIdempotencyKeyLength matches the imports.idempotency_key column
+const maxIdempotencyKeyLength = 255
+
+// CreateImport checks an uploaded file of articles and starts importing it in
+// the background with mode, skip when empty. format names the kind of file,
+// one of the utils.ImportFormats; when empty it is told by the file extension.
+// An atomic import creates all of its articles or, when any row fails, none;
+// otherwise every row that works is kept. refreshInterval is the minutes
+// between two checks of the scraped pages for changes, nil for the interval of
+// each host.
+// An upload sent again with the same idempotencyKey returns the import of the
+// first one instead of starting another; the bool reports whether the import
+// was started by this call.
+func (s *ImportsService) CreateImport(userID int, file *multipart.FileHeader, format string, mode string, atomic bool, refreshInterval *int, idempotencyKey string) (*models.ImportResponse, bool, *customerror.CustomError) {
+	mode, cuserr := importMode(mode)
+	if cuserr != nil {
+		return nil, false, cuserr
+	}
+	// Updates are written as rows go, they cannot wait for the others
+	if atomic && mode == importsmodels.ModeUpdate {
+		return nil, false, customerror.NewCustomError(errors.New("atomic update import"), "an atomic import cannot use mode update", 400)
+	}
+	if refreshInterval != nil && *refreshInterval < 0 {
+		return nil, false, customerror.NewCustomError(errors.New("negative refresh interval"), "refresh_interval must be a number of minutes, 0 to never check", 400)
+	}
+	if len(idempotencyKey) > maxIdempotencyKeyLength {
+		return nil, false, customerror.NewCustomError(errors.New("idempotency key too long"), fmt.Sprintf("Idempotency-Key must not be longer than %d characters", maxIdempotencyKeyLength), 400)
+	}
+
+	importFormat, data, cuserr := readImportUpload(file, format, s.maxUploadSize)
+	if cuserr != nil {
+		return nil, false, cuserr
+	}
+
+	imp := &importsmodels.Import{
+		UserID:   userID,
+		Source:   importFormat.Name,
+		Filename: file.Filename,
+		Mode:     mode,
+		Atomic:   atomic,
+	}
+	imp.RefreshInterval = refreshInterval
+	if idempotencyKey != "" {
+		requestHash := importRequestHash(importFormat.Name, mode, atomic, refreshInterval, data)
+		imp.IdempotencyKey = &idempotencyKey
+		imp.RequestHash = &requestHash
+
+		if replayed, cuserr := s.replayImport(userID, idempotencyKey, requestHash); replayed != nil || cuserr != nil {
+			return replayed, false, cuserr
+		}
+	}
+
+	reader, cuserr := importFormat.Open(data)
+	if cuserr != nil {
+		return nil, false, cuserr
+	}
+	leaseExpiresAt := time.Now().Add(importLease)
+	imp.Owner, imp.LeaseExpiresAt = &s.owner, &leaseExpiresAt
+	if cuserr := s.importsRepo.CreateImport(imp); cuserr != nil {
+		// A retry sent while the first upload was being created
+		if idempotencyKey != "" {
+			if replayed, replayErr := s.replayImport(userID, idempotencyKey, *imp.RequestHash); replayed != nil || replayErr != nil {
+				return replayed, false, replayErr
+			}
+		}
+		return nil, false, cuserr
+	}
+
+	s.start(imp.ID, func(ctx context.Context, progress *utils.ImportProgress) *customerror.CustomError {
+		return s.articlesService.Import(ctx, userID, reader, mode, atomic, refreshInterval, s.scraper, progress)
+	})
+	return newImportResponse(imp), true, nil
+}
+
+// Pages a preview scrapes when no sample is given, and at most
+const (
+	defaultPreviewSample = 5
+	maxPreviewSample     = 20
+)
+
+// PreviewImport reads an uploaded file of articles like CreateImport and
+// returns what importing it with mode would do, without writing anything.
+// Only the pages of the first sample rows to scrape are fetched, 5 when
+// sample is 0.
+func (s *ImportsService) PreviewImport(ctx context.Context, userID int, file *multipart.FileHeader, format string, mode string, sample int) (*models.ImportPreviewResponse, *customerror.CustomError) {
+	mode, cuserr := importMode(mode)
+	if cuserr != nil {
+		return nil, cuserr
+	}
+	if sample == 0 {
+		sample = defaultPreviewSample
+	}
+	if sample < 0 || sample > maxPreviewSample {
+		return nil, customerror.NewCustomError(errors.New("invalid sample"), fmt.Sprintf("sample must be between 1 and %d", maxPreviewSample), 400)
+	}
+
+	importFormat, data, cuserr := readImportUpload(file, format, s.maxUploadSize)
+	if cuserr != nil {
+		return nil, cuserr
+	}
+	reader, cuserr := importFormat.Open(data)
+	if cuserr != nil {
+		return nil, cuserr
+	}
+	rows, cuserr := s.articlesService.PreviewImport(ctx, userID, reader, mode, sample, s.scraper)
+	if cuserr != nil {
+		return nil, cuserr
+	}
+
+	response := &models.ImportPreviewResponse{
+		Source:   importFormat.Name,
+		Filename: file.Filename,
+		Mode:     mode,
+		Rows:     rows,
+	}
+	for _, row := range rows {
+		switch row.Action {
+		case importsmodels.ActionCreate:
+			response.Create++
+		case importsmodels.ActionUpdate:
+			response.Update++
+		case importsmodels.ActionSkip:
+			response.Skip++
+		case importsmodels.ActionFail:
+			response.Fail++
+		}
+		if row.Scraped {
+			response.Scraped++
+		}
+	}
+	return response, nil
+}
+
+// importMode checks the mode of an import, skip when empty
+func importMode(mode string) (string, *customerror.CustomError) {
+	if mode == "" {
+		return importsmodels.ModeSkip, nil
+	}
+	if !slices.Contains(importsmodels.Modes, mode) {
+		return "", customerror.NewCustomError(errors.New("invalid mode"), "mode must be one of "+strings.Join(importsmodels.Modes, ", "), 400)
+	}
+	return mode, nil
+}
+
+// readImportUpload finds the import format of an uploaded file, named by
+// format or told by its extension, and reads the file, at most maxSize bytes.
+// The upload is removed once the request ends, imports work on the copy.
+func readImportUpload(file *multipart.FileHeader, format string, maxSize int64) (*utils.ImportFormat, []byte, *customerror.CustomError) {
+	tooLarge := customerror.NewCustomError(errors.New("file too large"), fmt.Sprintf("file must not be larger than %d bytes", maxSize), http.StatusRequestEntityTooLarge)
+	if file.Size > maxSize {
+		return nil, nil, tooLarge
+	}
+
+	// Validate file extension
+	importFormat := utils.ImportFormatForFile(file.Filename)
+	if format != "" {
+		importFormat = utils.FindImportFormat(format)
+		if importFormat == nil {
+			return nil, nil, customerror.NewCustomError(errors.New("invalid format"), "format must be one of "+importFormatNames(), 400)
+		}
+		if !importFormat.Accepts(file.Filename) {
+			message := fmt.Sprintf("Only %s files are allowed", importFormat.Label)
+			return nil, nil, customerror.NewCustomError(errors.New(message), message, 400)
+		}
+	}
+	if importFormat == nil {
+		return nil, nil, customerror.NewCustomError(errors.New("unsupported file type"), "Unsupported file type, use one of "+utils.ImportExtensions(), 400)
+	}
+
+	f, err := file.Open()
+	if err != nil {
+		return nil, nil, customerror.NewCustomError(err, err.Error(), 400)
+	}
+	data, err := io.ReadAll(io.LimitReader(f, maxSize+1))
+	f.Close()
+	if err != nil {
+		return nil, nil, customerror.NewCustomError(err, err.Error(), 400)
+	}
+	if int64(len(data)) > maxSize {
+		return nil, nil, tooLarge
+	}
+	return importFormat, data, nil
+}
+
+// importFormatNames lists the names of the import formats, for messages
+func importFormatNames() string {
+	names := []string{}
+	for _, format := range utils.ImportFormats {
+		names = append(names, format.Name)
+	}
+	return strings.Join(names, ", ")
+}
+
+// replayImport returns the import userID started with idempotencyKey, nil if
+// there is none. A key sent before with another file or options is refused.
+func (s *ImportsService) replayImport(userID int, idempotencyKey string, requestHash string) (*models.ImportResponse, *customerror.CustomError) {
+	imp, cuserr := s.importsRepo.GetImportByIdempotencyKey(userID, idempotencyKey)
+	if cuserr != nil {
+		if cuserr.HTTPCode == 404 {
+			return nil, nil
+		}
+		return nil, cuserr
+	}
+
+	if imp.RequestHash == nil || *imp.RequestHash != requestHash {
+		return nil, customerror.NewCustomError(errors.New("idempotency key reused"), "Idempotency-Key was already used for a different upload", http.StatusUnprocessableEntity)
+	}
+	return newImportResponse(imp), nil
+}
+
+// importRequestHash identifies the format, options and file of an upload
+func importRequestHash(format string, mode string, atomic bool, refreshInterval *int, data []byte) string {
+	hash := sha256.New()
+	hash.Write([]byte(format + "\n" + mode + "\n" + strconv.FormatBool(atomic) + "\n"))
+	// Only when set, so uploads without one keep the hash they had before
+	if refreshInterval != nil {
+		hash.Write([]byte("refresh_interval=" + strconv.Itoa(*refreshInterval) + "\n"))
+	}
+	hash.Write(data)
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// GetImport returns the state and progress of an import of userID.
+func (s *ImportsService) GetImport(userID int, importID int) (*models.ImportResponse, *customerror.CustomError) {
+	imp, cuserr := s.checkImportOwner(userID, importID)
+	if cuserr != nil {
+		return nil, cuserr
+	}
+	return newImportResponse(imp), nil
+}
+
+// GetImportRows lists a page of what became of the rows of an import of userID,
+// in file order, only those with status when it is not empty.
+func (s *ImportsService) GetImportRows(userID int, importID int, status string, limit int, offset int) (*models.ImportRowsResponse, *customerror.CustomError) {
+	if limit <= 0 {
+		limit = defaultImportRowsLimit
+	}
+	if limit > maxImportRowsLimit {
+		limit = maxImportRowsLimit
+	}
+	if offset < 0 {
+		offset = 0
+	}
+	if cuserr := checkRowStatus(status); cuserr != nil {
+		return nil, cuserr
+	}
+	if _, cuserr := s.checkImportOwner(userID, importID); cuserr != nil {
+		return nil, cuserr
+	}
+
+	rows, total, cuserr := s.importsRepo.GetImportRows(importID, status, limit, offset)
+	if cuserr != nil {
+		return nil, cuserr
+	}
+
+	response := &models.ImportRowsResponse{
+		Rows:   []*models.ImportRowResponse{},
+		Total:  total,
+		Limit:  limit,
+		Offset: offset,
+	}
+	for _, row := range rows {
+		response.Rows = append(response.Rows, newImportRowResponse(row))
+	}
+	return response, nil
+}
+
+// GetImportReport returns every row of an import of userID in file order, only
+// those with status when it is not empty.
+func (s *ImportsService) GetImportReport(userID int, importID int, status string) ([]*models.ImportRowResponse, *customerror.CustomError) {
+	if cuserr := checkRowStatus(status); cuserr != nil {
+		return nil, cuserr
+	}
+	if _, cuserr := s.checkImportOwner(userID, importID); cuserr != nil {
+		return nil, cuserr
+	}
+
+	report := []*models.ImportRowResponse{}
+	for offset := 0; ; offset += importReportPage {
+		rows, _, cuserr := s.importsRepo.GetImportRows(importID, status, importReportPage, offset)
+		if cuserr != nil {
+			return nil, cuserr
+		}
+		for _, row := range rows {
+			report = append(report, newImportRowResponse(row))
+		}
+		if len(rows) < importReportPage {
+			return report, nil
+		}
+	}
+}
+
+func checkRowStatus(status string) *customerror.CustomError {
+	switch status {
+	case "", importsmodels.RowSucceeded, importsmodels.RowFailed, importsmodels.RowSkipped:
+		return nil
+	}
+	return customerror.NewCustomError(errors.New("invalid row status"), "status must be succeeded, failed or skipped", 400)
+}
+
+func newImportRowResponse(row *importsmodels.RowResult) *models.ImportRowResponse {
+	return &models.ImportRowResponse{
+		Row:       row.Row,
+		Title:     row.Title,
+		URL:       row.URL,
+		Status:    row.Status,
+		Error:     row.Error,
+		ArticleID: row.ArticleID,
+	}
+}
+
+// FollowImport returns the events of an import of userID running in this
+// process or finished here lately, nil with the import itself when there are
+// none to follow. A queued or running import without events runs in another
+// process and cannot be followed from this one.
+func (s *ImportsService) FollowImport(userID int, importID int) (*utils.ImportEvents, *models.ImportResponse, *customerror.CustomError) {
+	imp, cuserr := s.checkImportOwner(userID, importID)
+	if cuserr != nil {
+		return nil, nil, cuserr
+	}
+
+	s.mu.Lock()
+	var events *utils.ImportEvents
+	if job := s.running[importID]; job != nil {
+		events = job.events
+	} else {
+		events = s.finished[importID]
+	}
+	s.mu.Unlock()
+
+	if events == nil && !imp.Finished() {
+		return nil, nil, customerror.NewCustomError(errors.New("import not running in this process"), "Import is not running", http.StatusConflict)
+	}
+	return events, newImportResponse(imp), nil
+}
+
+// CancelImport stops a running import of userID. Articles already created are
+// kept. It returns once the import has stopped.
+func (s *ImportsService) CancelImport(userID int, importID int) (*models.ImportResponse, *customerror.CustomError) {
+	imp, cuserr := s.checkImportOwner(userID, importID)
+	if cuserr != nil {
+		return nil, cuserr
+	}
+	if imp.Finished() {
+		return nil, customerror.NewCustomError(errors.New("import finished"), "Import has already finished", http.StatusConflict)
+	}
+
+	s.mu.Lock()
+	job := s.running[importID]
+	s.mu.Unlock()
+	if job == nil {
+		return nil, customerror.NewCustomError(errors.New("import not running in this process"), "Import is not running", http.StatusConflict)
+	}
+
+	job.cancel()
+	select {
+	case <-job.done:
+	case <-time.After(importCancelTimeout):
+	}
+
+	return s.GetImport(userID, importID)
+}
+
+func (s *ImportsService) checkImportOwner(userID int, importID int) (*importsmodels.Import, *customerror.CustomError) {
+	imp, cuserr := s.importsRepo.GetImportByID(importID)
+	if cuserr != nil {
+		return nil, cuserr
+	}
+	if imp.UserID != userID {
+		return nil, customerror.NewCustomError(nil, "You are not authorized to access this import", 403)
+	}
+	return imp, nil
+}
+
+// saveProgress records the rows finished since the last save and the counts of an import.
+func (s *ImportsService) saveProgress(importID int, progress *utils.ImportProgress) {
+	s.saveRows(importID, progress)
+	if cuserr := s.importsRepo.UpdateImportCounts(importID, progress.Counts(), time.Now().Add(importLease)); cuserr != nil {
+		log.Printf("Error saving progress of import %d: %s", importID, cuserr.OriginalMessage())
+	}
+}
+
+func (s *ImportsService) saveRows(importID int, progress *utils.ImportProgress) {
+	rows := progress.TakeResults()
+	for _, row := range rows {
+		row.ImportID = importID
+	}
+	if cuserr := s.importsRepo.AddImportRows(importID, rows); cuserr != nil {
+		log.Printf("Error saving %d rows of import %d: %s", len(rows), importID, cuserr.OriginalMessage())
+	}
+}
+
+// start runs an import in the background, saving its progress and row results
+// every importProgressInterval and its final state once run returns.
+func (s *ImportsService) start(importID int, run func(ctx context.Context, progress *utils.ImportProgress) *customerror.CustomError) {
+	ctx, cancel := context.WithCancel(context.Background())
+	job := &runningImport{cancel: cancel, done: make(chan struct{}), events: utils.NewImportEvents(importEventsKept)}
+
+	s.mu.Lock()
+	s.running[importID] = job
+	s.mu.Unlock()
+
+	go func() {
+		defer func() {
+			job.events.Close()
+			s.mu.Lock()
+			delete(s.running, importID)
+			s.finished[importID] = job.events
+			s.mu.Unlock()
+			time.AfterFunc(importEventsRetention, func() {
+				s.mu.Lock()
+				delete(s.finished, importID)
+				s.mu.Unlock()
+			})
+			cancel()
+			close(job.done)
+		}()
+
+		if cuserr := s.importsRepo.StartImport(importID, time.Now().Add(importLease)); cuserr != nil {
+			log.Printf("Error starting import %d: %s", importID, cuserr.OriginalMessage())
+			return
+		}
+
+		progress := utils.NewImportProgress(job.events)
+		stop := make(chan struct{})
+		var saver sync.WaitGroup
+		saver.Add(1)
+		go func() {
+			defer saver.Done()
+			ticker := time.NewTicker(importProgressInterval)
+			defer ticker.Stop()
+			for {
+				select {
+				case <-ticker.C:
+					s.saveProgress(importID, progress)
+				case <-stop:
+					return
+				}
+			}
+		}()
+
+		cuserr := run(ctx, progress)
+		close(stop)
+		saver.Wait()
+		s.saveRows(importID, progress)
+
+		state := importsmodels.StateCompleted
+		var reason *string
+		switch {
+		case ctx.Err() != nil:
+			state = importsmodels.StateCancelled
+		case cuserr != nil:
+			state = importsmodels.StateFailed
+			message := cuserr.Error()
+			reason = &message
+		}
+		if cuserr := s.importsRepo.FinishImport(importID, state, progress.Counts(), reason); cuserr != nil {
+			log.Printf("Error finishing import %d: %s", importID, cuserr.OriginalMessage())
+		}
+		if imp, cuserr := s.importsRepo.GetImportByID(importID); cuserr == nil {
+			job.events.Publish(utils.ImportEventCompleted, newImportResponse(imp))
+		}
+	}()
+}
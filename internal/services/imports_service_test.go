@@ -0,0 +1,170 @@
+package services
+
+import (
+	"bytes"
+	"database/sql"
+	"errors"
+	"mime/multipart"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yantology/go-gin-simple-blog-with-fts/pkg/interfaces/importsinterface"
+	"github.com/yantology/go-gin-simple-blog-with-fts/pkg/models/importsmodels"
+	"github.com/yantology/go-gin-simple-blog-with-fts/pkg/repositories/importsrepository"
+	"github.com/yantology/go-gin-simple-blog-with-fts/pkg/utils/customerror"
+	"github.com/yantology/go-gin-simple-blog-with-fts/pkg/utils/customerror/postgreserror"
+)
+
+// fakeImports keeps imports in memory, refusing a second import of a user
+// with the same idempotency key like the unique index does. Imports are never
+// started, so that the jobs end before reading their file.
+type fakeImports struct {
+	importsinterface.ImportRepository
+	mu      sync.Mutex
+	imports []*importsmodels.Import
+	// racing, when set, is inserted by a concurrent request right before the
+	// next CreateImport
+	racing *importsmodels.Import
+}
+
+func (f *fakeImports) CreateImport(imp *importsmodels.Import) *customerror.CustomError {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.racing != nil {
+		f.insert(f.racing)
+		f.racing = nil
+	}
+	if imp.IdempotencyKey != nil {
+		for _, other := range f.imports {
+			if other.UserID == imp.UserID && other.IdempotencyKey != nil && *other.IdempotencyKey == *imp.IdempotencyKey {
+				return postgreserror.NewPostgresError(&pq.Error{Code: "23505"})
+			}
+		}
+	}
+	f.insert(imp)
+	return nil
+}
+
+func (f *fakeImports) insert(imp *importsmodels.Import) {
+	imp.ID = len(f.imports) + 1
+	imp.State = importsmodels.StateQueued
+	imp.CreatedAt = time.Now()
+	stored := *imp
+	f.imports = append(f.imports, &stored)
+}
+
+func (f *fakeImports) GetImportByIdempotencyKey(userID int, key string) (*importsmodels.Import, *customerror.CustomError) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, imp := range f.imports {
+		if imp.UserID == userID && imp.IdempotencyKey != nil && *imp.IdempotencyKey == key {
+			stored := *imp
+			return &stored, nil
+		}
+	}
+	return nil, postgreserror.NewPostgresError(sql.ErrNoRows)
+}
+
+func (f *fakeImports) StartImport(id int, leaseExpiresAt time.Time) *customerror.CustomError {
+	return customerror.NewCustomError(errors.New("not started"), "imports are not started in tests", 500)
+}
+
+// uploadFile returns content as a file uploaded with a multipart form
+func uploadFile(t *testing.T, filename string, content string) *multipart.FileHeader {
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+	part, err := writer.CreateFormFile("file", filename)
+	require.NoError(t, err)
+	_, err = part.Write([]byte(content))
+	require.NoError(t, err)
+	require.NoError(t, writer.Close())
+
+	form, err := multipart.NewReader(body, writer.Boundary()).ReadForm(1 << 20)
+	require.NoError(t, err)
+	return form.File["file"][0]
+}
+
+// waitImport waits for the job of an import to end
+func waitImport(service *ImportsService, importID int) {
+	service.mu.Lock()
+	job := service.running[importID]
+	service.mu.Unlock()
+	if job != nil {
+		<-job.done
+	}
+}
+
+func TestCreateImportIdempotencyKey(t *testing.T) {
+	const key = "5f1c2a9e"
+	csv := "title,url\nFirst,https://example.com/first\n"
+	otherCSV := "title,url\nSecond,https://example.com/second\n"
+
+	tests := []struct {
+		name string
+		// earlier is the file sent before with the key, in mode skip
+		earlier string
+		// racing is the file of the import a concurrent request with the key
+		// creates while this one is checked, in mode skip
+		racing  string
+		content string
+		mode    string
+		// replayed is set when the import sent before is returned
+		replayed bool
+		code     int
+	}{
+		{name: "New key starts an import", content: csv, mode: importsmodels.ModeSkip},
+		{name: "Same upload sent again", earlier: csv, content: csv, mode: importsmodels.ModeSkip, replayed: true},
+		{name: "Key reused with another file", earlier: csv, content: otherCSV, mode: importsmodels.ModeSkip, code: http.StatusUnprocessableEntity},
+		{name: "Key reused with other options", earlier: csv, content: csv, mode: importsmodels.ModeUpdate, code: http.StatusUnprocessableEntity},
+		{name: "Retry inserted after the first upload", racing: csv, content: csv, mode: importsmodels.ModeSkip, replayed: true},
+		{name: "Other upload inserted with the key first", racing: otherCSV, content: csv, mode: importsmodels.ModeSkip, code: http.StatusUnprocessableEntity},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			imports := &fakeImports{}
+			service := NewImportsService(importsrepository.NewImportsRepository(imports), nil, nil, 1<<20)
+
+			var earlierID int
+			if tt.earlier != "" {
+				earlier, started, cuserr := service.CreateImport(10, uploadFile(t, "posts.csv", tt.earlier), "", importsmodels.ModeSkip, false, nil, key)
+				require.Nil(t, cuserr)
+				require.True(t, started)
+				waitImport(service, earlier.ID)
+				earlierID = earlier.ID
+			}
+			if tt.racing != "" {
+				racingKey := key
+				requestHash := importRequestHash(importsmodels.SourceCSV, importsmodels.ModeSkip, false, nil, []byte(tt.racing))
+				imports.racing = &importsmodels.Import{UserID: 10, Source: importsmodels.SourceCSV, Filename: "posts.csv",
+					Mode: importsmodels.ModeSkip, IdempotencyKey: &racingKey, RequestHash: &requestHash}
+				earlierID = 1
+			}
+
+			response, started, cuserr := service.CreateImport(10, uploadFile(t, "posts.csv", tt.content), "", tt.mode, false, nil, key)
+			if tt.code != 0 {
+				if assert.NotNil(t, cuserr) {
+					assert.Equal(t, tt.code, cuserr.HTTPCode)
+				}
+				assert.Nil(t, response)
+				assert.False(t, started)
+				assert.Len(t, imports.imports, 1)
+				return
+			}
+			require.Nil(t, cuserr)
+			waitImport(service, response.ID)
+			assert.Len(t, imports.imports, 1)
+			if tt.replayed {
+				assert.False(t, started)
+				assert.Equal(t, earlierID, response.ID)
+			} else {
+				assert.True(t, started)
+			}
+		})
+	}
+}
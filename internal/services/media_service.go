@@ -0,0 +1,207 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"mime/multipart"
+	"net/http"
+	"time"
+
+	"github.com/yantology/go-gin-simple-blog-with-fts/internal/models"
+	"github.com/yantology/go-gin-simple-blog-with-fts/internal/utils"
+	"github.com/yantology/go-gin-simple-blog-with-fts/pkg/interfaces/mediainterface"
+	"github.com/yantology/go-gin-simple-blog-with-fts/pkg/models/mediamodels"
+	"github.com/yantology/go-gin-simple-blog-with-fts/pkg/repositories/mediarepository"
+	"github.com/yantology/go-gin-simple-blog-with-fts/pkg/utils/customerror"
+)
+
+// thumbnailWidths are the resized copies made of every upload; images are never enlarged
+var thumbnailWidths = []int{320, 800}
+
+type MediaService struct {
+	mediaRepo     *mediarepository.MediaRepository
+	blobStore     mediainterface.BlobStore
+	maxUploadSize int64
+}
+
+func NewMediaService(mediaRepo *mediarepository.MediaRepository, blobStore mediainterface.BlobStore, maxUploadSize int64) *MediaService {
+	return &MediaService{
+		mediaRepo:     mediaRepo,
+		blobStore:     blobStore,
+		maxUploadSize: maxUploadSize,
+	}
+}
+
+func newMediaResponse(media *mediamodels.Media) *models.MediaResponse {
+	response := &models.MediaResponse{
+		ID:          media.ID,
+		UserID:      media.UserID,
+		URL:         media.URL,
+		ContentType: media.ContentType,
+		Size:        media.Size,
+		Width:       media.Width,
+		Height:      media.Height,
+		Thumbnails:  []*models.MediaThumbnailResponse{},
+		CreatedAt:   media.CreatedAt,
+	}
+	for _, variant := range media.Variants {
+		response.Thumbnails = append(response.Thumbnails, &models.MediaThumbnailResponse{
+			URL:         variant.URL,
+			ContentType: variant.ContentType,
+			Width:       variant.Width,
+			Height:      variant.Height,
+		})
+	}
+	return response
+}
+
+// newStorageKey returns a unique, unguessable key prefix such as "media/2024/12/9f86d081884c7d65"
+func newStorageKey() (string, error) {
+	random := make([]byte, 8)
+	if _, err := rand.Read(random); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("media/%s/%s", time.Now().Format("2006/01"), hex.EncodeToString(random)), nil
+}
+
+// UploadMedia validates an uploaded image, strips its metadata, stores it with its
+// thumbnails and records it for userID.
+func (s *MediaService) UploadMedia(ctx context.Context, userID int, file *multipart.FileHeader) (*models.MediaResponse, *customerror.CustomError) {
+	tooLarge := customerror.NewCustomError(errors.New("file too large"), fmt.Sprintf("file must not be larger than %d bytes", s.maxUploadSize), http.StatusRequestEntityTooLarge)
+	if file.Size > s.maxUploadSize {
+		return nil, tooLarge
+	}
+
+	f, err := file.Open()
+	if err != nil {
+		return nil, customerror.NewCustomError(err, "failed to read file", 400)
+	}
+	defer f.Close()
+
+	data, err := io.ReadAll(io.LimitReader(f, s.maxUploadSize+1))
+	if err != nil {
+		return nil, customerror.NewCustomError(err, "failed to read file", 400)
+	}
+	if int64(len(data)) > s.maxUploadSize {
+		return nil, tooLarge
+	}
+
+	clean, err := utils.CleanImageUpload(data)
+	if errors.Is(err, utils.ErrUnsupportedImage) {
+		return nil, customerror.NewCustomError(err, err.Error(), http.StatusUnsupportedMediaType)
+	}
+	if err != nil {
+		return nil, customerror.NewCustomError(err, err.Error(), 400)
+	}
+
+	keyPrefix, err := newStorageKey()
+	if err != nil {
+		return nil, customerror.NewCustomError(err, "failed to store file", 500)
+	}
+
+	media := &mediamodels.Media{
+		UserID:      userID,
+		StorageKey:  keyPrefix + clean.Extension,
+		ContentType: clean.ContentType,
+		Size:        int64(len(clean.Data)),
+		Width:       clean.Width,
+		Height:      clean.Height,
+		Variants:    []mediamodels.MediaVariant{},
+	}
+	media.URL = s.blobStore.URL(media.StorageKey)
+
+	stored := []string{}
+	removeStored := func() {
+		for _, key := range stored {
+			if cuserr := s.blobStore.Delete(context.Background(), key); cuserr != nil {
+				log.Printf("failed to remove %s after a failed upload: %v", key, cuserr)
+			}
+		}
+	}
+
+	if cuserr := s.blobStore.Put(ctx, media.StorageKey, clean.Data, clean.ContentType); cuserr != nil {
+		return nil, cuserr
+	}
+	stored = append(stored, media.StorageKey)
+
+	for _, width := range thumbnailWidths {
+		if width >= clean.Width {
+			continue
+		}
+
+		thumbnail, contentType, height, err := utils.ResizeImage(clean.Image, width, clean.ContentType)
+		if err != nil {
+			removeStored()
+			return nil, customerror.NewCustomError(err, "failed to create thumbnail", 500)
+		}
+
+		extension := ".png"
+		if contentType == "image/jpeg" {
+			extension = ".jpg"
+		}
+		key := fmt.Sprintf("%s-%d%s", keyPrefix, width, extension)
+		if cuserr := s.blobStore.Put(ctx, key, thumbnail, contentType); cuserr != nil {
+			removeStored()
+			return nil, cuserr
+		}
+		stored = append(stored, key)
+
+		media.Variants = append(media.Variants, mediamodels.MediaVariant{
+			StorageKey:  key,
+			URL:         s.blobStore.URL(key),
+			ContentType: contentType,
+			Size:        int64(len(thumbnail)),
+			Width:       width,
+			Height:      height,
+		})
+	}
+
+	if cuserr := s.mediaRepo.CreateMedia(media); cuserr != nil {
+		removeStored()
+		return nil, cuserr
+	}
+
+	return newMediaResponse(media), nil
+}
+
+func (s *MediaService) GetMediaByID(id int) (*models.MediaResponse, *customerror.CustomError) {
+	media, cuserr := s.mediaRepo.GetMediaByID(id)
+	if cuserr != nil {
+		return nil, cuserr
+	}
+
+	return newMediaResponse(media), nil
+}
+
+// DeleteMedia removes an upload of userID and its files. Articles using it lose their cover.
+func (s *MediaService) DeleteMedia(ctx context.Context, userID int, id int) *customerror.CustomError {
+	media, cuserr := s.mediaRepo.GetMediaByID(id)
+	if cuserr != nil {
+		return cuserr
+	}
+
+	if media.UserID != userID {
+		return customerror.NewCustomError(nil, "You are not authorized to delete this media", 403)
+	}
+
+	if cuserr := s.mediaRepo.DeleteMediaByID(id); cuserr != nil {
+		return cuserr
+	}
+
+	// The record is gone, so a file left behind is only wasted space
+	keys := []string{media.StorageKey}
+	for _, variant := range media.Variants {
+		keys = append(keys, variant.StorageKey)
+	}
+	for _, key := range keys {
+		if cuserr := s.blobStore.Delete(ctx, key); cuserr != nil {
+			log.Printf("failed to delete %s: %v", key, cuserr)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,131 @@
+package services
+
+import (
+	"errors"
+	"slices"
+	"strings"
+
+	"github.com/yantology/go-gin-simple-blog-with-fts/internal/models"
+	"github.com/yantology/go-gin-simple-blog-with-fts/pkg/models/articlesmodels"
+	"github.com/yantology/go-gin-simple-blog-with-fts/pkg/repositories/articlesrepository"
+	"github.com/yantology/go-gin-simple-blog-with-fts/pkg/utils/customerror"
+)
+
+const (
+	defaultBookmarksLimit = 20
+	maxBookmarksLimit     = 100
+)
+
+type ReactionsService struct {
+	articlesRepo *articlesrepository.ArticlesRepository
+}
+
+func NewReactionsService(articlesRepo *articlesrepository.ArticlesRepository) *ReactionsService {
+	return &ReactionsService{
+		articlesRepo: articlesRepo,
+	}
+}
+
+func checkReactionKind(kind string) *customerror.CustomError {
+	if !slices.Contains(articlesmodels.ReactionKinds, kind) {
+		return customerror.NewCustomError(errors.New("unknown reaction"), "reaction must be one of: "+strings.Join(articlesmodels.ReactionKinds, ", "), 400)
+	}
+	return nil
+}
+
+// AddReaction leaves a reaction of userID on an article. Reacting twice with the same kind changes nothing.
+func (s *ReactionsService) AddReaction(userID int, articleID int, kind string) (*models.ReactionsResponse, *customerror.CustomError) {
+	if cuserr := checkReactionKind(kind); cuserr != nil {
+		return nil, cuserr
+	}
+	if _, cuserr := s.articlesRepo.GetArticleByID(articleID); cuserr != nil {
+		return nil, cuserr
+	}
+
+	if cuserr := s.articlesRepo.AddReaction(articleID, userID, kind); cuserr != nil {
+		return nil, cuserr
+	}
+	return s.GetReactions(userID, articleID)
+}
+
+// RemoveReaction withdraws a reaction of userID from an article. Removing a reaction that is not there changes nothing.
+func (s *ReactionsService) RemoveReaction(userID int, articleID int, kind string) (*models.ReactionsResponse, *customerror.CustomError) {
+	if cuserr := checkReactionKind(kind); cuserr != nil {
+		return nil, cuserr
+	}
+	if _, cuserr := s.articlesRepo.GetArticleByID(articleID); cuserr != nil {
+		return nil, cuserr
+	}
+
+	if cuserr := s.articlesRepo.RemoveReaction(articleID, userID, kind); cuserr != nil {
+		return nil, cuserr
+	}
+	return s.GetReactions(userID, articleID)
+}
+
+// GetReactions returns the reaction counts of an article and the reactions userID left on it.
+func (s *ReactionsService) GetReactions(userID int, articleID int) (*models.ReactionsResponse, *customerror.CustomError) {
+	article, cuserr := s.articlesRepo.GetArticleByID(articleID)
+	if cuserr != nil {
+		return nil, cuserr
+	}
+
+	kinds, cuserr := s.articlesRepo.GetUserReactions(articleID, userID)
+	if cuserr != nil {
+		return nil, cuserr
+	}
+
+	return &models.ReactionsResponse{
+		ArticleID:   article.ID,
+		Reactions:   reactionsOrEmpty(article.Reactions),
+		MyReactions: kinds,
+	}, nil
+}
+
+// AddBookmark bookmarks an article for userID. Bookmarking twice changes nothing.
+func (s *ReactionsService) AddBookmark(userID int, articleID int) (*models.BookmarkResponse, *customerror.CustomError) {
+	if _, cuserr := s.articlesRepo.GetArticleByID(articleID); cuserr != nil {
+		return nil, cuserr
+	}
+
+	if cuserr := s.articlesRepo.AddBookmark(userID, articleID); cuserr != nil {
+		return nil, cuserr
+	}
+	return &models.BookmarkResponse{ArticleID: articleID, Bookmarked: true}, nil
+}
+
+// RemoveBookmark removes a bookmark of userID. It also works for articles that are in the trash.
+func (s *ReactionsService) RemoveBookmark(userID int, articleID int) (*models.BookmarkResponse, *customerror.CustomError) {
+	if cuserr := s.articlesRepo.RemoveBookmark(userID, articleID); cuserr != nil {
+		return nil, cuserr
+	}
+	return &models.BookmarkResponse{ArticleID: articleID, Bookmarked: false}, nil
+}
+
+// GetBookmarks returns a page of the articles userID bookmarked, most recently bookmarked first.
+func (s *ReactionsService) GetBookmarks(userID int, limit int, offset int) (*models.BookmarksResponse, *customerror.CustomError) {
+	if limit <= 0 {
+		limit = defaultBookmarksLimit
+	}
+	if limit > maxBookmarksLimit {
+		limit = maxBookmarksLimit
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	articles, cuserr := s.articlesRepo.GetBookmarkedArticles(userID, limit, offset)
+	if cuserr != nil {
+		return nil, cuserr
+	}
+
+	response := &models.BookmarksResponse{
+		Articles: []*models.ArticleResponse{},
+		Limit:    limit,
+		Offset:   offset,
+	}
+	for _, article := range articles {
+		response.Articles = append(response.Articles, newArticleResponse(article))
+	}
+	return response, nil
+}
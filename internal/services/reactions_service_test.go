@@ -0,0 +1,192 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/yantology/go-gin-simple-blog-with-fts/internal/models"
+	"github.com/yantology/go-gin-simple-blog-with-fts/pkg/models/articlesmodels"
+	"github.com/yantology/go-gin-simple-blog-with-fts/pkg/repositories/articlesrepository"
+	"github.com/yantology/go-gin-simple-blog-with-fts/pkg/utils/customerror"
+)
+
+type reactionKey struct {
+	articleID, userID int
+	kind              string
+}
+
+type bookmarkKey struct {
+	userID, articleID int
+}
+
+// fakeReactions keeps reactions and bookmarks in memory, counting the
+// reactions into the articles like the database trigger does
+type fakeReactions struct {
+	*fakeArticles
+	reactions map[reactionKey]bool
+	bookmarks map[bookmarkKey]bool
+}
+
+func (f *fakeReactions) AddReaction(articleID int, userID int, kind string) *customerror.CustomError {
+	key := reactionKey{articleID, userID, kind}
+	if !f.reactions[key] {
+		f.reactions[key] = true
+		f.articles[articleID].Reactions[kind]++
+	}
+	return nil
+}
+
+func (f *fakeReactions) RemoveReaction(articleID int, userID int, kind string) *customerror.CustomError {
+	key := reactionKey{articleID, userID, kind}
+	if f.reactions[key] {
+		delete(f.reactions, key)
+		f.articles[articleID].Reactions[kind]--
+	}
+	return nil
+}
+
+func (f *fakeReactions) GetUserReactions(articleID int, userID int) ([]string, *customerror.CustomError) {
+	kinds := []string{}
+	for _, kind := range articlesmodels.ReactionKinds {
+		if f.reactions[reactionKey{articleID, userID, kind}] {
+			kinds = append(kinds, kind)
+		}
+	}
+	return kinds, nil
+}
+
+func (f *fakeReactions) AddBookmark(userID int, articleID int) *customerror.CustomError {
+	f.bookmarks[bookmarkKey{userID, articleID}] = true
+	return nil
+}
+
+func (f *fakeReactions) RemoveBookmark(userID int, articleID int) *customerror.CustomError {
+	delete(f.bookmarks, bookmarkKey{userID, articleID})
+	return nil
+}
+
+// newFakeReactionsService returns a service over article 1, which user 20 liked
+func newFakeReactionsService() (*ReactionsService, *fakeReactions) {
+	repo := &fakeReactions{
+		fakeArticles: &fakeArticles{articles: map[int]*articlesmodels.Article{
+			1: {ID: 1, UserID: 10, Reactions: map[string]int{"like": 1}},
+		}},
+		reactions: map[reactionKey]bool{{1, 20, "like"}: true},
+		bookmarks: map[bookmarkKey]bool{},
+	}
+	return NewReactionsService(articlesrepository.NewArticlesRepository(repo)), repo
+}
+
+func TestCheckReactionKind(t *testing.T) {
+	for _, kind := range articlesmodels.ReactionKinds {
+		t.Run(kind, func(t *testing.T) {
+			assert.Nil(t, checkReactionKind(kind))
+		})
+	}
+
+	for _, kind := range []string{"", "Like", "dislike", "like ", "like,love"} {
+		t.Run("Invalid "+kind, func(t *testing.T) {
+			cuserr := checkReactionKind(kind)
+			if assert.NotNil(t, cuserr) {
+				assert.Equal(t, 400, cuserr.HTTPCode)
+				assert.Equal(t, "reaction must be one of: like, love, laugh, wow, sad, clap", cuserr.Error())
+			}
+		})
+	}
+}
+
+func TestSetReaction(t *testing.T) {
+	tests := []struct {
+		name      string
+		add       bool
+		userID    int
+		articleID int
+		kind      string
+		expected  *models.ReactionsResponse
+		code      int
+	}{
+		{
+			name: "Add", add: true, userID: 30, articleID: 1, kind: "love",
+			expected: &models.ReactionsResponse{ArticleID: 1, Reactions: map[string]int{"like": 1, "love": 1}, MyReactions: []string{"love"}},
+		},
+		{
+			name: "Add again changes nothing", add: true, userID: 20, articleID: 1, kind: "like",
+			expected: &models.ReactionsResponse{ArticleID: 1, Reactions: map[string]int{"like": 1}, MyReactions: []string{"like"}},
+		},
+		{
+			name: "Remove", add: false, userID: 20, articleID: 1, kind: "like",
+			expected: &models.ReactionsResponse{ArticleID: 1, Reactions: map[string]int{"like": 0}, MyReactions: []string{}},
+		},
+		{
+			name: "Remove a missing reaction changes nothing", add: false, userID: 30, articleID: 1, kind: "like",
+			expected: &models.ReactionsResponse{ArticleID: 1, Reactions: map[string]int{"like": 1}, MyReactions: []string{}},
+		},
+		{name: "Add unknown kind", add: true, userID: 30, articleID: 1, kind: "dislike", code: 400},
+		{name: "Remove unknown kind", add: false, userID: 30, articleID: 1, kind: "dislike", code: 400},
+		{name: "Missing article", add: true, userID: 30, articleID: 99, kind: "like", code: 404},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, _ := newFakeReactionsService()
+
+			set := service.RemoveReaction
+			if tt.add {
+				set = service.AddReaction
+			}
+			response, cuserr := set(tt.userID, tt.articleID, tt.kind)
+			if tt.code != 0 {
+				if assert.NotNil(t, cuserr) {
+					assert.Equal(t, tt.code, cuserr.HTTPCode)
+				}
+				return
+			}
+			assert.Nil(t, cuserr)
+			assert.Equal(t, tt.expected, response)
+
+			// Sending the same request again answers the same
+			again, cuserr := set(tt.userID, tt.articleID, tt.kind)
+			assert.Nil(t, cuserr)
+			assert.Equal(t, response, again)
+		})
+	}
+}
+
+func TestSetBookmark(t *testing.T) {
+	tests := []struct {
+		name      string
+		add       bool
+		articleID int
+		expected  *models.BookmarkResponse
+		code      int
+	}{
+		{name: "Add", add: true, articleID: 1, expected: &models.BookmarkResponse{ArticleID: 1, Bookmarked: true}},
+		{name: "Remove", add: false, articleID: 1, expected: &models.BookmarkResponse{ArticleID: 1, Bookmarked: false}},
+		// Bookmarks of trashed articles can still be removed
+		{name: "Remove without article", add: false, articleID: 99, expected: &models.BookmarkResponse{ArticleID: 99, Bookmarked: false}},
+		{name: "Add missing article", add: true, articleID: 99, code: 404},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, repo := newFakeReactionsService()
+
+			set := service.RemoveBookmark
+			if tt.add {
+				set = service.AddBookmark
+			}
+			for i := 0; i < 2; i++ {
+				response, cuserr := set(20, tt.articleID)
+				if tt.code != 0 {
+					if assert.NotNil(t, cuserr) {
+						assert.Equal(t, tt.code, cuserr.HTTPCode)
+					}
+					return
+				}
+				assert.Nil(t, cuserr)
+				assert.Equal(t, tt.expected, response)
+				assert.Equal(t, tt.add, repo.bookmarks[bookmarkKey{20, tt.articleID}])
+			}
+		})
+	}
+}
@@ -0,0 +1,170 @@
+package services
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/yantology/go-gin-simple-blog-with-fts/internal/models"
+	"github.com/yantology/go-gin-simple-blog-with-fts/pkg/models/scrapingmodels"
+	"github.com/yantology/go-gin-simple-blog-with-fts/pkg/repositories/scrapingrepository"
+	"github.com/yantology/go-gin-simple-blog-with-fts/pkg/utils/customerror"
+	"github.com/yantology/go-gin-simple-blog-with-fts/pkg/utils/extract"
+)
+
+// Where a scraping rule comes from
+const (
+	ScrapingRuleDefault = "default"
+	ScrapingRuleFile    = "file"
+	ScrapingRuleAdmin   = "admin"
+)
+
+// ScrapingRulesService keeps the registry of scraping rules used by imports.
+// Rules stored by admins replace those of the rules file, which replace the
+// built-in ones, host by host.
+type ScrapingRulesService struct {
+	scrapingRepo *scrapingrepository.ScrapingRepository
+	fileRules    []extract.Rule
+	registry     *extract.Registry
+}
+
+// NewScrapingRulesService loads the stored rules on top of fileRules into a new registry.
+func NewScrapingRulesService(scrapingRepo *scrapingrepository.ScrapingRepository, fileRules []extract.Rule) (*ScrapingRulesService, *customerror.CustomError) {
+	registry, err := extract.NewRegistry()
+	if err != nil {
+		return nil, customerror.NewCustomError(err, "failed to create scraping rules", http.StatusInternalServerError)
+	}
+
+	s := &ScrapingRulesService{
+		scrapingRepo: scrapingRepo,
+		fileRules:    fileRules,
+		registry:     registry,
+	}
+	if _, cuserr := s.reload(); cuserr != nil {
+		return nil, cuserr
+	}
+	return s, nil
+}
+
+// Registry returns the registry the rules are kept in; it follows later changes.
+func (s *ScrapingRulesService) Registry() *extract.Registry {
+	return s.registry
+}
+
+// GetScrapingRules returns every rule in use with where it comes from.
+func (s *ScrapingRulesService) GetScrapingRules() (*models.ScrapingRulesResponse, *customerror.CustomError) {
+	stored, cuserr := s.reload()
+	if cuserr != nil {
+		return nil, cuserr
+	}
+
+	sources := map[string]string{}
+	for _, rule := range extract.DefaultRules {
+		sources[extract.NormalizeHost(rule.Host)] = ScrapingRuleDefault
+	}
+	for _, rule := range s.fileRules {
+		sources[rule.Host] = ScrapingRuleFile
+	}
+	adminRules := map[string]*scrapingmodels.ScrapingRule{}
+	for _, rule := range stored {
+		sources[rule.Host] = ScrapingRuleAdmin
+		adminRules[rule.Host] = rule
+	}
+
+	response := &models.ScrapingRulesResponse{Rules: []*models.ScrapingRuleResponse{}}
+	for _, rule := range s.registry.Rules() {
+		item := &models.ScrapingRuleResponse{
+			Host:             rule.Host,
+			TitleSelector:    rule.Title,
+			ContentSelector:  rule.Content,
+			ExcludeSelectors: rule.Exclude,
+			Source:           sources[rule.Host],
+		}
+		if item.ExcludeSelectors == nil {
+			item.ExcludeSelectors = []string{}
+		}
+		if stored, ok := adminRules[rule.Host]; ok {
+			item.UpdatedBy = stored.UpdatedBy
+			item.UpdatedAt = &stored.UpdatedAt
+		}
+		response.Rules = append(response.Rules, item)
+	}
+	return response, nil
+}
+
+// SaveScrapingRule stores the rule for host set by the admin userID and puts it in use.
+func (s *ScrapingRulesService) SaveScrapingRule(userID int, host string, req *models.ScrapingRuleRequest) (*models.ScrapingRuleResponse, *customerror.CustomError) {
+	rule := extract.Rule{
+		Host:    host,
+		Title:   req.TitleSelector,
+		Content: req.ContentSelector,
+		Exclude: req.ExcludeSelectors,
+	}
+	if err := rule.Validate(); err != nil {
+		return nil, customerror.NewCustomError(err, err.Error(), http.StatusBadRequest)
+	}
+	if rule.Exclude == nil {
+		rule.Exclude = []string{}
+	}
+
+	stored := &scrapingmodels.ScrapingRule{
+		Host:             rule.Host,
+		TitleSelector:    rule.Title,
+		ContentSelector:  rule.Content,
+		ExcludeSelectors: rule.Exclude,
+		UpdatedBy:        &userID,
+	}
+	if cuserr := s.scrapingRepo.SaveScrapingRule(stored); cuserr != nil {
+		return nil, cuserr
+	}
+	if _, cuserr := s.reload(); cuserr != nil {
+		return nil, cuserr
+	}
+
+	return &models.ScrapingRuleResponse{
+		Host:             stored.Host,
+		TitleSelector:    stored.TitleSelector,
+		ContentSelector:  stored.ContentSelector,
+		ExcludeSelectors: stored.ExcludeSelectors,
+		Source:           ScrapingRuleAdmin,
+		UpdatedBy:        stored.UpdatedBy,
+		UpdatedAt:        &stored.UpdatedAt,
+	}, nil
+}
+
+// DeleteScrapingRule removes the stored rule for host; a file or default rule for the host applies again.
+func (s *ScrapingRulesService) DeleteScrapingRule(host string) *customerror.CustomError {
+	host = extract.NormalizeHost(host)
+	if host == "" {
+		return customerror.NewCustomError(errors.New("empty host"), "host is required", http.StatusBadRequest)
+	}
+
+	if cuserr := s.scrapingRepo.DeleteScrapingRule(host); cuserr != nil {
+		return cuserr
+	}
+	_, cuserr := s.reload()
+	return cuserr
+}
+
+// reload rebuilds the registry from the built-in, file and stored rules and returns the stored ones.
+// Stored rules are read again each time so that changes made by other instances are picked up.
+func (s *ScrapingRulesService) reload() ([]*scrapingmodels.ScrapingRule, *customerror.CustomError) {
+	stored, cuserr := s.scrapingRepo.GetScrapingRules()
+	if cuserr != nil {
+		return nil, cuserr
+	}
+
+	rules := append([]extract.Rule{}, extract.DefaultRules...)
+	rules = append(rules, s.fileRules...)
+	for _, rule := range stored {
+		rules = append(rules, extract.Rule{
+			Host:    rule.Host,
+			Title:   rule.TitleSelector,
+			Content: rule.ContentSelector,
+			Exclude: rule.ExcludeSelectors,
+		})
+	}
+	if err := s.registry.Replace(rules); err != nil {
+		return nil, customerror.NewCustomError(err, "invalid scraping rule: "+err.Error(), http.StatusInternalServerError)
+	}
+	return stored, nil
+}
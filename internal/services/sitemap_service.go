@@ -0,0 +1,152 @@
+package services
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/yantology/go-gin-simple-blog-with-fts/internal/utils"
+	"github.com/yantology/go-gin-simple-blog-with-fts/pkg/repositories/articlesrepository"
+	"github.com/yantology/go-gin-simple-blog-with-fts/pkg/utils/customerror"
+	"github.com/yantology/go-gin-simple-blog-with-fts/pkg/utils/sitemap"
+)
+
+// SitemapService builds sitemap.xml and robots.txt for search engines.
+// The sitemap is built on first request and kept until Invalidate is called;
+// robots.txt only depends on the configuration and is built once.
+type SitemapService struct {
+	articlesRepo *articlesrepository.ArticlesRepository
+	links        *utils.SiteLinks
+	robots       []byte
+
+	mu    sync.Mutex
+	cache *sitemapCache
+	// generation counts invalidations, so a build that raced with one is not cached
+	generation int
+}
+
+type sitemapCache struct {
+	// index is nil while every article fits in the first page
+	index []byte
+	pages [][]byte
+}
+
+func NewSitemapService(articlesRepo *articlesrepository.ArticlesRepository, links *utils.SiteLinks) *SitemapService {
+	return &SitemapService{
+		articlesRepo: articlesRepo,
+		links:        links,
+		robots:       buildRobots(links.BaseURL),
+	}
+}
+
+// Invalidate drops the cached sitemap, the next request rebuilds it.
+func (s *SitemapService) Invalidate() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.cache = nil
+	s.generation++
+}
+
+// SitemapPageURL returns the address of page n of a sitemap split by an index.
+func (s *SitemapService) SitemapPageURL(n int) string {
+	return s.links.BaseURL + "/sitemaps/articles-" + strconv.Itoa(n) + ".xml"
+}
+
+// GetSitemap returns /sitemap.xml: the list of every visible article or, past
+// sitemap.MaxURLs articles, an index of the pages holding them.
+func (s *SitemapService) GetSitemap() ([]byte, *customerror.CustomError) {
+	cache, cuserr := s.load()
+	if cuserr != nil {
+		return nil, cuserr
+	}
+
+	if cache.index != nil {
+		return cache.index, nil
+	}
+	return cache.pages[0], nil
+}
+
+// GetSitemapPage returns page n, counting from 1, of a sitemap split by an index.
+func (s *SitemapService) GetSitemapPage(n int) ([]byte, *customerror.CustomError) {
+	cache, cuserr := s.load()
+	if cuserr != nil {
+		return nil, cuserr
+	}
+
+	if n < 1 || n > len(cache.pages) {
+		return nil, customerror.NewCustomError(errors.New("sitemap page out of range"), "Record not found", 404)
+	}
+	return cache.pages[n-1], nil
+}
+
+// GetSitemapPages returns every page of a sitemap split by an index, nil when
+// the sitemap fits in /sitemap.xml.
+func (s *SitemapService) GetSitemapPages() ([][]byte, *customerror.CustomError) {
+	cache, cuserr := s.load()
+	if cuserr != nil {
+		return nil, cuserr
+	}
+
+	if cache.index == nil {
+		return nil, nil
+	}
+	return cache.pages, nil
+}
+
+// GetRobots returns robots.txt.
+func (s *SitemapService) GetRobots() []byte {
+	return s.robots
+}
+
+// buildRobots lets crawlers in everywhere except the personal API routes and
+// points them to the sitemap.
+func buildRobots(siteURL string) []byte {
+	var b strings.Builder
+	b.WriteString("User-agent: *\n")
+	b.WriteString("Disallow: /api/v1/me/\n")
+	b.WriteString("\n")
+	b.WriteString("Sitemap: " + siteURL + "/sitemap.xml\n")
+	return []byte(b.String())
+}
+
+// load returns the cached sitemap, building it when there is none. The lock is
+// not held while reading articles so article writes never wait for a build.
+func (s *SitemapService) load() (*sitemapCache, *customerror.CustomError) {
+	s.mu.Lock()
+	cache, generation := s.cache, s.generation
+	s.mu.Unlock()
+	if cache != nil {
+		return cache, nil
+	}
+
+	cache, cuserr := s.build()
+	if cuserr != nil {
+		return nil, cuserr
+	}
+
+	s.mu.Lock()
+	if generation == s.generation {
+		s.cache = cache
+	}
+	s.mu.Unlock()
+	return cache, nil
+}
+
+func (s *SitemapService) build() (*sitemapCache, *customerror.CustomError) {
+	links, cuserr := s.articlesRepo.GetArticleLinks()
+	if cuserr != nil {
+		return nil, cuserr
+	}
+
+	urls := make([]sitemap.URL, 0, len(links))
+	for _, link := range links {
+		urls = append(urls, sitemap.URL{Loc: s.links.Article(link.Slug), LastMod: link.UpdatedAt})
+	}
+
+	index, pages, err := sitemap.Build(urls, s.SitemapPageURL)
+	if err != nil {
+		return nil, customerror.NewCustomError(err, "failed to build sitemap", 500)
+	}
+	return &sitemapCache{index: index, pages: pages}, nil
+}
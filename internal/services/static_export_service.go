@@ -0,0 +1,397 @@
+package services
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/yantology/go-gin-simple-blog-with-fts/internal/models"
+	"github.com/yantology/go-gin-simple-blog-with-fts/internal/theme"
+	"github.com/yantology/go-gin-simple-blog-with-fts/internal/utils"
+	"github.com/yantology/go-gin-simple-blog-with-fts/pkg/utils/customerror"
+	"github.com/yantology/go-gin-simple-blog-with-fts/pkg/utils/feed"
+)
+
+const (
+	// staticExportBatch is the number of articles read per query
+	staticExportBatch = 100
+	// staticExportPageSize is the number of articles on one page of a listing
+	staticExportPageSize = 10
+)
+
+// SearchIndexEntry is one article in the search index of a static export
+type SearchIndexEntry struct {
+	Title   string    `json:"title"`
+	URL     string    `json:"url"`
+	Author  string    `json:"author"`
+	Tags    []string  `json:"tags"`
+	Excerpt string    `json:"excerpt"`
+	Date    time.Time `json:"date"`
+}
+
+// StaticExportService writes a read-only copy of the public site as plain files,
+// laid out so that every address of the HTML frontend resolves to a file or to
+// the index.html of a directory.
+type StaticExportService struct {
+	articlesService *ArticlesService
+	feedsService    *FeedsService
+	sitemapService  *SitemapService
+	theme           *theme.Theme
+	site            *theme.Site
+}
+
+func NewStaticExportService(articlesService *ArticlesService, feedsService *FeedsService, sitemapService *SitemapService, theme *theme.Theme, site *theme.Site) *StaticExportService {
+	return &StaticExportService{
+		articlesService: articlesService,
+		feedsService:    feedsService,
+		sitemapService:  sitemapService,
+		theme:           theme,
+		site:            site,
+	}
+}
+
+// staticAuthor is an author and their articles, newest first
+type staticAuthor struct {
+	userID   int
+	username string
+	articles []*models.ArticleResponse
+}
+
+// staticTag is a tag and the articles carrying it, newest first
+type staticTag struct {
+	name     string
+	articles []*models.ArticleResponse
+}
+
+// Export writes the site into dir: every visible article, the paginated
+// listings of the front page, authors and tags, the feeds, the sitemap and a
+// search page backed by search-index.json. Files already in dir are replaced.
+// It returns the number of articles exported.
+func (s *StaticExportService) Export(dir string) (int, *customerror.CustomError) {
+	articles, cuserr := s.allArticles()
+	if cuserr != nil {
+		return 0, cuserr
+	}
+
+	var authors []*staticAuthor
+	authorsByID := map[int]*staticAuthor{}
+	tags := map[string]*staticTag{}
+	for _, article := range articles {
+		author, ok := authorsByID[article.UserID]
+		if !ok {
+			author = &staticAuthor{userID: article.UserID, username: article.Author}
+			authorsByID[article.UserID] = author
+			authors = append(authors, author)
+		}
+		author.articles = append(author.articles, article)
+
+		for _, name := range article.Tags {
+			tagSlug := utils.TagSlug(name)
+			tag, ok := tags[tagSlug]
+			if !ok {
+				tag = &staticTag{name: name}
+				tags[tagSlug] = tag
+			}
+			tag.articles = append(tag.articles, article)
+		}
+	}
+
+	if err := s.theme.WriteStatic(filepath.Join(dir, "theme")); err != nil {
+		return 0, exportError(err)
+	}
+
+	links := s.site.Links
+	if cuserr := s.writeListing(dir, theme.PageHome, articles, links.Home(), &theme.Page{
+		Description: s.site.Description,
+	}); cuserr != nil {
+		return 0, cuserr
+	}
+
+	for _, article := range articles {
+		page := &theme.Page{
+			Title:       article.Title,
+			Description: article.Excerpt,
+			URL:         links.Article(article.Slug),
+			Type:        "article",
+			FeedURL:     links.UserFeed(article.UserID, feed.FormatRSS),
+			Article:     article,
+		}
+		if article.CoverURL != nil {
+			page.Image = links.Absolute(*article.CoverURL)
+		}
+		if cuserr := s.writePage(dir, page.URL, theme.PageArticle, page); cuserr != nil {
+			return 0, cuserr
+		}
+	}
+
+	for _, author := range authors {
+		if cuserr := s.writeListing(dir, theme.PageAuthor, author.articles, links.Author(author.userID, author.username), &theme.Page{
+			Title:       author.username,
+			Description: "Articles by " + author.username,
+			Type:        "profile",
+			FeedURL:     links.UserFeed(author.userID, feed.FormatRSS),
+			Heading:     author.username,
+		}); cuserr != nil {
+			return 0, cuserr
+		}
+		if cuserr := s.writeFeeds(dir, func(format string) (*feed.Feed, *customerror.CustomError) {
+			return s.feedsService.GetUserFeed(author.userID, format)
+		}); cuserr != nil {
+			return 0, cuserr
+		}
+	}
+
+	for tagSlug, tag := range tags {
+		if cuserr := s.writeListing(dir, theme.PageTag, tag.articles, links.Tag(tagSlug), &theme.Page{
+			Title:       "#" + tag.name,
+			Description: "Articles tagged " + tag.name,
+			Heading:     tag.name,
+		}); cuserr != nil {
+			return 0, cuserr
+		}
+	}
+
+	if cuserr := s.writeSearch(dir, articles); cuserr != nil {
+		return 0, cuserr
+	}
+	if cuserr := s.writeFeeds(dir, s.feedsService.GetArticlesFeed); cuserr != nil {
+		return 0, cuserr
+	}
+	if cuserr := s.writeSitemap(dir); cuserr != nil {
+		return 0, cuserr
+	}
+
+	// Static hosts serve 404.html for addresses without a file
+	notFound := &theme.Page{
+		Title:   "Page not found",
+		Status:  404,
+		Message: "The page you are looking for does not exist.",
+	}
+	if cuserr := s.writePage(dir, links.BaseURL+"/404.html", theme.PageError, notFound); cuserr != nil {
+		return 0, cuserr
+	}
+
+	return len(articles), nil
+}
+
+// CopyAssets copies the public assets, such as locally stored uploads, into
+// dir below route so the addresses in article content keep working. The theme
+// directory is left out, the export holds the rendered theme already.
+func (s *StaticExportService) CopyAssets(dir string, assetsDir string, route string) *customerror.CustomError {
+	if _, err := os.Stat(assetsDir); errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+
+	target := filepath.Join(dir, filepath.FromSlash(strings.Trim(route, "/")))
+	err := filepath.WalkDir(assetsDir, func(name string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(assetsDir, name)
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() {
+			if rel == "theme" {
+				return filepath.SkipDir
+			}
+			return os.MkdirAll(filepath.Join(target, rel), 0o755)
+		}
+		if !entry.Type().IsRegular() {
+			return nil
+		}
+
+		data, err := os.ReadFile(name)
+		if err != nil {
+			return err
+		}
+		return os.WriteFile(filepath.Join(target, rel), data, 0o644)
+	})
+	if err != nil {
+		return exportError(err)
+	}
+	return nil
+}
+
+// allArticles reads every visible article, newest first.
+func (s *StaticExportService) allArticles() ([]*models.ArticleResponse, *customerror.CustomError) {
+	var articles []*models.ArticleResponse
+	for offset := 0; ; offset += staticExportBatch {
+		batch, cuserr := s.articlesService.GetLatestArticles(staticExportBatch, offset, nil)
+		if cuserr != nil {
+			return nil, cuserr
+		}
+		articles = append(articles, batch.Articles...)
+		if len(batch.Articles) < staticExportBatch {
+			return articles, nil
+		}
+	}
+}
+
+// writeListing writes articles in pages, the first at first and page n at first/page/n/.
+// data is copied for every page.
+func (s *StaticExportService) writeListing(dir string, name string, articles []*models.ArticleResponse, first string, data *theme.Page) *customerror.CustomError {
+	pageURL := func(n int) string {
+		if n == 1 {
+			return first
+		}
+		return strings.TrimSuffix(first, "/") + "/page/" + strconv.Itoa(n) + "/"
+	}
+
+	pages := (len(articles) + staticExportPageSize - 1) / staticExportPageSize
+	if pages == 0 {
+		pages = 1
+	}
+	for n := 1; n <= pages; n++ {
+		page := *data
+		page.URL = pageURL(n)
+		page.Articles = articles[(n-1)*staticExportPageSize : min(n*staticExportPageSize, len(articles))]
+		if n > 1 {
+			page.PrevURL = pageURL(n - 1)
+			if page.Title == "" {
+				page.Title = "Page " + strconv.Itoa(n)
+			}
+		}
+		if n < pages {
+			page.NextURL = pageURL(n + 1)
+		}
+
+		if cuserr := s.writePage(dir, page.URL, name, &page); cuserr != nil {
+			return cuserr
+		}
+	}
+	return nil
+}
+
+// writeSearch writes the search page and the index it searches in the browser.
+func (s *StaticExportService) writeSearch(dir string, articles []*models.ArticleResponse) *customerror.CustomError {
+	links := s.site.Links
+	index := make([]SearchIndexEntry, 0, len(articles))
+	for _, article := range articles {
+		index = append(index, SearchIndexEntry{
+			Title:   article.Title,
+			URL:     links.Article(article.Slug),
+			Author:  article.Author,
+			Tags:    article.Tags,
+			Excerpt: article.Excerpt,
+			Date:    article.CreatedAt,
+		})
+	}
+
+	body, err := json.Marshal(index)
+	if err != nil {
+		return exportError(err)
+	}
+	indexURL := links.BaseURL + "/search-index.json"
+	if cuserr := writeExportFile(dir, links, indexURL, body); cuserr != nil {
+		return cuserr
+	}
+
+	site := *s.site
+	site.SearchIndex = indexURL
+	page := &theme.Page{
+		Site:        &site,
+		Title:       "Search",
+		Description: s.site.Description,
+		URL:         links.BaseURL + "/search/",
+	}
+	return s.writePage(dir, page.URL, theme.PageSearch, page)
+}
+
+// writeFeeds writes the feed returned by get in every format.
+func (s *StaticExportService) writeFeeds(dir string, get func(format string) (*feed.Feed, *customerror.CustomError)) *customerror.CustomError {
+	for _, format := range []string{feed.FormatRSS, feed.FormatAtom, feed.FormatJSON} {
+		f, cuserr := get(format)
+		if cuserr != nil {
+			return cuserr
+		}
+		body, err := f.Render(format)
+		if err != nil {
+			return exportError(err)
+		}
+		if cuserr := writeExportFile(dir, s.site.Links, f.FeedURL, body); cuserr != nil {
+			return cuserr
+		}
+	}
+	return nil
+}
+
+// writeSitemap writes sitemap.xml, the pages it is split into and robots.txt.
+func (s *StaticExportService) writeSitemap(dir string) *customerror.CustomError {
+	links := s.site.Links
+	body, cuserr := s.sitemapService.GetSitemap()
+	if cuserr != nil {
+		return cuserr
+	}
+	if cuserr := writeExportFile(dir, links, links.BaseURL+"/sitemap.xml", body); cuserr != nil {
+		return cuserr
+	}
+
+	pages, cuserr := s.sitemapService.GetSitemapPages()
+	if cuserr != nil {
+		return cuserr
+	}
+	for i, body := range pages {
+		if cuserr := writeExportFile(dir, links, s.sitemapService.SitemapPageURL(i+1), body); cuserr != nil {
+			return cuserr
+		}
+	}
+
+	return writeExportFile(dir, links, links.BaseURL+"/robots.txt", s.sitemapService.GetRobots())
+}
+
+func (s *StaticExportService) writePage(dir string, address string, name string, data *theme.Page) *customerror.CustomError {
+	if data.Site == nil {
+		data.Site = s.site
+	}
+	if data.Type == "" {
+		data.Type = "website"
+	}
+	if data.FeedURL == "" {
+		data.FeedURL = s.site.Links.ArticlesFeed(feed.FormatRSS)
+	}
+
+	var buf bytes.Buffer
+	if err := s.theme.Render(&buf, name, data); err != nil {
+		return exportError(err)
+	}
+	return writeExportFile(dir, s.site.Links, address, buf.Bytes())
+}
+
+// writeExportFile writes body to the file that address, below links.BaseURL,
+// is served from: the index.html of the directory when the address has no extension.
+func writeExportFile(dir string, links *utils.SiteLinks, address string, body []byte) *customerror.CustomError {
+	name, err := url.PathUnescape(strings.TrimPrefix(address, links.BaseURL))
+	if err != nil {
+		return exportError(err)
+	}
+	if strings.HasSuffix(name, "/") || path.Ext(name) == "" {
+		name = path.Join(name, "index.html")
+	}
+	// Slugs and usernames come from the database, keep them inside dir
+	name = path.Clean("/" + name)
+	if strings.ContainsRune(name, '\\') {
+		return exportError(errors.New("unsafe path " + name))
+	}
+
+	target := filepath.Join(dir, filepath.FromSlash(name))
+	if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+		return exportError(err)
+	}
+	if err := os.WriteFile(target, body, 0o644); err != nil {
+		return exportError(err)
+	}
+	return nil
+}
+
+func exportError(err error) *customerror.CustomError {
+	return customerror.NewCustomError(err, "failed to write static site", 500)
+}
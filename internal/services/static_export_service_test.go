@@ -0,0 +1,155 @@
+package services
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yantology/go-gin-simple-blog-with-fts/internal/models"
+	"github.com/yantology/go-gin-simple-blog-with-fts/internal/theme"
+	"github.com/yantology/go-gin-simple-blog-with-fts/internal/utils"
+)
+
+func TestWriteExportFile(t *testing.T) {
+	links := utils.NewSiteLinks("https://blog.example.com", true)
+
+	tests := []struct {
+		name     string
+		address  string
+		expected string
+		wantErr  bool
+	}{
+		{"Directory address", "https://blog.example.com/articles/hello/", "articles/hello/index.html", false},
+		{"Address without extension", "https://blog.example.com/articles/hello", "articles/hello/index.html", false},
+		{"File", "https://blog.example.com/feed.xml", "feed.xml", false},
+		{"Escaped name", "https://blog.example.com/tags/go%20lang", "tags/go lang/index.html", false},
+		{"Parent directories stay inside", "https://blog.example.com/../../escape.txt", "escape.txt", false},
+		{"Escaped parent directories stay inside", "https://blog.example.com/articles/%2e%2e/%2e%2e/%2e%2e/escape.txt", "escape.txt", false},
+		{"Escaped slash stays inside", "https://blog.example.com/articles/..%2f..%2f..%2fescape.txt", "escape.txt", false},
+		{"Backslash", "https://blog.example.com/articles/..%5c..%5cescape.txt", "", true},
+		{"Malformed escape", "https://blog.example.com/articles/%zz", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parent := t.TempDir()
+			dir := filepath.Join(parent, "site")
+
+			cuserr := writeExportFile(dir, links, tt.address, []byte("body"))
+			if tt.wantErr {
+				assert.NotNil(t, cuserr)
+			} else if assert.Nil(t, cuserr) {
+				body, err := os.ReadFile(filepath.Join(dir, filepath.FromSlash(tt.expected)))
+				assert.NoError(t, err)
+				assert.Equal(t, "body", string(body))
+			}
+
+			// Nothing is written next to the output directory
+			entries, err := os.ReadDir(parent)
+			require.NoError(t, err)
+			for _, entry := range entries {
+				assert.Equal(t, "site", entry.Name())
+			}
+		})
+	}
+}
+
+func TestWriteListing(t *testing.T) {
+	links := utils.NewSiteLinks("https://blog.example.com", true)
+	siteTheme, err := theme.Load("", links)
+	require.NoError(t, err)
+	site := &theme.Site{Title: "Simple Blog", Links: links}
+	service := NewStaticExportService(nil, nil, nil, siteTheme, site)
+
+	articleList := func(n int) []*models.ArticleResponse {
+		articles := []*models.ArticleResponse{}
+		for i := 1; i <= n; i++ {
+			articles = append(articles, &models.ArticleResponse{ID: i, Title: "Article " + strconv.Itoa(i), Slug: "article-" + strconv.Itoa(i)})
+		}
+		return articles
+	}
+
+	type listingPage struct {
+		file  string
+		first int
+		prev  string
+		next  string
+	}
+	tests := []struct {
+		name     string
+		articles int
+		first    string
+		pages    []listingPage
+	}{
+		{
+			name: "No articles", articles: 0, first: links.Home(),
+			pages: []listingPage{{file: "index.html"}},
+		},
+		{
+			name: "One full page", articles: staticExportPageSize, first: links.Home(),
+			pages: []listingPage{{file: "index.html", first: 1}},
+		},
+		{
+			name: "Three pages", articles: 2*staticExportPageSize + 1, first: links.Home(),
+			pages: []listingPage{
+				{file: "index.html", first: 1, next: "https://blog.example.com/page/2/"},
+				{file: "page/2/index.html", first: 11, prev: "https://blog.example.com/", next: "https://blog.example.com/page/3/"},
+				{file: "page/3/index.html", first: 21, prev: "https://blog.example.com/page/2/"},
+			},
+		},
+		{
+			name: "Pages of a tag", articles: staticExportPageSize + 1, first: links.Tag("go"),
+			pages: []listingPage{
+				{file: "tags/go/index.html", first: 1, next: "https://blog.example.com/tags/go/page/2/"},
+				{file: "tags/go/page/2/index.html", first: 11, prev: "https://blog.example.com/tags/go"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			cuserr := service.writeListing(dir, theme.PageHome, articleList(tt.articles), tt.first, &theme.Page{Title: "Simple Blog"})
+			require.Nil(t, cuserr)
+
+			var written, expected []string
+			err := filepath.WalkDir(dir, func(name string, entry os.DirEntry, err error) error {
+				if err == nil && !entry.IsDir() {
+					rel, _ := filepath.Rel(dir, name)
+					written = append(written, filepath.ToSlash(rel))
+				}
+				return err
+			})
+			require.NoError(t, err)
+			for _, page := range tt.pages {
+				expected = append(expected, page.file)
+			}
+			assert.ElementsMatch(t, expected, written)
+
+			for _, page := range tt.pages {
+				body, err := os.ReadFile(filepath.Join(dir, filepath.FromSlash(page.file)))
+				require.NoError(t, err)
+				html := string(body)
+
+				// Each page starts with its own slice of the articles
+				if page.first > 0 {
+					assert.Contains(t, html, `href="https://blog.example.com/articles/article-`+strconv.Itoa(page.first)+`"`)
+					assert.NotContains(t, html, `href="https://blog.example.com/articles/article-`+strconv.Itoa(page.first-1)+`"`)
+				}
+				if page.prev != "" {
+					assert.Contains(t, html, `rel="prev" href="`+page.prev+`"`)
+				} else {
+					assert.NotContains(t, html, `rel="prev"`)
+				}
+				if page.next != "" {
+					assert.Contains(t, html, `rel="next" href="`+page.next+`"`)
+				} else {
+					assert.NotContains(t, html, `rel="next"`)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,56 @@
+package services
+
+import (
+	"github.com/yantology/go-gin-simple-blog-with-fts/internal/models"
+	"github.com/yantology/go-gin-simple-blog-with-fts/pkg/repositories/articlesrepository"
+	"github.com/yantology/go-gin-simple-blog-with-fts/pkg/utils/customerror"
+)
+
+type TagsService struct {
+	articlesRepo *articlesrepository.ArticlesRepository
+}
+
+func NewTagsService(articlesRepo *articlesrepository.ArticlesRepository) *TagsService {
+	return &TagsService{
+		articlesRepo: articlesRepo,
+	}
+}
+
+func (s *TagsService) GetTags() (*models.TagsResponse, *customerror.CustomError) {
+	tags, cuserr := s.articlesRepo.GetTags()
+	if cuserr != nil {
+		return nil, cuserr
+	}
+
+	var response = &models.TagsResponse{
+		Tags: []*models.TagResponse{},
+	}
+	for _, tag := range tags {
+		response.Tags = append(response.Tags, &models.TagResponse{
+			Name:         tag.Name,
+			Slug:         tag.Slug,
+			ArticleCount: tag.ArticleCount,
+		})
+	}
+	return response, nil
+}
+
+func (s *TagsService) GetArticlesByTag(tagSlug string) (*models.ArticlesResponse, *customerror.CustomError) {
+	tag, cuserr := s.articlesRepo.GetTagBySlug(tagSlug)
+	if cuserr != nil {
+		return nil, cuserr
+	}
+
+	articles, cuserr := s.articlesRepo.GetAllArticles([]string{tag.Slug})
+	if cuserr != nil {
+		return nil, cuserr
+	}
+
+	var response = &models.ArticlesResponse{
+		Articles: []*models.ArticleResponse{},
+	}
+	for _, article := range articles {
+		response.Articles = append(response.Articles, newArticleResponse(article))
+	}
+	return response, nil
+}
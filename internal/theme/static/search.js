@@ -0,0 +1,86 @@
+// Searches the prebuilt index of a static export in the browser.
+// The index is a JSON array of {title, url, author, tags, excerpt, date}.
+(function () {
+  var results = document.getElementById("search-results");
+  if (!results) {
+    return;
+  }
+
+  var query = (new URLSearchParams(window.location.search).get("q") || "").trim();
+  var input = document.querySelector(".search-form input[name=q]");
+  if (input) {
+    input.value = query;
+  }
+
+  var words = query.toLowerCase().split(/\s+/).filter(Boolean);
+  if (words.length === 0) {
+    return;
+  }
+
+  function text(value) {
+    return document.createTextNode(value);
+  }
+
+  function render(entries) {
+    var heading = document.createElement("h2");
+    heading.appendChild(text(entries.length + " results for “" + query + "”"));
+    results.appendChild(heading);
+
+    entries.forEach(function (entry) {
+      var article = document.createElement("article");
+      article.className = "summary";
+
+      var title = document.createElement("h2");
+      var link = document.createElement("a");
+      link.href = entry.url;
+      link.appendChild(text(entry.title));
+      title.appendChild(link);
+      article.appendChild(title);
+
+      var meta = document.createElement("p");
+      meta.className = "meta";
+      meta.appendChild(text([entry.author, entry.date.slice(0, 10)].filter(Boolean).join(" · ")));
+      article.appendChild(meta);
+
+      if (entry.excerpt) {
+        var excerpt = document.createElement("p");
+        excerpt.appendChild(text(entry.excerpt));
+        article.appendChild(excerpt);
+      }
+      results.appendChild(article);
+    });
+  }
+
+  fetch(results.dataset.index)
+    .then(function (response) {
+      return response.json();
+    })
+    .then(function (index) {
+      var scored = [];
+      index.forEach(function (entry) {
+        var title = entry.title.toLowerCase();
+        var body = [entry.author, entry.excerpt, (entry.tags || []).join(" ")].join(" ").toLowerCase();
+        var score = 0;
+        for (var i = 0; i < words.length; i++) {
+          if (title.indexOf(words[i]) >= 0) {
+            score += 2;
+          } else if (body.indexOf(words[i]) >= 0) {
+            score += 1;
+          } else {
+            return;
+          }
+        }
+        scored.push({ entry: entry, score: score });
+      });
+
+      scored.sort(function (a, b) {
+        return b.score - a.score;
+      });
+      render(scored.map(function (s) {
+        return s.entry;
+      }));
+    })
+    .catch(function () {
+      results.appendChild(text("Search is unavailable right now."));
+    });
+})();
@@ -0,0 +1,241 @@
+package theme
+
+import (
+	"bytes"
+	"embed"
+	"errors"
+	"fmt"
+	"html/template"
+	"io"
+	"io/fs"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/yantology/go-gin-simple-blog-with-fts/internal/models"
+	"github.com/yantology/go-gin-simple-blog-with-fts/internal/utils"
+)
+
+// defaults is the built-in theme. A theme directory overrides it file by file:
+// templates/<page>.html replaces a template and static/<name> a static file.
+//
+//go:embed templates/*.html static
+var defaults embed.FS
+
+// Pages that can be rendered, each a templates/<page>.html file defining "content"
+// and laid out by templates/base.html
+const (
+	PageHome    = "home"
+	PageArticle = "article"
+	PageAuthor  = "author"
+	PageTag     = "tag"
+	PageSearch  = "search"
+	PageError   = "error"
+)
+
+var pageNames = []string{PageHome, PageArticle, PageAuthor, PageTag, PageSearch, PageError}
+
+// Site is the part of every page that does not change between pages
+type Site struct {
+	Title       string
+	Description string
+	Links       *utils.SiteLinks
+	// SearchIndex is the address of a prebuilt JSON search index; when set the
+	// search page looks articles up in the browser instead of asking the server
+	SearchIndex string
+}
+
+// Page is the data a page template is executed with. Title, Description, URL,
+// Image and Type also fill the Open Graph and Twitter card meta tags.
+type Page struct {
+	Site        *Site
+	Title       string
+	Description string
+	// URL is the canonical absolute address of the page
+	URL string
+	// Image is an absolute image address, empty for none
+	Image string
+	// Type is the Open Graph type, "website" or "article"
+	Type    string
+	FeedURL string
+	Heading string
+
+	Article  *models.ArticleResponse
+	Articles []*models.ArticleResponse
+	Query    string
+	PrevURL  string
+	NextURL  string
+
+	Status  int
+	Message string
+}
+
+// Theme renders pages with html/template and serves the static files they use
+type Theme struct {
+	pages  map[string]*template.Template
+	static fs.FS
+	// staticDir holds the static files overriding staticDefaults, empty for none
+	staticDir      string
+	staticDefaults fs.FS
+}
+
+// Load parses the page templates, taking every file present in dir over the
+// built-in one. dir may be empty or missing to use the built-in theme.
+func Load(dir string, links *utils.SiteLinks) (*Theme, error) {
+	staticDefaults, err := fs.Sub(defaults, "static")
+	if err != nil {
+		return nil, err
+	}
+	t := &Theme{
+		pages:          map[string]*template.Template{},
+		static:         staticDefaults,
+		staticDefaults: staticDefaults,
+	}
+	if dir != "" {
+		t.staticDir = filepath.Join(dir, "static")
+		t.static = &overlayFS{dir: t.staticDir, fallback: staticDefaults}
+	}
+
+	layout, err := readFile(dir, "templates/base.html")
+	if err != nil {
+		return nil, err
+	}
+	for _, name := range pageNames {
+		source, err := readFile(dir, "templates/"+name+".html")
+		if err != nil {
+			return nil, err
+		}
+
+		tmpl, err := template.New("base.html").Funcs(funcs(links)).Parse(string(layout))
+		if err != nil {
+			return nil, fmt.Errorf("theme base.html: %w", err)
+		}
+		if _, err := tmpl.New(name + ".html").Parse(string(source)); err != nil {
+			return nil, fmt.Errorf("theme %s.html: %w", name, err)
+		}
+		t.pages[name] = tmpl
+	}
+	return t, nil
+}
+
+func readFile(dir string, name string) ([]byte, error) {
+	if dir != "" {
+		data, err := os.ReadFile(filepath.Join(dir, filepath.FromSlash(name)))
+		if err == nil {
+			return data, nil
+		}
+		if !errors.Is(err, fs.ErrNotExist) {
+			return nil, err
+		}
+	}
+	return defaults.ReadFile(name)
+}
+
+func funcs(links *utils.SiteLinks) template.FuncMap {
+	return template.FuncMap{
+		"articleURL": links.Article,
+		"authorURL":  links.Author,
+		"tagURL":     links.TagName,
+		"absURL":     links.Absolute,
+		"themeURL": func(name string) string {
+			return links.BaseURL + "/theme/" + name
+		},
+		// ContentHTML went through the sanitizer when the article was saved
+		"contentHTML": func(html string) template.HTML {
+			return template.HTML(html)
+		},
+		"date": func(t time.Time) string {
+			return t.Format("2 January 2006")
+		},
+		"isoDate": func(t time.Time) string {
+			return t.UTC().Format(time.RFC3339)
+		},
+	}
+}
+
+// Render writes page rendered with data to w. The page is rendered in full
+// before anything is written, so a template error never leaves half a page.
+func (t *Theme) Render(w io.Writer, page string, data *Page) error {
+	tmpl, ok := t.pages[page]
+	if !ok {
+		return fmt.Errorf("unknown page %q", page)
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.ExecuteTemplate(&buf, "base", data); err != nil {
+		return err
+	}
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+// Static returns the static files of the theme, served below /theme/.
+// Directories are not listed.
+func (t *Theme) Static() http.FileSystem {
+	return http.FS(filesOnlyFS{t.static})
+}
+
+// WriteStatic copies the static files of the theme into dir, as they are served below /theme/.
+func (t *Theme) WriteStatic(dir string) error {
+	if err := copyFS(dir, t.staticDefaults); err != nil {
+		return err
+	}
+	if t.staticDir == "" {
+		return nil
+	}
+	if _, err := os.Stat(t.staticDir); errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	return copyFS(dir, os.DirFS(t.staticDir))
+}
+
+// copyFS writes every file of fsys below dir, replacing files already there
+func copyFS(dir string, fsys fs.FS) error {
+	return fs.WalkDir(fsys, ".", func(name string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dir, filepath.FromSlash(name))
+		if entry.IsDir() {
+			return os.MkdirAll(target, 0o755)
+		}
+
+		data, err := fs.ReadFile(fsys, name)
+		if err != nil {
+			return err
+		}
+		return os.WriteFile(target, data, 0o644)
+	})
+}
+
+// overlayFS opens files from dir and falls back to another file system
+type overlayFS struct {
+	dir      string
+	fallback fs.FS
+}
+
+func (o *overlayFS) Open(name string) (fs.File, error) {
+	if f, err := os.DirFS(o.dir).Open(name); err == nil {
+		return f, nil
+	}
+	return o.fallback.Open(name)
+}
+
+// filesOnlyFS hides directories so a file server cannot list them
+type filesOnlyFS struct {
+	fs.FS
+}
+
+func (f filesOnlyFS) Open(name string) (fs.File, error) {
+	file, err := f.FS.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	info, err := file.Stat()
+	if err != nil || info.IsDir() {
+		file.Close()
+		return nil, fs.ErrNotExist
+	}
+	return file, nil
+}
@@ -0,0 +1,104 @@
+package theme
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yantology/go-gin-simple-blog-with-fts/internal/models"
+	"github.com/yantology/go-gin-simple-blog-with-fts/internal/utils"
+)
+
+func testPage(links *utils.SiteLinks) *Page {
+	created := time.Date(2024, 12, 10, 8, 30, 0, 0, time.UTC)
+	article := &models.ArticleResponse{
+		ID:          1,
+		UserID:      7,
+		Title:       "Hello <World>",
+		Slug:        "hello-world",
+		ContentHTML: "<p>Body</p>",
+		Excerpt:     "Body",
+		Tags:        []string{"Go Lang"},
+		Author:      "alice",
+		CreatedAt:   created,
+		UpdatedAt:   created,
+	}
+	return &Page{
+		Site:        &Site{Title: "Simple Blog", Description: "Latest articles", Links: links},
+		Title:       article.Title,
+		Description: article.Excerpt,
+		URL:         links.Article(article.Slug),
+		Image:       "https://blog.example.com/public/cover.png",
+		Type:        "article",
+		Article:     article,
+		Articles:    []*models.ArticleResponse{article},
+	}
+}
+
+func TestRenderPages(t *testing.T) {
+	links := utils.NewSiteLinks("https://blog.example.com", true)
+	theme, err := Load("", links)
+	require.NoError(t, err)
+
+	for _, name := range pageNames {
+		t.Run(name, func(t *testing.T) {
+			var buf bytes.Buffer
+			assert.NoError(t, theme.Render(&buf, name, testPage(links)))
+			assert.Contains(t, buf.String(), `<meta property="og:title" content="Hello &lt;World&gt;">`)
+			assert.Contains(t, buf.String(), `<meta name="twitter:card" content="summary_large_image">`)
+		})
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, theme.Render(&buf, PageArticle, testPage(links)))
+	assert.Contains(t, buf.String(), "<p>Body</p>")
+	assert.Contains(t, buf.String(), `href="https://blog.example.com/tags/go-lang"`)
+	assert.Contains(t, buf.String(), `href="https://blog.example.com/authors/alice"`)
+
+	assert.Error(t, theme.Render(&buf, "missing", testPage(links)))
+}
+
+func TestLoadOverride(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "templates"), 0o755))
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "static"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "templates", "home.html"), []byte(`{{define "content"}}custom home{{end}}`), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "static", "style.css"), []byte("body{}"), 0o644))
+
+	links := utils.NewSiteLinks("https://blog.example.com", true)
+	theme, err := Load(dir, links)
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	require.NoError(t, theme.Render(&buf, PageHome, testPage(links)))
+	assert.Contains(t, buf.String(), "custom home")
+
+	// Pages that are not overridden keep the built-in template
+	buf.Reset()
+	require.NoError(t, theme.Render(&buf, PageArticle, testPage(links)))
+	assert.Contains(t, buf.String(), "<p>Body</p>")
+
+	file, err := theme.Static().Open("/style.css")
+	require.NoError(t, err)
+	body, err := io.ReadAll(file)
+	file.Close()
+	require.NoError(t, err)
+	assert.Equal(t, "body{}", string(body))
+
+	_, err = theme.Static().Open("/")
+	assert.Error(t, err)
+}
+
+func TestLoadInvalidTemplate(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "templates"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "templates", "tag.html"), []byte(`{{define "content"}}`), 0o644))
+
+	_, err := Load(dir, utils.NewSiteLinks("https://blog.example.com", true))
+	assert.Error(t, err)
+}
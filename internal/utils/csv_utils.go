@@ -1,160 +1,116 @@
 package utils
 
 import (
+	"bytes"
 	"encoding/csv"
 	"errors"
 	"io"
-	"log"
-	"mime/multipart"
-	"net/http"
 	"strings"
-	"sync"
 
-	"github.com/PuerkitoBio/goquery"
+	"github.com/yantology/go-gin-simple-blog-with-fts/pkg/models/importsmodels"
 	"github.com/yantology/go-gin-simple-blog-with-fts/pkg/utils/customerror"
 )
 
-const (
-	batchSize   = 1000
-	workerCount = 100
-)
-
-type CSVProcessor struct {
-	wg       sync.WaitGroup
-	jobsChan chan map[string]string
-}
-
-func NewCSVProcessor() *CSVProcessor {
-	return &CSVProcessor{
-		jobsChan: make(chan map[string]string, batchSize),
-	}
+// CSVFormat reads CSV files with a title column and a url or content column.
+// Rows with content are imported as they are, the others scraped from their
+// URL. Optional format, tags (comma separated), date and status columns
+// describe the article further.
+var CSVFormat = &ImportFormat{
+	Name:       importsmodels.SourceCSV,
+	Label:      "CSV",
+	Extensions: []string{".csv"},
+	Open: func(data []byte) (ImportReader, *customerror.CustomError) {
+		return NewCSVImportReader(bytes.NewReader(data))
+	},
 }
 
-func ProssesCSV(file *multipart.FileHeader, enterFunc func(string, string) *customerror.CustomError) *customerror.CustomError {
-	processor := NewCSVProcessor()
-	return processor.ProcessCSVFile(file, enterFunc)
+// csvImportReader reads the rows of a CSV file as import records
+type csvImportReader struct {
+	reader *csv.Reader
+	// columns maps the known column names of the header to their index
+	columns map[string]int
 }
 
-func (p *CSVProcessor) ProcessCSVFile(file *multipart.FileHeader, enterFunc func(string, string) *customerror.CustomError) *customerror.CustomError {
-	urlIndex := -1
-	titleIndex := -1
+// csvColumnNames are the columns read from a CSV file, the others are ignored
+var csvColumnNames = []string{"title", "url", "content", "format", "tags", "date", "status"}
 
-	f, err := file.Open()
-	if err != nil {
-		return customerror.NewCustomError(err, err.Error(), 400)
-	}
-	defer f.Close()
-
-	reader := csv.NewReader(f)
+// NewCSVImportReader reads the header of a CSV file and returns a reader of
+// its rows. The header must name a title column and a url or content column.
+func NewCSVImportReader(r io.Reader) (ImportReader, *customerror.CustomError) {
+	reader := csv.NewReader(r)
+	// Short rows are reported like rows with empty columns instead of ending the file
+	reader.FieldsPerRecord = -1
 
-	//read header and find index of url and title
+	//read header and find index of the known columns
 	header, err := reader.Read()
 	if err != nil {
-		return customerror.NewCustomError(err, err.Error(), 400)
+		return nil, customerror.NewCustomError(err, err.Error(), 400)
 	}
+	columns := map[string]int{}
 	for i, v := range header {
-		if v == "title" {
-			titleIndex = i
-		} else if v == "url" {
-			urlIndex = i
-		}
-	}
-	if titleIndex == -1 || urlIndex == -1 {
-		return customerror.NewCustomError(errors.New("title or url not found"), "title or url not found", 400)
-
-	}
-	// Start workers
-	for i := 0; i < workerCount; i++ {
-		go p.worker(i, enterFunc)
-	}
-
-	// Read and process CSV
-	for {
-		record, err := reader.Read()
-		if err == io.EOF {
-			break
-		}
-		if err != nil {
-			return customerror.NewCustomError(err, err.Error(), 400)
-		}
-
-		data := map[string]string{"title": "", "url": ""}
-		for i, v := range record {
-			if i == titleIndex {
-				data["title"] = v
-			} else if i == urlIndex {
-				data["url"] = v
+		name := strings.ToLower(strings.TrimSpace(v))
+		for _, known := range csvColumnNames {
+			if _, seen := columns[known]; name == known && !seen {
+				columns[known] = i
 			}
 		}
-
-		//check if title or url is empty
-		if data["title"] == "" || data["url"] == "" {
-			log.Printf("Title or url is empty")
-			continue
-		}
-
-		p.wg.Add(1)
-		p.jobsChan <- data
 	}
 
-	close(p.jobsChan)
-	p.wg.Wait()
-
-	return nil
-}
-
-func (p *CSVProcessor) worker(id int, enterFunc func(string, string) *customerror.CustomError) *customerror.CustomError {
-	counter := 0
-	for data := range p.jobsChan {
-		//scraping
-		title := data["title"]
-		content, err := p.scraping(data["url"])
-		if err != nil {
-			log.Println("Error scraping url:", data["url"])
-			continue
-		}
-
-		// Enter data
-		cuserr := enterFunc(title, content)
-		if cuserr != nil {
-			return cuserr
-		}
-		counter++
-		log.Printf("Worker %d processed %d records success with title %s", id, counter, title)
+	_, hasTitle := columns["title"]
+	_, hasURL := columns["url"]
+	_, hasContent := columns["content"]
+	if !hasTitle || (!hasURL && !hasContent) {
+		return nil, customerror.NewCustomError(errors.New("title or url not found"), "title column and url or content column not found", 400)
 	}
-
-	return nil
+	return &csvImportReader{reader: reader, columns: columns}, nil
 }
 
-func (c *CSVProcessor) scraping(url string) (string, error) {
-	// stringsbuilder
-	var content strings.Builder
-
-	// Request the HTML page.
-	res, err := http.Get(url)
-	if err != nil {
-		log.Fatal(err)
+func (r *csvImportReader) Read() (*ImportRecord, error) {
+	row, err := r.reader.Read()
+	if err == io.EOF {
+		return nil, io.EOF
 	}
-	defer res.Body.Close()
-	if res.StatusCode != 200 {
-		log.Printf("status code error: %d %s", res.StatusCode, res.Status)
-		return "", errors.New("status code error")
+	var parseErr *csv.ParseError
+	if errors.As(err, &parseErr) {
+		return nil, &RowError{Line: parseErr.StartLine, Err: parseErr.Err}
+	}
+	if err != nil {
+		return nil, err
 	}
 
-	// Load the HTML document
-	doc, err := goquery.NewDocumentFromReader(res.Body)
+	line, _ := r.reader.FieldPos(0)
+	record := &ImportRecord{
+		Line:    line,
+		Title:   r.field(row, "title"),
+		URL:     r.field(row, "url"),
+		Content: r.field(row, "content"),
+		Format:  r.field(row, "format"),
+		Status:  r.field(row, "status"),
+	}
+	if tags := r.field(row, "tags"); tags != "" {
+		record.Tags = splitTags(tags)
+	}
+	record.Date, err = parseImportDate(r.field(row, "date"))
 	if err != nil {
-		log.Fatal(err)
+		return nil, &RowError{Line: line, Title: record.Title, Err: err}
 	}
+	return record, nil
+}
 
-	// Find the content
-	doc.Find("div.detail__body-text").Contents().Each(func(i int, s *goquery.Selection) {
-		if goquery.NodeName(s) == "#text" {
-			content.WriteString(s.Text())
-		} else if goquery.NodeName(s) == "p" {
-			content.WriteString(s.Text())
-		}
-	})
+// field returns the trimmed value of a column of row, empty when the file or row does not have it
+func (r *csvImportReader) field(row []string, column string) string {
+	index, ok := r.columns[column]
+	if !ok || index >= len(row) {
+		return ""
+	}
+	return strings.TrimSpace(row[index])
+}
 
-	return content.String(), nil
+// CSVSafe keeps a value written to a CSV file from being read as a formula by
+// spreadsheet programs, prefixing values that start like one with a quote.
+func CSVSafe(value string) string {
+	if value != "" && strings.ContainsRune("=+-@\t\r", rune(value[0])) {
+		return "'" + value
+	}
+	return value
 }
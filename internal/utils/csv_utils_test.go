@@ -0,0 +1,102 @@
+package utils
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewCSVImportReader(t *testing.T) {
+	tests := []struct {
+		name    string
+		csv     string
+		wantErr bool
+	}{
+		{name: "Title and url", csv: "title,url\n", wantErr: false},
+		{name: "Extra columns", csv: "id,url,title,tags\n", wantErr: false},
+		{name: "Title and content", csv: "title,content\n", wantErr: false},
+		{name: "Any case", csv: " Title ,URL\n", wantErr: false},
+		{name: "Missing url", csv: "title,link\n", wantErr: true},
+		{name: "Missing title", csv: "url,content\n", wantErr: true},
+		{name: "Empty file", csv: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, cuserr := NewCSVImportReader(strings.NewReader(tt.csv))
+			if tt.wantErr {
+				assert.NotNil(t, cuserr)
+				assert.Equal(t, 400, cuserr.HTTPCode)
+			} else {
+				assert.Nil(t, cuserr)
+			}
+		})
+	}
+}
+
+func TestCSVImportReaderRead(t *testing.T) {
+	csv := "title,content,url,format,tags,date,status\n" +
+		"Inline,\"Line one\nline two\",,markdown,\"go, web\",2024-03-01,published\n" +
+		"Scraped,,https://example.com/a,,,,\n" +
+		"Draft,Body,,,,,draft\n" +
+		"Bad date,Body,,,,yesterday,\n"
+
+	reader, cuserr := NewCSVImportReader(strings.NewReader(csv))
+	require.Nil(t, cuserr)
+
+	record, err := reader.Read()
+	require.NoError(t, err)
+	date := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	assert.Equal(t, &ImportRecord{
+		Line:    2,
+		Title:   "Inline",
+		Content: "Line one\nline two",
+		Format:  "markdown",
+		Tags:    []string{"go", "web"},
+		Date:    &date,
+		Status:  "published",
+	}, record)
+
+	record, err = reader.Read()
+	require.NoError(t, err)
+	assert.Equal(t, &ImportRecord{Line: 4, Title: "Scraped", URL: "https://example.com/a"}, record)
+
+	record, err = reader.Read()
+	require.NoError(t, err)
+	assert.Equal(t, "draft", record.Status)
+
+	_, err = reader.Read()
+	var rowErr *RowError
+	require.True(t, errors.As(err, &rowErr))
+	assert.Equal(t, 6, rowErr.Line)
+	assert.Equal(t, "Bad date", rowErr.Title)
+
+	_, err = reader.Read()
+	assert.Equal(t, io.EOF, err)
+}
+
+func TestCSVSafe(t *testing.T) {
+	tests := []struct {
+		value string
+		want  string
+	}{
+		{value: "", want: ""},
+		{value: "Plain title", want: "Plain title"},
+		{value: "=HYPERLINK(\"x\")", want: "'=HYPERLINK(\"x\")"},
+		{value: "+1", want: "'+1"},
+		{value: "-1", want: "'-1"},
+		{value: "@SUM(A1)", want: "'@SUM(A1)"},
+		{value: "https://example.com/a=b", want: "https://example.com/a=b"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			assert.Equal(t, tt.want, CSVSafe(tt.value))
+		})
+	}
+}
@@ -0,0 +1,42 @@
+package utils
+
+import (
+	"encoding/base64"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/yantology/go-gin-simple-blog-with-fts/pkg/models/articlesmodels"
+)
+
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// EncodeArticleCursor turns a position in an article list into an opaque, URL-safe token.
+func EncodeArticleCursor(cursor *articlesmodels.ArticleCursor) string {
+	raw := strconv.FormatInt(cursor.CreatedAt.UnixNano(), 10) + ":" + strconv.Itoa(cursor.ID)
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// DecodeArticleCursor reads a token made by EncodeArticleCursor.
+func DecodeArticleCursor(token string) (*articlesmodels.ArticleCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	nanos, id, ok := strings.Cut(string(raw), ":")
+	if !ok {
+		return nil, ErrInvalidCursor
+	}
+	createdAt, err := strconv.ParseInt(nanos, 10, 64)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	articleID, err := strconv.Atoi(id)
+	if err != nil || articleID <= 0 {
+		return nil, ErrInvalidCursor
+	}
+
+	return &articlesmodels.ArticleCursor{CreatedAt: time.Unix(0, createdAt).UTC(), ID: articleID}, nil
+}
@@ -0,0 +1,46 @@
+package utils
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/yantology/go-gin-simple-blog-with-fts/pkg/models/articlesmodels"
+)
+
+func TestArticleCursorRoundTrip(t *testing.T) {
+	cursor := &articlesmodels.ArticleCursor{
+		CreatedAt: time.Date(2024, 12, 10, 8, 30, 15, 123456000, time.UTC),
+		ID:        42,
+	}
+
+	token := EncodeArticleCursor(cursor)
+	assert.NotContains(t, token, "=")
+
+	decoded, err := DecodeArticleCursor(token)
+	assert.NoError(t, err)
+	assert.True(t, cursor.CreatedAt.Equal(decoded.CreatedAt))
+	assert.Equal(t, cursor.ID, decoded.ID)
+}
+
+func TestDecodeArticleCursor(t *testing.T) {
+	tests := []struct {
+		name  string
+		token string
+	}{
+		{name: "Empty", token: ""},
+		{name: "Not base64", token: "!!!"},
+		{name: "No separator", token: "MTIzNDU"},
+		{name: "Bad timestamp", token: "YWJjOjQy"},
+		{name: "Bad ID", token: "MTIzOmFiYw"},
+		{name: "Zero ID", token: "MTIzOjA"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cursor, err := DecodeArticleCursor(tt.token)
+			assert.ErrorIs(t, err, ErrInvalidCursor)
+			assert.Nil(t, cursor)
+		})
+	}
+}
@@ -0,0 +1,69 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiffLines(t *testing.T) {
+	tests := []struct {
+		name     string
+		a        string
+		b        string
+		expected []DiffLine
+	}{
+		{
+			name:     "Both empty",
+			a:        "",
+			b:        "",
+			expected: nil,
+		},
+		{
+			name: "Identical",
+			a:    "one\ntwo",
+			b:    "one\ntwo\n",
+			expected: []DiffLine{
+				{Op: DiffEqual, Text: "one"},
+				{Op: DiffEqual, Text: "two"},
+			},
+		},
+		{
+			name: "Insert into empty",
+			a:    "",
+			b:    "one\ntwo",
+			expected: []DiffLine{
+				{Op: DiffInsert, Text: "one"},
+				{Op: DiffInsert, Text: "two"},
+			},
+		},
+		{
+			name: "Replace middle line",
+			a:    "one\ntwo\nthree",
+			b:    "one\r\n2\r\nthree",
+			expected: []DiffLine{
+				{Op: DiffEqual, Text: "one"},
+				{Op: DiffDelete, Text: "two"},
+				{Op: DiffInsert, Text: "2"},
+				{Op: DiffEqual, Text: "three"},
+			},
+		},
+		{
+			name: "Delete and append",
+			a:    "a\nb\nc",
+			b:    "a\nc\nd",
+			expected: []DiffLine{
+				{Op: DiffEqual, Text: "a"},
+				{Op: DiffDelete, Text: "b"},
+				{Op: DiffEqual, Text: "c"},
+				{Op: DiffInsert, Text: "d"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, DiffLines(tt.a, tt.b))
+		})
+	}
+}
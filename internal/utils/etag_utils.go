@@ -0,0 +1,92 @@
+package utils
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ArticleETag returns the strong entity tag of an article response: its version,
+// followed by a hash of its reaction counts when it has any. Reactions are not
+// edits, so they change the tag without changing the version If-Match checks.
+func ArticleETag(version int, reactions map[string]int) string {
+	if len(reactions) == 0 {
+		return `"` + strconv.Itoa(version) + `"`
+	}
+
+	kinds := make([]string, 0, len(reactions))
+	for kind := range reactions {
+		kinds = append(kinds, kind)
+	}
+	sort.Strings(kinds)
+	hash := sha256.New()
+	for _, kind := range kinds {
+		fmt.Fprintf(hash, "%s=%d\n", kind, reactions[kind])
+	}
+	return `"` + strconv.Itoa(version) + "-" + hex.EncodeToString(hash.Sum(nil)[:8]) + `"`
+}
+
+// ParseETags splits an If-Match or If-None-Match header into its entity tags.
+func ParseETags(header string) []string {
+	var tags []string
+	for _, tag := range strings.Split(header, ",") {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
+
+// ETagVersions returns the article versions named by the strong tags in tags,
+// ignoring the reaction hash ArticleETag may add after the version.
+// Weak tags never match under strong comparison, so they are skipped.
+// The result is never nil, so an unusable If-Match still fails the precondition.
+func ETagVersions(tags []string) []int {
+	versions := []int{}
+	for _, tag := range tags {
+		if strings.HasPrefix(tag, "W/") || len(tag) < 2 || tag[0] != '"' || tag[len(tag)-1] != '"' {
+			continue
+		}
+		version, _, _ := strings.Cut(tag[1:len(tag)-1], "-")
+		if version, err := strconv.Atoi(version); err == nil {
+			versions = append(versions, version)
+		}
+	}
+	return versions
+}
+
+// IfNoneMatch reports whether an If-None-Match header matches etag.
+// It uses weak comparison as required for GET and HEAD requests.
+func IfNoneMatch(header string, etag string) bool {
+	for _, tag := range ParseETags(header) {
+		if tag == "*" || strings.TrimPrefix(tag, "W/") == strings.TrimPrefix(etag, "W/") {
+			return true
+		}
+	}
+	return false
+}
+
+// ContentETag returns a strong entity tag derived from the bytes of a response body.
+func ContentETag(body []byte) string {
+	sum := sha256.Sum256(body)
+	return `"` + hex.EncodeToString(sum[:16]) + `"`
+}
+
+// NotModifiedSince reports whether an If-Modified-Since header is at or after lastModified.
+// HTTP dates have whole seconds, so lastModified is truncated before comparing.
+// An empty or malformed header never matches.
+func NotModifiedSince(header string, lastModified time.Time) bool {
+	if header == "" {
+		return false
+	}
+	since, err := http.ParseTime(header)
+	if err != nil {
+		return false
+	}
+	return !lastModified.Truncate(time.Second).After(since)
+}
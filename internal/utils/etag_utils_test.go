@@ -0,0 +1,110 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestArticleETag(t *testing.T) {
+	tests := []struct {
+		name      string
+		version   int
+		reactions map[string]int
+		other     map[string]int
+		same      bool
+	}{
+		{"No reactions", 3, nil, map[string]int{}, true},
+		{"Same counts", 3, map[string]int{"like": 2, "wow": 1}, map[string]int{"wow": 1, "like": 2}, true},
+		{"Other count", 3, map[string]int{"like": 2}, map[string]int{"like": 3}, false},
+		{"Other kind", 3, map[string]int{"like": 2}, map[string]int{"love": 2}, false},
+		{"Reactions added", 3, nil, map[string]int{"like": 1}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			etag := ArticleETag(tt.version, tt.reactions)
+			assert.Equal(t, tt.same, etag == ArticleETag(tt.version, tt.other))
+			// If-Match still finds the version behind the tag
+			assert.Equal(t, []int{tt.version}, ETagVersions(ParseETags(etag)))
+		})
+	}
+	assert.Equal(t, `"3"`, ArticleETag(3, nil))
+}
+
+func TestParseETags(t *testing.T) {
+	tests := []struct {
+		name     string
+		header   string
+		expected []string
+	}{
+		{"Missing header", "", nil},
+		{"Only separators", " , ,", nil},
+		{"Any", "*", []string{"*"}},
+		{"Single tag", `"3"`, []string{`"3"`}},
+		{"Weak tag", `W/"3"`, []string{`W/"3"`}},
+		{"List with spaces", ` "1", W/"2" ,"3"`, []string{`"1"`, `W/"2"`, `"3"`}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, ParseETags(tt.header))
+		})
+	}
+}
+
+func TestETagVersions(t *testing.T) {
+	tests := []struct {
+		name     string
+		header   string
+		expected []int
+	}{
+		{"Missing header", "", []int{}},
+		{"Single tag", `"3"`, []int{3}},
+		{"List", `"1", "2"`, []int{1, 2}},
+		{"Weak tag is skipped", `W/"3"`, []int{}},
+		{"Weak tag in a list", `W/"3", "4"`, []int{4}},
+		{"Any is not a version", "*", []int{}},
+		{"Unquoted", "3", []int{}},
+		{"Unclosed quote", `"3`, []int{}},
+		{"Lone quote", `"`, []int{}},
+		{"Not a number", `"abc"`, []int{}},
+		{"Content hash", `"9f86d081884c7d659a2feaa0c55ad015"`, []int{}},
+		{"Version with reaction hash", `"3-9f86d081884c7d65"`, []int{3}},
+		{"Reaction hash without version", `"-9f86d081884c7d65"`, []int{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			versions := ETagVersions(ParseETags(tt.header))
+			// An empty list must stay non-nil so the precondition fails with 412
+			assert.NotNil(t, versions)
+			assert.Equal(t, tt.expected, versions)
+		})
+	}
+}
+
+func TestIfNoneMatch(t *testing.T) {
+	tests := []struct {
+		name     string
+		header   string
+		etag     string
+		expected bool
+	}{
+		{"Missing header", "", `"3"`, false},
+		{"Any", "*", `"3"`, true},
+		{"Same tag", `"3"`, `"3"`, true},
+		{"Other tag", `"2"`, `"3"`, false},
+		{"Weak tag matches a strong one", `W/"3"`, `"3"`, true},
+		{"Strong tag matches a weak one", `"3"`, `W/"3"`, true},
+		{"Match in a list", `"1", "2", "3"`, `"3"`, true},
+		{"No match in a list", `"1", "2"`, `"3"`, false},
+		{"Malformed tag", `3`, `"3"`, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, IfNoneMatch(tt.header, tt.etag))
+		})
+	}
+}
@@ -0,0 +1,298 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"image"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"net/http"
+
+	"golang.org/x/image/draw"
+)
+
+// imageExtensions lists the image types accepted for upload and their file extension
+var imageExtensions = map[string]string{
+	"image/jpeg": ".jpg",
+	"image/png":  ".png",
+	"image/gif":  ".gif",
+}
+
+// maxImagePixels rejects images whose decoded size would exhaust memory,
+// e.g. a tiny PNG declaring 100000x100000 pixels
+const maxImagePixels = 50_000_000
+
+// maxGIFFrames bounds the frames of an animated GIF, each decoded and encoded again
+const maxGIFFrames = 1000
+
+const jpegQuality = 88
+
+var ErrUnsupportedImage = errors.New("file is not a JPEG, PNG or GIF image")
+
+// CleanImage is an uploaded image re-encoded without its metadata
+type CleanImage struct {
+	ContentType string
+	Extension   string
+	Data        []byte
+	Width       int
+	Height      int
+	// Image is the decoded picture, the first frame for animated GIFs
+	Image image.Image
+}
+
+// CleanImageUpload checks that data is a supported image by sniffing its content,
+// not its file name, and re-encodes it. Re-encoding drops EXIF, XMP and comment
+// metadata such as GPS positions; JPEG orientation is applied to the pixels first
+// so photos keep displaying upright.
+func CleanImageUpload(data []byte) (*CleanImage, error) {
+	contentType := http.DetectContentType(data)
+	extension, ok := imageExtensions[contentType]
+	if !ok {
+		return nil, ErrUnsupportedImage
+	}
+
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrUnsupportedImage, err)
+	}
+	if config.Width <= 0 || config.Height <= 0 || config.Width*config.Height > maxImagePixels {
+		return nil, fmt.Errorf("image of %dx%d pixels is too large", config.Width, config.Height)
+	}
+
+	clean := &CleanImage{ContentType: contentType, Extension: extension}
+	var buf bytes.Buffer
+	switch contentType {
+	case "image/jpeg":
+		img, err := jpeg.Decode(bytes.NewReader(data))
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrUnsupportedImage, err)
+		}
+		clean.Image = applyOrientation(img, jpegOrientation(data))
+		err = jpeg.Encode(&buf, clean.Image, &jpeg.Options{Quality: jpegQuality})
+		if err != nil {
+			return nil, err
+		}
+	case "image/png":
+		img, err := png.Decode(bytes.NewReader(data))
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrUnsupportedImage, err)
+		}
+		clean.Image = img
+		if err := png.Encode(&buf, img); err != nil {
+			return nil, err
+		}
+	case "image/gif":
+		// Every frame is decoded, not only the screen checked above
+		frames, pixels, err := gifFrames(data)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrUnsupportedImage, err)
+		}
+		if frames > maxGIFFrames || pixels > maxImagePixels {
+			return nil, fmt.Errorf("animation of %d frames and %d pixels is too large", frames, pixels)
+		}
+		animation, err := gif.DecodeAll(bytes.NewReader(data))
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrUnsupportedImage, err)
+		}
+		clean.Image = animation.Image[0]
+		if err := gif.EncodeAll(&buf, animation); err != nil {
+			return nil, err
+		}
+	}
+
+	bounds := clean.Image.Bounds()
+	clean.Width, clean.Height = bounds.Dx(), bounds.Dy()
+	if contentType == "image/gif" {
+		clean.Width, clean.Height = config.Width, config.Height
+	}
+	clean.Data = buf.Bytes()
+	return clean, nil
+}
+
+// gifFrames walks the blocks of a GIF file without decompressing its frames and
+// returns how many frames it has and their pixels added up, so a small file of
+// many large, highly compressed frames is rejected before it is decoded.
+func gifFrames(data []byte) (int, int, error) {
+	errTruncated := errors.New("gif: truncated file")
+	// Header and logical screen descriptor, then the global color table
+	if len(data) < 13 {
+		return 0, 0, errTruncated
+	}
+	i := 13
+	if data[10]&0x80 != 0 {
+		i += 3 << (data[10]&0x07 + 1)
+	}
+
+	// skipSubBlocks moves i past data sub-blocks up to the empty one ending them
+	skipSubBlocks := func() bool {
+		for i < len(data) {
+			size := int(data[i])
+			i += 1 + size
+			if size == 0 {
+				return i <= len(data)
+			}
+		}
+		return false
+	}
+
+	frames, pixels := 0, 0
+	for i < len(data) {
+		switch data[i] {
+		case 0x21: // extension: introducer, label and sub-blocks
+			i += 2
+			if !skipSubBlocks() {
+				return 0, 0, errTruncated
+			}
+		case 0x2C: // image descriptor, local color table, LZW code size and sub-blocks
+			if i+10 > len(data) {
+				return 0, 0, errTruncated
+			}
+			width := int(binary.LittleEndian.Uint16(data[i+5:]))
+			height := int(binary.LittleEndian.Uint16(data[i+7:]))
+			packed := data[i+9]
+			i += 10
+			if packed&0x80 != 0 {
+				i += 3 << (packed&0x07 + 1)
+			}
+			i++
+			if !skipSubBlocks() {
+				return 0, 0, errTruncated
+			}
+			frames++
+			pixels += width * height
+		case 0x3B: // trailer
+			return frames, pixels, nil
+		default:
+			return 0, 0, fmt.Errorf("gif: unknown block 0x%02x", data[i])
+		}
+	}
+	return 0, 0, errTruncated
+}
+
+// ResizeImage scales img down to width pixels wide, keeping its aspect ratio, and
+// encodes it as contentType. GIFs become PNG thumbnails. It returns the encoded
+// image, its content type and its height.
+func ResizeImage(img image.Image, width int, contentType string) ([]byte, string, int, error) {
+	bounds := img.Bounds()
+	height := max(1, bounds.Dy()*width/bounds.Dx())
+
+	dst := image.NewNRGBA(image.Rect(0, 0, width, height))
+	draw.CatmullRom.Scale(dst, dst.Bounds(), img, bounds, draw.Src, nil)
+
+	var buf bytes.Buffer
+	if contentType == "image/jpeg" {
+		if err := jpeg.Encode(&buf, dst, &jpeg.Options{Quality: jpegQuality}); err != nil {
+			return nil, "", 0, err
+		}
+		return buf.Bytes(), contentType, height, nil
+	}
+	if err := png.Encode(&buf, dst); err != nil {
+		return nil, "", 0, err
+	}
+	return buf.Bytes(), "image/png", height, nil
+}
+
+// jpegOrientation reads the EXIF orientation tag (1-8) of a JPEG file, 1 when absent
+func jpegOrientation(data []byte) int {
+	if len(data) < 4 || data[0] != 0xFF || data[1] != 0xD8 {
+		return 1
+	}
+
+	for i := 2; i+4 <= len(data); {
+		if data[i] != 0xFF {
+			return 1
+		}
+		marker := data[i+1]
+		length := int(binary.BigEndian.Uint16(data[i+2:]))
+		if marker == 0xDA || length < 2 || i+2+length > len(data) {
+			// image data starts, no EXIF segment before it
+			return 1
+		}
+		segment := data[i+4 : i+2+length]
+		if marker == 0xE1 && bytes.HasPrefix(segment, []byte("Exif\x00\x00")) {
+			return exifOrientation(segment[6:])
+		}
+		i += 2 + length
+	}
+	return 1
+}
+
+// exifOrientation reads the orientation tag from the first IFD of a TIFF structure
+func exifOrientation(tiff []byte) int {
+	if len(tiff) < 8 {
+		return 1
+	}
+
+	var order binary.ByteOrder
+	switch string(tiff[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return 1
+	}
+
+	offset := int(order.Uint32(tiff[4:]))
+	if offset+2 > len(tiff) {
+		return 1
+	}
+	count := int(order.Uint16(tiff[offset:]))
+	for n := 0; n < count; n++ {
+		entry := offset + 2 + n*12
+		if entry+12 > len(tiff) {
+			return 1
+		}
+		if order.Uint16(tiff[entry:]) == 0x0112 {
+			orientation := int(order.Uint16(tiff[entry+8:]))
+			if orientation < 1 || orientation > 8 {
+				return 1
+			}
+			return orientation
+		}
+	}
+	return 1
+}
+
+// applyOrientation rotates and flips img so that it displays upright without the EXIF orientation tag
+func applyOrientation(img image.Image, orientation int) image.Image {
+	if orientation <= 1 || orientation > 8 {
+		return img
+	}
+
+	bounds := img.Bounds()
+	w, h := bounds.Dx(), bounds.Dy()
+	dw, dh := w, h
+	if orientation >= 5 {
+		// orientations 5-8 swap width and height
+		dw, dh = h, w
+	}
+
+	dst := image.NewNRGBA(image.Rect(0, 0, dw, dh))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			var dx, dy int
+			switch orientation {
+			case 2: // mirrored horizontally
+				dx, dy = w-1-x, y
+			case 3: // rotated 180
+				dx, dy = w-1-x, h-1-y
+			case 4: // mirrored vertically
+				dx, dy = x, h-1-y
+			case 5: // mirrored along the top-left diagonal
+				dx, dy = y, x
+			case 6: // needs a 90 degree clockwise rotation
+				dx, dy = h-1-y, x
+			case 7: // mirrored along the top-right diagonal
+				dx, dy = h-1-y, w-1-x
+			case 8: // needs a 90 degree counter-clockwise rotation
+				dx, dy = y, w-1-x
+			}
+			dst.Set(dx, dy, img.At(bounds.Min.X+x, bounds.Min.Y+y))
+		}
+	}
+	return dst
+}
@@ -0,0 +1,164 @@
+package utils
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/color/palette"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// jpegWithOrientation encodes a width x height JPEG carrying an EXIF orientation tag
+func jpegWithOrientation(t *testing.T, width, height int, orientation byte) []byte {
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	var buf bytes.Buffer
+	assert.NoError(t, jpeg.Encode(&buf, img, nil))
+
+	tiff := []byte{
+		'M', 'M', 0x00, 0x2A, 0x00, 0x00, 0x00, 0x08, // big endian header, IFD at offset 8
+		0x00, 0x01, // one entry
+		0x01, 0x12, 0x00, 0x03, 0x00, 0x00, 0x00, 0x01, 0x00, orientation, 0x00, 0x00, // orientation SHORT
+		0x00, 0x00, 0x00, 0x00, // no next IFD
+	}
+	segment := append([]byte("Exif\x00\x00"), tiff...)
+	app1 := append([]byte{0xFF, 0xE1, 0x00, byte(len(segment) + 2)}, segment...)
+
+	data := buf.Bytes()
+	return append(append([]byte{0xFF, 0xD8}, app1...), data[2:]...)
+}
+
+// encodeGIF encodes an animation of frames width x height frames
+func encodeGIF(t *testing.T, width, height, frames int) []byte {
+	animation := &gif.GIF{LoopCount: 0}
+	for range frames {
+		animation.Image = append(animation.Image, image.NewPaletted(image.Rect(0, 0, width, height), palette.Plan9))
+		animation.Delay = append(animation.Delay, 10)
+	}
+	var buf bytes.Buffer
+	assert.NoError(t, gif.EncodeAll(&buf, animation))
+	return buf.Bytes()
+}
+
+// gifDeclaring builds a GIF of frames declaring width x height pixels each, with
+// next to no image data; it is only ever read up to its frame descriptors
+func gifDeclaring(width, height, frames int) []byte {
+	data := []byte("GIF89a")
+	data = append(data, byte(width), byte(width>>8), byte(height), byte(height>>8), 0, 0, 0)
+	for range frames {
+		data = append(data, 0x2C, 0, 0, 0, 0, byte(width), byte(width>>8), byte(height), byte(height>>8), 0)
+		data = append(data, 2, 1, 0x44, 0)
+	}
+	return append(data, 0x3B)
+}
+
+func TestGIFFrames(t *testing.T) {
+	tests := []struct {
+		name           string
+		data           []byte
+		expectedFrames int
+		expectedPixels int
+		wantErr        bool
+	}{
+		{name: "Single frame", data: encodeGIF(t, 4, 3, 1), expectedFrames: 1, expectedPixels: 12},
+		{name: "Animation with extensions", data: encodeGIF(t, 4, 3, 5), expectedFrames: 5, expectedPixels: 60},
+		{name: "Declared frames", data: gifDeclaring(10000, 5000, 3), expectedFrames: 3, expectedPixels: 150_000_000},
+		{name: "Truncated", data: gifDeclaring(4, 3, 2)[:30], wantErr: true},
+		{name: "Header only", data: []byte("GIF89a"), wantErr: true},
+		{name: "Unknown block", data: append(gifDeclaring(4, 3, 1)[:13], 0x99), wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			frames, pixels, err := gifFrames(tt.data)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedFrames, frames)
+			assert.Equal(t, tt.expectedPixels, pixels)
+		})
+	}
+}
+
+func TestCleanImageUpload(t *testing.T) {
+	var pngData bytes.Buffer
+	pngImage := image.NewNRGBA(image.Rect(0, 0, 3, 2))
+	pngImage.Set(0, 0, color.NRGBA{R: 255, A: 255})
+	assert.NoError(t, png.Encode(&pngData, pngImage))
+
+	tests := []struct {
+		name           string
+		data           []byte
+		expectedType   string
+		expectedWidth  int
+		expectedHeight int
+		wantErr        bool
+	}{
+		{
+			name:           "PNG",
+			data:           pngData.Bytes(),
+			expectedType:   "image/png",
+			expectedWidth:  3,
+			expectedHeight: 2,
+		},
+		{
+			name:           "JPEG without rotation",
+			data:           jpegWithOrientation(t, 4, 2, 1),
+			expectedType:   "image/jpeg",
+			expectedWidth:  4,
+			expectedHeight: 2,
+		},
+		{
+			name:           "JPEG rotated by orientation",
+			data:           jpegWithOrientation(t, 4, 2, 6),
+			expectedType:   "image/jpeg",
+			expectedWidth:  2,
+			expectedHeight: 4,
+		},
+		{
+			name:           "Animated GIF",
+			data:           encodeGIF(t, 5, 4, 3),
+			expectedType:   "image/gif",
+			expectedWidth:  5,
+			expectedHeight: 4,
+		},
+		{
+			name:    "GIF with too many frames",
+			data:    gifDeclaring(1, 1, maxGIFFrames+1),
+			wantErr: true,
+		},
+		{
+			// Each frame is within the limit, not all of them together
+			name:    "GIF with too many pixels",
+			data:    gifDeclaring(5000, 5000, 3),
+			wantErr: true,
+		},
+		{
+			name:    "Not an image",
+			data:    []byte("<html><script>alert(1)</script></html>"),
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clean, err := CleanImageUpload(tt.data)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedType, clean.ContentType)
+			assert.Equal(t, tt.expectedWidth, clean.Width)
+			assert.Equal(t, tt.expectedHeight, clean.Height)
+			assert.NotContains(t, string(clean.Data), "Exif")
+			assert.Equal(t, 1, jpegOrientation(clean.Data))
+		})
+	}
+}
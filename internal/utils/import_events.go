@@ -0,0 +1,105 @@
+package utils
+
+import (
+	"sync"
+
+	"github.com/yantology/go-gin-simple-blog-with-fts/pkg/models/importsmodels"
+)
+
+// Import event types
+const (
+	// ImportEventStarted is sent when a worker picks a row up
+	ImportEventStarted = "started"
+	// ImportEventSucceeded, ImportEventFailed and ImportEventSkipped are sent
+	// with the result of a row
+	ImportEventSucceeded = "succeeded"
+	ImportEventFailed    = "failed"
+	ImportEventSkipped   = "skipped"
+	// ImportEventCompleted is the last event of an import, sent once its final state is saved
+	ImportEventCompleted = "completed"
+)
+
+// ImportEvent is a step of an import, sent to the clients following it
+type ImportEvent struct {
+	// ID numbers the events of an import from 1, in the order they happened
+	ID   int
+	Type string
+	// Data is sent as JSON
+	Data any
+}
+
+// ImportRowEvent is the data of the row events, with the counts of the import
+// once the row was reported
+type ImportRowEvent struct {
+	Row       int                   `json:"row"`
+	Title     string                `json:"title"`
+	URL       string                `json:"url"`
+	Status    string                `json:"status,omitempty"`
+	Error     *string               `json:"error,omitempty"`
+	ArticleID *int                  `json:"article_id,omitempty"`
+	Counts    *importsmodels.Counts `json:"counts,omitempty"`
+}
+
+// ImportEvents keeps the latest events of an import for the clients following
+// it, so one that reconnects can pick up after the last event it got. It is
+// safe for concurrent use.
+type ImportEvents struct {
+	mu sync.Mutex
+	// events are the latest events, at most size of them
+	events []ImportEvent
+	size   int
+	lastID int
+	closed bool
+	// changed is closed and replaced whenever an event is published or the log closed
+	changed chan struct{}
+}
+
+// NewImportEvents returns a log keeping the latest size events
+func NewImportEvents(size int) *ImportEvents {
+	return &ImportEvents{size: size, changed: make(chan struct{})}
+}
+
+// Publish adds an event of eventType with data. Events published after Close are dropped.
+func (e *ImportEvents) Publish(eventType string, data any) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.closed {
+		return
+	}
+
+	e.lastID++
+	e.events = append(e.events, ImportEvent{ID: e.lastID, Type: eventType, Data: data})
+	if len(e.events) > e.size {
+		// Copied so the dropped events do not pin the array
+		e.events = append([]ImportEvent(nil), e.events[len(e.events)-e.size:]...)
+	}
+	close(e.changed)
+	e.changed = make(chan struct{})
+}
+
+// Close ends the log once the last event of the import is published
+func (e *ImportEvents) Close() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if !e.closed {
+		e.closed = true
+		close(e.changed)
+	}
+}
+
+// Since returns the events kept after the event lastID, a channel closed once
+// there is more, and whether the log is closed so no more will come. Events
+// dropped from the log to make room are skipped.
+func (e *ImportEvents) Since(lastID int) ([]ImportEvent, <-chan struct{}, bool) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	var events []ImportEvent
+	for i, event := range e.events {
+		if event.ID > lastID {
+			events = append([]ImportEvent(nil), e.events[i:]...)
+			break
+		}
+	}
+	return events, e.changed, e.closed
+}
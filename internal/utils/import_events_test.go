@@ -0,0 +1,98 @@
+package utils
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/yantology/go-gin-simple-blog-with-fts/pkg/models/importsmodels"
+	"github.com/yantology/go-gin-simple-blog-with-fts/pkg/utils/customerror"
+)
+
+func TestImportEventsSince(t *testing.T) {
+	events := NewImportEvents(3)
+	pending, changed, closed := events.Since(0)
+	assert.Empty(t, pending)
+	assert.False(t, closed)
+
+	events.Publish(ImportEventStarted, "a")
+	select {
+	case <-changed:
+	default:
+		t.Fatal("publishing did not signal the followers")
+	}
+
+	for _, data := range []string{"b", "c", "d"} {
+		events.Publish(ImportEventSucceeded, data)
+	}
+
+	tests := []struct {
+		name   string
+		lastID int
+		want   []int
+	}{
+		{"From the start, the dropped event is skipped", 0, []int{2, 3, 4}},
+		{"After a kept event", 2, []int{3, 4}},
+		{"After the last event", 4, nil},
+		{"After an unknown event", 10, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pending, _, _ := events.Since(tt.lastID)
+			var ids []int
+			for _, event := range pending {
+				ids = append(ids, event.ID)
+			}
+			assert.Equal(t, tt.want, ids)
+		})
+	}
+
+	_, changed, _ = events.Since(4)
+	events.Close()
+	events.Publish(ImportEventSucceeded, "e")
+	pending, _, closed = events.Since(4)
+	assert.Empty(t, pending)
+	assert.True(t, closed)
+	select {
+	case <-changed:
+	default:
+		t.Fatal("closing did not signal the followers")
+	}
+}
+
+func TestImportProgressEvents(t *testing.T) {
+	reader := &sliceReader{items: []any{
+		&ImportRecord{Line: 2, Title: "First", Content: "Body"},
+		&ImportRecord{Line: 3, Content: "Body"},
+	}}
+	enterFunc := func(record *ImportRecord) (int, string, *customerror.CustomError) {
+		return 7, "", nil
+	}
+
+	events := NewImportEvents(10)
+	cuserr := ProcessImport(context.Background(), reader, nil, nil, enterFunc, NewImportProgress(events))
+	assert.Nil(t, cuserr)
+
+	pending, _, _ := events.Since(0)
+	byType := map[string][]*ImportRowEvent{}
+	for i, event := range pending {
+		assert.Equal(t, i+1, event.ID)
+		byType[event.Type] = append(byType[event.Type], event.Data.(*ImportRowEvent))
+	}
+	// Rows skipped while reading never reach a worker
+	assert.Len(t, byType[ImportEventStarted], 1)
+	if assert.Len(t, byType[ImportEventSucceeded], 1) {
+		succeeded := byType[ImportEventSucceeded][0]
+		assert.Equal(t, 2, succeeded.Row)
+		assert.Equal(t, 7, *succeeded.ArticleID)
+		assert.NotNil(t, succeeded.Counts)
+	}
+	if assert.Len(t, byType[ImportEventSkipped], 1) {
+		assert.Equal(t, "title is empty", *byType[ImportEventSkipped][0].Error)
+	}
+
+	// The counts of the last result are those of the whole import
+	last := pending[len(pending)-1].Data.(*ImportRowEvent)
+	assert.Equal(t, &importsmodels.Counts{Processed: 2, Succeeded: 1, Skipped: 1}, last.Counts)
+}
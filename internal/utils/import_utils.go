@@ -0,0 +1,539 @@
+package utils
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/yantology/go-gin-simple-blog-with-fts/pkg/models/importsmodels"
+	"github.com/yantology/go-gin-simple-blog-with-fts/pkg/utils/customerror"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	batchSize   = 1000
+	workerCount = 100
+)
+
+// ImportRecord is an article read from an imported file
+type ImportRecord struct {
+	// Line is where the record starts: its line in CSV and NDJSON files, its
+	// position among the posts of zip and WordPress files
+	Line  int
+	Title string
+	// URL is the page the article comes from, scraped when Content is empty
+	URL     string
+	Content string
+	// Format is the markup of Content, plain when empty
+	Format string
+	Tags   []string
+	// Date is when the article was first published, the time of import when nil
+	Date *time.Time
+	// Status is the publishing status in the source; only published records are imported
+	Status string
+	// Page is the page Content was scraped from, nil when the file had the content
+	Page *ScrapedPage
+	// TitleFromPage is set when the record had no title and took the one found on Page
+	TitleFromPage bool
+	// RepeatedURL is set when an earlier record of the file has the same URL,
+	// once normalized
+	RepeatedURL bool
+}
+
+// RowError is a record of an imported file that cannot be read. The records
+// after it can still be.
+type RowError struct {
+	Line int
+	// Title names the record in the report when it is known
+	Title string
+	Err   error
+}
+
+func (e *RowError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *RowError) Unwrap() error {
+	return e.Err
+}
+
+// ImportReader reads the records of an imported file in order
+type ImportReader interface {
+	// Read returns the next record, or io.EOF after the last one. A *RowError
+	// is recorded as a failed row and reading goes on; any other error ends
+	// the import.
+	Read() (*ImportRecord, error)
+}
+
+// ImportFormat is a kind of file articles can be imported from
+type ImportFormat struct {
+	// Name is stored as the source of its imports
+	Name string
+	// Label names the format in messages
+	Label string
+	// Extensions are the file name extensions of the format, in lower case
+	Extensions []string
+	// Open checks that data is a file of the format and returns a reader of its records
+	Open func(data []byte) (ImportReader, *customerror.CustomError)
+}
+
+// ImportFormats are the formats articles can be imported from
+var ImportFormats = []*ImportFormat{CSVFormat, NDJSONFormat, MarkdownZipFormat, WXRFormat}
+
+// FindImportFormat returns the format called name, nil if there is none
+func FindImportFormat(name string) *ImportFormat {
+	for _, format := range ImportFormats {
+		if format.Name == name {
+			return format
+		}
+	}
+	return nil
+}
+
+// ImportFormatForFile returns the format of a file by its extension, nil if there is none
+func ImportFormatForFile(filename string) *ImportFormat {
+	for _, format := range ImportFormats {
+		if format.Accepts(filename) {
+			return format
+		}
+	}
+	return nil
+}
+
+// Accepts reports whether filename has one of the extensions of the format
+func (f *ImportFormat) Accepts(filename string) bool {
+	ext := strings.ToLower(filepath.Ext(filename))
+	for _, extension := range f.Extensions {
+		if ext == extension {
+			return true
+		}
+	}
+	return false
+}
+
+// ImportExtensions lists the extensions of every format, for messages
+func ImportExtensions() string {
+	extensions := []string{}
+	for _, format := range ImportFormats {
+		extensions = append(extensions, format.Extensions...)
+	}
+	return strings.Join(extensions, ", ")
+}
+
+// isPublished reports whether a record with status is imported. Drafts,
+// private and deleted posts are not.
+func isPublished(status string) bool {
+	switch strings.ToLower(strings.TrimSpace(status)) {
+	case "", "publish", "published", "public":
+		return true
+	}
+	return false
+}
+
+// importDateLayouts are the date formats accepted in imported files
+var importDateLayouts = []string{
+	time.RFC3339,
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04",
+	"2006-01-02",
+	time.RFC1123Z,
+	time.RFC1123,
+}
+
+// parseImportDate reads a date of an imported file, nil when value is empty.
+// Dates without a zone are taken as UTC.
+func parseImportDate(value string) (*time.Time, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return nil, nil
+	}
+	for _, layout := range importDateLayouts {
+		if date, err := time.Parse(layout, value); err == nil {
+			return &date, nil
+		}
+	}
+	return nil, fmt.Errorf("invalid date %q", value)
+}
+
+// tagList is the tags of an imported article, given either as a list or as
+// one comma separated string
+type tagList []string
+
+// splitTags reads a comma separated list of tags
+func splitTags(value string) []string {
+	tags := []string{}
+	for _, tag := range strings.Split(value, ",") {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
+
+func (t *tagList) UnmarshalJSON(data []byte) error {
+	var value string
+	if err := json.Unmarshal(data, &value); err == nil {
+		*t = splitTags(value)
+		return nil
+	}
+	var tags []string
+	if err := json.Unmarshal(data, &tags); err != nil {
+		return errors.New("tags must be a list or a comma separated string")
+	}
+	*t = tags
+	return nil
+}
+
+func (t *tagList) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode {
+		*t = splitTags(node.Value)
+		return nil
+	}
+	var tags []string
+	if err := node.Decode(&tags); err != nil {
+		return errors.New("tags must be a list or a comma separated string")
+	}
+	*t = tags
+	return nil
+}
+
+// ImportCheckFunc is called with a record with a URL before it is scraped and
+// returns the reason to skip the record, empty to go on, and the ID of the
+// article the record was skipped for, if any
+type ImportCheckFunc func(record *ImportRecord) (int, string, *customerror.CustomError)
+
+// ImportEnterFunc stores a record with its content and returns the ID of its
+// article, or the reason the record was skipped and nothing was stored. An ID
+// of 0 without a reason means the record was staged with ImportProgress.Stage,
+// to be stored later with others and reported then.
+type ImportEnterFunc func(record *ImportRecord) (int, string, *customerror.CustomError)
+
+type ImportProcessor struct {
+	wg       sync.WaitGroup
+	jobsChan chan *ImportRecord
+	progress *ImportProgress
+	scraper  *Scraper
+
+	// limitScrapes makes only scrapesLeft more records be scraped; the records
+	// without content past them reach the enter function unscraped
+	limitScrapes bool
+	mu           sync.Mutex
+	scrapesLeft  int
+}
+
+// ImportProgress collects what became of each record of an imported file as
+// it is processed. It is safe for concurrent use.
+type ImportProgress struct {
+	mu     sync.Mutex
+	counts importsmodels.Counts
+	// pending holds the results not taken by TakeResults yet
+	pending []*importsmodels.RowResult
+	// staged holds the records whose article is not created yet
+	staged map[*ImportRecord]bool
+	// events, when not nil, is sent every row picked up and reported
+	events *ImportEvents
+}
+
+// NewImportProgress returns a progress publishing the rows to events. A zero
+// ImportProgress publishes nothing.
+func NewImportProgress(events *ImportEvents) *ImportProgress {
+	return &ImportProgress{events: events}
+}
+
+// started publishes that a worker picked record up
+func (p *ImportProgress) started(record *ImportRecord) {
+	if p.events == nil {
+		return
+	}
+	p.events.Publish(ImportEventStarted, &ImportRowEvent{Row: record.Line, Title: record.Title, URL: record.URL})
+}
+
+// Counts returns the rows processed so far
+func (p *ImportProgress) Counts() importsmodels.Counts {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.counts
+}
+
+// TakeResults returns the row results recorded since the last call
+func (p *ImportProgress) TakeResults() []*importsmodels.RowResult {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	results := p.pending
+	p.pending = nil
+	return results
+}
+
+// Stage holds back the result of record, whose article is created later
+// together with others. Settle or Discard reports it.
+func (p *ImportProgress) Stage(record *ImportRecord) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.staged == nil {
+		p.staged = map[*ImportRecord]bool{}
+	}
+	p.staged[record] = true
+}
+
+// Settle reports a staged record as succeeded with the article created for it,
+// or as failed with reason when articleID is 0
+func (p *ImportProgress) Settle(record *ImportRecord, articleID int, reason string) {
+	p.mu.Lock()
+	delete(p.staged, record)
+	p.mu.Unlock()
+
+	if articleID == 0 {
+		p.record(record, importsmodels.RowFailed, reason, nil)
+		return
+	}
+	p.record(record, importsmodels.RowSucceeded, "", &articleID)
+}
+
+// Discard reports every record still staged as skipped with reason, no
+// article having been created for it
+func (p *ImportProgress) Discard(reason string) {
+	p.mu.Lock()
+	staged := p.staged
+	p.staged = nil
+	p.mu.Unlock()
+
+	for record := range staged {
+		p.record(record, importsmodels.RowSkipped, reason, nil)
+	}
+}
+
+func (p *ImportProgress) record(record *ImportRecord, status string, reason string, articleID *int) {
+	result := &importsmodels.RowResult{
+		Row:       record.Line,
+		Title:     record.Title,
+		URL:       record.URL,
+		Status:    status,
+		ArticleID: articleID,
+	}
+	if reason != "" {
+		result.Error = &reason
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.counts.Processed++
+	switch status {
+	case importsmodels.RowSucceeded:
+		p.counts.Succeeded++
+	case importsmodels.RowFailed:
+		p.counts.Failed++
+	case importsmodels.RowSkipped:
+		p.counts.Skipped++
+	}
+	p.pending = append(p.pending, result)
+
+	// Published under the lock, so the counts of later events never go down
+	if p.events != nil {
+		counts := p.counts
+		p.events.Publish(status, &ImportRowEvent{
+			Row:       result.Row,
+			Title:     result.Title,
+			URL:       result.URL,
+			Status:    status,
+			Error:     result.Error,
+			ArticleID: articleID,
+			Counts:    &counts,
+		})
+	}
+}
+
+func NewImportProcessor(scraper *Scraper, progress *ImportProgress) *ImportProcessor {
+	return &ImportProcessor{
+		jobsChan: make(chan *ImportRecord, batchSize),
+		progress: progress,
+		scraper:  scraper,
+	}
+}
+
+// ProcessImport passes every published record of reader to enterFunc,
+// recording the result of each in progress. Records without content have the
+// page at their URL scraped with scraper, and take the title found on it when
+// they have none. checkFunc, when not nil, may skip a record with a URL
+// before anything else is done with it. Cancelling ctx stops it after the
+// records in flight.
+func ProcessImport(ctx context.Context, reader ImportReader, scraper *Scraper, checkFunc ImportCheckFunc, enterFunc ImportEnterFunc, progress *ImportProgress) *customerror.CustomError {
+	processor := NewImportProcessor(scraper, progress)
+	return processor.Process(ctx, reader, checkFunc, enterFunc)
+}
+
+// PreviewImport processes reader like ProcessImport, except that only the
+// first sample records without content are scraped. The others reach
+// enterFunc without content, for a preview of the import that does not fetch
+// every page.
+func PreviewImport(ctx context.Context, reader ImportReader, scraper *Scraper, sample int, checkFunc ImportCheckFunc, enterFunc ImportEnterFunc, progress *ImportProgress) *customerror.CustomError {
+	processor := NewImportProcessor(scraper, progress)
+	processor.limitScrapes = true
+	processor.scrapesLeft = sample
+	return processor.Process(ctx, reader, checkFunc, enterFunc)
+}
+
+// takeScrape reports whether one more record may be scraped
+func (p *ImportProcessor) takeScrape() bool {
+	if !p.limitScrapes {
+		return true
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.scrapesLeft <= 0 {
+		return false
+	}
+	p.scrapesLeft--
+	return true
+}
+
+// Process reads the records of reader and hands them to the workers. A record
+// that cannot be read or lacks what an article needs is recorded without
+// stopping the file; only a failing reader ends it early. Records are read in
+// file order, so a record is marked RepeatedURL here rather than by the
+// workers, which take them in any order.
+func (p *ImportProcessor) Process(ctx context.Context, reader ImportReader, checkFunc ImportCheckFunc, enterFunc ImportEnterFunc) *customerror.CustomError {
+	// Start workers
+	for i := 0; i < workerCount; i++ {
+		p.wg.Add(1)
+		go p.worker(ctx, i, checkFunc, enterFunc)
+	}
+	// Workers stop once the channel is closed and drained, whatever is returned
+	defer func() {
+		close(p.jobsChan)
+		p.wg.Wait()
+	}()
+
+	// Normalized URLs of the records handed to the workers
+	seen := map[string]bool{}
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		var rowErr *RowError
+		if errors.As(err, &rowErr) {
+			p.progress.record(&ImportRecord{Line: rowErr.Line, Title: rowErr.Title}, importsmodels.RowFailed, rowErr.Error(), nil)
+			continue
+		}
+		if err != nil {
+			return customerror.NewCustomError(err, err.Error(), 400)
+		}
+
+		//check what the record lacks, a missing title is taken from the page
+		switch {
+		case !isPublished(record.Status):
+			p.progress.record(record, importsmodels.RowSkipped, fmt.Sprintf("status %s is not imported", strings.ToLower(record.Status)), nil)
+			continue
+		case record.Title == "" && record.URL == "" && record.Content == "":
+			p.progress.record(record, importsmodels.RowSkipped, "title and url are empty", nil)
+			continue
+		case record.URL == "" && record.Content == "":
+			p.progress.record(record, importsmodels.RowSkipped, "url is empty", nil)
+			continue
+		case record.Title == "" && record.Content != "":
+			p.progress.record(record, importsmodels.RowSkipped, "title is empty", nil)
+			continue
+		}
+
+		if record.URL != "" {
+			sourceURL := NormalizeURL(record.URL)
+			record.RepeatedURL = seen[sourceURL]
+			seen[sourceURL] = true
+		}
+
+		select {
+		case p.jobsChan <- record:
+		case <-ctx.Done():
+			return nil
+		}
+	}
+
+	return nil
+}
+
+func (p *ImportProcessor) worker(ctx context.Context, id int, checkFunc ImportCheckFunc, enterFunc ImportEnterFunc) {
+	defer p.wg.Done()
+
+	counter := 0
+	for record := range p.jobsChan {
+		// Records still queued when the import is cancelled are left alone
+		if ctx.Err() != nil {
+			continue
+		}
+		p.progress.started(record)
+
+		if checkFunc != nil && record.URL != "" {
+			articleID, reason, cuserr := checkFunc(record)
+			if cuserr != nil {
+				p.progress.record(record, importsmodels.RowFailed, cuserr.Error(), nil)
+				continue
+			}
+			if reason != "" {
+				p.progress.record(record, importsmodels.RowSkipped, reason, optionalID(articleID))
+				continue
+			}
+		}
+
+		//scraping
+		if record.Content == "" && p.takeScrape() {
+			result, err := p.scraper.Scrape(ctx, record.URL)
+			if err != nil {
+				// A record cut short by cancelling is not a failure of the record
+				if ctx.Err() != nil {
+					continue
+				}
+				p.progress.record(record, importsmodels.RowFailed, err.Error(), nil)
+				continue
+			}
+			if result.Content == "" {
+				p.progress.record(record, importsmodels.RowFailed, "no content found", nil)
+				continue
+			}
+			if record.Title == "" {
+				if result.Title == "" {
+					p.progress.record(record, importsmodels.RowFailed, "title is empty and no title found", nil)
+					continue
+				}
+				record.Title = result.Title
+				record.TitleFromPage = true
+			}
+			record.Content = result.Content
+			record.Format = ""
+			record.Page = result
+		}
+
+		// Enter data
+		articleID, reason, cuserr := enterFunc(record)
+		if cuserr != nil {
+			p.progress.record(record, importsmodels.RowFailed, cuserr.Error(), nil)
+			continue
+		}
+		if reason != "" {
+			p.progress.record(record, importsmodels.RowSkipped, reason, optionalID(articleID))
+			continue
+		}
+		if articleID == 0 {
+			// Staged, reported once stored
+			continue
+		}
+		p.progress.record(record, importsmodels.RowSucceeded, "", &articleID)
+		counter++
+		log.Printf("Worker %d processed %d records success with title %s", id, counter, record.Title)
+	}
+}
+
+// optionalID returns nil for an ID of 0, the ID of no article
+func optionalID(id int) *int {
+	if id == 0 {
+		return nil
+	}
+	return &id
+}
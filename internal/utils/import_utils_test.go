@@ -0,0 +1,454 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/yantology/go-gin-simple-blog-with-fts/pkg/models/importsmodels"
+	"github.com/yantology/go-gin-simple-blog-with-fts/pkg/utils/customerror"
+	"github.com/yantology/go-gin-simple-blog-with-fts/pkg/utils/extract"
+	"github.com/yantology/go-gin-simple-blog-with-fts/pkg/utils/fetch"
+)
+
+// testFetcher reaches httptest servers without waiting between requests
+func testFetcher() fetch.Fetcher {
+	return fetch.New(fetch.Options{
+		Timeout:         time.Second,
+		RetryBackoff:    time.Millisecond,
+		HostConcurrency: 10,
+		AllowPrivate:    true,
+	})
+}
+
+func TestProcessImportCSVResults(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/missing":
+			http.NotFound(w, r)
+			return
+		case "/empty":
+			w.Write([]byte(`<div class="detail__body-text"></div>`))
+			return
+		}
+		w.Write([]byte(`<h1 class="headline">Headline of ` + r.URL.Path + `</h1><div class="detail__body-text"><p>Body of ` + r.URL.Path + `</p></div>`))
+	}))
+	defer server.Close()
+
+	rules, err := extract.NewRegistry(extract.Rule{Host: "127.0.0.1", Title: "h1.headline", Content: "div.detail__body-text"})
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	csv := "title,url\n" +
+		"First," + server.URL + "/first\n" +
+		"Second," + server.URL + "/second\n" +
+		"Missing," + server.URL + "/missing\n" +
+		",\n" +
+		"Rejected," + server.URL + "/rejected\n" +
+		"Short\n" +
+		"Bad \"quote\"," + server.URL + "/bad\n" +
+		"," + server.URL + "/untitled\n" +
+		"Empty," + server.URL + "/empty\n" +
+		"Known," + server.URL + "/known\n" +
+		"Unchanged," + server.URL + "/unchanged\n"
+
+	var mu sync.Mutex
+	created := map[string]string{}
+	checkFunc := func(record *ImportRecord) (int, string, *customerror.CustomError) {
+		if strings.HasSuffix(record.URL, "/known") {
+			return 7, "already imported", nil
+		}
+		return 0, "", nil
+	}
+	enterFunc := func(article *ImportRecord) (int, string, *customerror.CustomError) {
+		switch article.Title {
+		case "Rejected":
+			return 0, "", customerror.NewCustomError(errors.New("duplicate"), "duplicate article", 400)
+		case "Unchanged":
+			return 8, "content unchanged", nil
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		created[article.Title] = article.Content
+		return len(created) * 10, "", nil
+	}
+
+	progress := &ImportProgress{}
+	reader, cuserr := NewCSVImportReader(strings.NewReader(csv))
+	if !assert.Nil(t, cuserr) {
+		return
+	}
+	cuserr = ProcessImport(context.Background(), reader, NewScraper(testFetcher(), rules), checkFunc, enterFunc, progress)
+	assert.Nil(t, cuserr)
+	assert.Equal(t, importsmodels.Counts{Processed: 11, Succeeded: 3, Failed: 4, Skipped: 4}, progress.Counts())
+	assert.Equal(t, map[string]string{
+		"First":                 "Body of /first",
+		"Second":                "Body of /second",
+		"Headline of /untitled": "Body of /untitled",
+	}, created)
+
+	results := map[int]*importsmodels.RowResult{}
+	for _, result := range progress.TakeResults() {
+		results[result.Row] = result
+	}
+	assert.Empty(t, progress.TakeResults())
+	assert.Len(t, results, 11)
+
+	tests := []struct {
+		row     int
+		title   string
+		status  string
+		reason  string
+		created bool
+		// articleID is the article a skipped row points to
+		articleID int
+	}{
+		{row: 2, title: "First", status: importsmodels.RowSucceeded, created: true},
+		{row: 3, title: "Second", status: importsmodels.RowSucceeded, created: true},
+		{row: 4, title: "Missing", status: importsmodels.RowFailed, reason: "unexpected status 404 Not Found"},
+		{row: 5, title: "", status: importsmodels.RowSkipped, reason: "title and url are empty"},
+		{row: 6, title: "Rejected", status: importsmodels.RowFailed, reason: "duplicate article"},
+		{row: 7, title: "Short", status: importsmodels.RowSkipped, reason: "url is empty"},
+		{row: 8, title: "", status: importsmodels.RowFailed, reason: `bare " in non-quoted-field`},
+		{row: 9, title: "Headline of /untitled", status: importsmodels.RowSucceeded, created: true},
+		{row: 10, title: "Empty", status: importsmodels.RowFailed, reason: "no content found"},
+		{row: 11, title: "Known", status: importsmodels.RowSkipped, reason: "already imported", articleID: 7},
+		{row: 12, title: "Unchanged", status: importsmodels.RowSkipped, reason: "content unchanged", articleID: 8},
+	}
+	for _, tt := range tests {
+		t.Run(strconv.Itoa(tt.row), func(t *testing.T) {
+			result := results[tt.row]
+			if !assert.NotNil(t, result) {
+				return
+			}
+			assert.Equal(t, tt.title, result.Title)
+			assert.Equal(t, tt.status, result.Status)
+			if tt.reason == "" {
+				assert.Nil(t, result.Error)
+			} else if assert.NotNil(t, result.Error) {
+				assert.Equal(t, tt.reason, *result.Error)
+			}
+			switch {
+			case tt.created:
+				assert.NotNil(t, result.ArticleID)
+			case tt.articleID != 0:
+				if assert.NotNil(t, result.ArticleID) {
+					assert.Equal(t, tt.articleID, *result.ArticleID)
+				}
+			default:
+				assert.Nil(t, result.ArticleID)
+			}
+		})
+	}
+}
+
+func TestProcessImportCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	called := false
+	enterFunc := func(article *ImportRecord) (int, string, *customerror.CustomError) {
+		called = true
+		return 1, "", nil
+	}
+
+	progress := &ImportProgress{}
+	rules, _ := extract.NewRegistry()
+	reader, _ := NewCSVImportReader(strings.NewReader("title,url\nFirst,http://127.0.0.1:1/first\n"))
+	cuserr := ProcessImport(ctx, reader, NewScraper(testFetcher(), rules), nil, enterFunc, progress)
+	assert.Nil(t, cuserr)
+	assert.False(t, called)
+	assert.Equal(t, importsmodels.Counts{}, progress.Counts())
+}
+
+// sliceReader reads records and errors given up front
+type sliceReader struct {
+	items []any
+}
+
+func (r *sliceReader) Read() (*ImportRecord, error) {
+	if len(r.items) == 0 {
+		return nil, io.EOF
+	}
+	item := r.items[0]
+	r.items = r.items[1:]
+	if err, ok := item.(error); ok {
+		return nil, err
+	}
+	return item.(*ImportRecord), nil
+}
+
+func TestProcessImportInlineContent(t *testing.T) {
+	reader := &sliceReader{items: []any{
+		&ImportRecord{Line: 1, Title: "Inline", Content: "Body", Format: "markdown"},
+		&ImportRecord{Line: 2, Title: "Linked", URL: "http://127.0.0.1:1/linked", Content: "Body"},
+		&ImportRecord{Line: 3, Title: "Known", URL: "http://127.0.0.1:1/known", Content: "Body"},
+		&ImportRecord{Line: 4, Title: "Draft", Content: "Body", Status: "Draft"},
+		&ImportRecord{Line: 5, Content: "Body"},
+		&RowError{Line: 6, Title: "broken.md", Err: errors.New("invalid front matter")},
+	}}
+
+	var mu sync.Mutex
+	checked := []string{}
+	checkFunc := func(record *ImportRecord) (int, string, *customerror.CustomError) {
+		mu.Lock()
+		defer mu.Unlock()
+		checked = append(checked, record.URL)
+		if strings.HasSuffix(record.URL, "/known") {
+			return 3, "already imported", nil
+		}
+		return 0, "", nil
+	}
+	entered := map[string]*ImportRecord{}
+	enterFunc := func(record *ImportRecord) (int, string, *customerror.CustomError) {
+		mu.Lock()
+		defer mu.Unlock()
+		entered[record.Title] = record
+		return record.Line * 10, "", nil
+	}
+
+	progress := &ImportProgress{}
+	// No scraper: records with content are never scraped
+	cuserr := ProcessImport(context.Background(), reader, nil, checkFunc, enterFunc, progress)
+	assert.Nil(t, cuserr)
+	assert.Equal(t, importsmodels.Counts{Processed: 6, Succeeded: 2, Failed: 1, Skipped: 3}, progress.Counts())
+	assert.ElementsMatch(t, []string{"http://127.0.0.1:1/linked", "http://127.0.0.1:1/known"}, checked)
+	assert.Len(t, entered, 2)
+	if assert.Contains(t, entered, "Inline") {
+		assert.Equal(t, "markdown", entered["Inline"].Format)
+	}
+
+	reasons := map[int]string{}
+	for _, result := range progress.TakeResults() {
+		if result.Error != nil {
+			reasons[result.Row] = *result.Error
+		}
+	}
+	assert.Equal(t, map[int]string{
+		3: "already imported",
+		4: "status draft is not imported",
+		5: "title is empty",
+		6: "invalid front matter",
+	}, reasons)
+}
+
+func TestProcessImportRepeatedURLs(t *testing.T) {
+	// Many records, so that the workers take them out of file order
+	items := []any{&ImportRecord{Line: 1, Title: "Draft", URL: "https://example.com/a", Content: "Body", Status: "draft"}}
+	for line := 2; line <= 300; line++ {
+		record := &ImportRecord{Line: line, Title: "Record", Content: "Body"}
+		switch line % 3 {
+		case 0:
+			record.URL = "https://example.com/a"
+		case 1:
+			record.URL = "https://EXAMPLE.com/a#comments"
+		default:
+			record.URL = "https://example.com/b"
+		}
+		items = append(items, record)
+	}
+
+	var mu sync.Mutex
+	repeated := map[int]bool{}
+	checkFunc := func(record *ImportRecord) (int, string, *customerror.CustomError) {
+		mu.Lock()
+		defer mu.Unlock()
+		repeated[record.Line] = record.RepeatedURL
+		return 0, "", nil
+	}
+	enterFunc := func(record *ImportRecord) (int, string, *customerror.CustomError) {
+		return record.Line, "", nil
+	}
+
+	cuserr := ProcessImport(context.Background(), &sliceReader{items: items}, nil, checkFunc, enterFunc, &ImportProgress{})
+	assert.Nil(t, cuserr)
+	assert.Len(t, repeated, 299)
+	for line, isRepeated := range repeated {
+		// The draft is skipped before it can claim its URL
+		assert.Equal(t, line > 3, isRepeated, "line %d", line)
+	}
+}
+
+func TestProcessImportReaderError(t *testing.T) {
+	reader := &sliceReader{items: []any{
+		&ImportRecord{Line: 1, Title: "First", Content: "Body"},
+		errors.New("unexpected end of file"),
+		&ImportRecord{Line: 2, Title: "Never read", Content: "Body"},
+	}}
+	enterFunc := func(record *ImportRecord) (int, string, *customerror.CustomError) {
+		return 1, "", nil
+	}
+
+	progress := &ImportProgress{}
+	cuserr := ProcessImport(context.Background(), reader, nil, nil, enterFunc, progress)
+	if assert.NotNil(t, cuserr) {
+		assert.Equal(t, "unexpected end of file", cuserr.Error())
+	}
+	assert.Equal(t, importsmodels.Counts{Processed: 1, Succeeded: 1}, progress.Counts())
+}
+
+func TestPreviewImportSample(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/robots.txt" {
+			http.NotFound(w, r)
+			return
+		}
+		requests.Add(1)
+		w.Write([]byte(`<h1 class="headline">Scraped</h1><div class="detail__body-text"><p>Body</p></div>`))
+	}))
+	defer server.Close()
+
+	rules, err := extract.NewRegistry(extract.Rule{Host: "127.0.0.1", Title: "h1.headline", Content: "div.detail__body-text"})
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	reader := &sliceReader{items: []any{
+		&ImportRecord{Line: 1, Title: "Inline", Content: "Body"},
+		&ImportRecord{Line: 2, URL: server.URL + "/a"},
+		&ImportRecord{Line: 3, URL: server.URL + "/b"},
+		&ImportRecord{Line: 4, URL: server.URL + "/c"},
+	}}
+	var mu sync.Mutex
+	entered := []*ImportRecord{}
+	enterFunc := func(record *ImportRecord) (int, string, *customerror.CustomError) {
+		mu.Lock()
+		defer mu.Unlock()
+		entered = append(entered, record)
+		return 0, "", nil
+	}
+
+	progress := &ImportProgress{}
+	cuserr := PreviewImport(context.Background(), reader, NewScraper(testFetcher(), rules), 2, nil, enterFunc, progress)
+	assert.Nil(t, cuserr)
+	assert.Len(t, entered, 4)
+	assert.Equal(t, int32(2), requests.Load())
+
+	scraped := 0
+	for _, record := range entered {
+		if record.Page != nil {
+			scraped++
+			assert.Equal(t, "Scraped", record.Title)
+			assert.Equal(t, "Body", record.Content)
+		} else if record.URL != "" {
+			assert.Empty(t, record.Content)
+		}
+	}
+	assert.Equal(t, 2, scraped)
+}
+
+func TestParseImportDate(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    time.Time
+		wantNil bool
+		wantErr bool
+	}{
+		{value: "", wantNil: true},
+		{value: "2024-03-01", want: time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)},
+		{value: "2024-03-01 10:30:00", want: time.Date(2024, 3, 1, 10, 30, 0, 0, time.UTC)},
+		{value: "2024-03-01T10:30:00+07:00", want: time.Date(2024, 3, 1, 3, 30, 0, 0, time.UTC)},
+		{value: "Fri, 01 Mar 2024 10:30:00 +0000", want: time.Date(2024, 3, 1, 10, 30, 0, 0, time.UTC)},
+		{value: "yesterday", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			date, err := parseImportDate(tt.value)
+			switch {
+			case tt.wantErr:
+				assert.Error(t, err)
+			case tt.wantNil:
+				assert.NoError(t, err)
+				assert.Nil(t, date)
+			default:
+				assert.NoError(t, err)
+				if assert.NotNil(t, date) {
+					assert.True(t, tt.want.Equal(*date), date)
+				}
+			}
+		})
+	}
+}
+
+func TestImportFormatForFile(t *testing.T) {
+	tests := []struct {
+		filename string
+		want     string
+	}{
+		{filename: "articles.csv", want: "csv"},
+		{filename: "ARTICLES.CSV", want: "csv"},
+		{filename: "articles.ndjson", want: "ndjson"},
+		{filename: "articles.jsonl", want: "ndjson"},
+		{filename: "posts.zip", want: "markdown"},
+		{filename: "blog.WordPress.2024-03-01.xml", want: "wxr"},
+		{filename: "articles.json", want: ""},
+		{filename: "articles", want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.filename, func(t *testing.T) {
+			format := ImportFormatForFile(tt.filename)
+			if tt.want == "" {
+				assert.Nil(t, format)
+			} else if assert.NotNil(t, format) {
+				assert.Equal(t, tt.want, format.Name)
+				assert.Equal(t, format, FindImportFormat(tt.want))
+			}
+		})
+	}
+}
+
+func TestProcessImportStaged(t *testing.T) {
+	reader := &sliceReader{items: []any{
+		&ImportRecord{Line: 1, Title: "First", Content: "Body"},
+		&ImportRecord{Line: 2, Title: "Second", Content: "Body"},
+		&ImportRecord{Line: 3, Title: "Third", Content: "Body"},
+		&ImportRecord{Line: 4, Title: "Fourth", Content: "Body"},
+	}}
+
+	progress := &ImportProgress{}
+	var mu sync.Mutex
+	staged := map[string]*ImportRecord{}
+	enterFunc := func(record *ImportRecord) (int, string, *customerror.CustomError) {
+		progress.Stage(record)
+		mu.Lock()
+		defer mu.Unlock()
+		staged[record.Title] = record
+		return 0, "", nil
+	}
+
+	cuserr := ProcessImport(context.Background(), reader, nil, nil, enterFunc, progress)
+	assert.Nil(t, cuserr)
+	// Staged rows are reported once settled
+	assert.Equal(t, importsmodels.Counts{}, progress.Counts())
+	assert.Empty(t, progress.TakeResults())
+
+	progress.Settle(staged["First"], 11, "")
+	progress.Settle(staged["Second"], 0, "Record already exists")
+	progress.Discard("nothing was imported")
+	progress.Discard("reported twice")
+	assert.Equal(t, importsmodels.Counts{Processed: 4, Succeeded: 1, Failed: 1, Skipped: 2}, progress.Counts())
+
+	results := map[int]*importsmodels.RowResult{}
+	for _, result := range progress.TakeResults() {
+		results[result.Row] = result
+	}
+	if assert.Len(t, results, 4) {
+		assert.Equal(t, 11, *results[1].ArticleID)
+		assert.Equal(t, "Record already exists", *results[2].Error)
+		assert.Equal(t, importsmodels.RowSkipped, results[3].Status)
+		assert.Equal(t, "nothing was imported", *results[4].Error)
+	}
+}
@@ -0,0 +1,98 @@
+package utils
+
+import (
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/yantology/go-gin-simple-blog-with-fts/pkg/utils/slug"
+)
+
+// tagSlugLength matches the tags.slug column
+const tagSlugLength = 60
+
+// SiteLinks builds the absolute addresses of public pages. With HTML set the
+// pages of the HTML frontend are linked, otherwise the JSON API routes.
+type SiteLinks struct {
+	BaseURL string
+	HTML    bool
+}
+
+func NewSiteLinks(baseURL string, html bool) *SiteLinks {
+	return &SiteLinks{BaseURL: strings.TrimRight(baseURL, "/"), HTML: html}
+}
+
+// Home returns the address of the front page.
+func (l *SiteLinks) Home() string {
+	return l.BaseURL + "/"
+}
+
+// Article returns the address an article is read at.
+func (l *SiteLinks) Article(articleSlug string) string {
+	if l.HTML {
+		return l.BaseURL + "/articles/" + url.PathEscape(articleSlug)
+	}
+	return l.BaseURL + "/api/v1/articles/by-slug/" + url.PathEscape(articleSlug)
+}
+
+// ArticleID returns an address of an article that never changes, for feed item IDs.
+func (l *SiteLinks) ArticleID(articleID int) string {
+	return l.BaseURL + "/api/v1/articles/" + strconv.Itoa(articleID)
+}
+
+// Author returns the address listing the articles of a user.
+func (l *SiteLinks) Author(userID int, username string) string {
+	if l.HTML {
+		return l.BaseURL + "/authors/" + url.PathEscape(username)
+	}
+	return l.BaseURL + "/api/v1/users/" + strconv.Itoa(userID) + "/articles"
+}
+
+// Tag returns the address listing the articles carrying the tag with tagSlug.
+func (l *SiteLinks) Tag(tagSlug string) string {
+	if l.HTML {
+		return l.BaseURL + "/tags/" + url.PathEscape(tagSlug)
+	}
+	return l.BaseURL + "/api/v1/tags/" + url.PathEscape(tagSlug) + "/articles"
+}
+
+// TagName returns the address listing the articles carrying the tag called name.
+func (l *SiteLinks) TagName(name string) string {
+	return l.Tag(TagSlug(name))
+}
+
+// Search returns the address of the search results for query.
+func (l *SiteLinks) Search(query string) string {
+	if l.HTML {
+		return l.BaseURL + "/search?" + url.Values{"q": {query}}.Encode()
+	}
+	return l.BaseURL + "/api/v1/articles/search?" + url.Values{"query": {query}}.Encode()
+}
+
+// ArticlesFeed returns the address of the feed of every article in format.
+func (l *SiteLinks) ArticlesFeed(format string) string {
+	return l.BaseURL + "/feeds/articles." + format
+}
+
+// UserFeed returns the address of the feed of one author in format.
+func (l *SiteLinks) UserFeed(userID int, format string) string {
+	return l.BaseURL + "/users/" + strconv.Itoa(userID) + "/feed." + format
+}
+
+// SearchFeed returns the address of the feed of the search results for query in format.
+func (l *SiteLinks) SearchFeed(query string, format string) string {
+	return l.BaseURL + "/feeds/search?" + url.Values{"query": {query}, "format": {format}}.Encode()
+}
+
+// Absolute turns a root-relative address such as a local upload URL into an absolute one.
+func (l *SiteLinks) Absolute(address string) string {
+	if strings.HasPrefix(address, "/") && !strings.HasPrefix(address, "//") {
+		return l.BaseURL + address
+	}
+	return address
+}
+
+// TagSlug returns the slug the tag called name is stored under.
+func TagSlug(name string) string {
+	return slug.Make(name, tagSlugLength)
+}
@@ -0,0 +1,35 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSiteLinks(t *testing.T) {
+	api := NewSiteLinks("https://blog.example.com/", false)
+	html := NewSiteLinks("https://blog.example.com", true)
+
+	tests := []struct {
+		name     string
+		got      string
+		expected string
+	}{
+		{name: "API article", got: api.Article("belajar-go"), expected: "https://blog.example.com/api/v1/articles/by-slug/belajar-go"},
+		{name: "HTML article", got: html.Article("belajar-go"), expected: "https://blog.example.com/articles/belajar-go"},
+		{name: "API author", got: api.Author(2, "budi"), expected: "https://blog.example.com/api/v1/users/2/articles"},
+		{name: "HTML author", got: html.Author(2, "budi santoso"), expected: "https://blog.example.com/authors/budi%20santoso"},
+		{name: "HTML tag by name", got: html.TagName("Go Lang"), expected: "https://blog.example.com/tags/go-lang"},
+		{name: "HTML search", got: html.Search("go & sql"), expected: "https://blog.example.com/search?q=go+%26+sql"},
+		{name: "Search feed", got: api.SearchFeed("go", "atom"), expected: "https://blog.example.com/feeds/search?format=atom&query=go"},
+		{name: "Relative upload", got: html.Absolute("/public/media/a.jpg"), expected: "https://blog.example.com/public/media/a.jpg"},
+		{name: "Absolute upload", got: html.Absolute("https://cdn.example.com/a.jpg"), expected: "https://cdn.example.com/a.jpg"},
+		{name: "Protocol relative upload", got: html.Absolute("//cdn.example.com/a.jpg"), expected: "//cdn.example.com/a.jpg"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, tt.got)
+		})
+	}
+}
@@ -0,0 +1,174 @@
+package utils
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/yantology/go-gin-simple-blog-with-fts/pkg/models/importsmodels"
+	"github.com/yantology/go-gin-simple-blog-with-fts/pkg/utils/customerror"
+	"github.com/yantology/go-gin-simple-blog-with-fts/pkg/utils/markup"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// maxZipMarkdownFiles is the most Markdown files read from one zip file
+	maxZipMarkdownFiles = 10000
+	// maxMarkdownFileSize is the largest Markdown file read from a zip file, in bytes
+	maxMarkdownFileSize = 5 << 20
+)
+
+// MarkdownZipFormat reads zip files of Markdown files, one article each, in
+// the order of their names. The YAML front matter of a file gives its title,
+// tags, date and status; a file without a title is named after the file.
+var MarkdownZipFormat = &ImportFormat{
+	Name:       importsmodels.SourceMarkdown,
+	Label:      "Markdown zip",
+	Extensions: []string{".zip"},
+	Open:       NewMarkdownZipImportReader,
+}
+
+// frontMatter is the YAML block opening a Markdown file
+type frontMatter struct {
+	Title  string  `yaml:"title"`
+	Tags   tagList `yaml:"tags"`
+	Date   string  `yaml:"date"`
+	Status string  `yaml:"status"`
+	// Draft is how static site generators mark unpublished posts
+	Draft bool `yaml:"draft"`
+}
+
+// markdownZipImportReader reads the Markdown files of a zip file as import records
+type markdownZipImportReader struct {
+	files []*zip.File
+	next  int
+}
+
+// NewMarkdownZipImportReader opens a zip file and returns a reader of the
+// Markdown files in it. Other files, folders and hidden files are ignored.
+func NewMarkdownZipImportReader(data []byte) (ImportReader, *customerror.CustomError) {
+	archive, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, customerror.NewCustomError(err, "file is not a valid zip file", 400)
+	}
+
+	files := []*zip.File{}
+	for _, file := range archive.File {
+		if file.FileInfo().IsDir() || !isMarkdownFile(file.Name) {
+			continue
+		}
+		files = append(files, file)
+	}
+	if len(files) == 0 {
+		return nil, customerror.NewCustomError(errors.New("no markdown files"), "zip file has no Markdown files", 400)
+	}
+	if len(files) > maxZipMarkdownFiles {
+		return nil, customerror.NewCustomError(errors.New("too many markdown files"), fmt.Sprintf("zip file has more than %d Markdown files", maxZipMarkdownFiles), 400)
+	}
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].Name < files[j].Name
+	})
+	return &markdownZipImportReader{files: files}, nil
+}
+
+// isMarkdownFile reports whether name is a Markdown file that is not hidden or
+// in a hidden folder, like the __MACOSX folder of zip files made on macOS
+func isMarkdownFile(name string) bool {
+	for _, part := range strings.Split(name, "/") {
+		if strings.HasPrefix(part, ".") || strings.HasPrefix(part, "__") {
+			return false
+		}
+	}
+	ext := strings.ToLower(path.Ext(name))
+	return ext == ".md" || ext == ".markdown"
+}
+
+func (r *markdownZipImportReader) Read() (*ImportRecord, error) {
+	if r.next >= len(r.files) {
+		return nil, io.EOF
+	}
+	file := r.files[r.next]
+	r.next++
+
+	fail := func(err error) (*ImportRecord, error) {
+		return nil, &RowError{Line: r.next, Title: file.Name, Err: err}
+	}
+
+	if file.UncompressedSize64 > maxMarkdownFileSize {
+		return fail(fmt.Errorf("file is larger than %d bytes", maxMarkdownFileSize))
+	}
+	f, err := file.Open()
+	if err != nil {
+		return fail(err)
+	}
+	defer f.Close()
+	// The size in the header is not to be trusted
+	data, err := io.ReadAll(io.LimitReader(f, maxMarkdownFileSize+1))
+	if err != nil {
+		return fail(err)
+	}
+	if len(data) > maxMarkdownFileSize {
+		return fail(fmt.Errorf("file is larger than %d bytes", maxMarkdownFileSize))
+	}
+
+	meta, body, err := parseFrontMatter(data)
+	if err != nil {
+		return fail(err)
+	}
+	date, err := parseImportDate(meta.Date)
+	if err != nil {
+		return fail(err)
+	}
+
+	record := &ImportRecord{
+		Line:    r.next,
+		Title:   strings.TrimSpace(meta.Title),
+		Content: body,
+		Format:  markup.FormatMarkdown,
+		Tags:    meta.Tags,
+		Date:    date,
+		Status:  meta.Status,
+	}
+	if record.Title == "" {
+		record.Title = strings.TrimSuffix(path.Base(file.Name), path.Ext(file.Name))
+	}
+	if record.Status == "" && meta.Draft {
+		record.Status = "draft"
+	}
+	if record.Content == "" && isPublished(record.Status) {
+		return fail(errors.New("file has no content"))
+	}
+	return record, nil
+}
+
+// parseFrontMatter splits a Markdown file into its front matter, a YAML block
+// between two --- lines at the top, and the trimmed body. A file without front
+// matter is all body.
+func parseFrontMatter(data []byte) (*frontMatter, string, error) {
+	text := strings.TrimPrefix(string(data), "\ufeff")
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+
+	meta := &frontMatter{}
+	if !strings.HasPrefix(text, "---\n") {
+		return meta, strings.TrimSpace(text), nil
+	}
+	block, body, found := strings.Cut("\n"+text[len("---\n"):], "\n---")
+	if !found {
+		return nil, "", errors.New("front matter is not closed with ---")
+	}
+	// The closing line may only hold the dashes
+	rest, body, _ := strings.Cut(body, "\n")
+	if strings.TrimSpace(rest) != "" {
+		return nil, "", errors.New("front matter is not closed with ---")
+	}
+
+	if err := yaml.Unmarshal([]byte(block), meta); err != nil {
+		return nil, "", fmt.Errorf("invalid front matter: %w", err)
+	}
+	return meta, strings.TrimSpace(body), nil
+}
@@ -0,0 +1,124 @@
+package utils
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testZip builds a zip file holding files, in the order given
+func testZip(t *testing.T, files ...[2]string) []byte {
+	var buf bytes.Buffer
+	w := zip.NewWriter(&buf)
+	for _, file := range files {
+		f, err := w.Create(file[0])
+		require.NoError(t, err)
+		_, err = f.Write([]byte(file[1]))
+		require.NoError(t, err)
+	}
+	require.NoError(t, w.Close())
+	return buf.Bytes()
+}
+
+func TestNewMarkdownZipImportReader(t *testing.T) {
+	_, cuserr := NewMarkdownZipImportReader([]byte("title,url\n"))
+	if assert.NotNil(t, cuserr) {
+		assert.Equal(t, "file is not a valid zip file", cuserr.Error())
+	}
+
+	_, cuserr = NewMarkdownZipImportReader(testZip(t, [2]string{"notes.txt", "text"}, [2]string{"__MACOSX/post.md", "junk"}))
+	if assert.NotNil(t, cuserr) {
+		assert.Equal(t, "zip file has no Markdown files", cuserr.Error())
+	}
+}
+
+func TestMarkdownZipImportReaderRead(t *testing.T) {
+	data := testZip(t,
+		[2]string{"posts/b-second.md", "---\ntitle: Second post\ntags: go, web\ndate: 2024-03-02\n---\n\nSecond *body*\n"},
+		[2]string{"posts/a-first.markdown", "---\r\ntitle: \"First: post\"\r\ntags:\r\n  - go\r\ndate: 2024-03-01T08:00:00Z\r\nstatus: published\r\n---\r\nFirst body\r\n"},
+		[2]string{"posts/c-untitled.md", "No front matter here\n"},
+		[2]string{"posts/d-draft.md", "---\ntitle: Draft\ndraft: true\n---\n"},
+		[2]string{"posts/e-broken.md", "---\ntitle: [unclosed\n---\nBody\n"},
+		[2]string{"posts/.hidden.md", "Hidden"},
+		[2]string{"posts/cover.png", "png"},
+	)
+
+	reader, cuserr := NewMarkdownZipImportReader(data)
+	require.Nil(t, cuserr)
+
+	record, err := reader.Read()
+	require.NoError(t, err)
+	first := time.Date(2024, 3, 1, 8, 0, 0, 0, time.UTC)
+	assert.Equal(t, &ImportRecord{
+		Line:    1,
+		Title:   "First: post",
+		Content: "First body",
+		Format:  "markdown",
+		Tags:    []string{"go"},
+		Date:    &first,
+		Status:  "published",
+	}, record)
+
+	record, err = reader.Read()
+	require.NoError(t, err)
+	second := time.Date(2024, 3, 2, 0, 0, 0, 0, time.UTC)
+	assert.Equal(t, "Second post", record.Title)
+	assert.Equal(t, "Second *body*", record.Content)
+	assert.Equal(t, []string{"go", "web"}, []string(record.Tags))
+	assert.Equal(t, &second, record.Date)
+
+	record, err = reader.Read()
+	require.NoError(t, err)
+	assert.Equal(t, "c-untitled", record.Title)
+	assert.Equal(t, "No front matter here", record.Content)
+
+	record, err = reader.Read()
+	require.NoError(t, err)
+	assert.Equal(t, "draft", record.Status)
+
+	_, err = reader.Read()
+	var rowErr *RowError
+	require.True(t, errors.As(err, &rowErr))
+	assert.Equal(t, 5, rowErr.Line)
+	assert.Equal(t, "posts/e-broken.md", rowErr.Title)
+
+	_, err = reader.Read()
+	assert.Equal(t, io.EOF, err)
+}
+
+func TestParseFrontMatter(t *testing.T) {
+	tests := []struct {
+		name      string
+		text      string
+		wantTitle string
+		wantBody  string
+		wantErr   bool
+	}{
+		{name: "Front matter", text: "---\ntitle: Hello\n---\nBody", wantTitle: "Hello", wantBody: "Body"},
+		{name: "Empty front matter", text: "---\n---\nBody", wantTitle: "", wantBody: "Body"},
+		{name: "Byte order mark", text: "\ufeff---\ntitle: Hello\n---\nBody", wantTitle: "Hello", wantBody: "Body"},
+		{name: "No front matter", text: "# Hello\n\n---\n\nBody", wantTitle: "", wantBody: "# Hello\n\n---\n\nBody"},
+		{name: "Rule in the body", text: "---\ntitle: Hello\n---\nOne\n\n---\n\nTwo", wantTitle: "Hello", wantBody: "One\n\n---\n\nTwo"},
+		{name: "Not closed", text: "---\ntitle: Hello\nBody", wantErr: true},
+		{name: "Invalid YAML", text: "---\ntitle: [Hello\n---\nBody", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			meta, body, err := parseFrontMatter([]byte(tt.text))
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantTitle, meta.Title)
+			assert.Equal(t, tt.wantBody, body)
+		})
+	}
+}
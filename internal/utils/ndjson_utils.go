@@ -0,0 +1,111 @@
+package utils
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/yantology/go-gin-simple-blog-with-fts/pkg/models/importsmodels"
+	"github.com/yantology/go-gin-simple-blog-with-fts/pkg/utils/customerror"
+)
+
+// NDJSONFormat reads files of one JSON object per line with the title, url,
+// content, format, tags, date and status of an article. Like CSV rows,
+// articles without content are scraped from their URL.
+var NDJSONFormat = &ImportFormat{
+	Name:       importsmodels.SourceNDJSON,
+	Label:      "NDJSON",
+	Extensions: []string{".ndjson", ".jsonl"},
+	Open: func(data []byte) (ImportReader, *customerror.CustomError) {
+		return NewNDJSONImportReader(bytes.NewReader(data))
+	},
+}
+
+// ndjsonArticle is a line of an NDJSON file
+type ndjsonArticle struct {
+	Title   string  `json:"title"`
+	URL     string  `json:"url"`
+	Content string  `json:"content"`
+	Format  string  `json:"format"`
+	Tags    tagList `json:"tags"`
+	Date    string  `json:"date"`
+	Status  string  `json:"status"`
+}
+
+// ndjsonImportReader reads the lines of an NDJSON file as import records
+type ndjsonImportReader struct {
+	reader *bufio.Reader
+	line   int
+	// first is the first line, read to check the file
+	first []byte
+}
+
+// NewNDJSONImportReader returns a reader of the lines of an NDJSON file, once
+// its first line is found to hold a JSON object.
+func NewNDJSONImportReader(r io.Reader) (ImportReader, *customerror.CustomError) {
+	reader := &ndjsonImportReader{reader: bufio.NewReader(r)}
+	first, err := reader.next()
+	if err == io.EOF {
+		return nil, customerror.NewCustomError(errors.New("empty file"), "file has no articles", 400)
+	}
+	if err != nil {
+		return nil, customerror.NewCustomError(err, err.Error(), 400)
+	}
+	if first[0] != '{' {
+		return nil, customerror.NewCustomError(errors.New("not an object"), "every line must be a JSON object", 400)
+	}
+	reader.first = first
+	return reader, nil
+}
+
+// next returns the next line that is not blank
+func (r *ndjsonImportReader) next() ([]byte, error) {
+	for {
+		line, err := r.reader.ReadBytes('\n')
+		if len(line) > 0 {
+			r.line++
+		}
+		line = bytes.TrimSpace(line)
+		if len(line) > 0 {
+			return line, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+}
+
+func (r *ndjsonImportReader) Read() (*ImportRecord, error) {
+	line := r.first
+	r.first = nil
+	if line == nil {
+		var err error
+		if line, err = r.next(); err != nil {
+			return nil, err
+		}
+	}
+
+	var article ndjsonArticle
+	if err := json.Unmarshal(line, &article); err != nil {
+		return nil, &RowError{Line: r.line, Err: fmt.Errorf("invalid JSON: %w", err)}
+	}
+	record := &ImportRecord{
+		Line:    r.line,
+		Title:   strings.TrimSpace(article.Title),
+		URL:     strings.TrimSpace(article.URL),
+		Content: article.Content,
+		Format:  article.Format,
+		Tags:    article.Tags,
+		Status:  article.Status,
+	}
+	date, err := parseImportDate(article.Date)
+	if err != nil {
+		return nil, &RowError{Line: r.line, Title: record.Title, Err: err}
+	}
+	record.Date = date
+	return record, nil
+}
@@ -0,0 +1,84 @@
+package utils
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewNDJSONImportReader(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		wantErr bool
+	}{
+		{name: "Objects", data: `{"title":"First"}` + "\n", wantErr: false},
+		{name: "Leading blank lines", data: "\n\n" + `{"title":"First"}`, wantErr: false},
+		{name: "Array", data: `[{"title":"First"}]`, wantErr: true},
+		{name: "Empty file", data: "\n \n", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, cuserr := NewNDJSONImportReader(strings.NewReader(tt.data))
+			if tt.wantErr {
+				assert.NotNil(t, cuserr)
+				assert.Equal(t, 400, cuserr.HTTPCode)
+			} else {
+				assert.Nil(t, cuserr)
+			}
+		})
+	}
+}
+
+func TestNDJSONImportReaderRead(t *testing.T) {
+	data := `{"title":" Inline ","content":"# Body","format":"markdown","tags":["go","web"],"date":"2024-03-01T10:00:00Z","extra":1}` + "\n" +
+		"\n" +
+		`{"title":"Scraped","url":"https://example.com/a","tags":"go, web"}` + "\r\n" +
+		`{"title":"Broken",` + "\n" +
+		`{"title":"Bad date","content":"Body","date":"soon"}` + "\n" +
+		`{"title":"Last","content":"Body","status":"draft"}`
+
+	reader, cuserr := NewNDJSONImportReader(strings.NewReader(data))
+	require.Nil(t, cuserr)
+
+	record, err := reader.Read()
+	require.NoError(t, err)
+	date := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+	assert.Equal(t, &ImportRecord{
+		Line:    1,
+		Title:   "Inline",
+		Content: "# Body",
+		Format:  "markdown",
+		Tags:    []string{"go", "web"},
+		Date:    &date,
+	}, record)
+
+	record, err = reader.Read()
+	require.NoError(t, err)
+	assert.Equal(t, &ImportRecord{Line: 3, Title: "Scraped", URL: "https://example.com/a", Tags: []string{"go", "web"}}, record)
+
+	var rowErr *RowError
+	_, err = reader.Read()
+	require.True(t, errors.As(err, &rowErr))
+	assert.Equal(t, 4, rowErr.Line)
+	assert.Contains(t, rowErr.Error(), "invalid JSON")
+
+	_, err = reader.Read()
+	require.True(t, errors.As(err, &rowErr))
+	assert.Equal(t, 5, rowErr.Line)
+	assert.Equal(t, "Bad date", rowErr.Title)
+
+	record, err = reader.Read()
+	require.NoError(t, err)
+	assert.Equal(t, 6, record.Line)
+	assert.Equal(t, "draft", record.Status)
+
+	_, err = reader.Read()
+	assert.Equal(t, io.EOF, err)
+}
@@ -0,0 +1,77 @@
+package utils
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/yantology/go-gin-simple-blog-with-fts/pkg/utils/extract"
+)
+
+// RefreshIntervals tells how often the page of an imported article is fetched
+// again: at the interval its import set, else at the interval of its host or
+// of the closest parent domain, else at the default one. An interval of zero
+// means never.
+type RefreshIntervals struct {
+	defaultInterval time.Duration
+	hosts           map[string]time.Duration
+}
+
+// NewRefreshIntervals returns intervals of defaultMinutes, overridden for the
+// hosts of a comma separated list of host=minutes such as
+// "news.example.com=60,example.org=0".
+func NewRefreshIntervals(defaultMinutes int, hosts string) (*RefreshIntervals, error) {
+	if defaultMinutes < 0 {
+		return nil, fmt.Errorf("refresh interval %d is negative", defaultMinutes)
+	}
+
+	intervals := &RefreshIntervals{
+		defaultInterval: time.Duration(defaultMinutes) * time.Minute,
+		hosts:           map[string]time.Duration{},
+	}
+	for _, entry := range strings.Split(hosts, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		host, minutes, found := strings.Cut(entry, "=")
+		host = extract.NormalizeHost(host)
+		if !found || host == "" {
+			return nil, fmt.Errorf("refresh interval %q is not host=minutes", entry)
+		}
+		n, err := strconv.Atoi(strings.TrimSpace(minutes))
+		if err != nil || n < 0 {
+			return nil, fmt.Errorf("refresh interval of %s is not a number of minutes", host)
+		}
+		intervals.hosts[host] = time.Duration(n) * time.Minute
+	}
+	return intervals, nil
+}
+
+// For returns the interval of the page at sourceURL, importMinutes being the
+// interval set by the import of the article, nil when it set none.
+func (r *RefreshIntervals) For(sourceURL string, importMinutes *int) time.Duration {
+	if importMinutes != nil {
+		return time.Duration(*importMinutes) * time.Minute
+	}
+	if r == nil {
+		return 0
+	}
+
+	if u, err := url.Parse(sourceURL); err == nil {
+		host := extract.NormalizeHost(u.Hostname())
+		for host != "" {
+			if interval, ok := r.hosts[host]; ok {
+				return interval
+			}
+			_, parent, found := strings.Cut(host, ".")
+			if !found {
+				break
+			}
+			host = parent
+		}
+	}
+	return r.defaultInterval
+}
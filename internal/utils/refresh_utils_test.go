@@ -0,0 +1,62 @@
+package utils
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRefreshIntervals(t *testing.T) {
+	intervals, err := NewRefreshIntervals(1440, " news.example.com=60, Example.org=0,,blog.example.org:8080=10 ")
+	if !assert.NoError(t, err) {
+		return
+	}
+	hourly := 60
+
+	tests := []struct {
+		name          string
+		sourceURL     string
+		importMinutes *int
+		want          time.Duration
+	}{
+		{"Default", "https://example.com/a", nil, 24 * time.Hour},
+		{"Host", "https://news.example.com/a", nil, time.Hour},
+		{"Parent domain", "https://www.example.org/a", nil, 0},
+		{"Host with a port", "http://blog.example.org:8080/a", nil, 10 * time.Minute},
+		{"Closest domain", "https://blog.example.org/a", nil, 10 * time.Minute},
+		{"Import", "https://example.org/a", &hourly, time.Hour},
+		{"Not a URL", "::", nil, 24 * time.Hour},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, intervals.For(tt.sourceURL, tt.importMinutes))
+		})
+	}
+
+	var none *RefreshIntervals
+	assert.Equal(t, time.Duration(0), none.For("https://example.com/a", nil))
+	assert.Equal(t, time.Hour, none.For("https://example.com/a", &hourly))
+}
+
+func TestNewRefreshIntervalsInvalid(t *testing.T) {
+	tests := []struct {
+		name           string
+		defaultMinutes int
+		hosts          string
+	}{
+		{"Negative default", -1, ""},
+		{"No minutes", 60, "example.com"},
+		{"No host", 60, "=10"},
+		{"Not a number", 60, "example.com=hourly"},
+		{"Negative", 60, "example.com=-5"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewRefreshIntervals(tt.defaultMinutes, tt.hosts)
+			assert.Error(t, err)
+		})
+	}
+}
@@ -0,0 +1,93 @@
+package utils
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/url"
+	"strings"
+
+	"github.com/yantology/go-gin-simple-blog-with-fts/pkg/utils/extract"
+	"github.com/yantology/go-gin-simple-blog-with-fts/pkg/utils/fetch"
+)
+
+// Scraper fetches web pages and finds the article on them
+type Scraper struct {
+	fetcher fetch.Fetcher
+	rules   *extract.Registry
+}
+
+func NewScraper(fetcher fetch.Fetcher, rules *extract.Registry) *Scraper {
+	return &Scraper{
+		fetcher: fetcher,
+		rules:   rules,
+	}
+}
+
+// ScrapedPage is the article found on a page, with the validators of the
+// response to fetch the page again only if it changed
+type ScrapedPage struct {
+	*extract.Result
+	ETag         string
+	LastModified string
+}
+
+// Scrape fetches the page at url and extracts the article with the rule for
+// its host, or the fallback extractor when there is none.
+func (s *Scraper) Scrape(ctx context.Context, url string) (*ScrapedPage, error) {
+	return s.ScrapeIfChanged(ctx, &fetch.Request{URL: url})
+}
+
+// ScrapeIfChanged scrapes the page of req like Scrape, asking for it only if
+// it changed since the validators of req. A page that did not change returns
+// fetch.ErrNotModified.
+func (s *Scraper) ScrapeIfChanged(ctx context.Context, req *fetch.Request) (*ScrapedPage, error) {
+	res, err := s.fetcher.Fetch(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	// Find the content, on the final page when the request was redirected
+	result, err := s.rules.Extract(res.URL, bytes.NewReader(res.Body))
+	if err != nil {
+		return nil, err
+	}
+	return &ScrapedPage{
+		Result:       result,
+		ETag:         res.Header.Get("ETag"),
+		LastModified: res.Header.Get("Last-Modified"),
+	}, nil
+}
+
+// NormalizeURL returns the form a page URL is stored and compared in: scheme
+// and host lowercased, default port, empty path and fragment dropped. URLs
+// that cannot be parsed are returned trimmed.
+func NormalizeURL(rawURL string) string {
+	rawURL = strings.TrimSpace(rawURL)
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Host == "" {
+		return rawURL
+	}
+
+	u.Scheme = strings.ToLower(u.Scheme)
+	u.Host = strings.ToLower(u.Host)
+	if port := u.Port(); (u.Scheme == "http" && port == "80") || (u.Scheme == "https" && port == "443") {
+		u.Host = u.Hostname()
+		if strings.Contains(u.Host, ":") {
+			u.Host = "[" + u.Host + "]"
+		}
+	}
+	if u.Path == "" {
+		u.Path = "/"
+	}
+	u.Fragment = ""
+	u.RawFragment = ""
+	return u.String()
+}
+
+// ContentHash returns the hex SHA-256 of a scraped title and content, to tell whether a page changed
+func ContentHash(title string, content string) string {
+	sum := sha256.Sum256([]byte(title + "\x00" + content))
+	return hex.EncodeToString(sum[:])
+}
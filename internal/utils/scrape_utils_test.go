@@ -0,0 +1,72 @@
+package utils
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yantology/go-gin-simple-blog-with-fts/pkg/utils/extract"
+	"github.com/yantology/go-gin-simple-blog-with-fts/pkg/utils/fetch"
+)
+
+func TestNormalizeURL(t *testing.T) {
+	tests := []struct {
+		rawURL string
+		want   string
+	}{
+		{rawURL: "https://news.example.com/a/b?id=1", want: "https://news.example.com/a/b?id=1"},
+		{rawURL: "  HTTPS://News.Example.COM/a/B  ", want: "https://news.example.com/a/B"},
+		{rawURL: "https://example.com", want: "https://example.com/"},
+		{rawURL: "https://example.com:443/a", want: "https://example.com/a"},
+		{rawURL: "http://example.com:80/a", want: "http://example.com/a"},
+		{rawURL: "http://example.com:8080/a", want: "http://example.com:8080/a"},
+		{rawURL: "https://[2001:db8::1]:443/a", want: "https://[2001:db8::1]/a"},
+		{rawURL: "https://example.com/a#comments", want: "https://example.com/a"},
+		{rawURL: "not a url", want: "not a url"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.rawURL, func(t *testing.T) {
+			assert.Equal(t, tt.want, NormalizeURL(tt.rawURL))
+		})
+	}
+}
+
+func TestContentHash(t *testing.T) {
+	hash := ContentHash("Title", "Body")
+	assert.Len(t, hash, 64)
+	assert.Equal(t, hash, ContentHash("Title", "Body"))
+	assert.NotEqual(t, hash, ContentHash("Title", "Body changed"))
+	assert.NotEqual(t, hash, ContentHash("TitleB", "ody"))
+}
+
+func TestScrapeIfChanged(t *testing.T) {
+	const etag = `"v1"`
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("If-None-Match") == etag {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", etag)
+		w.Header().Set("Last-Modified", "Mon, 16 Dec 2024 09:00:00 GMT")
+		w.Write([]byte(`<h1 class="headline">Title</h1><div class="detail__body-text"><p>Body</p></div>`))
+	}))
+	defer server.Close()
+
+	rules, err := extract.NewRegistry(extract.Rule{Host: "127.0.0.1", Title: "h1.headline", Content: "div.detail__body-text"})
+	require.NoError(t, err)
+	scraper := NewScraper(testFetcher(), rules)
+
+	page, err := scraper.Scrape(context.Background(), server.URL+"/page")
+	require.NoError(t, err)
+	assert.Equal(t, "Title", page.Title)
+	assert.Equal(t, "Body", page.Content)
+	assert.Equal(t, etag, page.ETag)
+	assert.Equal(t, "Mon, 16 Dec 2024 09:00:00 GMT", page.LastModified)
+
+	_, err = scraper.ScrapeIfChanged(context.Background(), &fetch.Request{URL: server.URL + "/page", ETag: page.ETag})
+	assert.ErrorIs(t, err, fetch.ErrNotModified)
+}
@@ -0,0 +1,156 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"html"
+	"io"
+	"strings"
+
+	"github.com/yantology/go-gin-simple-blog-with-fts/pkg/models/importsmodels"
+	"github.com/yantology/go-gin-simple-blog-with-fts/pkg/utils/customerror"
+	"github.com/yantology/go-gin-simple-blog-with-fts/pkg/utils/markup"
+)
+
+// WXRFormat reads WordPress export (WXR) files. Every post becomes an HTML
+// article with its categories and tags; pages, attachments and other item
+// types are left out. The post link is kept as the source URL so a later
+// export of the same blog is recognised, but it is not scraped.
+var WXRFormat = &ImportFormat{
+	Name:       importsmodels.SourceWXR,
+	Label:      "WordPress WXR",
+	Extensions: []string{".xml", ".wxr"},
+	Open: func(data []byte) (ImportReader, *customerror.CustomError) {
+		return NewWXRImportReader(bytes.NewReader(data))
+	},
+}
+
+// wxrItem is an item of a WordPress export, the fields of a post read from it
+type wxrItem struct {
+	Title      string        `xml:"title"`
+	Link       string        `xml:"link"`
+	PubDate    string        `xml:"pubDate"`
+	Content    string        `xml:"http://purl.org/rss/1.0/modules/content/ encoded"`
+	PostDate   string        `xml:"post_date_gmt"`
+	PostType   string        `xml:"post_type"`
+	Status     string        `xml:"status"`
+	Categories []wxrCategory `xml:"category"`
+}
+
+// wxrCategory is a category or tag of a post, told apart by its domain
+type wxrCategory struct {
+	Domain   string `xml:"domain,attr"`
+	Nicename string `xml:"nicename,attr"`
+	Name     string `xml:",chardata"`
+}
+
+// wxrEmptyDate is the post date WordPress exports for posts never published
+const wxrEmptyDate = "0000-00-00 00:00:00"
+
+// wxrImportReader reads the posts of a WordPress export as import records
+type wxrImportReader struct {
+	decoder *xml.Decoder
+	// items is the number of items read so far, posts or not
+	items int
+}
+
+// NewWXRImportReader returns a reader of the posts of a WordPress export, once
+// the file is found to start with an rss element.
+func NewWXRImportReader(r io.Reader) (ImportReader, *customerror.CustomError) {
+	decoder := xml.NewDecoder(r)
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			return nil, customerror.NewCustomError(err, "file is not a WordPress export", 400)
+		}
+		if start, ok := token.(xml.StartElement); ok {
+			if start.Name.Local != "rss" {
+				return nil, customerror.NewCustomError(errors.New("no rss element"), "file is not a WordPress export", 400)
+			}
+			return &wxrImportReader{decoder: decoder}, nil
+		}
+	}
+}
+
+func (r *wxrImportReader) Read() (*ImportRecord, error) {
+	for {
+		token, err := r.decoder.Token()
+		if err != nil {
+			// A broken file stops at the first error, nothing after it can be found
+			return nil, err
+		}
+		start, ok := token.(xml.StartElement)
+		if !ok || start.Name.Local != "item" {
+			continue
+		}
+
+		r.items++
+		var item wxrItem
+		if err := r.decoder.DecodeElement(&item, &start); err != nil {
+			return nil, err
+		}
+		if item.PostType != "" && item.PostType != "post" {
+			continue
+		}
+		return newWXRRecord(r.items, &item)
+	}
+}
+
+func newWXRRecord(line int, item *wxrItem) (*ImportRecord, error) {
+	record := &ImportRecord{
+		Line:    line,
+		Title:   strings.TrimSpace(html.UnescapeString(item.Title)),
+		URL:     strings.TrimSpace(item.Link),
+		Content: autoParagraphs(strings.TrimSpace(item.Content)),
+		Format:  markup.FormatHTML,
+		Status:  item.Status,
+	}
+	for _, category := range item.Categories {
+		if category.Domain != "post_tag" && category.Domain != "category" {
+			continue
+		}
+		// Every post without a category is in this one
+		if category.Domain == "category" && category.Nicename == "uncategorized" {
+			continue
+		}
+		record.Tags = append(record.Tags, strings.TrimSpace(html.UnescapeString(category.Name)))
+	}
+
+	date := item.PostDate
+	if date == "" || date == wxrEmptyDate {
+		date = item.PubDate
+	}
+	parsed, err := parseImportDate(date)
+	if err != nil {
+		return nil, &RowError{Line: line, Title: record.Title, Err: err}
+	}
+	record.Date = parsed
+
+	if record.Content == "" && isPublished(record.Status) {
+		return nil, &RowError{Line: line, Title: record.Title, Err: errors.New("post has no content")}
+	}
+	return record, nil
+}
+
+// autoParagraphs wraps the text of a post written in the classic WordPress
+// editor, which stores paragraphs as blank lines, in p elements the way
+// WordPress does when showing it. Posts with p elements are left alone.
+func autoParagraphs(content string) string {
+	if content == "" || strings.Contains(content, "<p") {
+		return content
+	}
+	content = strings.ReplaceAll(content, "\r\n", "\n")
+
+	var b strings.Builder
+	for _, paragraph := range strings.Split(content, "\n\n") {
+		paragraph = strings.TrimSpace(paragraph)
+		if paragraph == "" {
+			continue
+		}
+		b.WriteString("<p>")
+		b.WriteString(strings.ReplaceAll(paragraph, "\n", "<br>\n"))
+		b.WriteString("</p>\n")
+	}
+	return strings.TrimSpace(b.String())
+}
@@ -0,0 +1,126 @@
+package utils
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testWXR = `<?xml version="1.0" encoding="UTF-8" ?>
+<rss version="2.0"
+	xmlns:excerpt="http://wordpress.org/export/1.2/excerpt/"
+	xmlns:content="http://purl.org/rss/1.0/modules/content/"
+	xmlns:dc="http://purl.org/dc/elements/1.1/"
+	xmlns:wp="http://wordpress.org/export/1.2/">
+<channel>
+	<title>Old blog</title>
+	<wp:wxr_version>1.2</wp:wxr_version>
+	<item>
+		<title>Hello &amp;amp; welcome</title>
+		<link>https://old.example.com/2024/03/hello/</link>
+		<pubDate>Fri, 01 Mar 2024 10:00:00 +0000</pubDate>
+		<content:encoded><![CDATA[First paragraph
+still first.
+
+Second paragraph.]]></content:encoded>
+		<excerpt:encoded><![CDATA[An excerpt]]></excerpt:encoded>
+		<wp:post_date_gmt><![CDATA[2024-03-01 10:00:00]]></wp:post_date_gmt>
+		<wp:status><![CDATA[publish]]></wp:status>
+		<wp:post_type><![CDATA[post]]></wp:post_type>
+		<category domain="category" nicename="uncategorized"><![CDATA[Uncategorized]]></category>
+		<category domain="category" nicename="news"><![CDATA[News]]></category>
+		<category domain="post_tag" nicename="go"><![CDATA[Go]]></category>
+		<wp:comment><wp:comment_content><![CDATA[Nice]]></wp:comment_content></wp:comment>
+	</item>
+	<item>
+		<title>logo.png</title>
+		<wp:post_type><![CDATA[attachment]]></wp:post_type>
+	</item>
+	<item>
+		<title>Unfinished</title>
+		<link>https://old.example.com/?p=3</link>
+		<content:encoded><![CDATA[<p>Not yet</p>]]></content:encoded>
+		<wp:post_date_gmt><![CDATA[0000-00-00 00:00:00]]></wp:post_date_gmt>
+		<wp:status><![CDATA[draft]]></wp:status>
+		<wp:post_type><![CDATA[post]]></wp:post_type>
+	</item>
+	<item>
+		<title>Empty</title>
+		<link>https://old.example.com/?p=4</link>
+		<content:encoded><![CDATA[]]></content:encoded>
+		<wp:status><![CDATA[publish]]></wp:status>
+		<wp:post_type><![CDATA[post]]></wp:post_type>
+	</item>
+</channel>
+</rss>
+`
+
+func TestNewWXRImportReader(t *testing.T) {
+	_, cuserr := NewWXRImportReader(strings.NewReader(testWXR))
+	assert.Nil(t, cuserr)
+
+	for _, data := range []string{"", "title,url\n", `<?xml version="1.0"?><feed></feed>`} {
+		_, cuserr := NewWXRImportReader(strings.NewReader(data))
+		if assert.NotNil(t, cuserr, data) {
+			assert.Equal(t, "file is not a WordPress export", cuserr.Error())
+		}
+	}
+}
+
+func TestWXRImportReaderRead(t *testing.T) {
+	reader, cuserr := NewWXRImportReader(strings.NewReader(testWXR))
+	require.Nil(t, cuserr)
+
+	record, err := reader.Read()
+	require.NoError(t, err)
+	date := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+	assert.Equal(t, &ImportRecord{
+		Line:    1,
+		Title:   "Hello & welcome",
+		URL:     "https://old.example.com/2024/03/hello/",
+		Content: "<p>First paragraph<br>\nstill first.</p>\n<p>Second paragraph.</p>",
+		Format:  "html",
+		Tags:    []string{"News", "Go"},
+		Date:    &date,
+		Status:  "publish",
+	}, record)
+
+	// The attachment is left out
+	record, err = reader.Read()
+	require.NoError(t, err)
+	assert.Equal(t, 3, record.Line)
+	assert.Equal(t, "draft", record.Status)
+	assert.Nil(t, record.Date)
+
+	_, err = reader.Read()
+	var rowErr *RowError
+	require.True(t, errors.As(err, &rowErr))
+	assert.Equal(t, 4, rowErr.Line)
+	assert.Equal(t, "post has no content", rowErr.Error())
+
+	_, err = reader.Read()
+	assert.Equal(t, io.EOF, err)
+}
+
+func TestAutoParagraphs(t *testing.T) {
+	tests := []struct {
+		content string
+		want    string
+	}{
+		{content: "", want: ""},
+		{content: "One line", want: "<p>One line</p>"},
+		{content: "One\r\n\r\nTwo\nlines", want: "<p>One</p>\n<p>Two<br>\nlines</p>"},
+		{content: "<p>Already</p>\n\n<p>wrapped</p>", want: "<p>Already</p>\n\n<p>wrapped</p>"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.content, func(t *testing.T) {
+			assert.Equal(t, tt.want, autoParagraphs(tt.content))
+		})
+	}
+}
@@ -1,6 +1,8 @@
 package articlesinterface
 
 import (
+	"time"
+
 	"github.com/yantology/go-gin-simple-blog-with-fts/pkg/models/articlesmodels"
 	"github.com/yantology/go-gin-simple-blog-with-fts/pkg/utils/customerror"
 )
@@ -11,9 +13,16 @@ type ArticleRepository interface {
 	// Returns the article and a custom error if the operation fails.
 	GetArticleByID(id int) (*articlesmodels.Article, *customerror.CustomError)
 
+	// GetArticleBySlug retrieves an article by its current slug or one it had before a title change.
+	// The returned article carries its current slug, so callers can redirect old links.
+	// Returns the article and a custom error if the operation fails.
+	GetArticleBySlug(slug string) (*articlesmodels.Article, *customerror.CustomError)
+
 	// GetAllArticles retrieves all articles from the database.
+	// Parameters:
+	//   - tags: Tag slugs every returned article must carry, nil for no filter
 	// Returns a slice of articles and a custom error if the operation fails.
-	GetAllArticles() ([]*articlesmodels.Article, *customerror.CustomError)
+	GetAllArticles(tags []string) ([]*articlesmodels.Article, *customerror.CustomError)
 
 	// GetArticlesByUserID retrieves all articles created by a specific user.
 	// Parameters:
@@ -24,31 +33,147 @@ type ArticleRepository interface {
 	//   - A custom error if the operation fails
 	GetArticlesByUserID(userID int) ([]*articlesmodels.Article, *customerror.CustomError)
 
+	// GetLatestArticles retrieves a page of the most recently created visible articles, newest first.
+	// Parameters:
+	//   - limit: The maximum number of articles to return
+	//   - offset: The number of articles to skip
+	//   - tags: Tag slugs every article must carry, nil for no filter
+	//
+	// Returns a slice of articles and a custom error if the operation fails.
+	GetLatestArticles(limit int, offset int, tags []string) ([]*articlesmodels.Article, *customerror.CustomError)
+
+	// GetLatestArticlesByUserID retrieves a page of the most recently created visible articles of a user, newest first.
+	// Returns at most limit articles after skipping offset, and a custom error if the operation fails.
+	GetLatestArticlesByUserID(userID int, limit int, offset int) ([]*articlesmodels.Article, *customerror.CustomError)
+
+	// GetArticleLinks retrieves the ID, author, slug and modification time of every visible article.
+	// Returns a slice of links and a custom error if the operation fails.
+	GetArticleLinks() ([]*articlesmodels.ArticleLink, *customerror.CustomError)
+
 	// SearchArticles searches for articles based on a query string with pagination.
 	// Parameters:
 	//   - limit: The maximum number of articles to return
 	//   - offset: The number of articles to skip before starting to collect the result set
 	//   - query: The search query string
+	//   - tags: Tag slugs every returned article must carry, nil for no filter
 	// Returns a slice of articles and a custom error if the operation fails.
-	SearchArticles(limit, offset int, query string) ([]*articlesmodels.Article, *customerror.CustomError)
+	SearchArticles(limit, offset int, query string, tags []string) ([]*articlesmodels.Article, *customerror.CustomError)
 
 	// CreateArticle creates a new article in the database.
 	// Parameters:
-	//   - userId: The ID of the user creating the article
-	//   - title: The title of the article
-	//   - content: The content of the article
+	//   - article: The article to insert; UserID, Title, Content, Format, Tags, SourceURL, ContentHash and Source are read,
+	//     ID, Slug, ContentHTML, Excerpt, Version, CreatedAt and UpdatedAt are filled in on success
 	// Returns a custom error if the operation fails.
-	CreateArticle(userId int, title string, content string) *customerror.CustomError
+	CreateArticle(article *articlesmodels.Article) *customerror.CustomError
 
-	// UpdateArticle updates an existing article in the database.
+	// CreateArticles creates many articles in one transaction, all of them or none.
+	// Parameters:
+	//   - articles: The articles to insert, read and filled in like the article of CreateArticle
+	// Returns a custom error if any article cannot be created, in which case none is.
+	CreateArticles(articles []*articlesmodels.Article) *customerror.CustomError
+
+	// PatchArticle updates only the columns set in changes.
+	// The previous title and content are stored as a new revision, and a new title
+	// moves the article to a new slug while the old one keeps resolving. Changes
+	// equal to the stored values add no revision and keep the version.
 	// Parameters:
 	//   - articleId: The unique identifier of the article to update
-	//   - title: The new title for the article
-	//   - content: The new content for the article
+	//   - editorID: The ID of the user making the change
+	//   - changes: The columns to write, nil fields are left unchanged
+	//   - ifMatch: The versions the caller expects the article to be at, nil to skip the check
+	// Returns a custom error if the operation fails, with status 412 on a version mismatch.
+	PatchArticle(articleId int, editorID int, changes *articlesmodels.ArticleChanges, ifMatch []int) *customerror.CustomError
+
+	// DeleteArticleByID moves an article to the trash by its unique identifier.
+	// Trashed articles are excluded from every other query except the trash ones below.
+	// ifMatch holds the versions the caller expects the article to be at, nil to skip the check.
+	// Returns a custom error if the operation fails, with status 412 on a version mismatch.
+	DeleteArticleByID(id int, ifMatch []int) *customerror.CustomError
+
+	// GetArticleRevisions retrieves the stored revisions of an article, newest first.
+	// Returns a slice of revisions and a custom error if the operation fails.
+	GetArticleRevisions(articleID int) ([]*articlesmodels.ArticleRevision, *customerror.CustomError)
+
+	// GetArticleRevision retrieves a single revision of an article by its revision number.
+	// Returns the revision and a custom error if the operation fails.
+	GetArticleRevision(articleID int, revision int) (*articlesmodels.ArticleRevision, *customerror.CustomError)
+
+	// GetArticleBySourceURL retrieves the article a user imported from a web page, trashed or not.
+	// Returns the article and a custom error if there is none or the operation fails.
+	GetArticleBySourceURL(userID int, sourceURL string) (*articlesmodels.Article, *customerror.CustomError)
+
+	// SaveArticleSource stores how an imported article follows its page, replacing what was stored.
+	// Parameters:
+	//   - source: The source to store, for the article of its ArticleID
 	// Returns a custom error if the operation fails.
-	UpdateArticle(articleId int, title string, content string) *customerror.CustomError
+	SaveArticleSource(source *articlesmodels.ArticleSource) *customerror.CustomError
+
+	// ClaimDueArticleSources takes the sources whose next check is past, the most overdue first,
+	// pushing their next check back by lease so no other process takes them meanwhile.
+	// Parameters:
+	//   - limit: The maximum number of sources to take
+	//   - lease: How long the caller has to check the pages and save the sources again
+	// Returns the sources as they were before being claimed and a custom error if the operation fails.
+	ClaimDueArticleSources(limit int, lease time.Duration) ([]*articlesmodels.ArticleSource, *customerror.CustomError)
+
+	// GetDeletedArticleByID retrieves an article that is in the trash.
+	// Returns the article and a custom error if it is not in the trash or the operation fails.
+	GetDeletedArticleByID(id int) (*articlesmodels.Article, *customerror.CustomError)
 
-	// DeleteArticleByID deletes an article by its unique identifier.
+	// GetDeletedArticlesByUserID retrieves the trashed articles of a user, most recently deleted first.
+	// Returns a slice of articles and a custom error if the operation fails.
+	GetDeletedArticlesByUserID(userID int) ([]*articlesmodels.Article, *customerror.CustomError)
+
+	// RestoreArticleByID takes an article out of the trash.
+	// Returns a custom error if the article is not in the trash or the operation fails.
+	RestoreArticleByID(id int) *customerror.CustomError
+
+	// PurgeArticleByID permanently deletes an article that is in the trash.
+	// Returns a custom error if the article is not in the trash or the operation fails.
+	PurgeArticleByID(id int) *customerror.CustomError
+
+	// PurgeDeletedArticles permanently deletes every article trashed before the given time.
+	// Returns the number of purged articles and a custom error if the operation fails.
+	PurgeDeletedArticles(before time.Time) (int64, *customerror.CustomError)
+
+	// GetTags retrieves every tag in use together with its number of visible articles.
+	// Returns a slice of tags and a custom error if the operation fails.
+	GetTags() ([]*articlesmodels.Tag, *customerror.CustomError)
+
+	// GetTagBySlug retrieves a tag by its slug.
+	// Returns the tag and a custom error if it does not exist or the operation fails.
+	GetTagBySlug(slug string) (*articlesmodels.Tag, *customerror.CustomError)
+
+	// AddReaction records a reaction of a user on an article. Adding it twice has no effect.
+	// Returns a custom error if the operation fails.
+	AddReaction(articleID int, userID int, kind string) *customerror.CustomError
+
+	// RemoveReaction withdraws a reaction of a user from an article. Removing a missing reaction has no effect.
 	// Returns a custom error if the operation fails.
-	DeleteArticleByID(id int) *customerror.CustomError
+	RemoveReaction(articleID int, userID int, kind string) *customerror.CustomError
+
+	// GetUserReactions retrieves the reaction kinds a user left on an article.
+	// Returns a slice of kinds and a custom error if the operation fails.
+	GetUserReactions(articleID int, userID int) ([]string, *customerror.CustomError)
+
+	// AddBookmark saves an article to the private bookmarks of a user. Adding it twice has no effect.
+	// Returns a custom error if the operation fails.
+	AddBookmark(userID int, articleID int) *customerror.CustomError
+
+	// RemoveBookmark removes an article from the bookmarks of a user. Removing a missing bookmark has no effect.
+	// Returns a custom error if the operation fails.
+	RemoveBookmark(userID int, articleID int) *customerror.CustomError
+
+	// GetBookmarkedArticles retrieves the visible articles a user bookmarked, most recently bookmarked first.
+	// Returns a slice of articles and a custom error if the operation fails.
+	GetBookmarkedArticles(userID int, limit int, offset int) ([]*articlesmodels.Article, *customerror.CustomError)
+
+	// GetFollowedArticles retrieves the visible articles of the authors followerID follows, newest first.
+	// Parameters:
+	//   - followerID: The user whose feed is read
+	//   - cursor: The last article of the previous page, nil for the first page
+	//   - limit: The maximum number of articles to return
+	//
+	// Returns a slice of articles and a custom error if the operation fails.
+	GetFollowedArticles(followerID int, cursor *articlesmodels.ArticleCursor, limit int) ([]*articlesmodels.Article, *customerror.CustomError)
 }
@@ -0,0 +1,46 @@
+package commentsinterface
+
+import (
+	"github.com/yantology/go-gin-simple-blog-with-fts/pkg/models/commentsmodels"
+	"github.com/yantology/go-gin-simple-blog-with-fts/pkg/utils/customerror"
+)
+
+// CommentRepository defines the interface for comment-related database operations.
+type CommentRepository interface {
+	// CreateComment creates a new comment in the database.
+	// Parameters:
+	//   - comment: The comment to insert; ArticleID, UserID, ParentID, RootID and Content are read,
+	//     ID, Username, CreatedAt and UpdatedAt are filled in on success
+	// Returns a custom error if the operation fails.
+	CreateComment(comment *commentsmodels.Comment) *customerror.CustomError
+
+	// GetCommentByID retrieves a comment by its unique identifier, including deleted and hidden ones.
+	// Returns the comment and a custom error if the operation fails.
+	GetCommentByID(id int) (*commentsmodels.Comment, *customerror.CustomError)
+
+	// GetCommentThreads retrieves a page of top-level comments of an article, oldest first,
+	// together with every reply in their threads.
+	// Parameters:
+	//   - articleID: The article the comments belong to
+	//   - limit: The maximum number of top-level comments to return
+	//   - offset: The number of top-level comments to skip
+	//
+	// Returns:
+	//   - The top-level comments and their replies, ordered by creation time
+	//   - The total number of top-level comments of the article
+	//   - A custom error if the operation fails
+	GetCommentThreads(articleID int, limit int, offset int) ([]*commentsmodels.Comment, int, *customerror.CustomError)
+
+	// UpdateComment replaces the content of a comment that is not deleted.
+	// Returns a custom error if the operation fails.
+	UpdateComment(id int, content string) *customerror.CustomError
+
+	// DeleteCommentByID marks a comment as deleted and clears its content.
+	// The row is kept so replies stay in their thread.
+	// Returns a custom error if the operation fails.
+	DeleteCommentByID(id int) *customerror.CustomError
+
+	// SetCommentHidden hides a comment from readers or shows it again.
+	// Returns a custom error if the operation fails.
+	SetCommentHidden(id int, hidden bool) *customerror.CustomError
+}
@@ -0,0 +1,45 @@
+package followsinterface
+
+import (
+	"github.com/yantology/go-gin-simple-blog-with-fts/pkg/models/followsmodels"
+	"github.com/yantology/go-gin-simple-blog-with-fts/pkg/utils/customerror"
+)
+
+// FollowRepository defines the interface for follow-related database operations.
+type FollowRepository interface {
+	// Follow makes followerID follow followeeID. Following twice has no effect.
+	// Returns a custom error if either user does not exist or the operation fails.
+	Follow(followerID int, followeeID int) *customerror.CustomError
+
+	// Unfollow makes followerID stop following followeeID. Unfollowing a user that is not followed has no effect.
+	// Returns a custom error if the operation fails.
+	Unfollow(followerID int, followeeID int) *customerror.CustomError
+
+	// IsFollowing reports whether followerID follows followeeID.
+	// Returns true when the follow exists and a custom error if the operation fails.
+	IsFollowing(followerID int, followeeID int) (bool, *customerror.CustomError)
+
+	// GetFollowers retrieves a page of the users following userID, most recent followers first.
+	// Parameters:
+	//   - userID: The followed user
+	//   - limit: The maximum number of users to return
+	//   - offset: The number of users to skip
+	//
+	// Returns:
+	//   - The followers, with the time they started following
+	//   - The total number of followers
+	//   - A custom error if the operation fails
+	GetFollowers(userID int, limit int, offset int) ([]*followsmodels.FollowUser, int, *customerror.CustomError)
+
+	// GetFollowing retrieves a page of the users userID follows, most recently followed first.
+	// Parameters:
+	//   - userID: The following user
+	//   - limit: The maximum number of users to return
+	//   - offset: The number of users to skip
+	//
+	// Returns:
+	//   - The followed users, with the time userID started following them
+	//   - The total number of followed users
+	//   - A custom error if the operation fails
+	GetFollowing(userID int, limit int, offset int) ([]*followsmodels.FollowUser, int, *customerror.CustomError)
+}
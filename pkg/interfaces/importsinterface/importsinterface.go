@@ -0,0 +1,67 @@
+package importsinterface
+
+import (
+	"time"
+
+	"github.com/yantology/go-gin-simple-blog-with-fts/pkg/models/importsmodels"
+	"github.com/yantology/go-gin-simple-blog-with-fts/pkg/utils/customerror"
+)
+
+// ImportRepository defines the interface for import job database operations.
+type ImportRepository interface {
+	// CreateImport records a queued import.
+	// Parameters:
+	//   - imp: The import to insert; ID, State and CreatedAt are filled in on success
+	// Returns a custom error if the operation fails, with status 400 when the user
+	// already sent an upload with imp.IdempotencyKey.
+	CreateImport(imp *importsmodels.Import) *customerror.CustomError
+
+	// GetImportByID retrieves an import by its unique identifier.
+	// Returns the import and a custom error if the operation fails.
+	GetImportByID(id int) (*importsmodels.Import, *customerror.CustomError)
+
+	// GetImportByIdempotencyKey retrieves the import a user started with an Idempotency-Key header.
+	// Returns the import and a custom error if there is none or the operation fails.
+	GetImportByIdempotencyKey(userID int, key string) (*importsmodels.Import, *customerror.CustomError)
+
+	// StartImport moves a queued import to running and records when it started.
+	// Parameters:
+	//   - id: The import ID
+	//   - leaseExpiresAt: When the import counts as left behind unless its lease is renewed
+	// Returns a custom error if the operation fails.
+	StartImport(id int, leaseExpiresAt time.Time) *customerror.CustomError
+
+	// UpdateImportCounts records the progress of a running import and renews its lease.
+	// Parameters:
+	//   - id: The import ID
+	//   - counts: Rows processed so far
+	//   - leaseExpiresAt: The new end of the lease
+	// Returns a custom error if the operation fails, or the import is no longer running.
+	UpdateImportCounts(id int, counts importsmodels.Counts, leaseExpiresAt time.Time) *customerror.CustomError
+
+	// FinishImport records the final state, progress and, for failed imports, the reason.
+	// Returns a custom error if the operation fails, or the import already finished.
+	FinishImport(id int, state string, counts importsmodels.Counts, reason *string) *customerror.CustomError
+
+	// FailExpiredImports marks the queued or running imports whose lease ran out as
+	// failed with reason, for imports left behind by a server process that stopped.
+	// Imports still renewed by a running process are left alone.
+	// Returns the number of imports marked and a custom error if the operation fails.
+	FailExpiredImports(reason string) (int, *customerror.CustomError)
+
+	// AddImportRows records what became of rows of an import.
+	// Parameters:
+	//   - importID: The import the rows belong to
+	//   - rows: The row results, with distinct row numbers
+	// Returns a custom error if the operation fails.
+	AddImportRows(importID int, rows []*importsmodels.RowResult) *customerror.CustomError
+
+	// GetImportRows retrieves a page of the row results of an import in file order.
+	// Parameters:
+	//   - importID: The import the rows belong to
+	//   - status: Only rows with this status, empty for every row
+	//   - limit: The maximum number of rows to return
+	//   - offset: The number of rows to skip
+	// Returns the rows, the number of rows matching status and a custom error if the operation fails.
+	GetImportRows(importID int, status string, limit int, offset int) ([]*importsmodels.RowResult, int, *customerror.CustomError)
+}
@@ -0,0 +1,44 @@
+package mediainterface
+
+import (
+	"context"
+
+	"github.com/yantology/go-gin-simple-blog-with-fts/pkg/models/mediamodels"
+	"github.com/yantology/go-gin-simple-blog-with-fts/pkg/utils/customerror"
+)
+
+// MediaRepository defines the interface for media-related database operations.
+type MediaRepository interface {
+	// CreateMedia records an uploaded file and its variants.
+	// Parameters:
+	//   - media: The media to insert; ID and CreatedAt are filled in on success
+	// Returns a custom error if the operation fails.
+	CreateMedia(media *mediamodels.Media) *customerror.CustomError
+
+	// GetMediaByID retrieves a media record by its unique identifier.
+	// Returns the media and a custom error if the operation fails.
+	GetMediaByID(id int) (*mediamodels.Media, *customerror.CustomError)
+
+	// DeleteMediaByID removes a media record. Articles using it as cover lose their cover and get a new version.
+	// Returns a custom error if the operation fails.
+	DeleteMediaByID(id int) *customerror.CustomError
+}
+
+// BlobStore stores uploaded files under slash-separated keys such as "media/2024/12/ab12.jpg".
+type BlobStore interface {
+	// Put writes data under key, replacing any existing file.
+	// Parameters:
+	//   - ctx: Cancels the write
+	//   - key: Where to store the file
+	//   - data: The file contents
+	//   - contentType: The MIME type the file is served with
+	// Returns a custom error if the operation fails.
+	Put(ctx context.Context, key string, data []byte, contentType string) *customerror.CustomError
+
+	// Delete removes the file under key. Deleting a missing file is not an error.
+	// Returns a custom error if the operation fails.
+	Delete(ctx context.Context, key string) *customerror.CustomError
+
+	// URL returns the public URL the file under key is served from.
+	URL(key string) string
+}
@@ -0,0 +1,23 @@
+package scrapinginterface
+
+import (
+	"github.com/yantology/go-gin-simple-blog-with-fts/pkg/models/scrapingmodels"
+	"github.com/yantology/go-gin-simple-blog-with-fts/pkg/utils/customerror"
+)
+
+// ScrapingRuleRepository defines the interface for scraping rule database operations.
+type ScrapingRuleRepository interface {
+	// GetScrapingRules retrieves every stored rule, sorted by host.
+	// Returns the rules and a custom error if the operation fails.
+	GetScrapingRules() ([]*scrapingmodels.ScrapingRule, *customerror.CustomError)
+
+	// SaveScrapingRule creates the rule for rule.Host or replaces the stored one.
+	// Parameters:
+	//   - rule: The rule to store; UpdatedAt is filled in on success
+	// Returns a custom error if the operation fails.
+	SaveScrapingRule(rule *scrapingmodels.ScrapingRule) *customerror.CustomError
+
+	// DeleteScrapingRule removes the stored rule for host.
+	// Returns a custom error if the operation fails.
+	DeleteScrapingRule(host string) *customerror.CustomError
+}
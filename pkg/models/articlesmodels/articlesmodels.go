@@ -2,11 +2,111 @@ package articlesmodels
 
 import "time"
 
+// Article is a blog post. Content is written in Format; ContentHTML and Excerpt
+// are derived from it whenever it is saved. CoverURL is read from the cover media.
+// Author is the username of UserID.
+// Reactions maps each reaction kind to its number of readers, kinds nobody used are absent.
+// SourceURL and ContentHash are set on articles imported from a web page.
+// Source, when set on a new article, is saved with it.
 type Article struct {
+	ID           int            `json:"id"`
+	UserID       int            `json:"user_id"`
+	Title        string         `json:"title"`
+	Slug         string         `json:"slug"`
+	Content      string         `json:"content"`
+	Format       string         `json:"format"`
+	ContentHTML  string         `json:"content_html"`
+	Excerpt      string         `json:"excerpt"`
+	CoverMediaID *int           `json:"cover_media_id"`
+	CoverURL     *string        `json:"cover_url"`
+	Version      int            `json:"version"`
+	CreatedAt    time.Time      `json:"created_at"`
+	UpdatedAt    time.Time      `json:"updated_at"`
+	DeletedAt    *time.Time     `json:"deleted_at"`
+	Tags         []string       `json:"tags"`
+	Reactions    map[string]int `json:"reactions"`
+	Author       string         `json:"author"`
+	SourceURL    *string        `json:"source_url"`
+	ContentHash  *string        `json:"content_hash"`
+	Source       *ArticleSource `json:"-"`
+}
+
+// ArticleLink is the part of an article needed to link to it, e.g. from a sitemap
+type ArticleLink struct {
+	ID        int
+	UserID    int
+	Slug      string
+	UpdatedAt time.Time
+}
+
+// ArticleCursor marks a position in a newest first list of articles.
+// The next page starts with the article created just before it.
+type ArticleCursor struct {
+	CreatedAt time.Time
+	ID        int
+}
+
+// ReactionKinds are the reactions a reader can leave on an article, one of each at most
+var ReactionKinds = []string{"like", "love", "laugh", "wow", "sad", "clap"}
+
+// ArticleChanges lists the columns a partial update writes.
+// Nil fields keep their current value.
+type ArticleChanges struct {
+	Title   *string
+	Content *string
+	Format  *string
+	Tags    *[]string
+	// CoverMediaID of 0 removes the cover
+	CoverMediaID *int
+	// ContentHash is not versioned, like the cover
+	ContentHash *string
+}
+
+// Tag is a label shared by articles. Slug is the unique, URL-safe form of Name.
+// ArticleCount is only filled when listing tags.
+type Tag struct {
+	ID           int       `json:"id"`
+	Name         string    `json:"name"`
+	Slug         string    `json:"slug"`
+	ArticleCount int       `json:"article_count"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// ArticleRevision is a snapshot of an article taken right before it was edited.
+// EditorID is the user who made the edit that replaced this version; it is nil
+// when the editor account no longer exists.
+type ArticleRevision struct {
 	ID        int       `json:"id"`
-	UserID    int       `json:"user_id"`
+	ArticleID int       `json:"article_id"`
+	Revision  int       `json:"revision"`
 	Title     string    `json:"title"`
 	Content   string    `json:"content"`
+	Format    string    `json:"format"`
+	EditorID  *int      `json:"editor_id"`
 	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// ArticleSource is how an article imported from a web page follows it. Once
+// NextCheckAt is past the page is fetched again, asking only for a version
+// newer than ETag and LastModified.
+type ArticleSource struct {
+	ArticleID int `json:"article_id"`
+	// RefreshInterval is the minutes between two checks set by the import, nil
+	// for the interval of the host
+	RefreshInterval *int `json:"refresh_interval"`
+	// TitleFromPage is set when the article took its title from the page
+	TitleFromPage bool       `json:"title_from_page"`
+	ETag          string     `json:"etag"`
+	LastModified  string     `json:"last_modified"`
+	CheckedAt     *time.Time `json:"checked_at"`
+	// NextCheckAt is nil when the page is not checked again
+	NextCheckAt *time.Time `json:"next_check_at"`
+	// ChangedAt is when a check last found the page changed
+	ChangedAt *time.Time `json:"changed_at"`
+	// Failures counts the checks failed in a row, LastError is why the last one failed
+	Failures  int     `json:"failures"`
+	LastError *string `json:"last_error"`
+	// PageHash is the hash of the title and content found on the page at the
+	// last check, nil for sources checked before it was kept
+	PageHash *string `json:"page_hash"`
 }
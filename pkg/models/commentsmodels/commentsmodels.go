@@ -0,0 +1,20 @@
+package commentsmodels
+
+import "time"
+
+// Comment is a reader's comment on an article. Replies point to the comment they
+// answer through ParentID and to the top-level comment of their thread through RootID;
+// both are nil for top-level comments.
+type Comment struct {
+	ID        int        `json:"id"`
+	ArticleID int        `json:"article_id"`
+	UserID    int        `json:"user_id"`
+	Username  string     `json:"username"`
+	ParentID  *int       `json:"parent_id"`
+	RootID    *int       `json:"root_id"`
+	Content   string     `json:"content"`
+	HiddenAt  *time.Time `json:"hidden_at"`
+	DeletedAt *time.Time `json:"deleted_at"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+}
@@ -0,0 +1,11 @@
+package followsmodels
+
+import "time"
+
+// FollowUser is a user in a follower or following list. FollowedAt is when the
+// follow relationship started.
+type FollowUser struct {
+	ID         int       `json:"id"`
+	Username   string    `json:"username"`
+	FollowedAt time.Time `json:"followed_at"`
+}
@@ -0,0 +1,110 @@
+package importsmodels
+
+import "time"
+
+// Import states. An import is queued until a worker picks it up and ends
+// completed, failed or cancelled.
+const (
+	StateQueued    = "queued"
+	StateRunning   = "running"
+	StateCompleted = "completed"
+	StateFailed    = "failed"
+	StateCancelled = "cancelled"
+)
+
+// Import sources, the kind of file an import reads
+const (
+	// SourceCSV is a CSV file of titles with URLs to scrape or inline content
+	SourceCSV = "csv"
+	// SourceNDJSON is a file of one JSON article per line
+	SourceNDJSON = "ndjson"
+	// SourceMarkdown is a zip file of Markdown files with front matter
+	SourceMarkdown = "markdown"
+	// SourceWXR is a WordPress export file
+	SourceWXR = "wxr"
+)
+
+// Import modes, what an import does with a URL the user imported before
+const (
+	// ModeSkip leaves the article of the URL alone
+	ModeSkip = "skip"
+	// ModeUpdate scrapes the URL again and updates the article when its content changed
+	ModeUpdate = "update"
+	// ModeDuplicate creates another article, not linked to the URL
+	ModeDuplicate = "duplicate"
+)
+
+// Modes are the valid import modes
+var Modes = []string{ModeSkip, ModeUpdate, ModeDuplicate}
+
+// Import is a background job creating articles from an uploaded file
+type Import struct {
+	ID         int        `json:"id"`
+	UserID     int        `json:"user_id"`
+	Source     string     `json:"source"`
+	Filename   string     `json:"filename"`
+	Mode       string     `json:"mode"`
+	State      string     `json:"state"`
+	Counts     Counts     `json:"counts"`
+	Error      *string    `json:"error"`
+	CreatedAt  time.Time  `json:"created_at"`
+	StartedAt  *time.Time `json:"started_at"`
+	FinishedAt *time.Time `json:"finished_at"`
+	// Atomic imports create every article or, when any row fails, none
+	Atomic bool `json:"atomic"`
+	// RefreshInterval is the minutes between two checks of the scraped pages
+	// for changes, nil for the interval of each host
+	RefreshInterval *int `json:"refresh_interval"`
+	// IdempotencyKey is the Idempotency-Key header of the upload, RequestHash
+	// identifies the format, mode and file sent with it
+	IdempotencyKey *string `json:"idempotency_key"`
+	RequestHash    *string `json:"request_hash"`
+	// Owner is the server process running the import, which renews
+	// LeaseExpiresAt until the import finishes
+	Owner          *string    `json:"owner"`
+	LeaseExpiresAt *time.Time `json:"lease_expires_at"`
+}
+
+// Counts is the progress of an import. Every processed row either succeeded,
+// failed or was skipped.
+type Counts struct {
+	Processed int `json:"processed"`
+	Succeeded int `json:"succeeded"`
+	Failed    int `json:"failed"`
+	Skipped   int `json:"skipped"`
+}
+
+// Finished reports whether the import has stopped for good
+func (i *Import) Finished() bool {
+	return i.State == StateCompleted || i.State == StateFailed || i.State == StateCancelled
+}
+
+// Row outcomes
+const (
+	RowSucceeded = "succeeded"
+	RowFailed    = "failed"
+	RowSkipped   = "skipped"
+)
+
+// Preview actions, what importing a row would do
+const (
+	ActionCreate = "create"
+	ActionUpdate = "update"
+	ActionSkip   = "skip"
+	ActionFail   = "fail"
+)
+
+// RowResult is what became of one row of an imported file
+type RowResult struct {
+	ImportID int `json:"import_id"`
+	// Row is the line of the file the row starts on, the header being line 1.
+	// For zip and WordPress files it is the position of the post in the file.
+	Row    int     `json:"row"`
+	Title  string  `json:"title"`
+	URL    string  `json:"url"`
+	Status string  `json:"status"`
+	Error  *string `json:"error"`
+	// ArticleID is the article created from the row, nil unless it succeeded
+	ArticleID *int      `json:"article_id"`
+	CreatedAt time.Time `json:"created_at"`
+}
@@ -0,0 +1,28 @@
+package mediamodels
+
+import "time"
+
+// Media is an uploaded image. StorageKey locates the file in the blob store
+// and URL is where it is served from.
+type Media struct {
+	ID          int            `json:"id"`
+	UserID      int            `json:"user_id"`
+	StorageKey  string         `json:"storage_key"`
+	URL         string         `json:"url"`
+	ContentType string         `json:"content_type"`
+	Size        int64          `json:"size"`
+	Width       int            `json:"width"`
+	Height      int            `json:"height"`
+	Variants    []MediaVariant `json:"variants"`
+	CreatedAt   time.Time      `json:"created_at"`
+}
+
+// MediaVariant is a resized copy of an uploaded image
+type MediaVariant struct {
+	StorageKey  string `json:"storage_key"`
+	URL         string `json:"url"`
+	ContentType string `json:"content_type"`
+	Size        int64  `json:"size"`
+	Width       int    `json:"width"`
+	Height      int    `json:"height"`
+}
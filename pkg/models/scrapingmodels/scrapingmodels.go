@@ -0,0 +1,13 @@
+package scrapingmodels
+
+import "time"
+
+// ScrapingRule is a scraping rule stored through the admin endpoints
+type ScrapingRule struct {
+	Host             string    `json:"host"`
+	TitleSelector    string    `json:"title_selector"`
+	ContentSelector  string    `json:"content_selector"`
+	ExcludeSelectors []string  `json:"exclude_selectors"`
+	UpdatedBy        *int      `json:"updated_by"`
+	UpdatedAt        time.Time `json:"updated_at"`
+}
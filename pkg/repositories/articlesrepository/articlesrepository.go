@@ -1,6 +1,8 @@
 package articlesrepository
 
 import (
+	"time"
+
 	"github.com/yantology/go-gin-simple-blog-with-fts/pkg/interfaces/articlesinterface"
 	"github.com/yantology/go-gin-simple-blog-with-fts/pkg/models/articlesmodels"
 	"github.com/yantology/go-gin-simple-blog-with-fts/pkg/utils/customerror"
@@ -40,6 +42,23 @@ func (r *ArticlesRepository) GetArticleByID(id int) (*articlesmodels.Article, *c
 	return r.service.GetArticleByID(id)
 }
 
+// GetArticleBySlug retrieves an article using its current or a previous slug
+// Parameters:
+//   - slug: string - The slug from the article URL
+//
+// Returns:
+//
+//	Success: (*Article{
+//	  ID: 1,
+//	  Title: "Belajar Golang",
+//	  Slug: "belajar-golang",
+//	  Content: "Content here...",
+//	}, nil) - Slug differs from the argument when an old slug was used
+//	Error: (nil, Error) - Article not found
+func (r *ArticlesRepository) GetArticleBySlug(slug string) (*articlesmodels.Article, *customerror.CustomError) {
+	return r.service.GetArticleBySlug(slug)
+}
+
 // GetArticlesByUserID retrieves all articles created by a specific user.
 // Parameters:
 //   - userID: The unique identifier of the user whose articles are to be retrieved
@@ -52,6 +71,9 @@ func (r *ArticlesRepository) GetArticlesByUserID(userID int) ([]*articlesmodels.
 }
 
 // GetAllArticles retrieves all available articles
+// Parameters:
+//   - tags: []string - Tag slugs every article must carry, nil for no filter
+//
 // Returns:
 //
 //	Success: ([]*Article{
@@ -59,8 +81,52 @@ func (r *ArticlesRepository) GetArticlesByUserID(userID int) ([]*articlesmodels.
 //	  {ID: 2, Title: "Second Post"}
 //	}, nil)
 //	Error: (nil, error) - Database errors
-func (r *ArticlesRepository) GetAllArticles() ([]*articlesmodels.Article, *customerror.CustomError) {
-	return r.service.GetAllArticles()
+func (r *ArticlesRepository) GetAllArticles(tags []string) ([]*articlesmodels.Article, *customerror.CustomError) {
+	return r.service.GetAllArticles(tags)
+}
+
+// GetLatestArticles retrieves a page of the newest visible articles
+// Parameters:
+//   - limit: int - Max articles to return
+//   - offset: int - Number of articles to skip
+//   - tags: []string - Tag slugs every article must carry, nil for no filter
+//
+// Returns:
+//
+//	Success: ([]*Article{
+//	  {ID: 9, Title: "Golang Generics"},
+//	  {ID: 8, Title: "Indexing in PostgreSQL"}
+//	}, nil)
+//	Error: (nil, error) - Database errors
+func (r *ArticlesRepository) GetLatestArticles(limit int, offset int, tags []string) ([]*articlesmodels.Article, *customerror.CustomError) {
+	return r.service.GetLatestArticles(limit, offset, tags)
+}
+
+// GetLatestArticlesByUserID retrieves a page of the newest visible articles of a user
+// Parameters:
+//   - userID: int - Author of the articles
+//   - limit: int - Max articles to return
+//   - offset: int - Number of articles to skip
+//
+// Returns:
+//
+//	Success: ([]*Article{
+//	  {ID: 9, UserID: 2, Title: "Golang Generics"}
+//	}, nil)
+//	Error: (nil, error) - Database errors
+func (r *ArticlesRepository) GetLatestArticlesByUserID(userID int, limit int, offset int) ([]*articlesmodels.Article, *customerror.CustomError) {
+	return r.service.GetLatestArticlesByUserID(userID, limit, offset)
+}
+
+// GetArticleLinks retrieves what is needed to link to every visible article
+// Returns:
+//
+//	Success: ([]*ArticleLink{
+//	  {ID: 1, Slug: "belajar-go", UpdatedAt: 2024-12-10}
+//	}, nil)
+//	Error: (nil, error) - Database errors
+func (r *ArticlesRepository) GetArticleLinks() ([]*articlesmodels.ArticleLink, *customerror.CustomError) {
+	return r.service.GetArticleLinks()
 }
 
 // SearchArticles performs full-text search on articles
@@ -68,6 +134,7 @@ func (r *ArticlesRepository) GetAllArticles() ([]*articlesmodels.Article, *custo
 //   - limit: int - Max results to return
 //   - offset: int - Number of results to skip
 //   - query: string - Search keywords
+//   - tags: []string - Tag slugs every article must carry, nil for no filter
 //
 // Returns:
 //
@@ -76,44 +143,307 @@ func (r *ArticlesRepository) GetAllArticles() ([]*articlesmodels.Article, *custo
 //	  {ID: 5, Title: "Go Programming", Content: "..."}
 //	}, nil)
 //	Error: (nil, error) - Search/DB errors
-func (r *ArticlesRepository) SearchArticles(limit, offset int, query string) ([]*articlesmodels.Article, *customerror.CustomError) {
-	return r.service.SearchArticles(limit, offset, query)
+func (r *ArticlesRepository) SearchArticles(limit, offset int, query string, tags []string) ([]*articlesmodels.Article, *customerror.CustomError) {
+	return r.service.SearchArticles(limit, offset, query, tags)
 }
 
 // CreateArticle creates a new article in the database.
 // Parameters:
-//   - userId: The ID of the user creating the article
-//   - title: The title of the article
-//   - content: The content of the article
+//   - article: The article to create, with UserID, Title, Content and Tags set
 //
 // Returns:
-//   - nil if the creation was successful
+//   - nil if the creation was successful, with article.ID and timestamps filled in
 //   - a custom error if there are validation or database errors
-func (r *ArticlesRepository) CreateArticle(userId int, title string, content string) *customerror.CustomError {
-	return r.service.CreateArticle(userId, title, content)
+func (r *ArticlesRepository) CreateArticle(article *articlesmodels.Article) *customerror.CustomError {
+	return r.service.CreateArticle(article)
 }
 
-// UpdateArticle modifies an existing article in the database.
+// CreateArticles creates many articles in one transaction, all of them or none.
+// Parameters:
+//   - articles: The articles to create, each with UserID, Title, Content and Tags set
+//
+// Returns:
+//   - nil if every article was created, with their IDs and timestamps filled in
+//   - a custom error if any article fails; then none is created
+func (r *ArticlesRepository) CreateArticles(articles []*articlesmodels.Article) *customerror.CustomError {
+	return r.service.CreateArticles(articles)
+}
+
+// PatchArticle modifies only the given columns of an existing article.
 // Parameters:
 //   - articleId: The unique identifier of the article to update
-//   - title: The new title for the article
-//   - content: The new content for the article
+//   - editorID: The ID of the user making the change
+//   - changes: The columns to write, nil fields are left unchanged
+//   - ifMatch: Versions the caller expects the article to be at, nil to skip the check
 //
 // Returns:
 //   - nil if the update was successful
-//   - a custom error if the article is not found or there are validation errors
-func (r *ArticlesRepository) UpdateArticle(articleId int, title string, content string) *customerror.CustomError {
-	return r.service.UpdateArticle(articleId, title, content)
+//   - a custom error if the article is not found, its version does not match or there are validation errors
+func (r *ArticlesRepository) PatchArticle(articleId int, editorID int, changes *articlesmodels.ArticleChanges, ifMatch []int) *customerror.CustomError {
+	return r.service.PatchArticle(articleId, editorID, changes, ifMatch)
 }
 
-// DeleteArticleByID removes an article by ID
+// DeleteArticleByID moves an article to the trash by ID
 // Parameters:
 //   - id: int - ID of article to delete
+//   - ifMatch: []int - Versions the caller expects, nil to skip the check
 //
 // Returns:
 //
 //	Success: (nil)
-//	Error: (error) - Not found/DB errors
-func (r *ArticlesRepository) DeleteArticleByID(id int) *customerror.CustomError {
-	return r.service.DeleteArticleByID(id)
+//	Error: (error) - Not found/version mismatch/DB errors
+func (r *ArticlesRepository) DeleteArticleByID(id int, ifMatch []int) *customerror.CustomError {
+	return r.service.DeleteArticleByID(id, ifMatch)
+}
+
+// GetArticleRevisions retrieves the revision history of an article
+// Parameters:
+//   - articleID: int - ID of the article
+//
+// Returns:
+//
+//	Success: ([]*ArticleRevision{
+//	  {Revision: 2, Title: "Golang Tips"},
+//	  {Revision: 1, Title: "Golang Tip"}
+//	}, nil)
+//	Error: (nil, error) - Database errors
+func (r *ArticlesRepository) GetArticleRevisions(articleID int) ([]*articlesmodels.ArticleRevision, *customerror.CustomError) {
+	return r.service.GetArticleRevisions(articleID)
+}
+
+// GetArticleRevision retrieves a single revision of an article
+// Parameters:
+//   - articleID: int - ID of the article
+//   - revision: int - Revision number
+//
+// Returns:
+//
+//	Success: (*ArticleRevision{Revision: 1, Title: "Golang Tip", Content: "..."}, nil)
+//	Error: (nil, error) - Revision not found/DB errors
+func (r *ArticlesRepository) GetArticleRevision(articleID int, revision int) (*articlesmodels.ArticleRevision, *customerror.CustomError) {
+	return r.service.GetArticleRevision(articleID, revision)
+}
+
+// GetArticleBySourceURL retrieves the article a user imported from a web page
+// Parameters:
+//   - userID: int - ID of the user who imported it
+//   - sourceURL: string - Normalized URL of the page
+//
+// Returns:
+//
+//	Success: (*Article{ID: 7, SourceURL: "https://news.example.com/a", DeletedAt: nil}, nil)
+//	Error: (nil, error) - Not found/DB errors
+func (r *ArticlesRepository) GetArticleBySourceURL(userID int, sourceURL string) (*articlesmodels.Article, *customerror.CustomError) {
+	return r.service.GetArticleBySourceURL(userID, sourceURL)
+}
+
+// SaveArticleSource stores how an imported article follows its page
+// Parameters:
+//   - source: *ArticleSource - Source of the article of its ArticleID
+//
+// Returns:
+//
+//	Success: (nil)
+//	Error: (error) - Article not found/DB errors
+func (r *ArticlesRepository) SaveArticleSource(source *articlesmodels.ArticleSource) *customerror.CustomError {
+	return r.service.SaveArticleSource(source)
+}
+
+// ClaimDueArticleSources takes the sources of imported articles due to be checked
+// Parameters:
+//   - limit: int - Maximum number of sources
+//   - lease: time.Duration - Time until a source not saved again is due again
+//
+// Returns:
+//
+//	Success: ([]*ArticleSource{
+//	  {ArticleID: 7, ETag: "\"v2\"", NextCheckAt: 2024-12-17 09:00}
+//	}, nil)
+//	Error: (nil, error) - Database errors
+func (r *ArticlesRepository) ClaimDueArticleSources(limit int, lease time.Duration) ([]*articlesmodels.ArticleSource, *customerror.CustomError) {
+	return r.service.ClaimDueArticleSources(limit, lease)
+}
+
+// GetDeletedArticleByID retrieves an article from the trash
+// Parameters:
+//   - id: int - ID of the trashed article
+//
+// Returns:
+//
+//	Success: (*Article{ID: 3, Title: "Old Draft", DeletedAt: 2024-01-21}, nil)
+//	Error: (nil, error) - Not in the trash/DB errors
+func (r *ArticlesRepository) GetDeletedArticleByID(id int) (*articlesmodels.Article, *customerror.CustomError) {
+	return r.service.GetDeletedArticleByID(id)
+}
+
+// GetDeletedArticlesByUserID retrieves the trash of a user
+// Parameters:
+//   - userID: int - Owner of the trashed articles
+//
+// Returns:
+//
+//	Success: ([]*Article{
+//	  {ID: 3, Title: "Old Draft", DeletedAt: 2024-01-21}
+//	}, nil)
+//	Error: (nil, error) - Database errors
+func (r *ArticlesRepository) GetDeletedArticlesByUserID(userID int) ([]*articlesmodels.Article, *customerror.CustomError) {
+	return r.service.GetDeletedArticlesByUserID(userID)
+}
+
+// RestoreArticleByID takes an article out of the trash
+// Parameters:
+//   - id: int - ID of the trashed article
+//
+// Returns:
+//
+//	Success: (nil)
+//	Error: (error) - Not in the trash/DB errors
+func (r *ArticlesRepository) RestoreArticleByID(id int) *customerror.CustomError {
+	return r.service.RestoreArticleByID(id)
+}
+
+// PurgeArticleByID permanently deletes a trashed article
+// Parameters:
+//   - id: int - ID of the trashed article
+//
+// Returns:
+//
+//	Success: (nil)
+//	Error: (error) - Not in the trash/DB errors
+func (r *ArticlesRepository) PurgeArticleByID(id int) *customerror.CustomError {
+	return r.service.PurgeArticleByID(id)
+}
+
+// PurgeDeletedArticles permanently deletes articles trashed before a given time
+// Parameters:
+//   - before: time.Time - Articles trashed earlier than this are purged
+//
+// Returns:
+//
+//	Success: (12, nil) - number of purged articles
+//	Error: (0, error) - Database errors
+func (r *ArticlesRepository) PurgeDeletedArticles(before time.Time) (int64, *customerror.CustomError) {
+	return r.service.PurgeDeletedArticles(before)
+}
+
+// GetTags retrieves all tags in use with their article counts
+// Returns:
+//
+//	Success: ([]*Tag{
+//	  {Name: "Golang", Slug: "golang", ArticleCount: 12}
+//	}, nil)
+//	Error: (nil, error) - Database errors
+func (r *ArticlesRepository) GetTags() ([]*articlesmodels.Tag, *customerror.CustomError) {
+	return r.service.GetTags()
+}
+
+// GetTagBySlug retrieves a tag by its slug
+// Parameters:
+//   - slug: string - URL-safe tag identifier
+//
+// Returns:
+//
+//	Success: (*Tag{Name: "Golang", Slug: "golang"}, nil)
+//	Error: (nil, error) - Tag not found/DB errors
+func (r *ArticlesRepository) GetTagBySlug(slug string) (*articlesmodels.Tag, *customerror.CustomError) {
+	return r.service.GetTagBySlug(slug)
+}
+
+// AddReaction records a reaction on an article, idempotently
+// Parameters:
+//   - articleID: int - Article reacted to
+//   - userID: int - Reacting user
+//   - kind: string - One of ReactionKinds, e.g. "like"
+//
+// Returns:
+//
+//	Success: nil
+//	Error: error - Database errors
+func (r *ArticlesRepository) AddReaction(articleID int, userID int, kind string) *customerror.CustomError {
+	return r.service.AddReaction(articleID, userID, kind)
+}
+
+// RemoveReaction withdraws a reaction from an article, idempotently
+// Parameters:
+//   - articleID: int - Article reacted to
+//   - userID: int - Reacting user
+//   - kind: string - Reaction kind, e.g. "like"
+//
+// Returns:
+//
+//	Success: nil
+//	Error: error - Database errors
+func (r *ArticlesRepository) RemoveReaction(articleID int, userID int, kind string) *customerror.CustomError {
+	return r.service.RemoveReaction(articleID, userID, kind)
+}
+
+// GetUserReactions retrieves the reactions a user left on an article
+// Parameters:
+//   - articleID: int - Article reacted to
+//   - userID: int - Reacting user
+//
+// Returns:
+//
+//	Success: ([]string{"like", "clap"}, nil)
+//	Error: (nil, error) - Database errors
+func (r *ArticlesRepository) GetUserReactions(articleID int, userID int) ([]string, *customerror.CustomError) {
+	return r.service.GetUserReactions(articleID, userID)
+}
+
+// AddBookmark bookmarks an article for a user, idempotently
+// Parameters:
+//   - userID: int - Owner of the bookmark
+//   - articleID: int - Bookmarked article
+//
+// Returns:
+//
+//	Success: nil
+//	Error: error - Database errors
+func (r *ArticlesRepository) AddBookmark(userID int, articleID int) *customerror.CustomError {
+	return r.service.AddBookmark(userID, articleID)
+}
+
+// RemoveBookmark removes a bookmark of a user, idempotently
+// Parameters:
+//   - userID: int - Owner of the bookmark
+//   - articleID: int - Bookmarked article
+//
+// Returns:
+//
+//	Success: nil
+//	Error: error - Database errors
+func (r *ArticlesRepository) RemoveBookmark(userID int, articleID int) *customerror.CustomError {
+	return r.service.RemoveBookmark(userID, articleID)
+}
+
+// GetBookmarkedArticles retrieves a page of a user's bookmarks
+// Parameters:
+//   - userID: int - Owner of the bookmarks
+//   - limit: int - Maximum number of articles
+//   - offset: int - Number of articles to skip
+//
+// Returns:
+//
+//	Success: ([]*Article{
+//	  {ID: 7, Title: "Golang Tips"}
+//	}, nil)
+//	Error: (nil, error) - Database errors
+func (r *ArticlesRepository) GetBookmarkedArticles(userID int, limit int, offset int) ([]*articlesmodels.Article, *customerror.CustomError) {
+	return r.service.GetBookmarkedArticles(userID, limit, offset)
+}
+
+// GetFollowedArticles retrieves a page of a user's feed
+// Parameters:
+//   - followerID: int - The user whose feed is read
+//   - cursor: *ArticleCursor - Last article of the previous page, nil for the first page
+//   - limit: int - Maximum number of articles
+//
+// Returns:
+//
+//	Success: ([]*Article{
+//	  {ID: 9, UserID: 2, Title: "Golang Generics"}
+//	}, nil)
+//	Error: (nil, error) - Database errors
+func (r *ArticlesRepository) GetFollowedArticles(followerID int, cursor *articlesmodels.ArticleCursor, limit int) ([]*articlesmodels.Article, *customerror.CustomError) {
+	return r.service.GetFollowedArticles(followerID, cursor, limit)
 }
@@ -0,0 +1,104 @@
+package commentsrepository
+
+import (
+	"github.com/yantology/go-gin-simple-blog-with-fts/pkg/interfaces/commentsinterface"
+	"github.com/yantology/go-gin-simple-blog-with-fts/pkg/models/commentsmodels"
+	"github.com/yantology/go-gin-simple-blog-with-fts/pkg/utils/customerror"
+)
+
+// CommentsRepository provides methods to interact with the comments service
+type CommentsRepository struct {
+	service commentsinterface.CommentRepository
+}
+
+// NewCommentsRepository creates a new instance of CommentsRepository
+// Parameters:
+//   - service: implementation of CommentRepository interface
+//
+// Returns:
+//   - *CommentsRepository: new repository instance
+func NewCommentsRepository(service commentsinterface.CommentRepository) *CommentsRepository {
+	return &CommentsRepository{service: service}
+}
+
+// CreateComment creates a comment or a reply
+// Parameters:
+//   - comment: *Comment - The comment to insert, ID, Username and timestamps are filled in
+//
+// Returns:
+//
+//	Success: nil
+//	Error: Error - Article or parent comment not found, or database failure
+func (r *CommentsRepository) CreateComment(comment *commentsmodels.Comment) *customerror.CustomError {
+	return r.service.CreateComment(comment)
+}
+
+// GetCommentByID retrieves a comment using its unique identifier
+// Parameters:
+//   - id: int - The comment's database ID
+//
+// Returns:
+//
+//	Success: (*Comment{
+//	  ID: 1,
+//	  ArticleID: 3,
+//	  UserID: 2,
+//	  Username: "yanto",
+//	  Content: "Nice article"
+//	}, nil)
+//	Error: (nil, Error) - Comment not found
+func (r *CommentsRepository) GetCommentByID(id int) (*commentsmodels.Comment, *customerror.CustomError) {
+	return r.service.GetCommentByID(id)
+}
+
+// GetCommentThreads retrieves a page of comment threads of an article
+// Parameters:
+//   - articleID: int - The article's database ID
+//   - limit: int - Maximum number of top-level comments
+//   - offset: int - Number of top-level comments to skip
+//
+// Returns:
+//
+//	Success: ([]*Comment{...}, 12, nil) - top-level comments with their replies, and the number of top-level comments
+//	Error: (nil, 0, Error) - Database failure
+func (r *CommentsRepository) GetCommentThreads(articleID int, limit int, offset int) ([]*commentsmodels.Comment, int, *customerror.CustomError) {
+	return r.service.GetCommentThreads(articleID, limit, offset)
+}
+
+// UpdateComment replaces the content of a comment
+// Parameters:
+//   - id: int - The comment's database ID
+//   - content: string - The new content
+//
+// Returns:
+//
+//	Success: nil
+//	Error: Error - Comment not found or deleted
+func (r *CommentsRepository) UpdateComment(id int, content string) *customerror.CustomError {
+	return r.service.UpdateComment(id, content)
+}
+
+// DeleteCommentByID marks a comment as deleted
+// Parameters:
+//   - id: int - The comment's database ID
+//
+// Returns:
+//
+//	Success: nil
+//	Error: Error - Comment not found or already deleted
+func (r *CommentsRepository) DeleteCommentByID(id int) *customerror.CustomError {
+	return r.service.DeleteCommentByID(id)
+}
+
+// SetCommentHidden hides a comment from readers or shows it again
+// Parameters:
+//   - id: int - The comment's database ID
+//   - hidden: bool - true to hide, false to show
+//
+// Returns:
+//
+//	Success: nil
+//	Error: Error - Comment not found
+func (r *CommentsRepository) SetCommentHidden(id int, hidden bool) *customerror.CustomError {
+	return r.service.SetCommentHidden(id, hidden)
+}
@@ -0,0 +1,93 @@
+package followsrepository
+
+import (
+	"github.com/yantology/go-gin-simple-blog-with-fts/pkg/interfaces/followsinterface"
+	"github.com/yantology/go-gin-simple-blog-with-fts/pkg/models/followsmodels"
+	"github.com/yantology/go-gin-simple-blog-with-fts/pkg/utils/customerror"
+)
+
+// FollowsRepository provides methods to interact with the follows service
+type FollowsRepository struct {
+	service followsinterface.FollowRepository
+}
+
+// NewFollowsRepository creates a new instance of FollowsRepository
+// Parameters:
+//   - service: implementation of FollowRepository interface
+//
+// Returns:
+//   - *FollowsRepository: new repository instance
+func NewFollowsRepository(service followsinterface.FollowRepository) *FollowsRepository {
+	return &FollowsRepository{service: service}
+}
+
+// Follow makes a user follow another, idempotently
+// Parameters:
+//   - followerID: int - The following user
+//   - followeeID: int - The followed user
+//
+// Returns:
+//
+//	Success: nil
+//	Error: Error - User not found or database failure
+func (r *FollowsRepository) Follow(followerID int, followeeID int) *customerror.CustomError {
+	return r.service.Follow(followerID, followeeID)
+}
+
+// Unfollow makes a user stop following another, idempotently
+// Parameters:
+//   - followerID: int - The following user
+//   - followeeID: int - The followed user
+//
+// Returns:
+//
+//	Success: nil
+//	Error: Error - Database failure
+func (r *FollowsRepository) Unfollow(followerID int, followeeID int) *customerror.CustomError {
+	return r.service.Unfollow(followerID, followeeID)
+}
+
+// IsFollowing checks whether a user follows another
+// Parameters:
+//   - followerID: int - The following user
+//   - followeeID: int - The followed user
+//
+// Returns:
+//
+//	Success: (true, nil) - followerID follows followeeID
+//	Error: (false, error) - Database failure
+func (r *FollowsRepository) IsFollowing(followerID int, followeeID int) (bool, *customerror.CustomError) {
+	return r.service.IsFollowing(followerID, followeeID)
+}
+
+// GetFollowers retrieves a page of a user's followers
+// Parameters:
+//   - userID: int - The followed user
+//   - limit: int - Maximum number of users
+//   - offset: int - Number of users to skip
+//
+// Returns:
+//
+//	Success: ([]*FollowUser{
+//	  {ID: 4, Username: "budi"}
+//	}, 1, nil)
+//	Error: (nil, 0, error) - Database failure
+func (r *FollowsRepository) GetFollowers(userID int, limit int, offset int) ([]*followsmodels.FollowUser, int, *customerror.CustomError) {
+	return r.service.GetFollowers(userID, limit, offset)
+}
+
+// GetFollowing retrieves a page of the users a user follows
+// Parameters:
+//   - userID: int - The following user
+//   - limit: int - Maximum number of users
+//   - offset: int - Number of users to skip
+//
+// Returns:
+//
+//	Success: ([]*FollowUser{
+//	  {ID: 2, Username: "ani"}
+//	}, 1, nil)
+//	Error: (nil, 0, error) - Database failure
+func (r *FollowsRepository) GetFollowing(userID int, limit int, offset int) ([]*followsmodels.FollowUser, int, *customerror.CustomError) {
+	return r.service.GetFollowing(userID, limit, offset)
+}
@@ -0,0 +1,143 @@
+package importsrepository
+
+import (
+	"time"
+
+	"github.com/yantology/go-gin-simple-blog-with-fts/pkg/interfaces/importsinterface"
+	"github.com/yantology/go-gin-simple-blog-with-fts/pkg/models/importsmodels"
+	"github.com/yantology/go-gin-simple-blog-with-fts/pkg/utils/customerror"
+)
+
+// ImportsRepository provides methods to interact with the imports service
+type ImportsRepository struct {
+	service importsinterface.ImportRepository
+}
+
+// NewImportsRepository creates a new instance of ImportsRepository
+// Parameters:
+//   - service: implementation of ImportRepository interface
+//
+// Returns:
+//   - *ImportsRepository: new repository instance
+func NewImportsRepository(service importsinterface.ImportRepository) *ImportsRepository {
+	return &ImportsRepository{service: service}
+}
+
+// CreateImport records a queued import
+// Parameters:
+//   - imp: *Import - The import to insert, ID, State and CreatedAt are filled in
+//
+// Returns:
+//
+//	Success: nil
+//	Error: Error - Database failure
+func (r *ImportsRepository) CreateImport(imp *importsmodels.Import) *customerror.CustomError {
+	return r.service.CreateImport(imp)
+}
+
+// GetImportByID retrieves an import by ID
+// Parameters:
+//   - id: int - The import ID
+//
+// Returns:
+//
+//	Success: (*Import, nil) - The import and its progress
+//	Error: (nil, error) - Import not found or database failure
+func (r *ImportsRepository) GetImportByID(id int) (*importsmodels.Import, *customerror.CustomError) {
+	return r.service.GetImportByID(id)
+}
+
+// GetImportByIdempotencyKey retrieves the import a user started with an Idempotency-Key
+// Parameters:
+//   - userID: int - The user who sent the upload
+//   - key: string - The Idempotency-Key header of the upload
+//
+// Returns:
+//
+//	Success: (*Import, nil) - The import started by the first upload with the key
+//	Error: (nil, error) - No upload with the key or database failure
+func (r *ImportsRepository) GetImportByIdempotencyKey(userID int, key string) (*importsmodels.Import, *customerror.CustomError) {
+	return r.service.GetImportByIdempotencyKey(userID, key)
+}
+
+// StartImport moves a queued import to running
+// Parameters:
+//   - id: int - The import ID
+//   - leaseExpiresAt: time.Time - When the import counts as left behind unless renewed
+//
+// Returns:
+//
+//	Success: nil
+//	Error: Error - No queued import with the ID or database failure
+func (r *ImportsRepository) StartImport(id int, leaseExpiresAt time.Time) *customerror.CustomError {
+	return r.service.StartImport(id, leaseExpiresAt)
+}
+
+// UpdateImportCounts records the progress of a running import and renews its lease
+// Parameters:
+//   - id: int - The import ID
+//   - counts: Counts - Rows processed so far
+//   - leaseExpiresAt: time.Time - The new end of the lease
+//
+// Returns:
+//
+//	Success: nil
+//	Error: Error - No running import with the ID or database failure
+func (r *ImportsRepository) UpdateImportCounts(id int, counts importsmodels.Counts, leaseExpiresAt time.Time) *customerror.CustomError {
+	return r.service.UpdateImportCounts(id, counts, leaseExpiresAt)
+}
+
+// FinishImport records how an import ended
+// Parameters:
+//   - id: int - The import ID
+//   - state: string - completed, failed or cancelled
+//   - counts: Counts - Rows processed in total
+//   - reason: *string - Why the import failed, nil otherwise
+//
+// Returns:
+//
+//	Success: nil
+//	Error: Error - No unfinished import with the ID or database failure
+func (r *ImportsRepository) FinishImport(id int, state string, counts importsmodels.Counts, reason *string) *customerror.CustomError {
+	return r.service.FinishImport(id, state, counts, reason)
+}
+
+// FailExpiredImports marks every queued or running import whose lease ran out as failed
+// Parameters:
+//   - reason: string - Recorded as the error of each import
+//
+// Returns:
+//
+//	Success: (int, nil) - The number of imports marked
+//	Error: (0, error) - Database failure
+func (r *ImportsRepository) FailExpiredImports(reason string) (int, *customerror.CustomError) {
+	return r.service.FailExpiredImports(reason)
+}
+
+// AddImportRows records what became of rows of an import
+// Parameters:
+//   - importID: int - The import ID
+//   - rows: []*RowResult - The row results
+//
+// Returns:
+//
+//	Success: nil
+//	Error: Error - Database failure
+func (r *ImportsRepository) AddImportRows(importID int, rows []*importsmodels.RowResult) *customerror.CustomError {
+	return r.service.AddImportRows(importID, rows)
+}
+
+// GetImportRows retrieves a page of the row results of an import
+// Parameters:
+//   - importID: int - The import ID
+//   - status: string - Only rows with this status, empty for all
+//   - limit: int - Maximum number of rows
+//   - offset: int - Rows to skip
+//
+// Returns:
+//
+//	Success: ([]*RowResult, int, nil) - The rows in file order and the number of matching rows
+//	Error: (nil, 0, error) - Database failure
+func (r *ImportsRepository) GetImportRows(importID int, status string, limit int, offset int) ([]*importsmodels.RowResult, int, *customerror.CustomError) {
+	return r.service.GetImportRows(importID, status, limit, offset)
+}
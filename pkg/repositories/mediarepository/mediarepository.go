@@ -0,0 +1,65 @@
+package mediarepository
+
+import (
+	"github.com/yantology/go-gin-simple-blog-with-fts/pkg/interfaces/mediainterface"
+	"github.com/yantology/go-gin-simple-blog-with-fts/pkg/models/mediamodels"
+	"github.com/yantology/go-gin-simple-blog-with-fts/pkg/utils/customerror"
+)
+
+// MediaRepository provides methods to interact with the media service
+type MediaRepository struct {
+	service mediainterface.MediaRepository
+}
+
+// NewMediaRepository creates a new instance of MediaRepository
+// Parameters:
+//   - service: implementation of MediaRepository interface
+//
+// Returns:
+//   - *MediaRepository: new repository instance
+func NewMediaRepository(service mediainterface.MediaRepository) *MediaRepository {
+	return &MediaRepository{service: service}
+}
+
+// CreateMedia records an uploaded file
+// Parameters:
+//   - media: *Media - The upload to record, ID and CreatedAt are filled in
+//
+// Returns:
+//
+//	Success: nil
+//	Error: Error - Storage key already used or database failure
+func (r *MediaRepository) CreateMedia(media *mediamodels.Media) *customerror.CustomError {
+	return r.service.CreateMedia(media)
+}
+
+// GetMediaByID retrieves a media record using its unique identifier
+// Parameters:
+//   - id: int - The media's database ID
+//
+// Returns:
+//
+//	Success: (*Media{
+//	  ID: 1,
+//	  UserID: 2,
+//	  URL: "/public/media/2024/12/ab12.jpg",
+//	  ContentType: "image/jpeg",
+//	  Width: 1600,
+//	  Height: 900
+//	}, nil)
+//	Error: (nil, Error) - Media not found
+func (r *MediaRepository) GetMediaByID(id int) (*mediamodels.Media, *customerror.CustomError) {
+	return r.service.GetMediaByID(id)
+}
+
+// DeleteMediaByID removes a media record
+// Parameters:
+//   - id: int - The media's database ID
+//
+// Returns:
+//
+//	Success: nil
+//	Error: Error - Media not found
+func (r *MediaRepository) DeleteMediaByID(id int) *customerror.CustomError {
+	return r.service.DeleteMediaByID(id)
+}
@@ -0,0 +1,55 @@
+package scrapingrepository
+
+import (
+	"github.com/yantology/go-gin-simple-blog-with-fts/pkg/interfaces/scrapinginterface"
+	"github.com/yantology/go-gin-simple-blog-with-fts/pkg/models/scrapingmodels"
+	"github.com/yantology/go-gin-simple-blog-with-fts/pkg/utils/customerror"
+)
+
+// ScrapingRepository provides methods to interact with the scraping rules service
+type ScrapingRepository struct {
+	service scrapinginterface.ScrapingRuleRepository
+}
+
+// NewScrapingRepository creates a new instance of ScrapingRepository
+// Parameters:
+//   - service: implementation of ScrapingRuleRepository interface
+//
+// Returns:
+//   - *ScrapingRepository: new repository instance
+func NewScrapingRepository(service scrapinginterface.ScrapingRuleRepository) *ScrapingRepository {
+	return &ScrapingRepository{service: service}
+}
+
+// GetScrapingRules retrieves every stored scraping rule
+// Returns:
+//
+//	Success: ([]*ScrapingRule, nil) - The rules sorted by host
+//	Error: (nil, error) - Database failure
+func (r *ScrapingRepository) GetScrapingRules() ([]*scrapingmodels.ScrapingRule, *customerror.CustomError) {
+	return r.service.GetScrapingRules()
+}
+
+// SaveScrapingRule creates or replaces the scraping rule for a host
+// Parameters:
+//   - rule: *ScrapingRule - The rule to store, UpdatedAt is filled in
+//
+// Returns:
+//
+//	Success: nil
+//	Error: Error - Database failure
+func (r *ScrapingRepository) SaveScrapingRule(rule *scrapingmodels.ScrapingRule) *customerror.CustomError {
+	return r.service.SaveScrapingRule(rule)
+}
+
+// DeleteScrapingRule removes the stored scraping rule for a host
+// Parameters:
+//   - host: string - The host of the rule
+//
+// Returns:
+//
+//	Success: nil
+//	Error: Error - No rule stored for the host or database failure
+func (r *ScrapingRepository) DeleteScrapingRule(host string) *customerror.CustomError {
+	return r.service.DeleteScrapingRule(host)
+}
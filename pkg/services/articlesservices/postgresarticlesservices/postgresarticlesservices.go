@@ -2,13 +2,21 @@ package postgresarticlesservices
 
 import (
 	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
 	"log"
+	"net/http"
+	"slices"
 	"strings"
 	"time"
 
+	"github.com/lib/pq"
 	"github.com/yantology/go-gin-simple-blog-with-fts/pkg/models/articlesmodels"
 	"github.com/yantology/go-gin-simple-blog-with-fts/pkg/utils/customerror"
 	"github.com/yantology/go-gin-simple-blog-with-fts/pkg/utils/customerror/postgreserror"
+	"github.com/yantology/go-gin-simple-blog-with-fts/pkg/utils/markup"
+	"github.com/yantology/go-gin-simple-blog-with-fts/pkg/utils/slug"
 )
 
 // PostgresArticlesService provides methods to interact with articles table in PostgreSQL database
@@ -21,25 +29,127 @@ func NewPostgresArticlesService(db *sql.DB) *PostgresArticlesService {
 	return &PostgresArticlesService{db: db}
 }
 
+// articleColumns is the column list read by every article query, in scanArticle order.
+// Queries must select FROM articles without an alias for the subqueries to resolve.
+// Reactions come from the trigger-maintained article_reaction_counts table, a primary
+// key lookup per row instead of counting article_reactions.
+const articleColumns = `id, user_id, title, slug, content, format, content_html, excerpt,
+        cover_media_id, (SELECT m.url FROM media m WHERE m.id = articles.cover_media_id) AS cover_url,
+        version, created_at, updated_at, deleted_at,
+        ARRAY(SELECT t.name FROM article_tags at JOIN tags t ON t.id = at.tag_id WHERE at.article_id = articles.id ORDER BY t.name) AS tags,
+        (SELECT COALESCE(json_object_agg(rc.kind, rc.count), '{}') FROM article_reaction_counts rc
+            WHERE rc.article_id = articles.id AND rc.count > 0) AS reactions,
+        (SELECT u.username FROM users u WHERE u.id = articles.user_id) AS author,
+        source_url, content_hash`
+
+// maxTagSlugLength matches the tags.slug column
+const maxTagSlugLength = 60
+
+// maxArticleSlugLength leaves room in the articles.slug column for a collision suffix
+const maxArticleSlugLength = 80
+
+// fallbackArticleSlug is used for titles without a single letter or digit
+const fallbackArticleSlug = "artikel"
+
+// maxExcerptLength is the number of characters kept in articles.excerpt
+const maxExcerptLength = 200
+
+// renderContent derives the cached content_html and excerpt columns from content
+func renderContent(format string, content string) (string, string, error) {
+	contentHTML, err := markup.Render(format, content)
+	if err != nil {
+		return "", "", err
+	}
+	return contentHTML, markup.Excerpt(contentHTML, maxExcerptLength), nil
+}
+
+// tagFilter restricts a query to articles carrying every tag slug in the $n text[] parameter.
+// A NULL parameter disables the filter.
+func tagFilter(n int) string {
+	return fmt.Sprintf(`($%[1]d::text[] IS NULL OR id IN (
+            SELECT at.article_id FROM article_tags at JOIN tags t ON t.id = at.tag_id
+            WHERE t.slug = ANY($%[1]d::text[])
+            GROUP BY at.article_id
+            HAVING COUNT(*) = cardinality($%[1]d::text[])))`, n)
+}
+
+// rowScanner is implemented by both *sql.Row and *sql.Rows
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+// scanArticle reads one row selected with articleColumns into an Article
+func scanArticle(row rowScanner) (*articlesmodels.Article, error) {
+	var article articlesmodels.Article
+	var reactions []byte
+	if err := row.Scan(&article.ID, &article.UserID, &article.Title, &article.Slug, &article.Content, &article.Format, &article.ContentHTML, &article.Excerpt, &article.CoverMediaID, &article.CoverURL, &article.Version, &article.CreatedAt, &article.UpdatedAt, &article.DeletedAt, pq.Array(&article.Tags), &reactions, &article.Author, &article.SourceURL, &article.ContentHash); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(reactions, &article.Reactions); err != nil {
+		return nil, err
+	}
+	return &article, nil
+}
+
+// queryArticles runs a query selecting articleColumns and scans every row
+func (r *PostgresArticlesService) queryArticles(query string, args ...any) ([]*articlesmodels.Article, *customerror.CustomError) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, postgreserror.NewPostgresError(err)
+	}
+	defer rows.Close()
+
+	var articles []*articlesmodels.Article
+	for rows.Next() {
+		article, err := scanArticle(rows)
+		if err != nil {
+			return nil, postgreserror.NewPostgresError(err)
+		}
+		articles = append(articles, article)
+	}
+
+	return articles, nil
+}
+
 // GetArticleByID retrieves a single article by its ID
-// Query: Selects all fields from articles table where id matches
+// Query: Selects all fields from articles table where id matches and the article is not in the trash
 // Returns:
 // - Success: *Article{ID: 1, Title: "Sample Article", Content: "Content here"...}
 // - Error: sql.ErrNoRows if article not found, or any other DB error
 func (r *PostgresArticlesService) GetArticleByID(id int) (*articlesmodels.Article, *customerror.CustomError) {
-	query := "SELECT id, user_id, title, content, created_at, updated_at FROM articles WHERE id = $1"
+	query := "SELECT " + articleColumns + " FROM articles WHERE id = $1 AND deleted_at IS NULL"
 	row := r.db.QueryRow(query, id)
 
-	var article articlesmodels.Article
-	if err := row.Scan(&article.ID, &article.UserID, &article.Title, &article.Content, &article.CreatedAt, &article.UpdatedAt); err != nil {
+	article, err := scanArticle(row)
+	if err != nil {
 		return nil, postgreserror.NewPostgresError(err)
 	}
 
-	return &article, nil
+	return article, nil
+}
+
+// GetArticleBySlug retrieves a single article by its current or a previous slug
+// Query: Selects the article whose slug matches, or whose slug history contains it, if not in the trash
+// Returns:
+// - Success: *Article{ID: 1, Title: "Sample Article", Slug: "sample-article"...}; Slug differs from the
+// requested slug when an old slug was used
+// - Error: sql.ErrNoRows if article not found, or any other DB error
+func (r *PostgresArticlesService) GetArticleBySlug(articleSlug string) (*articlesmodels.Article, *customerror.CustomError) {
+	query := `
+        SELECT ` + articleColumns + `
+        FROM articles
+        WHERE deleted_at IS NULL
+          AND (slug = $1 OR id = (SELECT article_id FROM article_slug_history WHERE slug = $1))`
+	article, err := scanArticle(r.db.QueryRow(query, articleSlug))
+	if err != nil {
+		return nil, postgreserror.NewPostgresError(err)
+	}
+
+	return article, nil
 }
 
 // GetArticlesByUserID retrieves all articles created by a specific user
-// Query: Selects all articles where user_id matches the specified ID
+// Query: Selects all articles where user_id matches the specified ID, excluding trashed ones
 // Returns:
 //   - Success: []*Article{
 //     {ID: 1, Title: "Article 1"...},
@@ -47,51 +157,79 @@ func (r *PostgresArticlesService) GetArticleByID(id int) (*articlesmodels.Articl
 //     }
 //   - Error: Database errors if query fails
 func (r *PostgresArticlesService) GetArticlesByUserID(userID int) ([]*articlesmodels.Article, *customerror.CustomError) {
-	query := "SELECT id, user_id, title, content, created_at, updated_at FROM articles WHERE user_id = $1"
-	rows, err := r.db.Query(query, userID)
-	if err != nil {
-		return nil, postgreserror.NewPostgresError(err)
-	}
-	defer rows.Close()
-
-	var articles []*articlesmodels.Article
-	for rows.Next() {
-		var article articlesmodels.Article
-		if err := rows.Scan(&article.ID, &article.UserID, &article.Title, &article.Content, &article.CreatedAt, &article.UpdatedAt); err != nil {
-			return nil, postgreserror.NewPostgresError(err)
-		}
-		articles = append(articles, &article)
-	}
-
-	return articles, nil
+	query := "SELECT " + articleColumns + " FROM articles WHERE user_id = $1 AND deleted_at IS NULL"
+	return r.queryArticles(query, userID)
 }
 
 // GetAllArticles retrieves all articles from the database
-// Query: Selects all articles without any conditions
+// Query: Selects all articles that are not in the trash, optionally only those carrying every tag in tags
 // Returns:
 //   - Success: []*Article{
 //     {ID: 1, Title: "Article 1"...},
 //     {ID: 2, Title: "Article 2"...},
 //     }
 //   - Error: Database errors if query fails
-func (r *PostgresArticlesService) GetAllArticles() ([]*articlesmodels.Article, *customerror.CustomError) {
-	query := "SELECT id, user_id, title, content, created_at, updated_at FROM articles"
+func (r *PostgresArticlesService) GetAllArticles(tags []string) ([]*articlesmodels.Article, *customerror.CustomError) {
+	query := "SELECT " + articleColumns + " FROM articles WHERE deleted_at IS NULL AND " + tagFilter(1)
+	return r.queryArticles(query, pq.Array(tags))
+}
+
+// GetLatestArticles retrieves a page of the most recently created articles
+// Query: Selects articles that are not in the trash, optionally only those carrying every tag in tags, newest first
+// Returns:
+//   - Success: []*Article{
+//     {ID: 9, Title: "Golang Generics", CreatedAt: 2024-12-11...},
+//     {ID: 8, Title: "Indexing in PostgreSQL", CreatedAt: 2024-12-10...},
+//     }
+//   - Error: Database errors if query fails
+func (r *PostgresArticlesService) GetLatestArticles(limit int, offset int, tags []string) ([]*articlesmodels.Article, *customerror.CustomError) {
+	query := `
+        SELECT ` + articleColumns + `
+        FROM articles
+        WHERE deleted_at IS NULL AND ` + tagFilter(3) + `
+        ORDER BY created_at DESC, id DESC
+        LIMIT $1 OFFSET $2`
+	return r.queryArticles(query, limit, offset, pq.Array(tags))
+}
+
+// GetLatestArticlesByUserID retrieves a page of the most recently created articles of a user
+// Query: Selects articles of the user that are not in the trash, newest first
+// Returns:
+//   - Success: []*Article{
+//     {ID: 9, UserID: 2, Title: "Golang Generics"...},
+//     }
+//   - Error: Database errors if query fails
+func (r *PostgresArticlesService) GetLatestArticlesByUserID(userID int, limit int, offset int) ([]*articlesmodels.Article, *customerror.CustomError) {
+	query := "SELECT " + articleColumns + " FROM articles WHERE user_id = $1 AND deleted_at IS NULL ORDER BY created_at DESC, id DESC LIMIT $2 OFFSET $3"
+	return r.queryArticles(query, userID, limit, offset)
+}
+
+// GetArticleLinks retrieves the slug and modification time of every visible article
+// Query: Selects a few columns of every article that is not in the trash, oldest first,
+// leaving out the content so even large blogs are read quickly
+// Returns:
+//   - Success: []*ArticleLink{
+//     {ID: 1, Slug: "belajar-go", UpdatedAt: 2024-12-10...},
+//     }
+//   - Error: Database errors if query fails
+func (r *PostgresArticlesService) GetArticleLinks() ([]*articlesmodels.ArticleLink, *customerror.CustomError) {
+	query := "SELECT id, user_id, slug, updated_at FROM articles WHERE deleted_at IS NULL ORDER BY id"
 	rows, err := r.db.Query(query)
 	if err != nil {
 		return nil, postgreserror.NewPostgresError(err)
 	}
 	defer rows.Close()
 
-	var articles []*articlesmodels.Article
+	var links []*articlesmodels.ArticleLink
 	for rows.Next() {
-		var article articlesmodels.Article
-		if err := rows.Scan(&article.ID, &article.UserID, &article.Title, &article.Content, &article.CreatedAt, &article.UpdatedAt); err != nil {
+		var link articlesmodels.ArticleLink
+		if err := rows.Scan(&link.ID, &link.UserID, &link.Slug, &link.UpdatedAt); err != nil {
 			return nil, postgreserror.NewPostgresError(err)
 		}
-		articles = append(articles, &article)
+		links = append(links, &link)
 	}
 
-	return articles, nil
+	return links, nil
 }
 
 // SearchArticles performs full-text search on articles using PostgreSQL's tsvector
@@ -100,90 +238,932 @@ func (r *PostgresArticlesService) GetAllArticles() ([]*articlesmodels.Article, *
 // - limit: maximum number of results
 // - offset: number of results to skip
 // - query: search terms (e.g., "golang programming")
+// - tags: tag slugs every result must carry, nil for no filter
 // Returns:
 //   - Success: []*Article matching search terms, ordered by relevance
 //     Example: query="golang" -> [{Title: "Intro to Golang"}, {Title: "Golang Tips"}]
 //   - Error: Database errors or invalid search query
-func (r *PostgresArticlesService) SearchArticles(limit int, offset int, query string) ([]*articlesmodels.Article, *customerror.CustomError) {
+func (r *PostgresArticlesService) SearchArticles(limit int, offset int, query string, tags []string) ([]*articlesmodels.Article, *customerror.CustomError) {
 	// Convert search query to tsquery format and use indonesian dictionary
 	searchQuery := `
-        SELECT id, user_id, title, content, created_at, updated_at 
-        FROM articles 
-        WHERE tsv @@ to_tsquery('indonesian', $1)
+        SELECT ` + articleColumns + `
+        FROM articles
+        WHERE tsv @@ to_tsquery('indonesian', $1) AND deleted_at IS NULL AND ` + tagFilter(4) + `
         ORDER BY ts_rank(tsv, to_tsquery('indonesian', $1)) DESC
         LIMIT $2 OFFSET $3`
 
 	// Convert space-separated words to tsquery format (word1 & word2)
 	formattedQuery := strings.Join(strings.Fields(query), " & ")
 
-	rows, err := r.db.Query(searchQuery, formattedQuery, limit, offset)
+	log.Printf("Search query: %s", formattedQuery)
+	return r.queryArticles(searchQuery, formattedQuery, limit, offset, pq.Array(tags))
+}
+
+// CreateArticle creates a new article in the database for article.UserID.
+// It inserts the article and links its tags in one transaction, creating tags
+// that do not exist yet.
+//
+// The article is created with current timestamp for both created_at and updated_at fields,
+// or with article.CreatedAt when it is set, as for imported articles published before.
+// Its slug is derived from the title, with a numeric suffix when another article
+// already uses or used it. Content is rendered to sanitized HTML according to
+// article.Format and stored alongside it. Imported articles carry their SourceURL,
+// which the user cannot have imported before.
+// On success article.ID, Slug, ContentHTML, Excerpt, Version, CreatedAt and UpdatedAt
+// are filled from the new row.
+//
+// Returns nil on successful creation. If the operation fails due to database constraints
+// or connection issues, returns a wrapped custom error.
+func (r *PostgresArticlesService) CreateArticle(article *articlesmodels.Article) *customerror.CustomError {
+	tx, err := r.db.Begin()
 	if err != nil {
-		return nil, postgreserror.NewPostgresError(err)
+		return postgreserror.NewPostgresError(err)
 	}
-	defer rows.Close()
+	defer tx.Rollback()
 
-	var articles []*articlesmodels.Article
+	articleSlug, err := uniqueArticleSlug(tx, 0, article.Title, "", nil)
+	if err != nil {
+		return postgreserror.NewPostgresError(err)
+	}
 
-	log.Printf("Search query: %s", formattedQuery)
-	for rows.Next() {
-		var article articlesmodels.Article
-		if err := rows.Scan(&article.ID, &article.UserID, &article.Title, &article.Content, &article.CreatedAt, &article.UpdatedAt); err != nil {
-			return nil, postgreserror.NewPostgresError(err)
-		}
-		articles = append(articles, &article)
+	contentHTML, excerpt, err := renderContent(article.Format, article.Content)
+	if err != nil {
+		return postgreserror.NewPostgresError(err)
 	}
 
-	return articles, nil
+	createdAt := article.CreatedAt
+	if createdAt.IsZero() {
+		createdAt = time.Now()
+	}
+
+	query := `
+        INSERT INTO articles (user_id, title, slug, content, format, content_html, excerpt, cover_media_id, source_url, content_hash, created_at, updated_at)
+        VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $11)
+        RETURNING id, slug, content_html, excerpt, version, created_at, updated_at`
+	err = tx.QueryRow(query, article.UserID, article.Title, articleSlug, article.Content, article.Format, contentHTML, excerpt, article.CoverMediaID,
+		article.SourceURL, article.ContentHash, createdAt).
+		Scan(&article.ID, &article.Slug, &article.ContentHTML, &article.Excerpt, &article.Version, &article.CreatedAt, &article.UpdatedAt)
+	if err != nil {
+		return postgreserror.NewPostgresError(err)
+	}
+
+	if _, err := setArticleTags(tx, article.ID, article.Tags); err != nil {
+		return postgreserror.NewPostgresError(err)
+	}
+
+	if err := addArticlesSources(tx, []*articlesmodels.Article{article}); err != nil {
+		return postgreserror.NewPostgresError(err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return postgreserror.NewPostgresError(err)
+	}
+	return nil
 }
 
-// CreateArticle creates a new article in the database for the specified user.
-// It takes the user ID, title, and content as input parameters and returns
-// a custom error if the operation fails.
+// articlesPerInsert bounds the rows of one multi-row INSERT, keeping it under
+// the 65535 parameters Postgres accepts
+const articlesPerInsert = 500
+
+// CreateArticles creates many articles in the database in one transaction:
+// all of them, or none when any fails. Articles are inserted with multi-row
+// INSERT statements and their tags linked with one statement, instead of a
+// transaction per article as CreateArticle does.
 //
-// The article is created with current timestamp for both created_at and updated_at fields.
+// Slugs, content rendering, timestamps and the fields filled in on success are
+// as for CreateArticle. Articles with the same title get numbered slugs.
 //
-// Returns nil on successful creation. If the operation fails due to database constraints
-// or connection issues, returns a wrapped custom error.
-func (r *PostgresArticlesService) CreateArticle(userId int, title string, content string) *customerror.CustomError {
-	query := "INSERT INTO articles (user_id, title, content, created_at, updated_at) VALUES ($1, $2, $3, $4, $5)"
-	_, err := r.db.Exec(query, userId, title, content, time.Now(), time.Now())
+// Returns nil on successful creation. If any article violates a constraint, such
+// as a SourceURL imported before, or the database fails, nothing is created and
+// a wrapped custom error is returned.
+func (r *PostgresArticlesService) CreateArticles(articles []*articlesmodels.Article) *customerror.CustomError {
+	if len(articles) == 0 {
+		return nil
+	}
+
+	tx, err := r.db.Begin()
 	if err != nil {
 		return postgreserror.NewPostgresError(err)
 	}
+	defer tx.Rollback()
+
+	// Slugs are taken in order of their base, so concurrent batches lock the
+	// bases in the same order and cannot deadlock
+	order := make([]int, len(articles))
+	bases := make([]string, len(articles))
+	for i, article := range articles {
+		order[i] = i
+		bases[i] = articleSlugBase(article.Title)
+	}
+	slices.SortStableFunc(order, func(a, b int) int {
+		return strings.Compare(bases[a], bases[b])
+	})
+	slugs := make([]string, len(articles))
+	reserved := map[string]bool{}
+	for _, i := range order {
+		articleSlug, err := uniqueArticleSlug(tx, 0, articles[i].Title, "", reserved)
+		if err != nil {
+			return postgreserror.NewPostgresError(err)
+		}
+		slugs[i] = articleSlug
+		reserved[articleSlug] = true
+	}
+
+	now := time.Now()
+	for start := 0; start < len(articles); start += articlesPerInsert {
+		chunk := articles[start:min(start+articlesPerInsert, len(articles))]
+
+		var query strings.Builder
+		query.WriteString("INSERT INTO articles (user_id, title, slug, content, format, content_html, excerpt, cover_media_id, source_url, content_hash, created_at, updated_at) VALUES ")
+		args := make([]any, 0, len(chunk)*11)
+		bySlug := map[string]*articlesmodels.Article{}
+		for i, article := range chunk {
+			contentHTML, excerpt, err := renderContent(article.Format, article.Content)
+			if err != nil {
+				return postgreserror.NewPostgresError(err)
+			}
+			createdAt := article.CreatedAt
+			if createdAt.IsZero() {
+				createdAt = now
+			}
+
+			if i > 0 {
+				query.WriteString(", ")
+			}
+			n := len(args)
+			fmt.Fprintf(&query, "($%d, $%d, $%d, $%d, $%d, $%d, $%d, $%d, $%d, $%d, $%d, $%d)",
+				n+1, n+2, n+3, n+4, n+5, n+6, n+7, n+8, n+9, n+10, n+11, n+11)
+			articleSlug := slugs[start+i]
+			args = append(args, article.UserID, article.Title, articleSlug, article.Content, article.Format, contentHTML, excerpt,
+				article.CoverMediaID, article.SourceURL, article.ContentHash, createdAt)
+			bySlug[articleSlug] = article
+		}
+		// Rows come back matched by their slug, unique among them
+		query.WriteString(" RETURNING id, slug, content_html, excerpt, version, created_at, updated_at")
+
+		rows, err := tx.Query(query.String(), args...)
+		if err != nil {
+			return postgreserror.NewPostgresError(err)
+		}
+		for rows.Next() {
+			var inserted articlesmodels.Article
+			if err := rows.Scan(&inserted.ID, &inserted.Slug, &inserted.ContentHTML, &inserted.Excerpt, &inserted.Version, &inserted.CreatedAt, &inserted.UpdatedAt); err != nil {
+				rows.Close()
+				return postgreserror.NewPostgresError(err)
+			}
+			article := bySlug[inserted.Slug]
+			article.ID, article.Slug, article.ContentHTML, article.Excerpt = inserted.ID, inserted.Slug, inserted.ContentHTML, inserted.Excerpt
+			article.Version, article.CreatedAt, article.UpdatedAt = inserted.Version, inserted.CreatedAt, inserted.UpdatedAt
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return postgreserror.NewPostgresError(err)
+		}
+	}
+
+	if err := addArticlesTags(tx, articles); err != nil {
+		return postgreserror.NewPostgresError(err)
+	}
+
+	if err := addArticlesSources(tx, articles); err != nil {
+		return postgreserror.NewPostgresError(err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return postgreserror.NewPostgresError(err)
+	}
 	return nil
 }
 
-// UpdateArticle updates an existing article in the database with the provided title and content.
-// The article's updated_at timestamp is automatically set to the current time.
+// addArticlesTags links new articles to their tags, creating each tag that does
+// not exist yet once for all of them.
+func addArticlesTags(tx *sql.Tx, articles []*articlesmodels.Article) error {
+	tagIDs := map[string]int64{}
+	articleIDs := []int64{}
+	linkedTagIDs := []int64{}
+	for _, article := range articles {
+		for _, name := range article.Tags {
+			tagID, ok := tagIDs[name]
+			if !ok {
+				// DO UPDATE instead of DO NOTHING so RETURNING also yields existing rows
+				upsertQuery := `
+                    INSERT INTO tags (name, slug) VALUES ($1, $2)
+                    ON CONFLICT (slug) DO UPDATE SET slug = EXCLUDED.slug
+                    RETURNING id`
+				if err := tx.QueryRow(upsertQuery, name, slug.Make(name, maxTagSlugLength)).Scan(&tagID); err != nil {
+					return err
+				}
+				tagIDs[name] = tagID
+			}
+			articleIDs = append(articleIDs, int64(article.ID))
+			linkedTagIDs = append(linkedTagIDs, tagID)
+		}
+	}
+	if len(articleIDs) == 0 {
+		return nil
+	}
+
+	insertQuery := `
+        INSERT INTO article_tags (article_id, tag_id)
+        SELECT unnest($1::int[]), unnest($2::int[])
+        ON CONFLICT DO NOTHING`
+	_, err := tx.Exec(insertQuery, pq.Array(articleIDs), pq.Array(linkedTagIDs))
+	return err
+}
+
+// addArticlesSources stores the sources of the new articles that have one
+func addArticlesSources(tx *sql.Tx, articles []*articlesmodels.Article) error {
+	for _, article := range articles {
+		if article.Source == nil {
+			continue
+		}
+		article.Source.ArticleID = article.ID
+		if _, err := tx.Exec(saveArticleSourceQuery, saveArticleSourceArgs(article.Source)...); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// uniqueArticleSlug derives a slug from title that no other article uses or used before.
+// Collisions get the first free numeric suffix: "judul", "judul-2", "judul-3".
+// currentSlug is kept when it already belongs to the title, so retitling "Go Tips"
+// to "Go tips!" does not move the article. articleID is 0 for a new article.
+// reserved holds slugs given to articles of tx that are not inserted yet.
+func uniqueArticleSlug(tx *sql.Tx, articleID int, title string, currentSlug string, reserved map[string]bool) (string, error) {
+	base := articleSlugBase(title)
+	if currentSlug == base || isNumberedSlug(currentSlug, base) {
+		return currentSlug, nil
+	}
+
+	// Serialize writers competing for the same base slug until this transaction ends
+	if _, err := tx.Exec("SELECT pg_advisory_xact_lock(hashtext($1))", base); err != nil {
+		return "", err
+	}
+
+	query := `
+        SELECT slug FROM articles WHERE id <> $1 AND (slug = $2 OR slug LIKE $2 || '-%')
+        UNION
+        SELECT slug FROM article_slug_history WHERE article_id <> $1 AND (slug = $2 OR slug LIKE $2 || '-%')`
+	rows, err := tx.Query(query, articleID, base)
+	if err != nil {
+		return "", err
+	}
+	defer rows.Close()
+
+	taken := map[string]bool{}
+	for rows.Next() {
+		var s string
+		if err := rows.Scan(&s); err != nil {
+			return "", err
+		}
+		taken[s] = true
+	}
+	if err := rows.Err(); err != nil {
+		return "", err
+	}
+
+	candidate := base
+	for n := 2; taken[candidate] || reserved[candidate]; n++ {
+		candidate = fmt.Sprintf("%s-%d", base, n)
+	}
+	return candidate, nil
+}
+
+// articleSlugBase is the slug of title before collisions are resolved
+func articleSlugBase(title string) string {
+	base := slug.MakeIndonesian(title, maxArticleSlugLength)
+	if base == "" {
+		return fallbackArticleSlug
+	}
+	return base
+}
+
+// isNumberedSlug reports whether s is base with a collision suffix such as "-2"
+func isNumberedSlug(s string, base string) bool {
+	suffix, ok := strings.CutPrefix(s, base+"-")
+	if !ok || suffix == "" {
+		return false
+	}
+	for _, c := range suffix {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// setArticleTags makes names the exact tag set of an article.
+// Missing tags are created, keyed by their slug, so "Golang" and "golang" are the same tag.
+// It reports whether the article's tag set changed.
+func setArticleTags(tx *sql.Tx, articleID int, names []string) (bool, error) {
+	tagIDs := []int64{}
+	for _, name := range names {
+		var tagID int64
+		// DO UPDATE instead of DO NOTHING so RETURNING also yields existing rows
+		upsertQuery := `
+            INSERT INTO tags (name, slug) VALUES ($1, $2)
+            ON CONFLICT (slug) DO UPDATE SET slug = EXCLUDED.slug
+            RETURNING id`
+		if err := tx.QueryRow(upsertQuery, name, slug.Make(name, maxTagSlugLength)).Scan(&tagID); err != nil {
+			return false, err
+		}
+		tagIDs = append(tagIDs, tagID)
+	}
+
+	deleteQuery := "DELETE FROM article_tags WHERE article_id = $1 AND tag_id <> ALL($2)"
+	deleted, err := tx.Exec(deleteQuery, articleID, pq.Array(tagIDs))
+	if err != nil {
+		return false, err
+	}
+
+	insertQuery := `
+        INSERT INTO article_tags (article_id, tag_id)
+        SELECT $1, unnest($2::int[])
+        ON CONFLICT DO NOTHING`
+	inserted, err := tx.Exec(insertQuery, articleID, pq.Array(tagIDs))
+	if err != nil {
+		return false, err
+	}
+
+	deletedCount, err := deleted.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	insertedCount, err := inserted.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return deletedCount > 0 || insertedCount > 0, nil
+}
+
+// PatchArticle updates only the columns set in changes.
+// The article's updated_at timestamp is automatically set to the current time and its
+// version is incremented.
+// A new title also moves the article to a new slug; the old slug is kept in
+// article_slug_history so existing links keep resolving.
+// New content or a new format re-renders the cached HTML and excerpt.
+// When the title, content or format changes, the current values are first copied into
+// article_revisions so the change can be reviewed or undone later. All statements run in
+// one transaction and the article row is locked so concurrent edits get consecutive
+// revision numbers. Values equal to the stored ones are not written: changes that
+// differ in nothing only check ifMatch, adding no revision and keeping the version.
 //
 // Parameters:
 //   - articleId: The unique identifier of the article to update
-//   - title: The new title for the article
-//   - content: The new content for the article
+//   - editorID: The ID of the user making the change
+//   - changes: The columns to write
+//   - ifMatch: versions the caller expects the article to be at, nil to skip the check
 //
 // Returns a *customerror.CustomError which is:
 //   - nil if the update was successful
+//   - 412 error if the current version is not in ifMatch
 //   - wrapped database error if the operation fails or article is not found
-func (r *PostgresArticlesService) UpdateArticle(articleId int, title string, content string) *customerror.CustomError {
-	query := "UPDATE articles SET title = $1, content = $2, updated_at = $3 WHERE id = $4"
-	_, err := r.db.Exec(query, title, content, time.Now(), articleId)
+func (r *PostgresArticlesService) PatchArticle(articleId int, editorID int, changes *articlesmodels.ArticleChanges, ifMatch []int) *customerror.CustomError {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return postgreserror.NewPostgresError(err)
+	}
+	defer tx.Rollback()
+
+	var oldTitle, oldSlug, oldContent, oldFormat string
+	var oldCoverMediaID sql.NullInt64
+	var oldContentHash sql.NullString
+	var version int
+	lockQuery := "SELECT title, slug, content, format, cover_media_id, content_hash, version FROM articles WHERE id = $1 AND deleted_at IS NULL FOR UPDATE"
+	if err := tx.QueryRow(lockQuery, articleId).Scan(&oldTitle, &oldSlug, &oldContent, &oldFormat, &oldCoverMediaID, &oldContentHash, &version); err != nil {
+		return postgreserror.NewPostgresError(err)
+	}
+
+	if ifMatch != nil && !slices.Contains(ifMatch, version) {
+		return newPreconditionFailedError()
+	}
+
+	tagsChanged := false
+	if changes.Tags != nil {
+		if tagsChanged, err = setArticleTags(tx, articleId, *changes.Tags); err != nil {
+			return postgreserror.NewPostgresError(err)
+		}
+	}
+
+	var sets []string
+	var args []any
+	if changes.Title != nil && *changes.Title != oldTitle {
+		args = append(args, *changes.Title)
+		sets = append(sets, fmt.Sprintf("title = $%d", len(args)))
+
+		newSlug, err := uniqueArticleSlug(tx, articleId, *changes.Title, oldSlug, nil)
+		if err != nil {
+			return postgreserror.NewPostgresError(err)
+		}
+		if newSlug != oldSlug {
+			if err := moveArticleSlug(tx, articleId, oldSlug, newSlug); err != nil {
+				return postgreserror.NewPostgresError(err)
+			}
+			args = append(args, newSlug)
+			sets = append(sets, fmt.Sprintf("slug = $%d", len(args)))
+		}
+	}
+	contentChanged := changes.Content != nil && *changes.Content != oldContent
+	formatChanged := changes.Format != nil && *changes.Format != oldFormat
+	if contentChanged || formatChanged {
+		content, format := oldContent, oldFormat
+		if contentChanged {
+			content = *changes.Content
+			args = append(args, content)
+			sets = append(sets, fmt.Sprintf("content = $%d", len(args)))
+		}
+		if formatChanged {
+			format = *changes.Format
+			args = append(args, format)
+			sets = append(sets, fmt.Sprintf("format = $%d", len(args)))
+		}
+
+		contentHTML, excerpt, err := renderContent(format, content)
+		if err != nil {
+			return postgreserror.NewPostgresError(err)
+		}
+		args = append(args, contentHTML, excerpt)
+		sets = append(sets, fmt.Sprintf("content_html = $%d, excerpt = $%d", len(args)-1, len(args)))
+	}
+	if len(sets) > 0 {
+		// title, content and format are versioned, the cover is not
+		revisionQuery := `
+            INSERT INTO article_revisions (article_id, revision, title, content, format, editor_id, created_at)
+            VALUES ($1, (SELECT COALESCE(MAX(revision), 0) + 1 FROM article_revisions WHERE article_id = $1), $2, $3, $4, $5, $6)`
+		if _, err := tx.Exec(revisionQuery, articleId, oldTitle, oldContent, oldFormat, editorID, time.Now()); err != nil {
+			return postgreserror.NewPostgresError(err)
+		}
+	}
+	if changes.CoverMediaID != nil && int64(*changes.CoverMediaID) != oldCoverMediaID.Int64 {
+		args = append(args, *changes.CoverMediaID)
+		sets = append(sets, fmt.Sprintf("cover_media_id = NULLIF($%d, 0)", len(args)))
+	}
+	if changes.ContentHash != nil && (!oldContentHash.Valid || *changes.ContentHash != oldContentHash.String) {
+		args = append(args, *changes.ContentHash)
+		sets = append(sets, fmt.Sprintf("content_hash = $%d", len(args)))
+	}
+	if len(sets) == 0 && !tagsChanged {
+		// Nothing differs from the stored article
+		return nil
+	}
+
+	args = append(args, time.Now())
+	sets = append(sets, "version = version + 1", fmt.Sprintf("updated_at = $%d", len(args)))
+	args = append(args, articleId)
+	query := fmt.Sprintf("UPDATE articles SET %s WHERE id = $%d", strings.Join(sets, ", "), len(args))
+	if _, err := tx.Exec(query, args...); err != nil {
+		return postgreserror.NewPostgresError(err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return postgreserror.NewPostgresError(err)
+	}
+	return nil
+}
+
+// moveArticleSlug records oldSlug as a redirect to the article and takes newSlug out
+// of its history, in case the article is given back an earlier title
+func moveArticleSlug(tx *sql.Tx, articleID int, oldSlug string, newSlug string) error {
+	insertQuery := `
+        INSERT INTO article_slug_history (slug, article_id, created_at) VALUES ($1, $2, $3)
+        ON CONFLICT (slug) DO NOTHING`
+	if _, err := tx.Exec(insertQuery, oldSlug, articleID, time.Now()); err != nil {
+		return err
+	}
+
+	deleteQuery := "DELETE FROM article_slug_history WHERE slug = $1 AND article_id = $2"
+	if _, err := tx.Exec(deleteQuery, newSlug, articleID); err != nil {
+		return err
+	}
+	return nil
+}
+
+// DeleteArticleByID moves an article to the trash
+// Query: Sets deleted_at on the article matching the specified ID, and the expected version when ifMatch is set.
+// Trashed articles are hidden from every other query until restored or purged.
+// Returns:
+// - Success: nil (article successfully moved to the trash)
+// - Error: 412 if the version does not match, Article not found or database errors
+func (r *PostgresArticlesService) DeleteArticleByID(id int, ifMatch []int) *customerror.CustomError {
+	query := `
+        UPDATE articles SET deleted_at = $2
+        WHERE id = $1 AND deleted_at IS NULL AND ($3::int[] IS NULL OR version = ANY($3))`
+	result, err := r.db.Exec(query, id, time.Now(), pq.Array(ifMatch))
+	if err != nil {
+		return postgreserror.NewPostgresError(err)
+
+	}
+
+	affected, err := result.RowsAffected()
 	if err != nil {
 		return postgreserror.NewPostgresError(err)
 	}
+	if affected == 0 {
+		// Either the article is gone or its version moved on
+		if _, cuserr := r.GetArticleByID(id); cuserr != nil {
+			return cuserr
+		}
+		return newPreconditionFailedError()
+	}
 	return nil
 }
 
-// DeleteArticleByID removes an article from the database
-// Query: Deletes article matching the specified ID
+// GetArticleBySourceURL retrieves the article a user imported from a web page
+// Query: Selects the article of the user with the source URL, in the trash or not
 // Returns:
-// - Success: nil (article successfully deleted)
-// - Error: Article not found or database errors
-func (r *PostgresArticlesService) DeleteArticleByID(id int) *customerror.CustomError {
-	query := "DELETE FROM articles WHERE id = $1"
-	_, err := r.db.Exec(query, id)
+// - Success: *Article{ID: 7, SourceURL: "https://news.example.com/a", ContentHash: "9f86d0..."...}
+// - Error: sql.ErrNoRows if the user imported no article from the URL, or any other DB error
+func (r *PostgresArticlesService) GetArticleBySourceURL(userID int, sourceURL string) (*articlesmodels.Article, *customerror.CustomError) {
+	query := "SELECT " + articleColumns + " FROM articles WHERE user_id = $1 AND source_url = $2"
+	article, err := scanArticle(r.db.QueryRow(query, userID, sourceURL))
 	if err != nil {
+		return nil, postgreserror.NewPostgresError(err)
+	}
+
+	return article, nil
+}
+
+// saveArticleSourceQuery inserts the source of an article or replaces the stored one
+const saveArticleSourceQuery = `
+        INSERT INTO article_sources (article_id, refresh_interval, title_from_page, etag, last_modified, checked_at, next_check_at, changed_at, failures, last_error,
+            page_hash)
+        VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+        ON CONFLICT (article_id) DO UPDATE SET
+            refresh_interval = EXCLUDED.refresh_interval,
+            title_from_page = EXCLUDED.title_from_page,
+            etag = EXCLUDED.etag,
+            last_modified = EXCLUDED.last_modified,
+            checked_at = EXCLUDED.checked_at,
+            next_check_at = EXCLUDED.next_check_at,
+            changed_at = EXCLUDED.changed_at,
+            failures = EXCLUDED.failures,
+            last_error = EXCLUDED.last_error,
+            page_hash = EXCLUDED.page_hash`
+
+// saveArticleSourceArgs are the parameters of saveArticleSourceQuery for source
+func saveArticleSourceArgs(source *articlesmodels.ArticleSource) []any {
+	return []any{source.ArticleID, source.RefreshInterval, source.TitleFromPage, source.ETag, source.LastModified,
+		source.CheckedAt, source.NextCheckAt, source.ChangedAt, source.Failures, source.LastError, source.PageHash}
+}
+
+// SaveArticleSource stores how an imported article follows its page
+// Query: Inserts the source of the article, or replaces every column of the stored one
+// Returns:
+// - Success: nil
+// - Error: a foreign key violation if the article does not exist, or any other DB error
+func (r *PostgresArticlesService) SaveArticleSource(source *articlesmodels.ArticleSource) *customerror.CustomError {
+	if _, err := r.db.Exec(saveArticleSourceQuery, saveArticleSourceArgs(source)...); err != nil {
 		return postgreserror.NewPostgresError(err)
+	}
+	return nil
+}
+
+// ClaimDueArticleSources takes the sources due to be checked
+// Query: Moves the next check of the most overdue sources lease ahead, skipping
+// sources another process is claiming, and returns them with their next check
+// as it was
+// Returns:
+// - Success: []*ArticleSource{{ArticleID: 7, ETag: "\"v2\""...}}, empty when none is due
+// - Error: Database errors
+func (r *PostgresArticlesService) ClaimDueArticleSources(limit int, lease time.Duration) ([]*articlesmodels.ArticleSource, *customerror.CustomError) {
+	query := `
+        WITH due AS (
+            SELECT article_id, next_check_at FROM article_sources
+            WHERE next_check_at <= NOW()
+            ORDER BY next_check_at
+            LIMIT $1
+            FOR UPDATE SKIP LOCKED
+        )
+        UPDATE article_sources s SET next_check_at = NOW() + $2 * INTERVAL '1 second'
+        FROM due WHERE s.article_id = due.article_id
+        RETURNING s.article_id, s.refresh_interval, s.title_from_page, s.etag, s.last_modified,
+            s.checked_at, due.next_check_at, s.changed_at, s.failures, s.last_error, s.page_hash`
+	rows, err := r.db.Query(query, limit, lease.Seconds())
+	if err != nil {
+		return nil, postgreserror.NewPostgresError(err)
+	}
+	defer rows.Close()
+
+	var sources []*articlesmodels.ArticleSource
+	for rows.Next() {
+		var source articlesmodels.ArticleSource
+		if err := rows.Scan(&source.ArticleID, &source.RefreshInterval, &source.TitleFromPage, &source.ETag, &source.LastModified,
+			&source.CheckedAt, &source.NextCheckAt, &source.ChangedAt, &source.Failures, &source.LastError, &source.PageHash); err != nil {
+			return nil, postgreserror.NewPostgresError(err)
+		}
+		sources = append(sources, &source)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, postgreserror.NewPostgresError(err)
+	}
+	return sources, nil
+}
+
+// GetDeletedArticleByID retrieves a single article from the trash
+// Query: Selects the article matching the ID only if it has been soft deleted
+// Returns:
+// - Success: *Article{ID: 1, Title: "Sample Article", DeletedAt: 2024-01-20...}
+// - Error: sql.ErrNoRows if the article is not in the trash, or any other DB error
+func (r *PostgresArticlesService) GetDeletedArticleByID(id int) (*articlesmodels.Article, *customerror.CustomError) {
+	query := "SELECT " + articleColumns + " FROM articles WHERE id = $1 AND deleted_at IS NOT NULL"
+	article, err := scanArticle(r.db.QueryRow(query, id))
+	if err != nil {
+		return nil, postgreserror.NewPostgresError(err)
+	}
 
+	return article, nil
+}
+
+// GetDeletedArticlesByUserID retrieves the trash of a specific user
+// Query: Selects soft deleted articles of the user, most recently deleted first
+// Returns:
+//   - Success: []*Article{
+//     {ID: 3, Title: "Old Draft", DeletedAt: 2024-01-21...},
+//     }
+//   - Error: Database errors if query fails
+func (r *PostgresArticlesService) GetDeletedArticlesByUserID(userID int) ([]*articlesmodels.Article, *customerror.CustomError) {
+	query := "SELECT " + articleColumns + " FROM articles WHERE user_id = $1 AND deleted_at IS NOT NULL ORDER BY deleted_at DESC"
+	return r.queryArticles(query, userID)
+}
+
+// RestoreArticleByID takes an article out of the trash
+// Query: Clears deleted_at on the trashed article matching the ID
+// Returns:
+// - Success: nil (article visible again)
+// - Error: Article not in the trash or database errors
+func (r *PostgresArticlesService) RestoreArticleByID(id int) *customerror.CustomError {
+	query := "UPDATE articles SET deleted_at = NULL WHERE id = $1 AND deleted_at IS NOT NULL"
+	return r.execAffectingOne(query, id)
+}
+
+// PurgeArticleByID permanently removes an article that is in the trash
+// Query: Deletes the trashed article matching the ID, cascading to its revisions
+// Returns:
+// - Success: nil (article permanently deleted)
+// - Error: Article not in the trash or database errors
+func (r *PostgresArticlesService) PurgeArticleByID(id int) *customerror.CustomError {
+	query := "DELETE FROM articles WHERE id = $1 AND deleted_at IS NOT NULL"
+	return r.execAffectingOne(query, id)
+}
+
+// PurgeDeletedArticles permanently removes every article trashed before the given time
+// Query: Deletes articles whose deleted_at is older than before
+// Returns:
+// - Success: number of purged articles
+// - Error: Database errors
+func (r *PostgresArticlesService) PurgeDeletedArticles(before time.Time) (int64, *customerror.CustomError) {
+	query := "DELETE FROM articles WHERE deleted_at IS NOT NULL AND deleted_at < $1"
+	result, err := r.db.Exec(query, before)
+	if err != nil {
+		return 0, postgreserror.NewPostgresError(err)
+	}
+
+	purged, err := result.RowsAffected()
+	if err != nil {
+		return 0, postgreserror.NewPostgresError(err)
+	}
+	return purged, nil
+}
+
+// execAffectingOne runs a statement and reports sql.ErrNoRows when it changed nothing
+func (r *PostgresArticlesService) execAffectingOne(query string, args ...any) *customerror.CustomError {
+	result, err := r.db.Exec(query, args...)
+	if err != nil {
+		return postgreserror.NewPostgresError(err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return postgreserror.NewPostgresError(err)
+	}
+	if affected == 0 {
+		return postgreserror.NewPostgresError(sql.ErrNoRows)
 	}
 	return nil
 }
+
+// newPreconditionFailedError reports that an article changed since the caller last read it
+func newPreconditionFailedError() *customerror.CustomError {
+	return customerror.NewCustomError(errors.New("article version mismatch"), "Article has been modified by someone else", http.StatusPreconditionFailed)
+}
+
+// GetArticleRevisions retrieves all stored revisions of an article
+// Query: Selects revisions for the article ordered from newest to oldest
+// Returns:
+//   - Success: []*ArticleRevision{
+//     {Revision: 2, Title: "Golang Tips"...},
+//     {Revision: 1, Title: "Golang Tip"...},
+//     }
+//   - Error: Database errors if query fails
+func (r *PostgresArticlesService) GetArticleRevisions(articleID int) ([]*articlesmodels.ArticleRevision, *customerror.CustomError) {
+	query := `
+        SELECT id, article_id, revision, title, content, format, editor_id, created_at
+        FROM article_revisions
+        WHERE article_id = $1 AND article_id IN (SELECT id FROM articles WHERE deleted_at IS NULL)
+        ORDER BY revision DESC`
+	rows, err := r.db.Query(query, articleID)
+	if err != nil {
+		return nil, postgreserror.NewPostgresError(err)
+	}
+	defer rows.Close()
+
+	var revisions []*articlesmodels.ArticleRevision
+	for rows.Next() {
+		var revision articlesmodels.ArticleRevision
+		if err := rows.Scan(&revision.ID, &revision.ArticleID, &revision.Revision, &revision.Title, &revision.Content, &revision.Format, &revision.EditorID, &revision.CreatedAt); err != nil {
+			return nil, postgreserror.NewPostgresError(err)
+		}
+		revisions = append(revisions, &revision)
+	}
+
+	return revisions, nil
+}
+
+// GetArticleRevision retrieves a single revision of an article
+// Query: Selects the revision matching both article_id and revision number
+// Returns:
+// - Success: *ArticleRevision{Revision: 1, Title: "Golang Tip", Content: "..."}
+// - Error: sql.ErrNoRows if revision not found, or any other DB error
+func (r *PostgresArticlesService) GetArticleRevision(articleID int, revision int) (*articlesmodels.ArticleRevision, *customerror.CustomError) {
+	query := `
+        SELECT id, article_id, revision, title, content, format, editor_id, created_at
+        FROM article_revisions
+        WHERE article_id = $1 AND revision = $2 AND article_id IN (SELECT id FROM articles WHERE deleted_at IS NULL)`
+	row := r.db.QueryRow(query, articleID, revision)
+
+	var rev articlesmodels.ArticleRevision
+	if err := row.Scan(&rev.ID, &rev.ArticleID, &rev.Revision, &rev.Title, &rev.Content, &rev.Format, &rev.EditorID, &rev.CreatedAt); err != nil {
+		return nil, postgreserror.NewPostgresError(err)
+	}
+
+	return &rev, nil
+}
+
+// GetTags retrieves every tag used by at least one visible article
+// Query: Counts non-trashed articles per tag, most used tags first
+// Returns:
+//   - Success: []*Tag{
+//     {Name: "Golang", Slug: "golang", ArticleCount: 12},
+//     {Name: "PostgreSQL", Slug: "postgresql", ArticleCount: 4},
+//     }
+//   - Error: Database errors if query fails
+func (r *PostgresArticlesService) GetTags() ([]*articlesmodels.Tag, *customerror.CustomError) {
+	query := `
+        SELECT t.id, t.name, t.slug, COUNT(a.id), t.created_at
+        FROM tags t
+        JOIN article_tags at ON at.tag_id = t.id
+        JOIN articles a ON a.id = at.article_id AND a.deleted_at IS NULL
+        GROUP BY t.id
+        ORDER BY COUNT(a.id) DESC, t.name`
+	rows, err := r.db.Query(query)
+	if err != nil {
+		return nil, postgreserror.NewPostgresError(err)
+	}
+	defer rows.Close()
+
+	var tags []*articlesmodels.Tag
+	for rows.Next() {
+		var tag articlesmodels.Tag
+		if err := rows.Scan(&tag.ID, &tag.Name, &tag.Slug, &tag.ArticleCount, &tag.CreatedAt); err != nil {
+			return nil, postgreserror.NewPostgresError(err)
+		}
+		tags = append(tags, &tag)
+	}
+
+	return tags, nil
+}
+
+// GetTagBySlug retrieves a single tag by its slug
+// Query: Selects the tag matching the slug
+// Returns:
+// - Success: *Tag{ID: 1, Name: "Golang", Slug: "golang"}
+// - Error: sql.ErrNoRows if tag not found, or any other DB error
+func (r *PostgresArticlesService) GetTagBySlug(tagSlug string) (*articlesmodels.Tag, *customerror.CustomError) {
+	query := "SELECT id, name, slug, created_at FROM tags WHERE slug = $1"
+
+	var tag articlesmodels.Tag
+	if err := r.db.QueryRow(query, tagSlug).Scan(&tag.ID, &tag.Name, &tag.Slug, &tag.CreatedAt); err != nil {
+		return nil, postgreserror.NewPostgresError(err)
+	}
+
+	return &tag, nil
+}
+
+// AddReaction records a reaction of a user on an article
+// Query: Inserts the reaction, doing nothing when the user already left it; a trigger keeps
+// article_reaction_counts in step
+// Returns:
+// - Success: nil (reaction present)
+// - Error: Database errors
+func (r *PostgresArticlesService) AddReaction(articleID int, userID int, kind string) *customerror.CustomError {
+	query := `
+        INSERT INTO article_reactions (article_id, user_id, kind, created_at) VALUES ($1, $2, $3, $4)
+        ON CONFLICT DO NOTHING`
+	if _, err := r.db.Exec(query, articleID, userID, kind, time.Now()); err != nil {
+		return postgreserror.NewPostgresError(err)
+	}
+	return nil
+}
+
+// RemoveReaction withdraws a reaction of a user from an article
+// Query: Deletes the reaction if present; a trigger keeps article_reaction_counts in step
+// Returns:
+// - Success: nil (reaction absent)
+// - Error: Database errors
+func (r *PostgresArticlesService) RemoveReaction(articleID int, userID int, kind string) *customerror.CustomError {
+	query := "DELETE FROM article_reactions WHERE article_id = $1 AND user_id = $2 AND kind = $3"
+	if _, err := r.db.Exec(query, articleID, userID, kind); err != nil {
+		return postgreserror.NewPostgresError(err)
+	}
+	return nil
+}
+
+// GetUserReactions retrieves the reactions a user left on an article
+// Query: Selects the reaction kinds of the user on the article
+// Returns:
+// - Success: []string{"like", "clap"}
+// - Error: Database errors
+func (r *PostgresArticlesService) GetUserReactions(articleID int, userID int) ([]string, *customerror.CustomError) {
+	query := "SELECT ARRAY(SELECT kind FROM article_reactions WHERE article_id = $1 AND user_id = $2 ORDER BY kind)"
+
+	kinds := []string{}
+	if err := r.db.QueryRow(query, articleID, userID).Scan(pq.Array(&kinds)); err != nil {
+		return nil, postgreserror.NewPostgresError(err)
+	}
+	return kinds, nil
+}
+
+// AddBookmark saves an article to the bookmarks of a user
+// Query: Inserts the bookmark, doing nothing when it already exists
+// Returns:
+// - Success: nil (article bookmarked)
+// - Error: Database errors
+func (r *PostgresArticlesService) AddBookmark(userID int, articleID int) *customerror.CustomError {
+	query := `
+        INSERT INTO bookmarks (user_id, article_id, created_at) VALUES ($1, $2, $3)
+        ON CONFLICT DO NOTHING`
+	if _, err := r.db.Exec(query, userID, articleID, time.Now()); err != nil {
+		return postgreserror.NewPostgresError(err)
+	}
+	return nil
+}
+
+// RemoveBookmark removes an article from the bookmarks of a user
+// Query: Deletes the bookmark if present
+// Returns:
+// - Success: nil (article not bookmarked)
+// - Error: Database errors
+func (r *PostgresArticlesService) RemoveBookmark(userID int, articleID int) *customerror.CustomError {
+	query := "DELETE FROM bookmarks WHERE user_id = $1 AND article_id = $2"
+	if _, err := r.db.Exec(query, userID, articleID); err != nil {
+		return postgreserror.NewPostgresError(err)
+	}
+	return nil
+}
+
+// GetBookmarkedArticles retrieves the articles a user bookmarked, most recently bookmarked first
+// Query: Joins the user's bookmarks to articles that are not in the trash
+// Returns:
+//   - Success: []*Article{
+//     {ID: 7, Title: "Golang Tips"...},
+//     }
+//   - Error: Database errors if query fails
+func (r *PostgresArticlesService) GetBookmarkedArticles(userID int, limit int, offset int) ([]*articlesmodels.Article, *customerror.CustomError) {
+	// The bookmarks columns are renamed so they do not clash with articleColumns
+	query := `
+        SELECT ` + articleColumns + `
+        FROM articles
+        JOIN (SELECT article_id, created_at AS bookmarked_at FROM bookmarks WHERE user_id = $1) b ON b.article_id = articles.id
+        WHERE deleted_at IS NULL
+        ORDER BY b.bookmarked_at DESC, id DESC
+        LIMIT $2 OFFSET $3`
+	return r.queryArticles(query, userID, limit, offset)
+}
+
+// GetFollowedArticles retrieves a page of a user's feed, the articles of the authors they follow
+// Query: Selects visible articles of followed authors created before the cursor, newest first.
+// (created_at, id) orders articles sharing a timestamp so pages neither skip nor repeat them.
+// Returns:
+//   - Success: []*Article{
+//     {ID: 9, UserID: 2, Title: "Golang Generics"...},
+//     {ID: 8, UserID: 5, Title: "Indexing in PostgreSQL"...},
+//     }
+//   - Error: Database errors if query fails
+func (r *PostgresArticlesService) GetFollowedArticles(followerID int, cursor *articlesmodels.ArticleCursor, limit int) ([]*articlesmodels.Article, *customerror.CustomError) {
+	var before *time.Time
+	var beforeID int
+	if cursor != nil {
+		before, beforeID = &cursor.CreatedAt, cursor.ID
+	}
+
+	query := `
+        SELECT ` + articleColumns + `
+        FROM articles
+        WHERE deleted_at IS NULL
+          AND user_id IN (SELECT followee_id FROM follows WHERE follower_id = $1)
+          AND ($2::timestamp IS NULL OR (created_at, id) < ($2::timestamp, $3))
+        ORDER BY created_at DESC, id DESC
+        LIMIT $4`
+	return r.queryArticles(query, followerID, before, beforeID, limit)
+}
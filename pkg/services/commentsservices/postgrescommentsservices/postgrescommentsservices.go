@@ -0,0 +1,169 @@
+package postgrescommentsservices
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/yantology/go-gin-simple-blog-with-fts/pkg/models/commentsmodels"
+	"github.com/yantology/go-gin-simple-blog-with-fts/pkg/utils/customerror"
+	"github.com/yantology/go-gin-simple-blog-with-fts/pkg/utils/customerror/postgreserror"
+)
+
+// PostgresCommentsService provides methods to interact with comments table in PostgreSQL database
+type PostgresCommentsService struct {
+	db *sql.DB
+}
+
+// NewPostgresCommentsService creates a new instance of PostgresCommentsService
+func NewPostgresCommentsService(db *sql.DB) *PostgresCommentsService {
+	return &PostgresCommentsService{db: db}
+}
+
+// commentColumns is the column list read by every comment query, in scanComment order.
+// Queries must select FROM comments c JOIN users u ON u.id = c.user_id.
+const commentColumns = `c.id, c.article_id, c.user_id, u.username, c.parent_id, c.root_id, c.content,
+        c.hidden_at, c.deleted_at, c.created_at, c.updated_at`
+
+// rowScanner is implemented by both *sql.Row and *sql.Rows
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+// scanComment reads one row selected with commentColumns into a Comment
+func scanComment(row rowScanner) (*commentsmodels.Comment, error) {
+	var comment commentsmodels.Comment
+	err := row.Scan(&comment.ID, &comment.ArticleID, &comment.UserID, &comment.Username, &comment.ParentID, &comment.RootID,
+		&comment.Content, &comment.HiddenAt, &comment.DeletedAt, &comment.CreatedAt, &comment.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &comment, nil
+}
+
+// CreateComment creates a new comment
+// Query: Inserts the comment and reads back the author's username
+// Returns:
+// - Success: nil, comment.ID, Username, CreatedAt and UpdatedAt are filled from the new row
+// - Error: Database errors, e.g. an article or parent that does not exist
+func (s *PostgresCommentsService) CreateComment(comment *commentsmodels.Comment) *customerror.CustomError {
+	query := `
+        WITH inserted AS (
+            INSERT INTO comments (article_id, user_id, parent_id, root_id, content, created_at, updated_at)
+            VALUES ($1, $2, $3, $4, $5, $6, $6)
+            RETURNING id, user_id, created_at, updated_at
+        )
+        SELECT inserted.id, u.username, inserted.created_at, inserted.updated_at
+        FROM inserted JOIN users u ON u.id = inserted.user_id`
+	err := s.db.QueryRow(query, comment.ArticleID, comment.UserID, comment.ParentID, comment.RootID, comment.Content, time.Now()).
+		Scan(&comment.ID, &comment.Username, &comment.CreatedAt, &comment.UpdatedAt)
+	if err != nil {
+		return postgreserror.NewPostgresError(err)
+	}
+	return nil
+}
+
+// GetCommentByID retrieves a single comment by its ID
+// Query: Selects the comment matching the ID, whether deleted, hidden or not
+// Returns:
+// - Success: *Comment{ID: 1, ArticleID: 3, Content: "Nice article"...}
+// - Error: sql.ErrNoRows if comment not found, or any other DB error
+func (s *PostgresCommentsService) GetCommentByID(id int) (*commentsmodels.Comment, *customerror.CustomError) {
+	query := "SELECT " + commentColumns + " FROM comments c JOIN users u ON u.id = c.user_id WHERE c.id = $1"
+	comment, err := scanComment(s.db.QueryRow(query, id))
+	if err != nil {
+		return nil, postgreserror.NewPostgresError(err)
+	}
+
+	return comment, nil
+}
+
+// GetCommentThreads retrieves a page of comment threads of an article
+// Query: Pages over top-level comments, oldest first, and selects them with every reply in their threads
+// Returns:
+//   - Success: []*Comment{
+//     {ID: 1, ParentID: nil...},
+//     {ID: 4, ParentID: 1, RootID: 1...},
+//     }, total number of top-level comments
+//   - Error: Database errors if query fails
+func (s *PostgresCommentsService) GetCommentThreads(articleID int, limit int, offset int) ([]*commentsmodels.Comment, int, *customerror.CustomError) {
+	var total int
+	countQuery := "SELECT COUNT(*) FROM comments WHERE article_id = $1 AND parent_id IS NULL"
+	if err := s.db.QueryRow(countQuery, articleID).Scan(&total); err != nil {
+		return nil, 0, postgreserror.NewPostgresError(err)
+	}
+
+	query := `
+        WITH roots AS (
+            SELECT id FROM comments
+            WHERE article_id = $1 AND parent_id IS NULL
+            ORDER BY created_at, id
+            LIMIT $2 OFFSET $3
+        )
+        SELECT ` + commentColumns + `
+        FROM comments c JOIN users u ON u.id = c.user_id
+        WHERE c.id IN (SELECT id FROM roots) OR c.root_id IN (SELECT id FROM roots)
+        ORDER BY c.created_at, c.id`
+	rows, err := s.db.Query(query, articleID, limit, offset)
+	if err != nil {
+		return nil, 0, postgreserror.NewPostgresError(err)
+	}
+	defer rows.Close()
+
+	var comments []*commentsmodels.Comment
+	for rows.Next() {
+		comment, err := scanComment(rows)
+		if err != nil {
+			return nil, 0, postgreserror.NewPostgresError(err)
+		}
+		comments = append(comments, comment)
+	}
+
+	return comments, total, nil
+}
+
+// UpdateComment replaces the content of a comment
+// Query: Updates content and updated_at of the comment unless it is deleted
+// Returns:
+// - Success: nil
+// - Error: sql.ErrNoRows if the comment is not found or deleted, or any other DB error
+func (s *PostgresCommentsService) UpdateComment(id int, content string) *customerror.CustomError {
+	query := "UPDATE comments SET content = $2, updated_at = $3 WHERE id = $1 AND deleted_at IS NULL"
+	return s.execAffectingOne(query, id, content, time.Now())
+}
+
+// DeleteCommentByID marks a comment as deleted
+// Query: Sets deleted_at and empties the content, keeping the row for its replies
+// Returns:
+// - Success: nil
+// - Error: sql.ErrNoRows if the comment is not found or already deleted, or any other DB error
+func (s *PostgresCommentsService) DeleteCommentByID(id int) *customerror.CustomError {
+	query := "UPDATE comments SET content = '', deleted_at = $2 WHERE id = $1 AND deleted_at IS NULL"
+	return s.execAffectingOne(query, id, time.Now())
+}
+
+// SetCommentHidden hides or shows a comment
+// Query: Sets hidden_at to now when hiding, or clears it when showing
+// Returns:
+// - Success: nil
+// - Error: sql.ErrNoRows if the comment is not found, or any other DB error
+func (s *PostgresCommentsService) SetCommentHidden(id int, hidden bool) *customerror.CustomError {
+	query := "UPDATE comments SET hidden_at = CASE WHEN $2 THEN COALESCE(hidden_at, $3) END WHERE id = $1"
+	return s.execAffectingOne(query, id, hidden, time.Now())
+}
+
+// execAffectingOne runs a statement and reports sql.ErrNoRows when it changed nothing
+func (s *PostgresCommentsService) execAffectingOne(query string, args ...any) *customerror.CustomError {
+	result, err := s.db.Exec(query, args...)
+	if err != nil {
+		return postgreserror.NewPostgresError(err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return postgreserror.NewPostgresError(err)
+	}
+	if affected == 0 {
+		return postgreserror.NewPostgresError(sql.ErrNoRows)
+	}
+	return nil
+}
@@ -0,0 +1,124 @@
+package postgresfollowsservices
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/yantology/go-gin-simple-blog-with-fts/pkg/models/followsmodels"
+	"github.com/yantology/go-gin-simple-blog-with-fts/pkg/utils/customerror"
+	"github.com/yantology/go-gin-simple-blog-with-fts/pkg/utils/customerror/postgreserror"
+)
+
+// PostgresFollowsService provides methods to interact with follows table in PostgreSQL database
+type PostgresFollowsService struct {
+	db *sql.DB
+}
+
+// NewPostgresFollowsService creates a new instance of PostgresFollowsService
+func NewPostgresFollowsService(db *sql.DB) *PostgresFollowsService {
+	return &PostgresFollowsService{db: db}
+}
+
+// Follow records that followerID follows followeeID
+// Query: Inserts the follow, doing nothing when it already exists
+// Returns:
+// - Success: nil (follow present)
+// - Error: Database errors, e.g. a user that does not exist
+func (s *PostgresFollowsService) Follow(followerID int, followeeID int) *customerror.CustomError {
+	query := `
+        INSERT INTO follows (follower_id, followee_id, created_at) VALUES ($1, $2, $3)
+        ON CONFLICT DO NOTHING`
+	if _, err := s.db.Exec(query, followerID, followeeID, time.Now()); err != nil {
+		return postgreserror.NewPostgresError(err)
+	}
+	return nil
+}
+
+// Unfollow removes the follow of followeeID by followerID
+// Query: Deletes the follow if present
+// Returns:
+// - Success: nil (follow absent)
+// - Error: Database errors
+func (s *PostgresFollowsService) Unfollow(followerID int, followeeID int) *customerror.CustomError {
+	query := "DELETE FROM follows WHERE follower_id = $1 AND followee_id = $2"
+	if _, err := s.db.Exec(query, followerID, followeeID); err != nil {
+		return postgreserror.NewPostgresError(err)
+	}
+	return nil
+}
+
+// IsFollowing checks whether followerID follows followeeID
+// Query: Checks for the follow row
+// Returns:
+// - Success: true if the follow exists
+// - Error: Database errors
+func (s *PostgresFollowsService) IsFollowing(followerID int, followeeID int) (bool, *customerror.CustomError) {
+	query := "SELECT EXISTS(SELECT 1 FROM follows WHERE follower_id = $1 AND followee_id = $2)"
+
+	var following bool
+	if err := s.db.QueryRow(query, followerID, followeeID).Scan(&following); err != nil {
+		return false, postgreserror.NewPostgresError(err)
+	}
+	return following, nil
+}
+
+// GetFollowers retrieves a page of the followers of a user
+// Query: Joins follows on followee_id to users, most recent follow first
+// Returns:
+//   - Success: []*FollowUser{
+//     {ID: 4, Username: "budi", FollowedAt: 2024-12-10...},
+//     }, total number of followers
+//   - Error: Database errors if query fails
+func (s *PostgresFollowsService) GetFollowers(userID int, limit int, offset int) ([]*followsmodels.FollowUser, int, *customerror.CustomError) {
+	countQuery := "SELECT COUNT(*) FROM follows WHERE followee_id = $1"
+	query := `
+        SELECT u.id, u.username, f.created_at
+        FROM follows f JOIN users u ON u.id = f.follower_id
+        WHERE f.followee_id = $1
+        ORDER BY f.created_at DESC, u.id
+        LIMIT $2 OFFSET $3`
+	return s.queryFollowUsers(countQuery, query, userID, limit, offset)
+}
+
+// GetFollowing retrieves a page of the users a user follows
+// Query: Joins follows on follower_id to users, most recent follow first
+// Returns:
+//   - Success: []*FollowUser{
+//     {ID: 2, Username: "ani", FollowedAt: 2024-12-10...},
+//     }, total number of followed users
+//   - Error: Database errors if query fails
+func (s *PostgresFollowsService) GetFollowing(userID int, limit int, offset int) ([]*followsmodels.FollowUser, int, *customerror.CustomError) {
+	countQuery := "SELECT COUNT(*) FROM follows WHERE follower_id = $1"
+	query := `
+        SELECT u.id, u.username, f.created_at
+        FROM follows f JOIN users u ON u.id = f.followee_id
+        WHERE f.follower_id = $1
+        ORDER BY f.created_at DESC, u.id
+        LIMIT $2 OFFSET $3`
+	return s.queryFollowUsers(countQuery, query, userID, limit, offset)
+}
+
+// queryFollowUsers counts the rows of a follow list with countQuery and reads one page of it with query
+func (s *PostgresFollowsService) queryFollowUsers(countQuery string, query string, userID int, limit int, offset int) ([]*followsmodels.FollowUser, int, *customerror.CustomError) {
+	var total int
+	if err := s.db.QueryRow(countQuery, userID).Scan(&total); err != nil {
+		return nil, 0, postgreserror.NewPostgresError(err)
+	}
+
+	rows, err := s.db.Query(query, userID, limit, offset)
+	if err != nil {
+		return nil, 0, postgreserror.NewPostgresError(err)
+	}
+	defer rows.Close()
+
+	var users []*followsmodels.FollowUser
+	for rows.Next() {
+		var user followsmodels.FollowUser
+		if err := rows.Scan(&user.ID, &user.Username, &user.FollowedAt); err != nil {
+			return nil, 0, postgreserror.NewPostgresError(err)
+		}
+		users = append(users, &user)
+	}
+
+	return users, total, nil
+}
@@ -0,0 +1,250 @@
+package postgresimportsservices
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/yantology/go-gin-simple-blog-with-fts/pkg/models/importsmodels"
+	"github.com/yantology/go-gin-simple-blog-with-fts/pkg/utils/customerror"
+	"github.com/yantology/go-gin-simple-blog-with-fts/pkg/utils/customerror/postgreserror"
+)
+
+// PostgresImportsService provides methods to interact with imports table in PostgreSQL database
+type PostgresImportsService struct {
+	db *sql.DB
+}
+
+// NewPostgresImportsService creates a new instance of PostgresImportsService
+func NewPostgresImportsService(db *sql.DB) *PostgresImportsService {
+	return &PostgresImportsService{db: db}
+}
+
+// CreateImport records a queued import
+// Query: Inserts the import row
+// Returns:
+// - Success: nil, imp.ID, imp.State and imp.CreatedAt are filled from the new row
+// - Error: Database errors
+func (s *PostgresImportsService) CreateImport(imp *importsmodels.Import) *customerror.CustomError {
+	query := `
+        INSERT INTO imports (user_id, source, filename, mode, atomic, refresh_interval, state, idempotency_key, request_hash, created_at,
+            owner, lease_expires_at)
+        VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+        RETURNING id, state, created_at`
+	err := s.db.QueryRow(query, imp.UserID, imp.Source, imp.Filename, imp.Mode, imp.Atomic, imp.RefreshInterval, importsmodels.StateQueued,
+		imp.IdempotencyKey, imp.RequestHash, time.Now(), imp.Owner, imp.LeaseExpiresAt).
+		Scan(&imp.ID, &imp.State, &imp.CreatedAt)
+	if err != nil {
+		return postgreserror.NewPostgresError(err)
+	}
+	return nil
+}
+
+// importColumns is the column list read by every import query, in scanImport order
+const importColumns = `id, user_id, source, filename, mode, atomic, refresh_interval, state, processed, succeeded, failed, skipped,
+        error, created_at, started_at, finished_at, idempotency_key, request_hash, owner, lease_expires_at`
+
+// scanImport reads one row selected with importColumns into an Import
+func scanImport(row *sql.Row) (*importsmodels.Import, error) {
+	var imp importsmodels.Import
+	err := row.Scan(&imp.ID, &imp.UserID, &imp.Source, &imp.Filename, &imp.Mode, &imp.Atomic, &imp.RefreshInterval, &imp.State,
+		&imp.Counts.Processed, &imp.Counts.Succeeded, &imp.Counts.Failed, &imp.Counts.Skipped,
+		&imp.Error, &imp.CreatedAt, &imp.StartedAt, &imp.FinishedAt, &imp.IdempotencyKey, &imp.RequestHash,
+		&imp.Owner, &imp.LeaseExpiresAt)
+	if err != nil {
+		return nil, err
+	}
+	return &imp, nil
+}
+
+// GetImportByID retrieves a single import by its ID
+// Query: Selects the import row matching the ID
+// Returns:
+// - Success: *Import{ID: 1, State: "running", Counts: {Processed: 120...}...}
+// - Error: sql.ErrNoRows if import not found, or any other DB error
+func (s *PostgresImportsService) GetImportByID(id int) (*importsmodels.Import, *customerror.CustomError) {
+	imp, err := scanImport(s.db.QueryRow("SELECT "+importColumns+" FROM imports WHERE id = $1", id))
+	if err != nil {
+		return nil, postgreserror.NewPostgresError(err)
+	}
+	return imp, nil
+}
+
+// GetImportByIdempotencyKey retrieves the import a user started with an Idempotency-Key
+// Query: Selects the import row of the user with the key
+// Returns:
+// - Success: *Import{ID: 1, IdempotencyKey: "5f1c...", RequestHash: "9f86d0..."...}
+// - Error: sql.ErrNoRows if the user sent no upload with the key, or any other DB error
+func (s *PostgresImportsService) GetImportByIdempotencyKey(userID int, key string) (*importsmodels.Import, *customerror.CustomError) {
+	imp, err := scanImport(s.db.QueryRow("SELECT "+importColumns+" FROM imports WHERE user_id = $1 AND idempotency_key = $2", userID, key))
+	if err != nil {
+		return nil, postgreserror.NewPostgresError(err)
+	}
+	return imp, nil
+}
+
+// StartImport moves a queued import to running
+// Query: Updates the state, started_at and lease of the import if it is still queued
+// Returns:
+// - Success: nil
+// - Error: sql.ErrNoRows if no queued import has the ID, or any other DB error
+func (s *PostgresImportsService) StartImport(id int, leaseExpiresAt time.Time) *customerror.CustomError {
+	result, err := s.db.Exec(`
+        UPDATE imports SET state = $2, started_at = $3, lease_expires_at = $4
+        WHERE id = $1 AND state = $5`,
+		id, importsmodels.StateRunning, time.Now(), leaseExpiresAt, importsmodels.StateQueued)
+	return checkAffected(result, err)
+}
+
+// UpdateImportCounts records the progress of a running import and renews its lease
+// Query: Updates the counters and lease of the import while it is running
+// Returns:
+// - Success: nil
+// - Error: sql.ErrNoRows if no running import has the ID, or any other DB error
+func (s *PostgresImportsService) UpdateImportCounts(id int, counts importsmodels.Counts, leaseExpiresAt time.Time) *customerror.CustomError {
+	result, err := s.db.Exec(`
+        UPDATE imports SET processed = $2, succeeded = $3, failed = $4, skipped = $5, lease_expires_at = $6
+        WHERE id = $1 AND state = $7`,
+		id, counts.Processed, counts.Succeeded, counts.Failed, counts.Skipped, leaseExpiresAt, importsmodels.StateRunning)
+	return checkAffected(result, err)
+}
+
+// FinishImport records how an import ended
+// Query: Updates the state, counters, error and finished_at of the import unless it already finished
+// Returns:
+// - Success: nil
+// - Error: sql.ErrNoRows if no unfinished import has the ID, e.g. one failed once its lease ran out, or any other DB error
+func (s *PostgresImportsService) FinishImport(id int, state string, counts importsmodels.Counts, reason *string) *customerror.CustomError {
+	result, err := s.db.Exec(`
+        UPDATE imports
+        SET state = $2, processed = $3, succeeded = $4, failed = $5, skipped = $6, error = $7, finished_at = $8
+        WHERE id = $1 AND state IN ($9, $10)`,
+		id, state, counts.Processed, counts.Succeeded, counts.Failed, counts.Skipped, reason, time.Now(),
+		importsmodels.StateQueued, importsmodels.StateRunning)
+	return checkAffected(result, err)
+}
+
+// FailExpiredImports marks imports left behind by a stopped server as failed
+// Query: Updates every queued or running import whose lease ran out, or that has none
+// Returns:
+// - Success: (2, nil) - the number of imports marked
+// - Error: (0, error) - Database failure
+func (s *PostgresImportsService) FailExpiredImports(reason string) (int, *customerror.CustomError) {
+	now := time.Now()
+	result, err := s.db.Exec(`
+        UPDATE imports SET state = $1, error = $2, finished_at = $3
+        WHERE state IN ($4, $5) AND (lease_expires_at IS NULL OR lease_expires_at < $3)`,
+		importsmodels.StateFailed, reason, now, importsmodels.StateQueued, importsmodels.StateRunning)
+	if err != nil {
+		return 0, postgreserror.NewPostgresError(err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return 0, postgreserror.NewPostgresError(err)
+	}
+	return int(affected), nil
+}
+
+// importRowsPerInsert keeps the parameters of one insert well below the Postgres limit of 65535
+const importRowsPerInsert = 1000
+
+// AddImportRows records the results of rows of an import
+// Query: Inserts the rows in multi-row INSERT statements within one transaction
+// Returns:
+// - Success: nil
+// - Error: Database errors, e.g. a row number recorded twice
+func (s *PostgresImportsService) AddImportRows(importID int, rows []*importsmodels.RowResult) *customerror.CustomError {
+	if len(rows) == 0 {
+		return nil
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return postgreserror.NewPostgresError(err)
+	}
+	defer tx.Rollback()
+
+	now := time.Now()
+	for start := 0; start < len(rows); start += importRowsPerInsert {
+		chunk := rows[start:min(start+importRowsPerInsert, len(rows))]
+
+		var query strings.Builder
+		query.WriteString("INSERT INTO import_rows (import_id, row_number, title, url, status, error, article_id, created_at) VALUES ")
+		args := make([]any, 0, len(chunk)*7+2)
+		args = append(args, importID, now)
+		for i, row := range chunk {
+			if i > 0 {
+				query.WriteString(", ")
+			}
+			n := len(args)
+			fmt.Fprintf(&query, "($1, $%d, $%d, $%d, $%d, $%d, $%d, $2)", n+1, n+2, n+3, n+4, n+5, n+6)
+			args = append(args, row.Row, row.Title, row.URL, row.Status, row.Error, row.ArticleID)
+		}
+
+		if _, err := tx.Exec(query.String(), args...); err != nil {
+			return postgreserror.NewPostgresError(err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return postgreserror.NewPostgresError(err)
+	}
+	return nil
+}
+
+// GetImportRows retrieves a page of the row results of an import
+// Query: Counts the rows of the import, optionally with one status, then selects a page of them in file order
+// Returns:
+// - Success: ([]*RowResult{{Row: 2, Status: "failed", Error: "unexpected status 404"...}}, 1, nil)
+// - Error: (nil, 0, error) - Database failure
+func (s *PostgresImportsService) GetImportRows(importID int, status string, limit int, offset int) ([]*importsmodels.RowResult, int, *customerror.CustomError) {
+	var total int
+	countQuery := "SELECT COUNT(*) FROM import_rows WHERE import_id = $1 AND ($2 = '' OR status = $2)"
+	if err := s.db.QueryRow(countQuery, importID, status).Scan(&total); err != nil {
+		return nil, 0, postgreserror.NewPostgresError(err)
+	}
+
+	query := `
+        SELECT import_id, row_number, title, url, status, error, article_id, created_at
+        FROM import_rows
+        WHERE import_id = $1 AND ($2 = '' OR status = $2)
+        ORDER BY row_number
+        LIMIT $3 OFFSET $4`
+	rows, err := s.db.Query(query, importID, status, limit, offset)
+	if err != nil {
+		return nil, 0, postgreserror.NewPostgresError(err)
+	}
+	defer rows.Close()
+
+	results := []*importsmodels.RowResult{}
+	for rows.Next() {
+		var result importsmodels.RowResult
+		if err := rows.Scan(&result.ImportID, &result.Row, &result.Title, &result.URL, &result.Status,
+			&result.Error, &result.ArticleID, &result.CreatedAt); err != nil {
+			return nil, 0, postgreserror.NewPostgresError(err)
+		}
+		results = append(results, &result)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, postgreserror.NewPostgresError(err)
+	}
+
+	return results, total, nil
+}
+
+func checkAffected(result sql.Result, err error) *customerror.CustomError {
+	if err != nil {
+		return postgreserror.NewPostgresError(err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return postgreserror.NewPostgresError(err)
+	}
+	if affected == 0 {
+		return postgreserror.NewPostgresError(sql.ErrNoRows)
+	}
+	return nil
+}
@@ -0,0 +1,85 @@
+package localmediaservices
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/yantology/go-gin-simple-blog-with-fts/pkg/utils/customerror"
+)
+
+// LocalBlobStore keeps files in a directory on the local disk,
+// typically one that is already served as static files
+type LocalBlobStore struct {
+	dir     string
+	baseURL string
+}
+
+// NewLocalBlobStore creates a store writing below dir whose files are served under baseURL
+func NewLocalBlobStore(dir string, baseURL string) *LocalBlobStore {
+	return &LocalBlobStore{dir: dir, baseURL: strings.TrimRight(baseURL, "/")}
+}
+
+// path maps a key to a file below the store directory, rejecting keys that would escape it
+func (s *LocalBlobStore) path(key string) (string, *customerror.CustomError) {
+	cleaned := filepath.Clean(filepath.FromSlash(key))
+	if !filepath.IsLocal(cleaned) {
+		return "", customerror.NewCustomError(errors.New("invalid storage key"), "invalid storage key", 400)
+	}
+	return filepath.Join(s.dir, cleaned), nil
+}
+
+// Put writes the file through a temporary file and a rename, so readers never see a partial file
+func (s *LocalBlobStore) Put(ctx context.Context, key string, data []byte, contentType string) *customerror.CustomError {
+	if err := ctx.Err(); err != nil {
+		return customerror.NewCustomError(err, "upload cancelled", 500)
+	}
+
+	path, cuserr := s.path(key)
+	if cuserr != nil {
+		return cuserr
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return customerror.NewCustomError(err, "failed to store file", 500)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".upload-*")
+	if err != nil {
+		return customerror.NewCustomError(err, "failed to store file", 500)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return customerror.NewCustomError(err, "failed to store file", 500)
+	}
+	if err := tmp.Close(); err != nil {
+		return customerror.NewCustomError(err, "failed to store file", 500)
+	}
+	if err := os.Chmod(tmp.Name(), 0o644); err != nil {
+		return customerror.NewCustomError(err, "failed to store file", 500)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return customerror.NewCustomError(err, "failed to store file", 500)
+	}
+	return nil
+}
+
+// Delete removes the file under key
+func (s *LocalBlobStore) Delete(ctx context.Context, key string) *customerror.CustomError {
+	path, cuserr := s.path(key)
+	if cuserr != nil {
+		return cuserr
+	}
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return customerror.NewCustomError(err, "failed to delete file", 500)
+	}
+	return nil
+}
+
+// URL returns baseURL joined with key
+func (s *LocalBlobStore) URL(key string) string {
+	return s.baseURL + "/" + key
+}
@@ -0,0 +1,105 @@
+package postgresmediaservices
+
+import (
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	"github.com/yantology/go-gin-simple-blog-with-fts/pkg/models/mediamodels"
+	"github.com/yantology/go-gin-simple-blog-with-fts/pkg/utils/customerror"
+	"github.com/yantology/go-gin-simple-blog-with-fts/pkg/utils/customerror/postgreserror"
+)
+
+// PostgresMediaService provides methods to interact with media table in PostgreSQL database
+type PostgresMediaService struct {
+	db *sql.DB
+}
+
+// NewPostgresMediaService creates a new instance of PostgresMediaService
+func NewPostgresMediaService(db *sql.DB) *PostgresMediaService {
+	return &PostgresMediaService{db: db}
+}
+
+// CreateMedia records an uploaded file
+// Query: Inserts the media row with its variants stored as JSON
+// Returns:
+// - Success: nil, media.ID and media.CreatedAt are filled from the new row
+// - Error: Database errors, e.g. a storage key that is already used
+func (s *PostgresMediaService) CreateMedia(media *mediamodels.Media) *customerror.CustomError {
+	variants, err := json.Marshal(media.Variants)
+	if err != nil {
+		return customerror.NewCustomError(err, "failed to encode media variants", 500)
+	}
+
+	query := `
+        INSERT INTO media (user_id, storage_key, url, content_type, size, width, height, variants, created_at)
+        VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+        RETURNING id, created_at`
+	err = s.db.QueryRow(query, media.UserID, media.StorageKey, media.URL, media.ContentType, media.Size, media.Width, media.Height, variants, time.Now()).
+		Scan(&media.ID, &media.CreatedAt)
+	if err != nil {
+		return postgreserror.NewPostgresError(err)
+	}
+	return nil
+}
+
+// GetMediaByID retrieves a single media record by its ID
+// Query: Selects the media row matching the ID
+// Returns:
+// - Success: *Media{ID: 1, URL: "/public/media/2024/12/ab12.jpg", Width: 1600...}
+// - Error: sql.ErrNoRows if media not found, or any other DB error
+func (s *PostgresMediaService) GetMediaByID(id int) (*mediamodels.Media, *customerror.CustomError) {
+	query := `
+        SELECT id, user_id, storage_key, url, content_type, size, width, height, variants, created_at
+        FROM media WHERE id = $1`
+
+	var media mediamodels.Media
+	var variants []byte
+	err := s.db.QueryRow(query, id).
+		Scan(&media.ID, &media.UserID, &media.StorageKey, &media.URL, &media.ContentType, &media.Size, &media.Width, &media.Height, &variants, &media.CreatedAt)
+	if err != nil {
+		return nil, postgreserror.NewPostgresError(err)
+	}
+	if err := json.Unmarshal(variants, &media.Variants); err != nil {
+		return nil, customerror.NewCustomError(err, "failed to decode media variants", 500)
+	}
+
+	return &media, nil
+}
+
+// DeleteMediaByID removes a media record
+// Query: Clears the cover of the articles using the media, bumping their version
+// since the cover is part of their ETag, then deletes the media row, in one transaction
+// Returns:
+// - Success: nil
+// - Error: sql.ErrNoRows if media not found, or any other DB error
+func (s *PostgresMediaService) DeleteMediaByID(id int) *customerror.CustomError {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return postgreserror.NewPostgresError(err)
+	}
+	defer tx.Rollback()
+
+	coverQuery := "UPDATE articles SET cover_media_id = NULL, version = version + 1 WHERE cover_media_id = $1"
+	if _, err := tx.Exec(coverQuery, id); err != nil {
+		return postgreserror.NewPostgresError(err)
+	}
+
+	result, err := tx.Exec("DELETE FROM media WHERE id = $1", id)
+	if err != nil {
+		return postgreserror.NewPostgresError(err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return postgreserror.NewPostgresError(err)
+	}
+	if affected == 0 {
+		return postgreserror.NewPostgresError(sql.ErrNoRows)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return postgreserror.NewPostgresError(err)
+	}
+	return nil
+}
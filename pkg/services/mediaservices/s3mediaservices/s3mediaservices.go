@@ -0,0 +1,203 @@
+package s3mediaservices
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/yantology/go-gin-simple-blog-with-fts/pkg/utils/customerror"
+)
+
+// S3BlobStore keeps files in a bucket of an S3-compatible object store such as
+// AWS S3, MinIO or Cloudflare R2. Requests use path-style addressing and are
+// signed with AWS Signature Version 4.
+type S3BlobStore struct {
+	endpoint  *url.URL
+	region    string
+	bucket    string
+	accessKey string
+	secretKey string
+	publicURL string
+	client    *http.Client
+}
+
+// NewS3BlobStore creates a store for bucket at endpoint, e.g. "https://s3.ap-southeast-1.amazonaws.com".
+// Files are served from publicURL, which defaults to <endpoint>/<bucket>.
+func NewS3BlobStore(endpoint, region, bucket, accessKey, secretKey, publicURL string) (*S3BlobStore, error) {
+	u, err := url.Parse(strings.TrimRight(endpoint, "/"))
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		return nil, fmt.Errorf("invalid S3 endpoint %q", endpoint)
+	}
+	if bucket == "" {
+		return nil, errors.New("S3 bucket is not set")
+	}
+	if publicURL == "" {
+		publicURL = u.String() + "/" + bucket
+	}
+
+	return &S3BlobStore{
+		endpoint:  u,
+		region:    region,
+		bucket:    bucket,
+		accessKey: accessKey,
+		secretKey: secretKey,
+		publicURL: strings.TrimRight(publicURL, "/"),
+		client:    &http.Client{Timeout: 60 * time.Second},
+	}, nil
+}
+
+// Put uploads data with a PUT Object request
+func (s *S3BlobStore) Put(ctx context.Context, key string, data []byte, contentType string) *customerror.CustomError {
+	header := http.Header{}
+	header.Set("Content-Type", contentType)
+	header.Set("Cache-Control", "public, max-age=31536000, immutable")
+	if err := s.do(ctx, http.MethodPut, key, data, header); err != nil {
+		return customerror.NewCustomError(err, "failed to store file", 500)
+	}
+	return nil
+}
+
+// Delete removes the object with a DELETE Object request, which succeeds for missing objects too
+func (s *S3BlobStore) Delete(ctx context.Context, key string) *customerror.CustomError {
+	if err := s.do(ctx, http.MethodDelete, key, nil, http.Header{}); err != nil {
+		return customerror.NewCustomError(err, "failed to delete file", 500)
+	}
+	return nil
+}
+
+// URL returns publicURL joined with key
+func (s *S3BlobStore) URL(key string) string {
+	return s.publicURL + "/" + key
+}
+
+func (s *S3BlobStore) do(ctx context.Context, method string, key string, body []byte, header http.Header) error {
+	u := *s.endpoint
+	u.Path = s.endpoint.Path + "/" + s.bucket + "/" + key
+	u.RawPath = s.endpoint.Path + "/" + uriEncode(s.bucket) + "/" + uriEncode(key)
+
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header = header
+	req.ContentLength = int64(len(body))
+	s.sign(req, u.RawPath, body, time.Now().UTC())
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("S3 %s %s: %s: %s", method, key, resp.Status, message)
+	}
+	return nil
+}
+
+// sign adds the AWS Signature Version 4 headers for a request without query
+// parameters. The host and every header set on req are signed.
+func (s *S3BlobStore) sign(req *http.Request, canonicalURI string, body []byte, now time.Time) {
+	amzDate := now.Format("20060102T150405Z")
+	date := now.Format("20060102")
+	payloadHash := sha256Hex(body)
+
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+
+	canonical, signedHeaders := canonicalRequest(req, canonicalURI, payloadHash)
+	scope := date + "/" + s.region + "/s3/aws4_request"
+	signature := hex.EncodeToString(hmacSHA256(signingKey(s.secretKey, date, s.region), stringToSign(amzDate, scope, canonical)))
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		s.accessKey, scope, signedHeaders, signature))
+}
+
+// canonicalRequest returns the canonical form of req that Signature Version 4
+// signs, and the names of its signed headers: the host and every header of
+// req, lowercased and sorted
+func canonicalRequest(req *http.Request, canonicalURI string, payloadHash string) (string, string) {
+	host := req.Host
+	if host == "" {
+		host = req.URL.Host
+	}
+	values := map[string]string{"host": host}
+	for name, value := range req.Header {
+		values[strings.ToLower(name)] = strings.Join(value, ",")
+	}
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var headers strings.Builder
+	for _, name := range names {
+		headers.WriteString(name + ":" + strings.TrimSpace(values[name]) + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+	return strings.Join([]string{
+		req.Method,
+		canonicalURI,
+		"",
+		headers.String(),
+		signedHeaders,
+		payloadHash,
+	}, "\n"), signedHeaders
+}
+
+// stringToSign is what the signature of a canonical request made at amzDate is computed over
+func stringToSign(amzDate string, scope string, canonicalRequest string) string {
+	return strings.Join([]string{
+		"AWS4-HMAC-SHA256",
+		amzDate,
+		scope,
+		sha256Hex([]byte(canonicalRequest)),
+	}, "\n")
+}
+
+// signingKey derives the key signing the requests of a day to S3 in region
+func signingKey(secretKey string, date string, region string) []byte {
+	key := hmacSHA256([]byte("AWS4"+secretKey), date)
+	key = hmacSHA256(key, region)
+	key = hmacSHA256(key, "s3")
+	return hmacSHA256(key, "aws4_request")
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+// uriEncode escapes a path the way Signature Version 4 expects:
+// everything except unreserved characters and "/" is percent-encoded
+func uriEncode(path string) string {
+	var b strings.Builder
+	for _, c := range []byte(path) {
+		switch {
+		case 'a' <= c && c <= 'z', 'A' <= c && c <= 'Z', '0' <= c && c <= '9',
+			c == '-', c == '_', c == '.', c == '~', c == '/':
+			b.WriteByte(c)
+		default:
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
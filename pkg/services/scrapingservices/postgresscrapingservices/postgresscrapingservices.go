@@ -0,0 +1,96 @@
+package postgresscrapingservices
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/yantology/go-gin-simple-blog-with-fts/pkg/models/scrapingmodels"
+	"github.com/yantology/go-gin-simple-blog-with-fts/pkg/utils/customerror"
+	"github.com/yantology/go-gin-simple-blog-with-fts/pkg/utils/customerror/postgreserror"
+)
+
+// PostgresScrapingService provides methods to interact with scraping_rules table in PostgreSQL database
+type PostgresScrapingService struct {
+	db *sql.DB
+}
+
+// NewPostgresScrapingService creates a new instance of PostgresScrapingService
+func NewPostgresScrapingService(db *sql.DB) *PostgresScrapingService {
+	return &PostgresScrapingService{db: db}
+}
+
+// GetScrapingRules retrieves every stored scraping rule
+// Query: Selects all rows of scraping_rules ordered by host
+// Returns:
+// - Success: []*ScrapingRule{{Host: "detik.com", ContentSelector: "div.detail__body-text"...}}
+// - Error: Database errors
+func (s *PostgresScrapingService) GetScrapingRules() ([]*scrapingmodels.ScrapingRule, *customerror.CustomError) {
+	query := `
+        SELECT host, title_selector, content_selector, exclude_selectors, updated_by, updated_at
+        FROM scraping_rules ORDER BY host`
+	rows, err := s.db.Query(query)
+	if err != nil {
+		return nil, postgreserror.NewPostgresError(err)
+	}
+	defer rows.Close()
+
+	rules := []*scrapingmodels.ScrapingRule{}
+	for rows.Next() {
+		var rule scrapingmodels.ScrapingRule
+		if err := rows.Scan(&rule.Host, &rule.TitleSelector, &rule.ContentSelector, pq.Array(&rule.ExcludeSelectors),
+			&rule.UpdatedBy, &rule.UpdatedAt); err != nil {
+			return nil, postgreserror.NewPostgresError(err)
+		}
+		rules = append(rules, &rule)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, postgreserror.NewPostgresError(err)
+	}
+	return rules, nil
+}
+
+// SaveScrapingRule stores the scraping rule for a host
+// Query: Inserts the rule, replacing the row of the same host
+// Returns:
+// - Success: nil, rule.UpdatedAt is filled from the row
+// - Error: Database errors
+func (s *PostgresScrapingService) SaveScrapingRule(rule *scrapingmodels.ScrapingRule) *customerror.CustomError {
+	query := `
+        INSERT INTO scraping_rules (host, title_selector, content_selector, exclude_selectors, updated_by, updated_at)
+        VALUES ($1, $2, $3, $4, $5, $6)
+        ON CONFLICT (host) DO UPDATE SET
+            title_selector = EXCLUDED.title_selector,
+            content_selector = EXCLUDED.content_selector,
+            exclude_selectors = EXCLUDED.exclude_selectors,
+            updated_by = EXCLUDED.updated_by,
+            updated_at = EXCLUDED.updated_at
+        RETURNING updated_at`
+	err := s.db.QueryRow(query, rule.Host, rule.TitleSelector, rule.ContentSelector, pq.Array(rule.ExcludeSelectors),
+		rule.UpdatedBy, time.Now()).Scan(&rule.UpdatedAt)
+	if err != nil {
+		return postgreserror.NewPostgresError(err)
+	}
+	return nil
+}
+
+// DeleteScrapingRule removes the stored scraping rule for a host
+// Query: Deletes the row of the host
+// Returns:
+// - Success: nil
+// - Error: sql.ErrNoRows if no rule is stored for the host, or any other DB error
+func (s *PostgresScrapingService) DeleteScrapingRule(host string) *customerror.CustomError {
+	result, err := s.db.Exec("DELETE FROM scraping_rules WHERE host = $1", host)
+	if err != nil {
+		return postgreserror.NewPostgresError(err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return postgreserror.NewPostgresError(err)
+	}
+	if affected == 0 {
+		return postgreserror.NewPostgresError(sql.ErrNoRows)
+	}
+	return nil
+}
@@ -0,0 +1,128 @@
+// Package extract finds the title and body text of an article on a web page,
+// with per-site CSS selector rules and a readability-style fallback for other sites.
+package extract
+
+import (
+	"io"
+	"net/url"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+	"golang.org/x/net/html"
+)
+
+// Result is the article found on a page
+type Result struct {
+	Title string
+	// Content is plain text, paragraphs separated by a blank line
+	Content string
+	// Rule is the host of the rule used, empty when the fallback extractor was
+	Rule string
+}
+
+// Extract reads the HTML page at pageURL from body and finds the article on
+// it with the rule for the host, or the fallback extractor when there is none.
+func (r *Registry) Extract(pageURL *url.URL, body io.Reader) (*Result, error) {
+	doc, err := goquery.NewDocumentFromReader(body)
+	if err != nil {
+		return nil, err
+	}
+
+	if rule, ok := r.Lookup(pageURL.Hostname()); ok {
+		return ExtractWithRule(doc, rule), nil
+	}
+	return Readability(doc), nil
+}
+
+// ExtractWithRule finds the article on doc with the selectors of rule.
+func ExtractWithRule(doc *goquery.Document, rule Rule) *Result {
+	content := doc.Find(rule.Content)
+	for _, selector := range rule.Exclude {
+		content.Find(selector).Remove()
+	}
+	content.Find(noiseSelector).Remove()
+
+	var paragraphs []string
+	content.Each(func(i int, s *goquery.Selection) {
+		paragraphs = append(paragraphs, blockText(s)...)
+	})
+
+	title := ""
+	if rule.Title != "" {
+		title = cleanText(doc.Find(rule.Title).First().Text())
+	}
+	if title == "" {
+		title = pageTitle(doc)
+	}
+
+	return &Result{
+		Title:   title,
+		Content: strings.Join(paragraphs, "\n\n"),
+		Rule:    rule.Host,
+	}
+}
+
+// noiseSelector matches elements that never hold article text
+const noiseSelector = "script, style, noscript, template, iframe, svg, canvas, form, button, select, textarea"
+
+// pageTitle returns the headline from the page metadata: og:title, the first h1 or the title element.
+func pageTitle(doc *goquery.Document) string {
+	if title, ok := doc.Find(`meta[property="og:title"]`).Attr("content"); ok && cleanText(title) != "" {
+		return cleanText(title)
+	}
+	if title := cleanText(doc.Find("h1").First().Text()); title != "" {
+		return title
+	}
+	return cleanText(doc.Find("title").First().Text())
+}
+
+// cleanText collapses runs of whitespace into single spaces
+func cleanText(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
+
+// blockElements end a paragraph of text
+var blockElements = map[string]bool{
+	"address": true, "article": true, "aside": true, "blockquote": true, "br": true, "dd": true,
+	"div": true, "dl": true, "dt": true, "figcaption": true, "figure": true, "footer": true,
+	"h1": true, "h2": true, "h3": true, "h4": true, "h5": true, "h6": true, "header": true,
+	"hr": true, "li": true, "main": true, "ol": true, "p": true, "pre": true, "section": true,
+	"table": true, "td": true, "th": true, "tr": true, "ul": true,
+}
+
+// blockText returns the text of s split into paragraphs at block elements, so
+// that text placed directly in a container and text in its paragraphs both count.
+func blockText(s *goquery.Selection) []string {
+	var paragraphs []string
+	var current strings.Builder
+
+	flush := func() {
+		if text := cleanText(current.String()); text != "" {
+			paragraphs = append(paragraphs, text)
+		}
+		current.Reset()
+	}
+
+	var walk func(n *html.Node)
+	walk = func(n *html.Node) {
+		switch n.Type {
+		case html.TextNode:
+			current.WriteString(n.Data)
+			return
+		case html.ElementNode:
+			if blockElements[n.Data] {
+				flush()
+				defer flush()
+			}
+		}
+		for child := n.FirstChild; child != nil; child = child.NextSibling {
+			walk(child)
+		}
+	}
+
+	for _, n := range s.Nodes {
+		walk(n)
+		flush()
+	}
+	return paragraphs
+}
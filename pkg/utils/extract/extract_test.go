@@ -0,0 +1,95 @@
+package extract
+
+import (
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const detikPage = `<html><head><title>Judul | detikNews</title></head><body>
+<h1 class="detail__title"> Judul   Berita </h1>
+<div class="detail__body-text">
+Jakarta - Paragraf pembuka langsung di dalam div.
+<p>Paragraf kedua.</p>
+<div class="parallaxindetail">Iklan</div>
+<table class="linksisip"><tr><td>Baca juga: berita lain</td></tr></table>
+<script>track()</script>
+<p>Paragraf <strong>ketiga</strong>.</p>
+</div>
+</body></html>`
+
+const blogPage = `<html><head>
+<meta property="og:title" content="A Long Read">
+<title>A Long Read - Some Blog</title>
+</head><body>
+<nav><a href="/">Home</a> <a href="/about">About</a></nav>
+<div class="sidebar"><p>Subscribe to the newsletter, it is free, weekly, and full of links to other posts.</p></div>
+<div id="main-content">
+<div class="post-body">
+<p>The first paragraph of the article is long enough, with commas, to count as real content here.</p>
+<p>The second paragraph continues the story, adding more detail, more commas, and more words to it.</p>
+<p>Short one.</p>
+</div>
+</div>
+<div class="comments"><p>Great post, thanks, I learned a lot from reading this one today!</p></div>
+<footer><p>Copyright notice with enough text to look like a paragraph on its own.</p></footer>
+</body></html>`
+
+func TestExtractWithRule(t *testing.T) {
+	registry, err := NewRegistry(DefaultRules...)
+	require.NoError(t, err)
+
+	pageURL, _ := url.Parse("https://news.detik.com/berita/d-1/judul")
+	result, err := registry.Extract(pageURL, strings.NewReader(detikPage))
+	require.NoError(t, err)
+
+	assert.Equal(t, "detik.com", result.Rule)
+	assert.Equal(t, "Judul Berita", result.Title)
+	assert.Equal(t, "Jakarta - Paragraf pembuka langsung di dalam div.\n\nParagraf kedua.\n\nParagraf ketiga.", result.Content)
+}
+
+func TestExtractFallback(t *testing.T) {
+	registry, err := NewRegistry(DefaultRules...)
+	require.NoError(t, err)
+
+	pageURL, _ := url.Parse("https://someblog.example/2024/a-long-read")
+	result, err := registry.Extract(pageURL, strings.NewReader(blogPage))
+	require.NoError(t, err)
+
+	assert.Equal(t, "", result.Rule)
+	assert.Equal(t, "A Long Read", result.Title)
+	assert.Contains(t, result.Content, "The first paragraph of the article")
+	assert.Contains(t, result.Content, "The second paragraph continues the story")
+	assert.Contains(t, result.Content, "Short one.")
+	assert.NotContains(t, result.Content, "newsletter")
+	assert.NotContains(t, result.Content, "Great post")
+	assert.NotContains(t, result.Content, "Copyright")
+	assert.NotContains(t, result.Content, "Home")
+}
+
+func TestReadabilityTitle(t *testing.T) {
+	tests := []struct {
+		name string
+		page string
+		want string
+	}{
+		{name: "Open Graph", page: `<meta property="og:title" content="OG"><title>T</title><h1>H</h1>`, want: "OG"},
+		{name: "Heading", page: `<title>T</title><h1> H  1 </h1>`, want: "H 1"},
+		{name: "Title element", page: `<title>T</title>`, want: "T"},
+		{name: "Nothing", page: `<p>text</p>`, want: ""},
+	}
+
+	registry, err := NewRegistry()
+	require.NoError(t, err)
+	pageURL, _ := url.Parse("https://example.com/")
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := registry.Extract(pageURL, strings.NewReader(tt.page))
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, result.Title)
+		})
+	}
+}
@@ -0,0 +1,112 @@
+package extract
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+	"golang.org/x/net/html"
+)
+
+var (
+	// unlikelyCandidates name page furniture around an article
+	unlikelyCandidates = regexp.MustCompile(`(?i)banner|breadcrumb|comment|cookie|disqus|footer|header|menu|modal|nav|newsletter|popup|promo|related|share|sidebar|social|sponsor|subscribe|tags|widget|advert|\bads?\b`)
+	// maybeCandidates rescue elements that name both furniture and content
+	maybeCandidates = regexp.MustCompile(`(?i)and|article|body|column|content|main|shadow|story|text`)
+	positiveWeight  = regexp.MustCompile(`(?i)article|body|content|entry|main|page|post|story|text|detail`)
+	negativeWeight  = regexp.MustCompile(`(?i)comment|footer|meta|related|share|sidebar|social|sponsor|widget|promo|advert`)
+)
+
+// minParagraphLength is the shortest paragraph that counts towards the score of its container
+const minParagraphLength = 25
+
+// Readability finds the article on a page of an unknown site: paragraphs score
+// their parent and grandparent by length and commas, containers named like
+// content are favoured, and the best container minus its links wins.
+func Readability(doc *goquery.Document) *Result {
+	title := pageTitle(doc)
+
+	doc.Find(noiseSelector + ", nav, header, footer, aside").Remove()
+	doc.Find("div, section, ul, ol, table, span, p").Each(func(i int, s *goquery.Selection) {
+		match := s.AttrOr("class", "") + " " + s.AttrOr("id", "")
+		if unlikelyCandidates.MatchString(match) && !maybeCandidates.MatchString(match) {
+			s.Remove()
+		}
+	})
+
+	scores := map[*html.Node]float64{}
+	var candidates []*html.Node
+	addScore := func(n *html.Node, score float64) {
+		if n == nil || n.Type != html.ElementNode {
+			return
+		}
+		if _, ok := scores[n]; !ok {
+			scores[n] = classWeight(n)
+			candidates = append(candidates, n)
+		}
+		scores[n] += score
+	}
+
+	doc.Find("p, pre, td").Each(func(i int, s *goquery.Selection) {
+		text := cleanText(s.Text())
+		if len(text) < minParagraphLength {
+			return
+		}
+
+		score := 1 + float64(strings.Count(text, ",")) + min(float64(len(text))/100, 3)
+		parent := s.Nodes[0].Parent
+		addScore(parent, score)
+		if parent != nil {
+			addScore(parent.Parent, score/2)
+		}
+	})
+
+	var best *goquery.Selection
+	bestScore := 0.0
+	for _, n := range candidates {
+		s := goquery.NewDocumentFromNode(n).Selection
+		score := scores[n] * (1 - linkDensity(s))
+		if best == nil || score > bestScore {
+			best, bestScore = s, score
+		}
+	}
+	if best == nil {
+		best = doc.Find("body")
+	}
+
+	return &Result{
+		Title:   title,
+		Content: strings.Join(blockText(best), "\n\n"),
+	}
+}
+
+// classWeight favours elements whose class or id name content and penalizes furniture
+func classWeight(n *html.Node) float64 {
+	weight := 0.0
+	for _, attr := range n.Attr {
+		if attr.Key != "class" && attr.Key != "id" {
+			continue
+		}
+		if positiveWeight.MatchString(attr.Val) {
+			weight += 25
+		}
+		if negativeWeight.MatchString(attr.Val) {
+			weight -= 25
+		}
+	}
+	return weight
+}
+
+// linkDensity is the share of the text of s inside links
+func linkDensity(s *goquery.Selection) float64 {
+	length := len(cleanText(s.Text()))
+	if length == 0 {
+		return 0
+	}
+
+	links := 0
+	s.Find("a").Each(func(i int, a *goquery.Selection) {
+		links += len(cleanText(a.Text()))
+	})
+	return float64(links) / float64(length)
+}
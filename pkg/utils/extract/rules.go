@@ -0,0 +1,164 @@
+package extract
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/andybalholm/cascadia"
+	"gopkg.in/yaml.v3"
+)
+
+// Rule tells where the article is on the pages of one site. Selectors are CSS
+// selectors; a rule for example.com also covers www.example.com and
+// news.example.com unless those have a rule of their own.
+type Rule struct {
+	Host string `json:"host" yaml:"host"`
+	// Title selects the headline, empty to use the page metadata
+	Title string `json:"title" yaml:"title"`
+	// Content selects the element(s) holding the article body
+	Content string `json:"content" yaml:"content"`
+	// Exclude selects elements inside the content to drop, such as ads and related links
+	Exclude []string `json:"exclude" yaml:"exclude"`
+}
+
+// RulesFile is the layout of a rules file
+type RulesFile struct {
+	Rules []Rule `json:"rules" yaml:"rules"`
+}
+
+// DefaultRules are the rules known without any configuration
+var DefaultRules = []Rule{
+	{
+		Host:    "detik.com",
+		Title:   "h1.detail__title",
+		Content: "div.detail__body-text",
+		Exclude: []string{".parallaxindetail", ".linksisip", ".detail__body-tag", ".staticdetail_container"},
+	},
+}
+
+// NormalizeHost lowercases host and drops a port and a trailing dot, the form rules are keyed by.
+func NormalizeHost(host string) string {
+	host = strings.ToLower(strings.TrimSpace(host))
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	return strings.TrimSuffix(host, ".")
+}
+
+// Validate normalizes the host of rule and checks its selectors.
+func (rule *Rule) Validate() error {
+	rule.Host = NormalizeHost(rule.Host)
+	if rule.Host == "" || strings.ContainsAny(rule.Host, "/ ") {
+		return fmt.Errorf("invalid host %q", rule.Host)
+	}
+	if strings.TrimSpace(rule.Content) == "" {
+		return fmt.Errorf("rule for %s: content selector is required", rule.Host)
+	}
+
+	selectors := append([]string{rule.Content}, rule.Exclude...)
+	if rule.Title != "" {
+		selectors = append(selectors, rule.Title)
+	}
+	for _, selector := range selectors {
+		if _, err := cascadia.ParseGroup(selector); err != nil {
+			return fmt.Errorf("rule for %s: invalid selector %q: %w", rule.Host, selector, err)
+		}
+	}
+	return nil
+}
+
+// LoadRules reads a rules file, YAML or JSON depending on its extension.
+func LoadRules(path string) ([]Rule, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var file RulesFile
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, &file)
+	case ".json":
+		err = json.Unmarshal(data, &file)
+	default:
+		return nil, fmt.Errorf("rules file %s: extension must be .yaml, .yml or .json", path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("rules file %s: %w", path, err)
+	}
+
+	for i := range file.Rules {
+		if err := file.Rules[i].Validate(); err != nil {
+			return nil, fmt.Errorf("rules file %s: %w", path, err)
+		}
+	}
+	return file.Rules, nil
+}
+
+// Registry holds the rules in use, keyed by host. It is safe for concurrent use.
+type Registry struct {
+	mu    sync.RWMutex
+	rules map[string]Rule
+}
+
+// NewRegistry returns a registry holding rules, later rules replacing earlier ones for the same host.
+func NewRegistry(rules ...Rule) (*Registry, error) {
+	r := &Registry{}
+	if err := r.Replace(rules); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// Replace swaps every rule of the registry for rules.
+func (r *Registry) Replace(rules []Rule) error {
+	byHost := make(map[string]Rule, len(rules))
+	for _, rule := range rules {
+		if err := rule.Validate(); err != nil {
+			return err
+		}
+		byHost[rule.Host] = rule
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.rules = byHost
+	return nil
+}
+
+// Rules returns every rule sorted by host.
+func (r *Registry) Rules() []Rule {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	rules := make([]Rule, 0, len(r.rules))
+	for _, rule := range r.rules {
+		rules = append(rules, rule)
+	}
+	sort.Slice(rules, func(i, j int) bool { return rules[i].Host < rules[j].Host })
+	return rules
+}
+
+// Lookup returns the rule for host: its own or that of the closest parent domain.
+func (r *Registry) Lookup(host string) (Rule, bool) {
+	host = NormalizeHost(host)
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	for {
+		if rule, ok := r.rules[host]; ok {
+			return rule, true
+		}
+		dot := strings.IndexByte(host, '.')
+		if dot < 0 {
+			return Rule{}, false
+		}
+		host = host[dot+1:]
+	}
+}
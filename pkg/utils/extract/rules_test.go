@@ -0,0 +1,115 @@
+package extract
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalizeHost(t *testing.T) {
+	tests := []struct {
+		host string
+		want string
+	}{
+		{host: "detik.com", want: "detik.com"},
+		{host: " News.Detik.COM ", want: "news.detik.com"},
+		{host: "example.com:8080", want: "example.com"},
+		{host: "example.com.", want: "example.com"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.host, func(t *testing.T) {
+			assert.Equal(t, tt.want, NormalizeHost(tt.host))
+		})
+	}
+}
+
+func TestRuleValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		rule    Rule
+		wantErr bool
+	}{
+		{name: "Valid", rule: Rule{Host: "Example.com", Title: "h1", Content: "article .body", Exclude: []string{".ad"}}},
+		{name: "Missing host", rule: Rule{Content: "article"}, wantErr: true},
+		{name: "Host with path", rule: Rule{Host: "example.com/news", Content: "article"}, wantErr: true},
+		{name: "Missing content", rule: Rule{Host: "example.com"}, wantErr: true},
+		{name: "Invalid content", rule: Rule{Host: "example.com", Content: "div["}, wantErr: true},
+		{name: "Invalid exclude", rule: Rule{Host: "example.com", Content: "article", Exclude: []string{">>"}}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.rule.Validate()
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, "example.com", tt.rule.Host)
+			}
+		})
+	}
+}
+
+func TestRegistryLookup(t *testing.T) {
+	registry, err := NewRegistry(
+		Rule{Host: "example.com", Content: "article"},
+		Rule{Host: "blog.example.com", Content: ".post"},
+	)
+	require.NoError(t, err)
+
+	tests := []struct {
+		host    string
+		want    string
+		wantHit bool
+	}{
+		{host: "example.com", want: "article", wantHit: true},
+		{host: "www.example.com", want: "article", wantHit: true},
+		{host: "blog.example.com", want: ".post", wantHit: true},
+		{host: "a.blog.example.com:443", want: ".post", wantHit: true},
+		{host: "notexample.com", wantHit: false},
+		{host: "com", wantHit: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.host, func(t *testing.T) {
+			rule, ok := registry.Lookup(tt.host)
+			assert.Equal(t, tt.wantHit, ok)
+			assert.Equal(t, tt.want, rule.Content)
+		})
+	}
+
+	assert.Equal(t, []string{"blog.example.com", "example.com"}, []string{registry.Rules()[0].Host, registry.Rules()[1].Host})
+}
+
+func TestLoadRules(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"rules.yaml": "rules:\n  - host: Example.com\n    title: h1.headline\n    content: div.story\n    exclude: [.ad, .related]\n",
+		"rules.json": `{"rules": [{"host": "example.com", "title": "h1.headline", "content": "div.story", "exclude": [".ad", ".related"]}]}`,
+		"bad.yaml":   "rules:\n  - host: example.com\n    content: 'div['\n",
+		"rules.txt":  "",
+	}
+	for name, content := range files {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644))
+	}
+
+	want := []Rule{{Host: "example.com", Title: "h1.headline", Content: "div.story", Exclude: []string{".ad", ".related"}}}
+	for _, name := range []string{"rules.yaml", "rules.json"} {
+		t.Run(name, func(t *testing.T) {
+			rules, err := LoadRules(filepath.Join(dir, name))
+			require.NoError(t, err)
+			assert.Equal(t, want, rules)
+		})
+	}
+
+	for _, name := range []string{"bad.yaml", "rules.txt", "missing.yaml"} {
+		t.Run(name, func(t *testing.T) {
+			_, err := LoadRules(filepath.Join(dir, name))
+			assert.Error(t, err)
+		})
+	}
+}
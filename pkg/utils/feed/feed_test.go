@@ -0,0 +1,116 @@
+package feed
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testFeed() *Feed {
+	published := time.Date(2024, 12, 10, 8, 0, 0, 0, time.UTC)
+	return &Feed{
+		Title:       "Blog",
+		Description: "Artikel terbaru",
+		Link:        "https://blog.example.com/",
+		FeedURL:     "https://blog.example.com/feeds/articles.rss",
+		Updated:     published.Add(time.Hour),
+		Items: []*Item{
+			{
+				ID:          "https://blog.example.com/api/v1/articles/1",
+				Title:       "Belajar <Go>",
+				Link:        "https://blog.example.com/api/v1/articles/by-slug/belajar-go",
+				Author:      "budi",
+				Summary:     "Pengantar Go",
+				ContentHTML: "<p>Pengantar <strong>Go</strong></p>",
+				Tags:        []string{"golang"},
+				Published:   published,
+				Updated:     published.Add(time.Hour),
+			},
+		},
+	}
+}
+
+func TestRSS(t *testing.T) {
+	body, err := testFeed().RSS()
+	require.NoError(t, err)
+
+	var parsed struct {
+		Version string `xml:"version,attr"`
+		Channel struct {
+			Title string `xml:"title"`
+			Items []struct {
+				Title   string `xml:"title"`
+				GUID    string `xml:"guid"`
+				PubDate string `xml:"pubDate"`
+				Creator string `xml:"http://purl.org/dc/elements/1.1/ creator"`
+				Content string `xml:"http://purl.org/rss/1.0/modules/content/ encoded"`
+			} `xml:"item"`
+		} `xml:"channel"`
+	}
+	require.NoError(t, xml.Unmarshal(body, &parsed))
+	assert.Equal(t, "2.0", parsed.Version)
+	assert.Equal(t, "Blog", parsed.Channel.Title)
+	require.Len(t, parsed.Channel.Items, 1)
+	item := parsed.Channel.Items[0]
+	assert.Equal(t, "Belajar <Go>", item.Title)
+	assert.Equal(t, "https://blog.example.com/api/v1/articles/1", item.GUID)
+	assert.Equal(t, "Tue, 10 Dec 2024 08:00:00 +0000", item.PubDate)
+	assert.Equal(t, "budi", item.Creator)
+	assert.Equal(t, "<p>Pengantar <strong>Go</strong></p>", item.Content)
+}
+
+func TestAtom(t *testing.T) {
+	body, err := testFeed().Atom()
+	require.NoError(t, err)
+
+	var parsed struct {
+		XMLName xml.Name `xml:"http://www.w3.org/2005/Atom feed"`
+		Updated string   `xml:"updated"`
+		Entries []struct {
+			ID      string `xml:"id"`
+			Author  string `xml:"author>name"`
+			Content struct {
+				Type  string `xml:"type,attr"`
+				Value string `xml:",chardata"`
+			} `xml:"content"`
+		} `xml:"entry"`
+	}
+	require.NoError(t, xml.Unmarshal(body, &parsed))
+	assert.Equal(t, "2024-12-10T09:00:00Z", parsed.Updated)
+	require.Len(t, parsed.Entries, 1)
+	assert.Equal(t, "https://blog.example.com/api/v1/articles/1", parsed.Entries[0].ID)
+	assert.Equal(t, "budi", parsed.Entries[0].Author)
+	assert.Equal(t, "html", parsed.Entries[0].Content.Type)
+	assert.Equal(t, "<p>Pengantar <strong>Go</strong></p>", parsed.Entries[0].Content.Value)
+}
+
+func TestJSON(t *testing.T) {
+	body, err := testFeed().JSON()
+	require.NoError(t, err)
+
+	var parsed map[string]any
+	require.NoError(t, json.Unmarshal(body, &parsed))
+	assert.Equal(t, "https://jsonfeed.org/version/1.1", parsed["version"])
+	items := parsed["items"].([]any)
+	require.Len(t, items, 1)
+	item := items[0].(map[string]any)
+	assert.Equal(t, "2024-12-10T08:00:00Z", item["date_published"])
+	assert.Equal(t, []any{map[string]any{"name": "budi"}}, item["authors"])
+}
+
+func TestRenderEmptyFeed(t *testing.T) {
+	for _, format := range []string{FormatRSS, FormatAtom, FormatJSON} {
+		t.Run(format, func(t *testing.T) {
+			body, err := (&Feed{Title: "Kosong"}).Render(format)
+			assert.NoError(t, err)
+			assert.NotEmpty(t, body)
+		})
+	}
+
+	_, err := (&Feed{}).Render("html")
+	assert.Error(t, err)
+}
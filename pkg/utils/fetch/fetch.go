@@ -0,0 +1,347 @@
+// Package fetch downloads web pages for the importers politely and safely:
+// with timeouts, retries, per-host concurrency and rate limits, robots.txt
+// compliance, a response size limit and no access to private addresses.
+package fetch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Fetcher downloads web pages. Implementations must be safe for concurrent use.
+type Fetcher interface {
+	// Fetch requests the page at req.URL. Responses with a status other than
+	// 200 are returned as a *StatusError, except 304 to a conditional request,
+	// returned as ErrNotModified.
+	Fetch(ctx context.Context, req *Request) (*Response, error)
+}
+
+// Request is a page to fetch
+type Request struct {
+	URL string
+	// ETag and LastModified, when set, make the request conditional: the page
+	// is only sent if it changed since the response they were read from
+	ETag         string
+	LastModified string
+}
+
+// Response is a fetched page
+type Response struct {
+	// URL is the address the page was served from, after redirects
+	URL        *url.URL
+	StatusCode int
+	Header     http.Header
+	Body       []byte
+}
+
+var (
+	// ErrBlockedAddress is returned for URLs resolving to loopback, private or other internal addresses
+	ErrBlockedAddress = errors.New("address is not public")
+	// ErrDisallowed is returned for URLs the robots.txt of their site disallows
+	ErrDisallowed = errors.New("disallowed by robots.txt")
+	// ErrTooLarge is returned for responses larger than Options.MaxBodySize
+	ErrTooLarge = errors.New("response too large")
+	// ErrNotModified is returned when the page of a conditional request did not change
+	ErrNotModified = errors.New("page not modified")
+)
+
+// StatusError is returned for responses with a status other than 200
+type StatusError struct {
+	StatusCode int
+	Status     string
+}
+
+func (e *StatusError) Error() string {
+	return "unexpected status " + e.Status
+}
+
+// Options configures an HTTPFetcher
+type Options struct {
+	UserAgent string
+	// Timeout bounds one attempt, from connecting to reading the whole body
+	Timeout time.Duration
+	// MaxBodySize is the largest response body read, in bytes
+	MaxBodySize int64
+	// Retries is how many times a request failing with a network error, 429 or 5xx is repeated
+	Retries int
+	// RetryBackoff is the wait before the first retry, doubled for every further one
+	RetryBackoff time.Duration
+	// HostConcurrency is how many requests to one host may run at once
+	HostConcurrency int
+	// HostInterval is the least time between the start of two requests to one host
+	HostInterval time.Duration
+	// AllowPrivate lets requests reach loopback and private addresses, for tests and intranets
+	AllowPrivate bool
+}
+
+// DefaultOptions are the options of a fetcher not configured otherwise
+var DefaultOptions = Options{
+	UserAgent:       "SimpleBlogImporter/1.0",
+	Timeout:         15 * time.Second,
+	MaxBodySize:     5 << 20,
+	Retries:         2,
+	RetryBackoff:    500 * time.Millisecond,
+	HostConcurrency: 2,
+	HostInterval:    time.Second,
+}
+
+const (
+	// maxRedirects is how many redirects are followed for one request
+	maxRedirects = 5
+	// maxRetryWait caps the wait before a retry, including one asked for with Retry-After
+	maxRetryWait = 30 * time.Second
+)
+
+// HTTPFetcher is a Fetcher over HTTP
+type HTTPFetcher struct {
+	opts   Options
+	client *http.Client
+	hosts  *hostLimits
+	robots *robotsCache
+}
+
+// New returns an HTTPFetcher with opts. UserAgent, Timeout, MaxBodySize,
+// RetryBackoff and HostConcurrency left zero take their value from DefaultOptions.
+func New(opts Options) *HTTPFetcher {
+	if opts.UserAgent == "" {
+		opts.UserAgent = DefaultOptions.UserAgent
+	}
+	if opts.Timeout <= 0 {
+		opts.Timeout = DefaultOptions.Timeout
+	}
+	if opts.MaxBodySize <= 0 {
+		opts.MaxBodySize = DefaultOptions.MaxBodySize
+	}
+	if opts.Retries < 0 {
+		opts.Retries = 0
+	}
+	if opts.RetryBackoff <= 0 {
+		opts.RetryBackoff = DefaultOptions.RetryBackoff
+	}
+	if opts.HostConcurrency <= 0 {
+		opts.HostConcurrency = DefaultOptions.HostConcurrency
+	}
+	if opts.HostInterval < 0 {
+		opts.HostInterval = 0
+	}
+
+	dialer := &net.Dialer{Timeout: 10 * time.Second, KeepAlive: 30 * time.Second}
+	if !opts.AllowPrivate {
+		// Checked on the address actually dialled, so DNS answers and redirects cannot get around it
+		dialer.Control = checkDialAddress
+	}
+	transport := &http.Transport{
+		// No proxy: the address check must see the real destination
+		Proxy:                 nil,
+		DialContext:           dialer.DialContext,
+		ForceAttemptHTTP2:     true,
+		MaxIdleConns:          100,
+		MaxIdleConnsPerHost:   opts.HostConcurrency,
+		IdleConnTimeout:       90 * time.Second,
+		TLSHandshakeTimeout:   10 * time.Second,
+		ResponseHeaderTimeout: opts.Timeout,
+	}
+
+	f := &HTTPFetcher{
+		opts: opts,
+		client: &http.Client{
+			Transport:     transport,
+			CheckRedirect: checkRedirect,
+		},
+		hosts: newHostLimits(opts.HostConcurrency, opts.HostInterval),
+	}
+	f.robots = newRobotsCache(func(ctx context.Context, pageURL *url.URL) (*result, error) {
+		return f.get(ctx, pageURL, nil)
+	})
+	return f
+}
+
+// Fetch requests the page at req.URL once robots.txt allows it, retrying
+// failures that may be temporary.
+func (f *HTTPFetcher) Fetch(ctx context.Context, req *Request) (*Response, error) {
+	pageURL, err := parseURL(req.URL)
+	if err != nil {
+		return nil, err
+	}
+
+	allowed, err := f.robots.allowed(ctx, pageURL, f.opts.UserAgent)
+	if err != nil {
+		return nil, err
+	}
+	if !allowed {
+		return nil, ErrDisallowed
+	}
+
+	var header http.Header
+	if req.ETag != "" || req.LastModified != "" {
+		header = http.Header{}
+		if req.ETag != "" {
+			header.Set("If-None-Match", req.ETag)
+		}
+		if req.LastModified != "" {
+			header.Set("If-Modified-Since", req.LastModified)
+		}
+	}
+
+	for attempt := 0; ; attempt++ {
+		res, err := f.get(ctx, pageURL, header)
+		if err == nil && res.StatusCode == http.StatusNotModified && header != nil {
+			return nil, ErrNotModified
+		}
+		if err == nil && res.StatusCode != http.StatusOK {
+			err = &StatusError{StatusCode: res.StatusCode, Status: res.Status}
+		}
+		if err == nil {
+			return res.Response, nil
+		}
+		if attempt >= f.opts.Retries || !retryable(ctx, err) {
+			return nil, err
+		}
+
+		wait := f.opts.RetryBackoff << attempt
+		wait += rand.N(wait/2 + 1)
+		if res != nil {
+			if after := retryAfter(res.Header); after > wait {
+				wait = after
+			}
+		}
+		if wait > maxRetryWait {
+			wait = maxRetryWait
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// result is a response with its status line, which Response leaves out
+type result struct {
+	*Response
+	Status string
+}
+
+// get makes one GET request with header within the limits of the host of
+// pageURL and reads the body.
+func (f *HTTPFetcher) get(ctx context.Context, pageURL *url.URL, header http.Header) (*result, error) {
+	release, err := f.hosts.acquire(ctx, pageURL.Hostname())
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	ctx, cancel := context.WithTimeout(ctx, f.opts.Timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, pageURL.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	for name, values := range header {
+		req.Header[name] = values
+	}
+	req.Header.Set("User-Agent", f.opts.UserAgent)
+	req.Header.Set("Accept", "text/html,application/xhtml+xml;q=0.9,*/*;q=0.8")
+
+	res, err := f.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	if res.ContentLength > f.opts.MaxBodySize {
+		return nil, fmt.Errorf("%w: %d bytes", ErrTooLarge, res.ContentLength)
+	}
+	body, err := io.ReadAll(io.LimitReader(res.Body, f.opts.MaxBodySize+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(body)) > f.opts.MaxBodySize {
+		return nil, fmt.Errorf("%w: more than %d bytes", ErrTooLarge, f.opts.MaxBodySize)
+	}
+
+	return &result{
+		Response: &Response{
+			URL:        res.Request.URL,
+			StatusCode: res.StatusCode,
+			Header:     res.Header,
+			Body:       body,
+		},
+		Status: res.Status,
+	}, nil
+}
+
+// parseURL accepts absolute http and https URLs only
+func parseURL(rawURL string) (*url.URL, error) {
+	pageURL, err := url.Parse(strings.TrimSpace(rawURL))
+	if err != nil {
+		return nil, err
+	}
+	if pageURL.Scheme != "http" && pageURL.Scheme != "https" {
+		return nil, fmt.Errorf("unsupported url scheme %q", pageURL.Scheme)
+	}
+	if pageURL.Hostname() == "" {
+		return nil, errors.New("url has no host")
+	}
+	return pageURL, nil
+}
+
+func checkRedirect(req *http.Request, via []*http.Request) error {
+	if len(via) > maxRedirects {
+		return fmt.Errorf("stopped after %d redirects", maxRedirects)
+	}
+	if req.URL.Scheme != "http" && req.URL.Scheme != "https" {
+		return fmt.Errorf("redirect to unsupported url scheme %q", req.URL.Scheme)
+	}
+	return nil
+}
+
+// retryable reports whether a request failing with err may succeed when repeated
+func retryable(ctx context.Context, err error) bool {
+	if ctx.Err() != nil || errors.Is(err, ErrBlockedAddress) || errors.Is(err, ErrTooLarge) {
+		return false
+	}
+
+	var statusErr *StatusError
+	if errors.As(err, &statusErr) {
+		return statusErr.StatusCode == http.StatusTooManyRequests || statusErr.StatusCode >= 500
+	}
+
+	// A host that does not exist will not exist a moment later either
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) && dnsErr.IsNotFound {
+		return false
+	}
+	// Connection failures and timeouts of a single attempt
+	var opErr *net.OpError
+	if errors.As(err, &opErr) {
+		return true
+	}
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) && urlErr.Timeout() {
+		return true
+	}
+	return errors.Is(err, context.DeadlineExceeded) || errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF)
+}
+
+// retryAfter reads a Retry-After header given in seconds
+func retryAfter(header http.Header) time.Duration {
+	seconds, err := strconv.Atoi(header.Get("Retry-After"))
+	if err != nil || seconds < 0 {
+		return 0
+	}
+	return time.Duration(seconds) * time.Second
+}
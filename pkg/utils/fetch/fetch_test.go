@@ -0,0 +1,350 @@
+package fetch
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testOptions are fast options reaching httptest servers on the loopback address
+func testOptions() Options {
+	return Options{
+		UserAgent:    "TestBot/1.0",
+		Timeout:      time.Second,
+		Retries:      2,
+		RetryBackoff: time.Millisecond,
+		HostInterval: 0,
+		AllowPrivate: true,
+	}
+}
+
+func TestFetch(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/old":
+			http.Redirect(w, r, "/new", http.StatusMovedPermanently)
+		case "/new":
+			w.Header().Set("Content-Type", "text/html")
+			w.Write([]byte("<p>" + r.UserAgent() + "</p>"))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	f := New(testOptions())
+
+	res, err := f.Fetch(context.Background(), &Request{URL: server.URL + "/old"})
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+	assert.Equal(t, "/new", res.URL.Path)
+	assert.Equal(t, "text/html", res.Header.Get("Content-Type"))
+	assert.Equal(t, "<p>TestBot/1.0</p>", string(res.Body))
+
+	_, err = f.Fetch(context.Background(), &Request{URL: server.URL + "/missing"})
+	var statusErr *StatusError
+	require.ErrorAs(t, err, &statusErr)
+	assert.Equal(t, http.StatusNotFound, statusErr.StatusCode)
+	assert.Equal(t, "unexpected status 404 Not Found", err.Error())
+}
+
+func TestFetchConditional(t *testing.T) {
+	const etag = `"v2"`
+	const lastModified = "Mon, 16 Dec 2024 09:00:00 GMT"
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("ETag", etag)
+		w.Header().Set("Last-Modified", lastModified)
+		if r.Header.Get("If-None-Match") == etag || r.Header.Get("If-Modified-Since") == lastModified {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Write([]byte("<p>page</p>"))
+	}))
+	defer server.Close()
+
+	f := New(testOptions())
+
+	tests := []struct {
+		name    string
+		request Request
+		wantErr error
+	}{
+		{"Unconditional", Request{}, nil},
+		{"Changed", Request{ETag: `"v1"`}, nil},
+		{"Same ETag", Request{ETag: etag}, ErrNotModified},
+		{"Same Last-Modified", Request{LastModified: lastModified}, ErrNotModified},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.request.URL = server.URL + "/page"
+			res, err := f.Fetch(context.Background(), &tt.request)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, "<p>page</p>", string(res.Body))
+			assert.Equal(t, etag, res.Header.Get("ETag"))
+		})
+	}
+}
+
+func TestFetchInvalidURL(t *testing.T) {
+	f := New(testOptions())
+	for _, rawURL := range []string{"ftp://example.com/file", "file:///etc/passwd", "/relative", "http://"} {
+		t.Run(rawURL, func(t *testing.T) {
+			_, err := f.Fetch(context.Background(), &Request{URL: rawURL})
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestFetchRetries(t *testing.T) {
+	tests := []struct {
+		name         string
+		failures     int
+		status       int
+		wantErr      bool
+		wantAttempts int32
+	}{
+		{name: "Recovers after server errors", failures: 2, status: http.StatusServiceUnavailable, wantErr: false, wantAttempts: 3},
+		{name: "Gives up after the retries", failures: 5, status: http.StatusBadGateway, wantErr: true, wantAttempts: 3},
+		{name: "Retries rate limiting", failures: 1, status: http.StatusTooManyRequests, wantErr: false, wantAttempts: 2},
+		{name: "Client errors are not retried", failures: 5, status: http.StatusForbidden, wantErr: true, wantAttempts: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var attempts atomic.Int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path == "/robots.txt" {
+					http.NotFound(w, r)
+					return
+				}
+				if attempts.Add(1) <= int32(tt.failures) {
+					w.WriteHeader(tt.status)
+					return
+				}
+				w.Write([]byte("ok"))
+			}))
+			defer server.Close()
+
+			_, err := New(testOptions()).Fetch(context.Background(), &Request{URL: server.URL + "/page"})
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tt.wantAttempts, attempts.Load())
+		})
+	}
+}
+
+func TestFetchMaxBodySize(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body := strings.Repeat("a", 100)
+		if r.URL.Path == "/chunked" {
+			// Written in two parts so the length is not known up front
+			w.Write([]byte(body))
+			w.(http.Flusher).Flush()
+			w.Write([]byte(body))
+			return
+		}
+		w.Write([]byte(body))
+	}))
+	defer server.Close()
+
+	opts := testOptions()
+	opts.MaxBodySize = 150
+	f := New(opts)
+
+	res, err := f.Fetch(context.Background(), &Request{URL: server.URL + "/small"})
+	require.NoError(t, err)
+	assert.Len(t, res.Body, 100)
+
+	_, err = f.Fetch(context.Background(), &Request{URL: server.URL + "/chunked"})
+	assert.ErrorIs(t, err, ErrTooLarge)
+}
+
+func TestFetchBlocksPrivateAddresses(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		w.Write([]byte("internal"))
+	}))
+	defer server.Close()
+
+	opts := testOptions()
+	opts.AllowPrivate = false
+	f := New(opts)
+
+	for _, rawURL := range []string{server.URL + "/", strings.Replace(server.URL, "127.0.0.1", "localhost", 1) + "/"} {
+		_, err := f.Fetch(context.Background(), &Request{URL: rawURL})
+		assert.ErrorIs(t, err, ErrBlockedAddress, rawURL)
+	}
+	assert.Equal(t, int32(0), requests.Load())
+}
+
+func TestIsPublicAddr(t *testing.T) {
+	tests := []struct {
+		addr string
+		want bool
+	}{
+		{addr: "93.184.216.34", want: true},
+		{addr: "2606:2800:220:1:248:1893:25c8:1946", want: true},
+		{addr: "127.0.0.1", want: false},
+		{addr: "::1", want: false},
+		{addr: "10.1.2.3", want: false},
+		{addr: "172.16.0.1", want: false},
+		{addr: "192.168.1.1", want: false},
+		{addr: "169.254.169.254", want: false},
+		{addr: "100.64.0.1", want: false},
+		{addr: "0.0.0.0", want: false},
+		{addr: "fd00::1", want: false},
+		{addr: "fe80::1", want: false},
+		{addr: "::ffff:127.0.0.1", want: false},
+		{addr: "255.255.255.255", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.addr, func(t *testing.T) {
+			assert.Equal(t, tt.want, IsPublicAddr(netip.MustParseAddr(tt.addr)))
+		})
+	}
+}
+
+func TestFetchRobots(t *testing.T) {
+	var robotsRequests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/robots.txt" {
+			robotsRequests.Add(1)
+			w.Write([]byte("User-agent: *\nDisallow: /private/\n"))
+			return
+		}
+		w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	f := New(testOptions())
+
+	_, err := f.Fetch(context.Background(), &Request{URL: server.URL + "/private/page"})
+	assert.ErrorIs(t, err, ErrDisallowed)
+
+	_, err = f.Fetch(context.Background(), &Request{URL: server.URL + "/public/page"})
+	assert.NoError(t, err)
+	assert.Equal(t, int32(1), robotsRequests.Load())
+}
+
+func TestFetchRobotsServerError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/robots.txt" {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	_, err := New(testOptions()).Fetch(context.Background(), &Request{URL: server.URL + "/page"})
+	assert.ErrorIs(t, err, ErrDisallowed)
+}
+
+func TestFetchHostConcurrency(t *testing.T) {
+	var running, most atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/robots.txt" {
+			http.NotFound(w, r)
+			return
+		}
+		now := running.Add(1)
+		defer running.Add(-1)
+		for {
+			prev := most.Load()
+			if now <= prev || most.CompareAndSwap(prev, now) {
+				break
+			}
+		}
+		time.Sleep(20 * time.Millisecond)
+		w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	opts := testOptions()
+	opts.HostConcurrency = 2
+	f := New(opts)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := f.Fetch(context.Background(), &Request{URL: server.URL + "/page"})
+			assert.NoError(t, err)
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, int32(2), most.Load())
+}
+
+func TestFetchHostInterval(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	opts := testOptions()
+	opts.HostInterval = 50 * time.Millisecond
+	f := New(opts)
+
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		_, err := f.Fetch(context.Background(), &Request{URL: server.URL + "/page"})
+		require.NoError(t, err)
+	}
+	// robots.txt and three pages, each starting 50ms after the one before
+	assert.GreaterOrEqual(t, time.Since(start), 150*time.Millisecond)
+}
+
+func TestFetchCancelAndTimeout(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/robots.txt" {
+			http.NotFound(w, r)
+			return
+		}
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	}))
+	defer server.Close()
+	defer close(release)
+
+	opts := testOptions()
+	opts.Timeout = 50 * time.Millisecond
+	opts.Retries = 0
+	f := New(opts)
+
+	start := time.Now()
+	_, err := f.Fetch(context.Background(), &Request{URL: server.URL + "/slow"})
+	assert.True(t, errors.Is(err, context.DeadlineExceeded) || strings.Contains(err.Error(), "timeout"), err)
+	assert.Less(t, time.Since(start), time.Second)
+
+	opts.Timeout = time.Minute
+	f = New(opts)
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(50*time.Millisecond, cancel)
+	_, err = f.Fetch(ctx, &Request{URL: server.URL + "/slow"})
+	assert.ErrorIs(t, err, context.Canceled)
+}
@@ -0,0 +1,77 @@
+package fetch
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+)
+
+// hostLimits holds the concurrency and rate limit of every host requested so far
+type hostLimits struct {
+	concurrency int
+	interval    time.Duration
+
+	mu    sync.Mutex
+	hosts map[string]*hostLimit
+}
+
+// hostLimit limits the requests to one host
+type hostLimit struct {
+	// slots holds a value for every request running
+	slots chan struct{}
+
+	mu sync.Mutex
+	// next is the earliest time the next request may start
+	next time.Time
+}
+
+func newHostLimits(concurrency int, interval time.Duration) *hostLimits {
+	return &hostLimits{
+		concurrency: concurrency,
+		interval:    interval,
+		hosts:       map[string]*hostLimit{},
+	}
+}
+
+// acquire waits until a request to host may start and returns the function to
+// call once it is done. It gives up when ctx is cancelled.
+func (l *hostLimits) acquire(ctx context.Context, host string) (func(), error) {
+	host = strings.ToLower(host)
+
+	l.mu.Lock()
+	limit, ok := l.hosts[host]
+	if !ok {
+		limit = &hostLimit{slots: make(chan struct{}, l.concurrency)}
+		l.hosts[host] = limit
+	}
+	l.mu.Unlock()
+
+	select {
+	case limit.slots <- struct{}{}:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	release := func() { <-limit.slots }
+
+	// Requests start interval apart, in the order they got a slot
+	limit.mu.Lock()
+	start := time.Now()
+	if limit.next.After(start) {
+		start = limit.next
+	}
+	limit.next = start.Add(l.interval)
+	limit.mu.Unlock()
+
+	if wait := time.Until(start); wait > 0 {
+		timer := time.NewTimer(wait)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			release()
+			return nil, ctx.Err()
+		}
+	}
+	return release, nil
+}
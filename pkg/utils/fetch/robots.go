@@ -0,0 +1,252 @@
+package fetch
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// robotsTTL is how long the robots.txt of a site is kept
+	robotsTTL = time.Hour
+	// robotsErrorTTL is how long a site whose robots.txt could not be read is left alone
+	robotsErrorTTL = time.Minute
+)
+
+// robotsCache keeps the robots.txt rules of every site requested so far
+type robotsCache struct {
+	get func(ctx context.Context, pageURL *url.URL) (*result, error)
+
+	mu    sync.Mutex
+	sites map[string]*robotsEntry
+}
+
+// robotsEntry is the robots.txt of one site, being read until ready is closed
+type robotsEntry struct {
+	ready   chan struct{}
+	rules   *robotsRules
+	err     error
+	expires time.Time
+}
+
+func newRobotsCache(get func(ctx context.Context, pageURL *url.URL) (*result, error)) *robotsCache {
+	return &robotsCache{
+		get:   get,
+		sites: map[string]*robotsEntry{},
+	}
+}
+
+// allowed reports whether the robots.txt of the site of pageURL lets userAgent fetch it.
+// The robots.txt is read once per site and shared by concurrent callers.
+func (c *robotsCache) allowed(ctx context.Context, pageURL *url.URL, userAgent string) (bool, error) {
+	site := &url.URL{Scheme: pageURL.Scheme, Host: strings.ToLower(pageURL.Host)}
+	if pageURL.EscapedPath() == "/robots.txt" {
+		return true, nil
+	}
+
+	for {
+		c.mu.Lock()
+		entry, ok := c.sites[site.String()]
+		if ok && entry.loaded() && time.Now().After(entry.expires) {
+			ok = false
+		}
+		if !ok {
+			entry = &robotsEntry{ready: make(chan struct{})}
+			c.sites[site.String()] = entry
+			c.mu.Unlock()
+			c.load(ctx, site, entry)
+		} else {
+			c.mu.Unlock()
+		}
+
+		select {
+		case <-entry.ready:
+		case <-ctx.Done():
+			return false, ctx.Err()
+		}
+		if entry.err != nil {
+			return false, entry.err
+		}
+		if entry.rules == nil {
+			// The caller loading it gave up, try again
+			if err := ctx.Err(); err != nil {
+				return false, err
+			}
+			continue
+		}
+		return entry.rules.allowed(userAgent, pageURL), nil
+	}
+}
+
+func (e *robotsEntry) loaded() bool {
+	select {
+	case <-e.ready:
+		return true
+	default:
+		return false
+	}
+}
+
+// load reads the robots.txt of site into entry. Following RFC 9309 a missing
+// robots.txt allows everything and a failing server disallows everything.
+func (c *robotsCache) load(ctx context.Context, site *url.URL, entry *robotsEntry) {
+	defer close(entry.ready)
+
+	res, err := c.get(ctx, site.JoinPath("robots.txt"))
+	if ctx.Err() != nil {
+		// Not cached, the next caller reads it again
+		c.mu.Lock()
+		delete(c.sites, site.String())
+		c.mu.Unlock()
+		return
+	}
+
+	entry.expires = time.Now().Add(robotsTTL)
+	switch {
+	case err != nil:
+		entry.err = fmt.Errorf("robots.txt: %w", err)
+		entry.expires = time.Now().Add(robotsErrorTTL)
+	case res.StatusCode >= 200 && res.StatusCode < 300:
+		entry.rules = parseRobots(res.Body)
+	case res.StatusCode >= 500 || res.StatusCode == http.StatusTooManyRequests:
+		entry.rules = &robotsRules{disallowAll: true}
+		entry.expires = time.Now().Add(robotsErrorTTL)
+	default:
+		entry.rules = &robotsRules{}
+	}
+}
+
+// robotsRules are the groups of a robots.txt file
+type robotsRules struct {
+	groups      []*robotsGroup
+	disallowAll bool
+}
+
+// robotsGroup are the rules for the user agents it names
+type robotsGroup struct {
+	agents []string
+	rules  []robotsRule
+}
+
+type robotsRule struct {
+	allow   bool
+	pattern string
+}
+
+// parseRobots reads the user-agent, allow and disallow lines of a robots.txt file, ignoring the rest
+func parseRobots(body []byte) *robotsRules {
+	rules := &robotsRules{}
+	var group *robotsGroup
+
+	scanner := bufio.NewScanner(bytes.NewReader(body))
+	for scanner.Scan() {
+		line, _, _ := strings.Cut(scanner.Text(), "#")
+		key, value, found := strings.Cut(line, ":")
+		if !found {
+			continue
+		}
+		key = strings.ToLower(strings.TrimSpace(key))
+		value = strings.TrimSpace(value)
+
+		switch key {
+		case "user-agent":
+			// A user-agent line after rules starts a new group
+			if group == nil || len(group.rules) > 0 {
+				group = &robotsGroup{}
+				rules.groups = append(rules.groups, group)
+			}
+			group.agents = append(group.agents, strings.ToLower(value))
+		case "allow", "disallow":
+			if group == nil {
+				continue
+			}
+			if value == "" {
+				// "Disallow:" with no path allows everything; record it so the group ends here
+				group.rules = append(group.rules, robotsRule{allow: true})
+				continue
+			}
+			group.rules = append(group.rules, robotsRule{allow: key == "allow", pattern: value})
+		}
+	}
+	return rules
+}
+
+// allowed reports whether userAgent may fetch pageURL. The rules of the groups
+// naming the product token of userAgent apply, else those of the * groups; the
+// longest matching pattern decides, allow winning a tie.
+func (r *robotsRules) allowed(userAgent string, pageURL *url.URL) bool {
+	if r.disallowAll {
+		return false
+	}
+
+	token, _, _ := strings.Cut(userAgent, "/")
+	token = strings.ToLower(strings.TrimSpace(token))
+	var matched, wildcard []robotsRule
+	for _, group := range r.groups {
+		for _, agent := range group.agents {
+			if agent == token {
+				matched = append(matched, group.rules...)
+			} else if agent == "*" {
+				wildcard = append(wildcard, group.rules...)
+			}
+		}
+	}
+	if matched == nil {
+		matched = wildcard
+	}
+
+	path := pageURL.EscapedPath()
+	if path == "" {
+		path = "/"
+	}
+	if pageURL.RawQuery != "" {
+		path += "?" + pageURL.RawQuery
+	}
+
+	allowed := true
+	longest := -1
+	for _, rule := range matched {
+		if rule.pattern == "" || !matchRobotsPattern(rule.pattern, path) {
+			continue
+		}
+		if len(rule.pattern) > longest || (len(rule.pattern) == longest && rule.allow) {
+			longest = len(rule.pattern)
+			allowed = rule.allow
+		}
+	}
+	return allowed
+}
+
+// matchRobotsPattern matches path against a robots.txt path pattern, where *
+// stands for any characters and a trailing $ anchors the end of the path.
+func matchRobotsPattern(pattern string, path string) bool {
+	anchored := strings.HasSuffix(pattern, "$")
+	pattern = strings.TrimSuffix(pattern, "$")
+
+	parts := strings.Split(pattern, "*")
+	if !strings.HasPrefix(path, parts[0]) {
+		return false
+	}
+	rest := path[len(parts[0]):]
+	if len(parts) == 1 {
+		return !anchored || rest == ""
+	}
+
+	for i, part := range parts[1:] {
+		if anchored && i == len(parts)-2 {
+			return strings.HasSuffix(rest, part)
+		}
+		index := strings.Index(rest, part)
+		if index < 0 {
+			return false
+		}
+		rest = rest[index+len(part):]
+	}
+	return true
+}
@@ -0,0 +1,84 @@
+package fetch
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRobotsAllowed(t *testing.T) {
+	robots := parseRobots([]byte(`# comment
+User-agent: *
+Disallow: /private/
+Allow: /private/open
+Disallow: /*.pdf$
+Disallow: /search?
+
+User-agent: OtherBot
+User-agent: TestBot
+Disallow: /
+Allow: /news/
+
+Sitemap: https://example.com/sitemap.xml
+`))
+
+	tests := []struct {
+		name      string
+		userAgent string
+		path      string
+		want      bool
+	}{
+		{name: "Unlisted path", userAgent: "Importer/1.0", path: "/articles/1", want: true},
+		{name: "Disallowed prefix", userAgent: "Importer/1.0", path: "/private/notes", want: false},
+		{name: "Longer allow wins", userAgent: "Importer/1.0", path: "/private/open/page", want: true},
+		{name: "Anchored wildcard", userAgent: "Importer/1.0", path: "/files/report.pdf", want: false},
+		{name: "Anchored wildcard, longer path", userAgent: "Importer/1.0", path: "/files/report.pdf.html", want: true},
+		{name: "Query string", userAgent: "Importer/1.0", path: "/search?q=go", want: false},
+		{name: "Named group replaces *", userAgent: "TestBot/1.0 (+https://example.com)", path: "/articles/1", want: false},
+		{name: "Named group allow", userAgent: "testbot/2.0", path: "/news/today", want: true},
+		{name: "Named group ignores * rules", userAgent: "TestBot/1.0", path: "/news/report.pdf", want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pageURL, err := url.Parse("https://example.com" + tt.path)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, robots.allowed(tt.userAgent, pageURL))
+		})
+	}
+}
+
+func TestRobotsEmptyDisallow(t *testing.T) {
+	robots := parseRobots([]byte("User-agent: *\nDisallow:\n"))
+	pageURL, _ := url.Parse("https://example.com/anything")
+	assert.True(t, robots.allowed("Importer/1.0", pageURL))
+
+	assert.False(t, (&robotsRules{disallowAll: true}).allowed("Importer/1.0", pageURL))
+	assert.True(t, (&robotsRules{}).allowed("Importer/1.0", pageURL))
+}
+
+func TestMatchRobotsPattern(t *testing.T) {
+	tests := []struct {
+		pattern string
+		path    string
+		want    bool
+	}{
+		{pattern: "/", path: "/anything", want: true},
+		{pattern: "/fish", path: "/fish.html", want: true},
+		{pattern: "/fish", path: "/Fish", want: false},
+		{pattern: "/fish$", path: "/fish", want: true},
+		{pattern: "/fish$", path: "/fish/", want: false},
+		{pattern: "/*.php", path: "/folder/index.php?x=1", want: true},
+		{pattern: "/*.php$", path: "/folder/index.php?x=1", want: false},
+		{pattern: "/a*b*c", path: "/a-b-c", want: true},
+		{pattern: "/a*b*c", path: "/a-c-b", want: false},
+		{pattern: "/*$", path: "/anything", want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.pattern+" "+tt.path, func(t *testing.T) {
+			assert.Equal(t, tt.want, matchRobotsPattern(tt.pattern, tt.path))
+		})
+	}
+}
@@ -0,0 +1,103 @@
+package markup
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/microcosm-cc/bluemonday"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/extension"
+	gmhtml "github.com/yuin/goldmark/renderer/html"
+)
+
+// Content formats an article can be written in
+const (
+	FormatMarkdown = "markdown"
+	FormatHTML     = "html"
+	FormatPlain    = "plain"
+)
+
+// markdown renders GitHub flavored Markdown. Raw HTML is passed through
+// because the sanitizer below decides what survives.
+var markdown = goldmark.New(
+	goldmark.WithExtensions(extension.GFM),
+	goldmark.WithRendererOptions(gmhtml.WithUnsafe()),
+)
+
+// policy is the allowlist every rendered article goes through. It keeps
+// formatting, links, images and tables and drops scripts, styles, event
+// handlers and javascript: URLs.
+var policy = newPolicy()
+
+// stripPolicy removes every tag, leaving only text
+var stripPolicy = bluemonday.StrictPolicy()
+
+func newPolicy() *bluemonday.Policy {
+	p := bluemonday.UGCPolicy()
+	p.RequireNoFollowOnLinks(true)
+	p.AddTargetBlankToFullyQualifiedLinks(true)
+	p.AllowAttrs("class").Matching(bluemonday.SpaceSeparatedTokens).OnElements("code")
+	return p
+}
+
+// IsValidFormat reports whether format is one of the supported content formats
+func IsValidFormat(format string) bool {
+	return format == FormatMarkdown || format == FormatHTML || format == FormatPlain
+}
+
+// Render turns content written in format into sanitized HTML that is safe to embed in a page.
+// Plain text is escaped and split into paragraphs on blank lines.
+func Render(format string, content string) (string, error) {
+	switch format {
+	case FormatMarkdown:
+		var buf bytes.Buffer
+		if err := markdown.Convert([]byte(content), &buf); err != nil {
+			return "", err
+		}
+		return policy.Sanitize(buf.String()), nil
+	case FormatHTML:
+		return policy.Sanitize(content), nil
+	case FormatPlain:
+		return renderPlain(content), nil
+	default:
+		return "", fmt.Errorf("unknown content format %q", format)
+	}
+}
+
+func renderPlain(content string) string {
+	content = strings.ReplaceAll(content, "\r\n", "\n")
+
+	var b strings.Builder
+	for _, paragraph := range strings.Split(content, "\n\n") {
+		paragraph = strings.TrimSpace(paragraph)
+		if paragraph == "" {
+			continue
+		}
+		b.WriteString("<p>")
+		b.WriteString(strings.ReplaceAll(html.EscapeString(paragraph), "\n", "<br>"))
+		b.WriteString("</p>\n")
+	}
+	return b.String()
+}
+
+// Excerpt returns the text of rendered HTML with whitespace collapsed, cut at a
+// word boundary to at most maxLength characters. A cut excerpt ends with an ellipsis,
+// which counts towards maxLength.
+func Excerpt(renderedHTML string, maxLength int) string {
+	// Tags are replaced by a space so words in adjacent blocks do not run together
+	text := stripPolicy.Sanitize(strings.ReplaceAll(renderedHTML, "<", " <"))
+	text = strings.Join(strings.Fields(html.UnescapeString(text)), " ")
+	if utf8.RuneCountInString(text) <= maxLength {
+		return text
+	}
+
+	runes := []rune(text)
+	cut := string(runes[:maxLength-1])
+	if i := strings.LastIndexByte(cut, ' '); i > 0 {
+		cut = cut[:i]
+	}
+	return strings.TrimRight(cut, " .,;:") + "…"
+}
@@ -0,0 +1,88 @@
+package markup
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRender(t *testing.T) {
+	tests := []struct {
+		name     string
+		format   string
+		content  string
+		expected string
+		wantErr  bool
+	}{
+		{
+			name:     "Markdown",
+			format:   FormatMarkdown,
+			content:  "# Judul\n\nTeks **tebal**.",
+			expected: "<h1>Judul</h1>\n<p>Teks <strong>tebal</strong>.</p>\n",
+		},
+		{
+			name:     "Markdown drops raw scripts",
+			format:   FormatMarkdown,
+			content:  "Halo <script>alert(1)</script>",
+			expected: "<p>Halo </p>\n",
+		},
+		{
+			name:     "HTML drops event handlers and javascript links",
+			format:   FormatHTML,
+			content:  `<p onclick="x()">Hi <a href="javascript:alert(1)">there</a></p>`,
+			expected: "<p>Hi there</p>",
+		},
+		{
+			name:     "Plain text is escaped",
+			format:   FormatPlain,
+			content:  "a < b\nc\r\n\r\n<i>d</i>",
+			expected: "<p>a &lt; b<br>c</p>\n<p>&lt;i&gt;d&lt;/i&gt;</p>\n",
+		},
+		{
+			name:    "Unknown format",
+			format:  "rtf",
+			content: "x",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rendered, err := Render(tt.format, tt.content)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, rendered)
+		})
+	}
+}
+
+func TestExcerpt(t *testing.T) {
+	tests := []struct {
+		name      string
+		html      string
+		maxLength int
+		expected  string
+	}{
+		{
+			name:      "Short text is kept",
+			html:      "<h1>Judul</h1>\n<p>Isi &amp; teks</p>",
+			maxLength: 100,
+			expected:  "Judul Isi & teks",
+		},
+		{
+			name:      "Cut at word boundary",
+			html:      "<p>belajar golang untuk pemula</p>",
+			maxLength: 16,
+			expected:  "belajar golang…",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, Excerpt(tt.html, tt.maxLength))
+		})
+	}
+}
@@ -0,0 +1,91 @@
+package sitemap
+
+import (
+	"encoding/xml"
+	"time"
+)
+
+// MaxURLs is the largest number of URLs the sitemap protocol allows in one file
+const MaxURLs = 50000
+
+const namespace = "http://www.sitemaps.org/schemas/sitemap/0.9"
+
+// URL is a page listed in a sitemap
+type URL struct {
+	Loc     string
+	LastMod time.Time
+}
+
+type urlSet struct {
+	XMLName xml.Name   `xml:"urlset"`
+	NS      string     `xml:"xmlns,attr"`
+	URLs    []*xmlLink `xml:"url"`
+}
+
+type sitemapIndex struct {
+	XMLName  xml.Name   `xml:"sitemapindex"`
+	NS       string     `xml:"xmlns,attr"`
+	Sitemaps []*xmlLink `xml:"sitemap"`
+}
+
+type xmlLink struct {
+	Loc     string `xml:"loc"`
+	LastMod string `xml:"lastmod,omitempty"`
+}
+
+func newXMLLink(u URL) *xmlLink {
+	link := &xmlLink{Loc: u.Loc}
+	if !u.LastMod.IsZero() {
+		link.LastMod = u.LastMod.UTC().Format(time.RFC3339)
+	}
+	return link
+}
+
+// Build writes urls as sitemap files. Up to MaxURLs they fit in a single file and
+// index is nil. Beyond that urls are split into pages of MaxURLs and index lists
+// every page at pageURL(n), n counting from 1, dated by its most recent URL.
+func Build(urls []URL, pageURL func(n int) string) (index []byte, pages [][]byte, err error) {
+	var entries []URL
+	for start := 0; start == 0 || start < len(urls); start += MaxURLs {
+		chunk := urls[start:min(start+MaxURLs, len(urls))]
+
+		page, err := marshal(&urlSet{NS: namespace, URLs: links(chunk)})
+		if err != nil {
+			return nil, nil, err
+		}
+		pages = append(pages, page)
+
+		entry := URL{Loc: pageURL(len(pages))}
+		for _, u := range chunk {
+			if u.LastMod.After(entry.LastMod) {
+				entry.LastMod = u.LastMod
+			}
+		}
+		entries = append(entries, entry)
+	}
+
+	if len(pages) == 1 {
+		return nil, pages, nil
+	}
+	index, err = marshal(&sitemapIndex{NS: namespace, Sitemaps: links(entries)})
+	if err != nil {
+		return nil, nil, err
+	}
+	return index, pages, nil
+}
+
+func links(urls []URL) []*xmlLink {
+	result := []*xmlLink{}
+	for _, u := range urls {
+		result = append(result, newXMLLink(u))
+	}
+	return result
+}
+
+func marshal(v any) ([]byte, error) {
+	body, err := xml.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), append(body, '\n')...), nil
+}
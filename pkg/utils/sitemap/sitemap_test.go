@@ -0,0 +1,78 @@
+package sitemap
+
+import (
+	"encoding/xml"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func pageURL(n int) string {
+	return fmt.Sprintf("https://blog.example.com/sitemaps/articles-%d.xml", n)
+}
+
+type parsedSet struct {
+	URLs []struct {
+		Loc     string `xml:"loc"`
+		LastMod string `xml:"lastmod"`
+	} `xml:"url"`
+}
+
+func TestBuildSingleFile(t *testing.T) {
+	urls := []URL{
+		{Loc: "https://blog.example.com/a", LastMod: time.Date(2024, 12, 10, 8, 0, 0, 0, time.UTC)},
+		{Loc: "https://blog.example.com/b?x=1&y=2"},
+	}
+
+	index, pages, err := Build(urls, pageURL)
+	require.NoError(t, err)
+	assert.Nil(t, index)
+	require.Len(t, pages, 1)
+
+	var parsed parsedSet
+	require.NoError(t, xml.Unmarshal(pages[0], &parsed))
+	require.Len(t, parsed.URLs, 2)
+	assert.Equal(t, "2024-12-10T08:00:00Z", parsed.URLs[0].LastMod)
+	assert.Equal(t, "https://blog.example.com/b?x=1&y=2", parsed.URLs[1].Loc)
+	assert.Empty(t, parsed.URLs[1].LastMod)
+}
+
+func TestBuildEmpty(t *testing.T) {
+	index, pages, err := Build(nil, pageURL)
+	require.NoError(t, err)
+	assert.Nil(t, index)
+	require.Len(t, pages, 1)
+	assert.Contains(t, string(pages[0]), "<urlset")
+}
+
+func TestBuildIndex(t *testing.T) {
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	urls := make([]URL, MaxURLs+1)
+	for i := range urls {
+		urls[i] = URL{Loc: fmt.Sprintf("https://blog.example.com/%d", i), LastMod: base.Add(time.Duration(i%100) * time.Hour)}
+	}
+
+	index, pages, err := Build(urls, pageURL)
+	require.NoError(t, err)
+	require.Len(t, pages, 2)
+
+	var parsed struct {
+		Sitemaps []struct {
+			Loc     string `xml:"loc"`
+			LastMod string `xml:"lastmod"`
+		} `xml:"sitemap"`
+	}
+	require.NoError(t, xml.Unmarshal(index, &parsed))
+	require.Len(t, parsed.Sitemaps, 2)
+	assert.Equal(t, pageURL(1), parsed.Sitemaps[0].Loc)
+	assert.Equal(t, "2024-01-05T03:00:00Z", parsed.Sitemaps[0].LastMod)
+	assert.Equal(t, pageURL(2), parsed.Sitemaps[1].Loc)
+	assert.Equal(t, "2024-01-01T00:00:00Z", parsed.Sitemaps[1].LastMod)
+
+	var last parsedSet
+	require.NoError(t, xml.Unmarshal(pages[1], &last))
+	assert.Len(t, last.URLs, 1)
+}
@@ -0,0 +1,100 @@
+package slug
+
+import (
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// transliterations covers letters that do not decompose into an ASCII base letter
+var transliterations = map[rune]string{
+	'ß': "ss",
+	'æ': "ae",
+	'œ': "oe",
+	'ø': "o",
+	'đ': "d",
+	'ð': "d",
+	'ł': "l",
+	'þ': "th",
+	'ı': "i",
+}
+
+// indonesianWords spells out symbols that carry meaning in Indonesian titles
+var indonesianWords = map[rune]string{
+	'&': "dan",
+	'%': "persen",
+	'+': "plus",
+	'@': "di",
+}
+
+// apostrophes join the parts of a word instead of separating them, as in "Jum'at" or "Qur'an"
+var apostrophes = map[rune]bool{
+	'\'': true,
+	'’':  true,
+	'‘':  true,
+	'`':  true,
+}
+
+// Make turns text into a lowercase, URL-safe slug of a-z, 0-9 and single hyphens.
+// Accented letters are reduced to their base letter, so "Café Déjà Vu" becomes
+// "cafe-deja-vu". The result is cut at a word boundary to at most maxLength bytes;
+// a maxLength of zero or less means no limit.
+func Make(text string, maxLength int) string {
+	return build(text, maxLength, nil, false)
+}
+
+// MakeIndonesian works like Make but reads the text as Indonesian: symbols such as
+// "&" and "%" become the words "dan" and "persen", and apostrophes are dropped so
+// "Tips & Trik Jum'at" becomes "tips-dan-trik-jumat".
+func MakeIndonesian(text string, maxLength int) string {
+	return build(text, maxLength, indonesianWords, true)
+}
+
+func build(text string, maxLength int, words map[rune]string, joinApostrophes bool) string {
+	var b strings.Builder
+	pendingHyphen := false
+
+	write := func(s string) {
+		if pendingHyphen && b.Len() > 0 {
+			b.WriteByte('-')
+		}
+		pendingHyphen = false
+		b.WriteString(s)
+	}
+
+	for _, r := range norm.NFD.String(strings.ToLower(text)) {
+		switch {
+		case unicode.Is(unicode.Mn, r):
+			// combining accent left over from decomposition
+		case r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)):
+			write(string(r))
+		case transliterations[r] != "":
+			write(transliterations[r])
+		case words[r] != "":
+			pendingHyphen = true
+			write(words[r])
+			pendingHyphen = true
+		case joinApostrophes && apostrophes[r]:
+			// keep the word together
+		default:
+			pendingHyphen = true
+		}
+	}
+
+	return truncate(b.String(), maxLength)
+}
+
+// truncate shortens a slug to maxLength bytes, preferring to cut at a hyphen
+func truncate(s string, maxLength int) string {
+	if maxLength <= 0 || len(s) <= maxLength {
+		return s
+	}
+
+	cutsWord := s[maxLength] != '-'
+	s = s[:maxLength]
+	if i := strings.LastIndexByte(s, '-'); cutsWord && i > 0 {
+		s = s[:i]
+	}
+	return strings.Trim(s, "-")
+}
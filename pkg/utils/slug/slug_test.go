@@ -0,0 +1,105 @@
+package slug
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMake(t *testing.T) {
+	tests := []struct {
+		name      string
+		text      string
+		maxLength int
+		expected  string
+	}{
+		{
+			name:      "Plain words",
+			text:      "Hello World",
+			maxLength: 0,
+			expected:  "hello-world",
+		},
+		{
+			name:      "Accented letters",
+			text:      "Café Déjà Vu",
+			maxLength: 0,
+			expected:  "cafe-deja-vu",
+		},
+		{
+			name:      "Letters without decomposition",
+			text:      "Straße Øresund Łódź",
+			maxLength: 0,
+			expected:  "strasse-oresund-lodz",
+		},
+		{
+			name:      "Punctuation and spaces collapse",
+			text:      "  Go -- PostgreSQL!!  ",
+			maxLength: 0,
+			expected:  "go-postgresql",
+		},
+		{
+			name:      "Only symbols",
+			text:      "!!! ???",
+			maxLength: 0,
+			expected:  "",
+		},
+		{
+			name:      "Cut at word boundary",
+			text:      "belajar golang untuk pemula",
+			maxLength: 16,
+			expected:  "belajar-golang",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, Make(tt.text, tt.maxLength))
+		})
+	}
+}
+
+func TestMakeIndonesian(t *testing.T) {
+	tests := []struct {
+		name      string
+		text      string
+		maxLength int
+		expected  string
+	}{
+		{
+			name:      "Symbols become words",
+			text:      "Tips & Trik: Diskon 50% + Gratis Ongkir",
+			maxLength: 0,
+			expected:  "tips-dan-trik-diskon-50-persen-plus-gratis-ongkir",
+		},
+		{
+			name:      "Apostrophes join words",
+			text:      "Sholat Jum’at dan Qur'an",
+			maxLength: 0,
+			expected:  "sholat-jumat-dan-quran",
+		},
+		{
+			name:      "Accented letters",
+			text:      "Resensi Café di Jakarta",
+			maxLength: 0,
+			expected:  "resensi-cafe-di-jakarta",
+		},
+		{
+			name:      "Symbol at the edges",
+			text:      "& Lainnya",
+			maxLength: 0,
+			expected:  "dan-lainnya",
+		},
+		{
+			name:      "Cut at word boundary",
+			text:      "Belajar Go & PostgreSQL",
+			maxLength: 14,
+			expected:  "belajar-go-dan",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, MakeIndonesian(tt.text, tt.maxLength))
+		})
+	}
+}